@@ -0,0 +1,24 @@
+#!/usr/bin/env bash
+# Runs the benchmarks of the collections and writes the results in the format of benchstat.
+# Compare two runs with `benchstat old.txt new.txt` (go install golang.org/x/perf/cmd/benchstat@latest).
+#
+# Usage: hack/benchmark.sh <output file> [benchmark regexp]
+# Environment variables:
+#   COUNT: the number of times each benchmark runs, 10 by default
+#   CPU:   the values of -cpu, "1,4" by default
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+if [[ $# -lt 1 ]]; then
+  echo "Usage: $0 <output file> [benchmark regexp]" >&2
+  exit 1
+fi
+
+output=$1
+bench=${2:-.}
+
+cd "$(dirname "${BASH_SOURCE[0]}")/.."
+go test ./pkg/util/collection/ -run '^$' -bench "${bench}" -benchmem \
+  -count "${COUNT:-10}" -cpu "${CPU:-1,4}" | tee "${output}"
@@ -0,0 +1,129 @@
+package util
+
+import (
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/linxiaokun528/go-kit/pkg/util/collection"
+)
+
+// AtomicValue holds a value of T that can be loaded and stored atomically. Unlike atomic.Value, it's typed and can
+// hold nil interfaces. Every Store keeps a new copy of the value, like atomic.Pointer, which needs Go 1.19.
+// The zero value holds the zero value of T.
+type AtomicValue[T any] struct {
+	pointer unsafe.Pointer // *T
+}
+
+func NewAtomicValue[T any](value T) *AtomicValue[T] {
+	return &AtomicValue[T]{pointer: unsafe.Pointer(&value)}
+}
+
+func (a *AtomicValue[T]) Load() (value T) {
+	if pointer := (*T)(atomic.LoadPointer(&a.pointer)); pointer != nil {
+		return *pointer
+	}
+	return
+}
+
+func (a *AtomicValue[T]) Store(value T) {
+	atomic.StorePointer(&a.pointer, unsafe.Pointer(&value))
+}
+
+// Swap stores value and returns the old one
+func (a *AtomicValue[T]) Swap(value T) (old T) {
+	if pointer := (*T)(atomic.SwapPointer(&a.pointer, unsafe.Pointer(&value))); pointer != nil {
+		return *pointer
+	}
+	return
+}
+
+// CompareAndSwap stores newValue only if the current value equals expectedOld according to equaler,
+// because T may not be comparable. It returns false if newValue is not stored.
+func (a *AtomicValue[T]) CompareAndSwap(expectedOld T, newValue T, equaler collection.Equaler[T]) bool {
+	for {
+		pointer := atomic.LoadPointer(&a.pointer)
+		var current T
+		if pointer != nil {
+			current = *(*T)(pointer)
+		}
+		if !equaler(current, expectedOld) {
+			return false
+		}
+		if atomic.CompareAndSwapPointer(&a.pointer, pointer, unsafe.Pointer(&newValue)) {
+			return true
+		}
+		// Another goroutine has stored a value in between, which may still equal expectedOld
+	}
+}
+
+// AtomicCounter is an int64 that can be updated atomically. The zero value is 0.
+type AtomicCounter struct {
+	value int64
+}
+
+func (a *AtomicCounter) Load() int64 {
+	return atomic.LoadInt64(&a.value)
+}
+
+func (a *AtomicCounter) Store(value int64) {
+	atomic.StoreInt64(&a.value, value)
+}
+
+// Add adds delta and returns the new value
+func (a *AtomicCounter) Add(delta int64) int64 {
+	return atomic.AddInt64(&a.value, delta)
+}
+
+// Inc adds 1 and returns the new value
+func (a *AtomicCounter) Inc() int64 {
+	return a.Add(1)
+}
+
+// Dec subtracts 1 and returns the new value
+func (a *AtomicCounter) Dec() int64 {
+	return a.Add(-1)
+}
+
+// Swap stores value and returns the old one
+func (a *AtomicCounter) Swap(value int64) (old int64) {
+	return atomic.SwapInt64(&a.value, value)
+}
+
+func (a *AtomicCounter) CompareAndSwap(expectedOld, newValue int64) bool {
+	return atomic.CompareAndSwapInt64(&a.value, expectedOld, newValue)
+}
+
+// AtomicFlag is a bool that can be updated atomically. The zero value is false.
+type AtomicFlag struct {
+	value uint32
+}
+
+func (a *AtomicFlag) Load() bool {
+	return atomic.LoadUint32(&a.value) == 1
+}
+
+func (a *AtomicFlag) Store(value bool) {
+	atomic.StoreUint32(&a.value, boolToUint32(value))
+}
+
+// Set sets the flag to true. It returns false if the flag has been true.
+func (a *AtomicFlag) Set() bool {
+	return atomic.CompareAndSwapUint32(&a.value, 0, 1)
+}
+
+// Clear sets the flag to false. It returns false if the flag has been false.
+func (a *AtomicFlag) Clear() bool {
+	return atomic.CompareAndSwapUint32(&a.value, 1, 0)
+}
+
+// Swap stores value and returns the old one
+func (a *AtomicFlag) Swap(value bool) (old bool) {
+	return atomic.SwapUint32(&a.value, boolToUint32(value)) == 1
+}
+
+func boolToUint32(value bool) uint32 {
+	if value {
+		return 1
+	}
+	return 0
+}
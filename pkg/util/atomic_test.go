@@ -0,0 +1,109 @@
+package util_test
+
+import (
+	"sync"
+
+	"github.com/linxiaokun528/go-kit/pkg/util"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AtomicValue", func() {
+	sliceEqualer := func(first, second []int) bool {
+		if len(first) != len(second) {
+			return false
+		}
+		for i := range first {
+			if first[i] != second[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	It("holds the zero value at the beginning.", func() {
+		var value util.AtomicValue[error]
+		Expect(value.Load()).To(BeNil())
+		Expect(value.Swap(nil)).To(BeNil())
+	})
+
+	It("loads and stores the values.", func() {
+		value := util.NewAtomicValue([]int{1})
+		Expect(value.Load()).To(Equal([]int{1}))
+		value.Store([]int{2})
+		Expect(value.Swap([]int{3})).To(Equal([]int{2}))
+		Expect(value.Load()).To(Equal([]int{3}))
+	})
+
+	It("compares and swaps the values that are not comparable.", func() {
+		value := util.NewAtomicValue([]int{1})
+		Expect(value.CompareAndSwap([]int{2}, []int{3}, sliceEqualer)).To(BeFalse())
+		Expect(value.CompareAndSwap([]int{1}, []int{3}, sliceEqualer)).To(BeTrue())
+		Expect(value.Load()).To(Equal([]int{3}))
+	})
+
+	It("can be compared and swapped concurrently.", func() {
+		var value util.AtomicValue[int]
+		var wait sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wait.Add(1)
+			go func() {
+				defer wait.Done()
+				for j := 0; j < 100; j++ {
+					for {
+						old := value.Load()
+						if value.CompareAndSwap(old, old+1, func(first, second int) bool {
+							return first == second
+						}) {
+							break
+						}
+					}
+				}
+			}()
+		}
+		wait.Wait()
+		Expect(value.Load()).To(Equal(1000))
+	})
+})
+
+var _ = Describe("AtomicCounter", func() {
+	It("can be updated concurrently.", func() {
+		var counter util.AtomicCounter
+		var wait sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wait.Add(1)
+			go func() {
+				defer wait.Done()
+				for j := 0; j < 100; j++ {
+					counter.Inc()
+				}
+			}()
+		}
+		wait.Wait()
+		Expect(counter.Load()).To(BeEquivalentTo(1000))
+
+		Expect(counter.Dec()).To(BeEquivalentTo(999))
+		Expect(counter.Add(-999)).To(BeZero())
+		counter.Store(5)
+		Expect(counter.Swap(6)).To(BeEquivalentTo(5))
+		Expect(counter.CompareAndSwap(5, 7)).To(BeFalse())
+		Expect(counter.CompareAndSwap(6, 7)).To(BeTrue())
+		Expect(counter.Load()).To(BeEquivalentTo(7))
+	})
+})
+
+var _ = Describe("AtomicFlag", func() {
+	It("can be set only once.", func() {
+		var flag util.AtomicFlag
+		Expect(flag.Load()).To(BeFalse())
+		Expect(flag.Set()).To(BeTrue())
+		Expect(flag.Set()).To(BeFalse())
+		Expect(flag.Load()).To(BeTrue())
+
+		Expect(flag.Clear()).To(BeTrue())
+		Expect(flag.Clear()).To(BeFalse())
+		Expect(flag.Swap(true)).To(BeFalse())
+		flag.Store(false)
+		Expect(flag.Load()).To(BeFalse())
+	})
+})
@@ -0,0 +1,72 @@
+package util
+
+import "context"
+
+// BoundedBlockingChannel is a channel with a fixed capacity. Senders are blocked when it's full,
+// which can be used for back-pressure.
+type BoundedBlockingChannel[T any] struct {
+	ch chan T
+}
+
+func NewBoundedBlockingChannel[T any](capacity int) *BoundedBlockingChannel[T] {
+	return &BoundedBlockingChannel[T]{
+		ch: make(chan T, capacity),
+	}
+}
+
+// Send blocks until there is room for the entry
+func (b *BoundedBlockingChannel[T]) Send(entry T) {
+	b.ch <- entry
+}
+
+// TrySend returns false immediately if the channel is full
+func (b *BoundedBlockingChannel[T]) TrySend(entry T) bool {
+	select {
+	case b.ch <- entry:
+		return true
+	default:
+		return false
+	}
+}
+
+// SendWithContext blocks until there is room for the entry or ctx is done.
+// If ctx is done, ctx.Err() will be returned.
+func (b *BoundedBlockingChannel[T]) SendWithContext(entry T, ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case b.ch <- entry:
+		return nil
+	}
+}
+
+// Recv blocks until there is an entry
+func (b *BoundedBlockingChannel[T]) Recv() T {
+	return <-b.ch
+}
+
+// TryRecv returns false immediately if the channel is empty
+func (b *BoundedBlockingChannel[T]) TryRecv() (entry T, exists bool) {
+	select {
+	case entry = <-b.ch:
+		return entry, true
+	default:
+		return
+	}
+}
+
+// RecvWithContext blocks until there is an entry or ctx is done.
+// If ctx is done, ctx.Err() will be returned.
+func (b *BoundedBlockingChannel[T]) RecvWithContext(ctx context.Context) (entry T, err error) {
+	select {
+	case <-ctx.Done():
+		err = ctx.Err()
+		return
+	case entry = <-b.ch:
+		return entry, nil
+	}
+}
+
+func (b *BoundedBlockingChannel[T]) Len() int {
+	return len(b.ch)
+}
@@ -0,0 +1,89 @@
+package util_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/linxiaokun528/go-kit/pkg/util"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BoundedBlockingChannel", func() {
+	var ch *util.BoundedBlockingChannel[int]
+	var ctx context.Context
+	var cancelFunc context.CancelFunc
+
+	BeforeEach(func() {
+		ch = util.NewBoundedBlockingChannel[int](2)
+		ctx, cancelFunc = context.WithCancel(context.Background())
+		DeferCleanup(cancelFunc)
+	})
+
+	It("can receive what it sends.", func() {
+		ch.Send(1)
+		ch.Send(2)
+		Expect(ch.Len()).To(Equal(2))
+		Expect(ch.Recv()).To(Equal(1))
+		Expect(ch.Recv()).To(Equal(2))
+		Expect(ch.Len()).To(Equal(0))
+	})
+
+	It("blocks Send when it's full.", func() {
+		ch.Send(1)
+		ch.Send(2)
+
+		sent := make(chan bool)
+		go func() {
+			ch.Send(3)
+			close(sent)
+		}()
+		Consistently(sent, 200*time.Millisecond).ShouldNot(BeClosed())
+
+		Expect(ch.Recv()).To(Equal(1))
+		Eventually(sent).Should(BeClosed())
+		Expect(ch.Len()).To(Equal(2))
+	})
+
+	It("returns false from TrySend when it's full.", func() {
+		Expect(ch.TrySend(1)).To(BeTrue())
+		Expect(ch.TrySend(2)).To(BeTrue())
+		Expect(ch.TrySend(3)).To(BeFalse())
+		Expect(ch.Len()).To(Equal(2))
+	})
+
+	It("returns ctx.Err() from SendWithContext when ctx is cancelled.", func() {
+		Expect(ch.SendWithContext(1, ctx)).To(Succeed())
+		Expect(ch.SendWithContext(2, ctx)).To(Succeed())
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- ch.SendWithContext(3, ctx)
+		}()
+		Consistently(errCh, 200*time.Millisecond).ShouldNot(Receive())
+		cancelFunc()
+		Eventually(errCh).Should(Receive(Equal(context.Canceled)))
+		Expect(ch.Len()).To(Equal(2))
+	})
+
+	It("returns false from TryRecv when it's empty.", func() {
+		_, exists := ch.TryRecv()
+		Expect(exists).To(BeFalse())
+
+		ch.Send(1)
+		value, exists := ch.TryRecv()
+		Expect(exists).To(BeTrue())
+		Expect(value).To(Equal(1))
+	})
+
+	It("returns ctx.Err() from RecvWithContext when ctx is cancelled.", func() {
+		ch.Send(1)
+		value, err := ch.RecvWithContext(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(Equal(1))
+
+		cancelFunc()
+		_, err = ch.RecvWithContext(ctx)
+		Expect(err).To(Equal(context.Canceled))
+	})
+})
@@ -0,0 +1,253 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/linxiaokun528/go-kit/pkg/util"
+	"github.com/linxiaokun528/go-kit/pkg/util/collection"
+	"k8s.io/utils/clock"
+)
+
+// ErrNotFound is returned by Get if the key is absent and the cache has no loader
+var ErrNotFound = errors.New("the key is not found")
+
+// Loader loads the value of a key that is absent in the cache
+type Loader[K any, V any] func(ctx context.Context, key K) (V, error)
+
+// EvictionListener is called when an entry is evicted because the cache is full or the entry expires.
+// It's not called for Invalidate or the replaced values.
+type EvictionListener[K any, V any] func(key K, value V)
+
+type options[K any, V any] struct {
+	maxSize   int
+	ttl       time.Duration
+	loader    Loader[K, V]
+	listeners []EvictionListener[K, V]
+	clock     clock.PassiveClock
+}
+
+// Option configures a Cache
+type Option[K any, V any] func(*options[K, V])
+
+// WithMaxSize keeps at most maxSize entries. When the cache is full, the least recently used entry is evicted.
+// By default, the size is unlimited.
+func WithMaxSize[K any, V any](maxSize int) Option[K, V] {
+	if maxSize <= 0 {
+		panic(fmt.Errorf("maxSize should be positive"))
+	}
+
+	return func(o *options[K, V]) {
+		o.maxSize = maxSize
+	}
+}
+
+// WithTTL makes the entries expire ttl after they are put. By default, the entries never expire.
+func WithTTL[K any, V any](ttl time.Duration) Option[K, V] {
+	if ttl <= 0 {
+		panic(fmt.Errorf("ttl should be positive"))
+	}
+
+	return func(o *options[K, V]) {
+		o.ttl = ttl
+	}
+}
+
+// WithLoader makes Get load the absent keys with loader. The concurrent loads of the same key are collapsed into one.
+func WithLoader[K any, V any](loader Loader[K, V]) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.loader = loader
+	}
+}
+
+// WithEvictionListener adds a listener. The listeners are called in the order they are added, after the lock of the
+// cache is released.
+func WithEvictionListener[K any, V any](listener EvictionListener[K, V]) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.listeners = append(o.listeners, listener)
+	}
+}
+
+// WithClock makes the cache use clock for the TTL, e.g. a fake clock in the tests
+func WithClock[K any, V any](clock clock.PassiveClock) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.clock = clock
+	}
+}
+
+// Stats is the statistics of a Cache
+type Stats struct {
+	Hits   uint64
+	Misses uint64
+	// Loads is the number of the successful loads
+	Loads      uint64
+	LoadErrors uint64
+	Evictions  uint64
+}
+
+// HitRate returns Hits / (Hits + Misses), or 0 if there is no request
+func (s Stats) HitRate() float64 {
+	requests := s.Hits + s.Misses
+	if requests == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(requests)
+}
+
+type eviction[K any, V any] struct {
+	key   K
+	value V
+}
+
+// Cache is a thread-safe cache built on collection.LRUCache, which can load the absent keys with a Loader.
+// The keys don't need to be comparable, because they are stored with a custom hasher.
+type Cache[K any, V any] struct {
+	options[K, V]
+	lock    sync.Mutex
+	entries collection.LRUCache[K, V]
+	// evicted is guarded by lock. It holds the evictions that the listeners haven't been told about.
+	evicted      []eviction[K, V]
+	singleFlight *util.SingleFlight[K, V]
+
+	hits, misses, loads, loadErrors, evictions uint64
+}
+
+func New[K any, V any, C comparable](hasher collection.Hasher[K, C], equaler collection.Equaler[K],
+	opts ...Option[K, V]) *Cache[K, V] {
+	o := options[K, V]{
+		maxSize: math.MaxInt,
+		clock:   clock.RealClock{},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	cache := &Cache[K, V]{
+		options:      o,
+		singleFlight: util.NewSingleFlight[K, V, C](hasher, equaler),
+	}
+	cache.entries = collection.NewLRUCache[K, V, C](o.maxSize, o.clock, func(key K, value V) {
+		atomic.AddUint64(&cache.evictions, 1)
+		if len(cache.listeners) > 0 {
+			cache.evicted = append(cache.evicted, eviction[K, V]{key: key, value: value})
+		}
+	}, hasher, equaler)
+	return cache
+}
+
+// unlock releases the lock and tells the listeners about the evictions
+func (c *Cache[K, V]) unlock() {
+	evicted := c.evicted
+	c.evicted = nil
+	c.lock.Unlock()
+
+	for _, e := range evicted {
+		for _, listener := range c.listeners {
+			listener(e.key, e.value)
+		}
+	}
+}
+
+// GetIfPresent returns the value of the key without loading it
+func (c *Cache[K, V]) GetIfPresent(key K) (value V, exists bool) {
+	c.lock.Lock()
+	value, exists = c.entries.Get(key)
+	c.unlock()
+
+	if exists {
+		atomic.AddUint64(&c.hits, 1)
+	} else {
+		atomic.AddUint64(&c.misses, 1)
+	}
+	return
+}
+
+// Get returns the value of the key. If the key is absent, it's loaded with the Loader and put into the cache.
+// The concurrent Gets of the same key share the load that runs with the ctx of the first Get.
+// It returns ErrNotFound if the key is absent and the cache has no loader.
+func (c *Cache[K, V]) Get(ctx context.Context, key K) (V, error) {
+	value, exists := c.GetIfPresent(key)
+	if exists {
+		return value, nil
+	}
+	if c.loader == nil {
+		return value, ErrNotFound
+	}
+
+	value, err, _ := c.singleFlight.Do(key, func() (V, error) {
+		// Another load may have finished after the GetIfPresent above
+		c.lock.Lock()
+		value, exists := c.entries.Get(key)
+		c.unlock()
+		if exists {
+			return value, nil
+		}
+
+		value, err := c.loader(ctx, key)
+		if err != nil {
+			atomic.AddUint64(&c.loadErrors, 1)
+			return value, err
+		}
+		atomic.AddUint64(&c.loads, 1)
+		c.Put(key, value)
+		return value, nil
+	})
+	return value, err
+}
+
+// Put puts the value of the key, which expires after the TTL if there is one
+func (c *Cache[K, V]) Put(key K, value V) {
+	c.lock.Lock()
+	if c.ttl > 0 {
+		c.entries.PutWithTTL(key, value, c.ttl)
+	} else {
+		c.entries.Put(key, value)
+	}
+	c.unlock()
+}
+
+// Invalidate removes the key. It returns false if the key is absent.
+func (c *Cache[K, V]) Invalidate(key K) bool {
+	c.lock.Lock()
+	_, exists := c.entries.Remove(key)
+	c.unlock()
+	return exists
+}
+
+// InvalidateAll removes all the keys
+func (c *Cache[K, V]) InvalidateAll() {
+	c.lock.Lock()
+	c.entries.Clear()
+	c.unlock()
+}
+
+// Len returns the number of the entries, which may include the expired ones that haven't been evicted
+func (c *Cache[K, V]) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.entries.Len()
+}
+
+// EvictExpired evicts all the expired entries
+func (c *Cache[K, V]) EvictExpired() {
+	c.lock.Lock()
+	c.entries.EvictExpired()
+	c.unlock()
+}
+
+// Stats returns a snapshot of the statistics
+func (c *Cache[K, V]) Stats() Stats {
+	return Stats{
+		Hits:       atomic.LoadUint64(&c.hits),
+		Misses:     atomic.LoadUint64(&c.misses),
+		Loads:      atomic.LoadUint64(&c.loads),
+		LoadErrors: atomic.LoadUint64(&c.loadErrors),
+		Evictions:  atomic.LoadUint64(&c.evictions),
+	}
+}
@@ -0,0 +1,132 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/linxiaokun528/go-kit/pkg/util/cache"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func newCache(opts ...cache.Option[int, string]) *cache.Cache[int, string] {
+	return cache.New[int, string, int](func(key int) int {
+		return key
+	}, func(first, second int) bool {
+		return first == second
+	}, opts...)
+}
+
+var _ = Describe("Cache", func() {
+	It("works without a loader.", func() {
+		c := newCache()
+		_, err := c.Get(context.Background(), 1)
+		Expect(err).To(Equal(cache.ErrNotFound))
+
+		c.Put(1, "1")
+		Expect(c.Get(context.Background(), 1)).To(Equal("1"))
+		Expect(c.Len()).To(Equal(1))
+		Expect(c.Invalidate(1)).To(BeTrue())
+		Expect(c.Invalidate(1)).To(BeFalse())
+		_, exists := c.GetIfPresent(1)
+		Expect(exists).To(BeFalse())
+
+		Expect(c.Stats()).To(Equal(cache.Stats{Hits: 1, Misses: 2}))
+		Expect(c.Stats().HitRate()).To(BeNumerically("~", 1.0/3, 0.001))
+	})
+
+	It("loads the absent keys.", func() {
+		errTest := errors.New("error for test")
+		c := newCache(cache.WithLoader(func(ctx context.Context, key int) (string, error) {
+			if key < 0 {
+				return "", errTest
+			}
+			return strconv.Itoa(key), nil
+		}))
+
+		Expect(c.Get(context.Background(), 1)).To(Equal("1"))
+		Expect(c.Get(context.Background(), 1)).To(Equal("1"))
+		_, err := c.Get(context.Background(), -1)
+		Expect(err).To(Equal(errTest))
+		Expect(c.Len()).To(Equal(1))
+		Expect(c.Stats()).To(Equal(cache.Stats{Hits: 1, Misses: 2, Loads: 1, LoadErrors: 1}))
+	})
+
+	It("collapses the concurrent loads of the same key.", func() {
+		var loads int64
+		block := make(chan struct{})
+		c := newCache(cache.WithLoader(func(ctx context.Context, key int) (string, error) {
+			atomic.AddInt64(&loads, 1)
+			<-block
+			return strconv.Itoa(key), nil
+		}))
+
+		var wait sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wait.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wait.Done()
+				Expect(c.Get(context.Background(), 1)).To(Equal("1"))
+			}()
+		}
+		Eventually(func() int64 { return atomic.LoadInt64(&loads) }).Should(BeEquivalentTo(1))
+		time.Sleep(10 * time.Millisecond)
+		close(block)
+		wait.Wait()
+		Expect(atomic.LoadInt64(&loads)).To(BeEquivalentTo(1))
+	})
+
+	It("evicts the least recently used entries and tells the listeners.", func() {
+		var evicted []int
+		c := newCache(cache.WithMaxSize[int, string](2),
+			cache.WithEvictionListener(func(key int, value string) {
+				evicted = append(evicted, key)
+			}))
+		c.Put(1, "1")
+		c.Put(2, "2")
+		c.GetIfPresent(1)
+		c.Put(3, "3")
+
+		Expect(evicted).To(Equal([]int{2}))
+		Expect(c.Len()).To(Equal(2))
+		Expect(c.Stats().Evictions).To(BeEquivalentTo(1))
+
+		c.InvalidateAll()
+		Expect(c.Len()).To(Equal(0))
+		Expect(evicted).To(Equal([]int{2}))
+	})
+
+	It("expires the entries after the TTL.", func() {
+		fakeClock := clocktesting.NewFakeClock(time.Now())
+		var evicted []int
+		c := newCache(cache.WithTTL[int, string](time.Second), cache.WithClock[int, string](fakeClock),
+			cache.WithEvictionListener(func(key int, value string) {
+				evicted = append(evicted, key)
+			}))
+		c.Put(1, "1")
+		fakeClock.Step(500 * time.Millisecond)
+		c.Put(2, "2")
+		fakeClock.Step(500 * time.Millisecond)
+
+		_, exists := c.GetIfPresent(1)
+		Expect(exists).To(BeFalse())
+		value, exists := c.GetIfPresent(2)
+		Expect(exists).To(BeTrue())
+		Expect(value).To(Equal("2"))
+		fakeClock.Step(500 * time.Millisecond)
+		c.EvictExpired()
+		Expect(evicted).To(Equal([]int{1, 2}))
+		Expect(c.Len()).To(Equal(0))
+	})
+
+	It("panics with invalid options.", func() {
+		Expect(func() { cache.WithMaxSize[int, string](0) }).To(Panic())
+		Expect(func() { cache.WithTTL[int, string](0) }).To(Panic())
+	})
+})
@@ -0,0 +1,168 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/linxiaokun528/go-kit/pkg/util/collection"
+)
+
+// The helpers in this file stop when ctx is done, in which case the entries that haven't been sent are dropped.
+// The returned channels are closed after the helpers stop, so the receivers can use `for range`.
+
+// FanIn sends the entries of all the chs to the returned channel, in the order they are received.
+// The returned channel is closed after all the chs are closed.
+func FanIn[T any](ctx context.Context, chs ...<-chan T) <-chan T {
+	out := make(chan T)
+	wait := sync.WaitGroup{}
+	wait.Add(len(chs))
+	for _, ch := range chs {
+		go func(ch <-chan T) {
+			defer wait.Done()
+			for {
+				entry, ok := recvWithContext(ctx, ch)
+				if !ok || !sendWithContext(ctx, out, entry) {
+					return
+				}
+			}
+		}(ch)
+	}
+
+	go func() {
+		wait.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// Merge merges the sorted chs into a sorted channel. comparator(first, second) is true if first should be sent
+// before second. An entry is sent after all the open chs have an entry, so a slow ch blocks the others.
+func Merge[T any](ctx context.Context, comparator collection.Comparator[T], chs ...<-chan T) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+
+		type head struct {
+			entry T
+			ch    <-chan T
+		}
+		heads := make([]head, 0, len(chs))
+		for _, ch := range chs {
+			if entry, ok := recvWithContext(ctx, ch); ok {
+				heads = append(heads, head{entry: entry, ch: ch})
+			} else if ctx.Err() != nil {
+				return
+			}
+		}
+
+		for len(heads) > 0 {
+			first := 0
+			for i := 1; i < len(heads); i++ {
+				if comparator(heads[i].entry, heads[first].entry) {
+					first = i
+				}
+			}
+			if !sendWithContext(ctx, out, heads[first].entry) {
+				return
+			}
+
+			if entry, ok := recvWithContext(ctx, heads[first].ch); ok {
+				heads[first].entry = entry
+			} else if ctx.Err() != nil {
+				return
+			} else {
+				heads = append(heads[:first], heads[first+1:]...)
+			}
+		}
+	}()
+	return out
+}
+
+// FanOut distributes the entries of ch to n channels. Every entry is sent to only one of them, which is ready
+// to receive it, so a slow receiver doesn't block the others.
+// The returned channels are closed after ch is closed.
+func FanOut[T any](ctx context.Context, ch <-chan T, n int) []<-chan T {
+	if n <= 0 {
+		panic(fmt.Errorf("n should be positive"))
+	}
+
+	outs := make([]<-chan T, n)
+	for i := range outs {
+		out := make(chan T)
+		outs[i] = out
+		go func() {
+			defer close(out)
+			for {
+				entry, ok := recvWithContext(ctx, ch)
+				if !ok || !sendWithContext(ctx, out, entry) {
+					return
+				}
+			}
+		}()
+	}
+	return outs
+}
+
+// Broadcast sends every entry of ch to all the n channels. The next entry is received from ch after all the
+// returned channels receive the current one, so a slow receiver blocks the others.
+// The returned channels are closed after ch is closed.
+func Broadcast[T any](ctx context.Context, ch <-chan T, n int) []<-chan T {
+	if n <= 0 {
+		panic(fmt.Errorf("n should be positive"))
+	}
+
+	outs := make([]chan T, n)
+	result := make([]<-chan T, n)
+	for i := range outs {
+		outs[i] = make(chan T)
+		result[i] = outs[i]
+	}
+
+	go func() {
+		defer func() {
+			for _, out := range outs {
+				close(out)
+			}
+		}()
+
+		for {
+			entry, ok := recvWithContext(ctx, ch)
+			if !ok {
+				return
+			}
+			for _, out := range outs {
+				if !sendWithContext(ctx, out, entry) {
+					return
+				}
+			}
+		}
+	}()
+	return result
+}
+
+// Tee is Broadcast with 2 channels
+func Tee[T any](ctx context.Context, ch <-chan T) (<-chan T, <-chan T) {
+	outs := Broadcast(ctx, ch, 2)
+	return outs[0], outs[1]
+}
+
+// recvWithContext returns false if ch is closed or ctx is done
+func recvWithContext[T any](ctx context.Context, ch <-chan T) (entry T, ok bool) {
+	select {
+	case <-ctx.Done():
+		return
+	case entry, ok = <-ch:
+		return
+	}
+}
+
+// sendWithContext returns false if ctx is done
+func sendWithContext[T any](ctx context.Context, ch chan<- T, entry T) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case ch <- entry:
+		return true
+	}
+}
@@ -0,0 +1,106 @@
+package util_test
+
+import (
+	"context"
+	"sync"
+
+	"github.com/linxiaokun528/go-kit/pkg/util"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func sliceToChannel(entries ...int) <-chan int {
+	ch := make(chan int, len(entries))
+	for _, entry := range entries {
+		ch <- entry
+	}
+	close(ch)
+	return ch
+}
+
+func channelToSlice(ch <-chan int) (result []int) {
+	for entry := range ch {
+		result = append(result, entry)
+	}
+	return
+}
+
+// channelsToSlices drains chs concurrently
+func channelsToSlices(chs ...<-chan int) [][]int {
+	result := make([][]int, len(chs))
+	wait := sync.WaitGroup{}
+	wait.Add(len(chs))
+	for i, ch := range chs {
+		go func(i int, ch <-chan int) {
+			defer wait.Done()
+			result[i] = channelToSlice(ch)
+		}(i, ch)
+	}
+	wait.Wait()
+	return result
+}
+
+var _ = Describe("Channel helpers", func() {
+	var ctx context.Context
+	var cancel context.CancelFunc
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("FanIn", func() {
+		out := util.FanIn(ctx, sliceToChannel(1, 2, 3), sliceToChannel(4, 5), sliceToChannel())
+		Expect(channelToSlice(out)).To(ConsistOf(1, 2, 3, 4, 5))
+
+		Expect(channelToSlice(util.FanIn[int](ctx))).To(BeEmpty())
+	})
+
+	It("Merge", func() {
+		out := util.Merge(ctx, func(first, second int) bool { return first < second },
+			sliceToChannel(1, 4, 7), sliceToChannel(2, 5, 8, 9, 10), sliceToChannel(3, 6), sliceToChannel())
+		Expect(channelToSlice(out)).To(Equal(getSequence(11)[1:]))
+	})
+
+	It("FanOut", func() {
+		outs := util.FanOut(ctx, sliceToChannel(getSequence(100)...), 3)
+		Expect(outs).To(HaveLen(3))
+
+		var all []int
+		for _, result := range channelsToSlices(outs...) {
+			all = append(all, result...)
+		}
+		Expect(all).To(ConsistOf(getSequence(100)))
+	})
+
+	It("Broadcast", func() {
+		outs := util.Broadcast(ctx, sliceToChannel(1, 2, 3), 3)
+		Expect(channelsToSlices(outs...)).To(Equal([][]int{{1, 2, 3}, {1, 2, 3}, {1, 2, 3}}))
+	})
+
+	It("Tee", func() {
+		first, second := util.Tee(ctx, sliceToChannel(1, 2, 3))
+		Expect(channelsToSlices(first, second)).To(Equal([][]int{{1, 2, 3}, {1, 2, 3}}))
+	})
+
+	It("stops when ctx is done.", func() {
+		in := make(chan int)
+		fanIn := util.FanIn(ctx, in)
+		merged := util.Merge(ctx, func(first, second int) bool { return first < second }, in)
+		fanOut := util.FanOut(ctx, in, 2)
+		broadcast := util.Broadcast(ctx, in, 2)
+
+		cancel()
+		for _, ch := range append([]<-chan int{fanIn, merged}, append(fanOut, broadcast...)...) {
+			Eventually(ch).Should(BeClosed())
+		}
+	})
+
+	It("panics with invalid n.", func() {
+		Expect(func() { util.FanOut(ctx, sliceToChannel(), 0) }).To(Panic())
+		Expect(func() { util.Broadcast(ctx, sliceToChannel(), 0) }).To(Panic())
+	})
+})
@@ -0,0 +1,165 @@
+package collection_test
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
+)
+
+var benchmarkSizes = []int{100, 10000, 1000000}
+
+func newBenchmarkMap(size int, hasher Hasher[int, int]) Map[int, int] {
+	m := NewMap[int, int, int](hasher, basicEquator[int])
+	for i := 0; i < size; i++ {
+		m.Put(i, i)
+	}
+	return m
+}
+
+// collidingHasher puts every 8 keys into the same bucket, which measures the bucket scans of mapImpl
+func collidingHasher(value int) int {
+	return value / 8
+}
+
+func BenchmarkMapPut(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			m := newBenchmarkMap(size, basicHasher[int])
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				m.Put(i%size, i)
+			}
+		})
+	}
+}
+
+func BenchmarkMapGet(b *testing.B) {
+	hashers := map[string]Hasher[int, int]{"basic": basicHasher[int], "colliding": collidingHasher}
+	for name, hasher := range hashers {
+		for _, size := range benchmarkSizes {
+			b.Run(fmt.Sprintf("hasher=%s/size=%d", name, size), func(b *testing.B) {
+				m := newBenchmarkMap(size, hasher)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					m.Get(i % size)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkMapRemove(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			m := newBenchmarkMap(size, basicHasher[int])
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				key := i % size
+				m.Remove(key)
+				b.StopTimer()
+				m.Put(key, key)
+				b.StartTimer()
+			}
+		})
+	}
+}
+
+func newBenchmarkPriorityQueue(size int) PriorityQueue[int] {
+	return NewPriorityQueueFromSlice[int](getRandomArray(size), intAscComparator, basicEquator[int])
+}
+
+func BenchmarkPriorityQueueAdd(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			pq := newBenchmarkPriorityQueue(size)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				pq.Add(i % size)
+				pq.TryPop()
+			}
+		})
+	}
+}
+
+func BenchmarkPriorityQueuePop(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			pq := newBenchmarkPriorityQueue(size)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				item, _ := pq.TryPop()
+				pq.Add(item)
+			}
+		})
+	}
+}
+
+// BenchmarkPriorityQueueRemoveFirst measures the O(n) lookup of priorityQueue.RemoveFirst
+func BenchmarkPriorityQueueRemoveFirst(b *testing.B) {
+	for _, size := range benchmarkSizes[:2] {
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			pq := NewPriorityQueueFromSlice[int](getSequence(size), intAscComparator, basicEquator[int])
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				item := i % size
+				pq.RemoveFirst(item)
+				pq.Add(item)
+			}
+		})
+	}
+}
+
+// BenchmarkThreadSafeSetContention should be run with -cpu, like `-cpu 1,2,4,8`, to measure the lock contention
+func BenchmarkThreadSafeSetContention(b *testing.B) {
+	for _, writePercent := range []int{0, 10, 50} {
+		b.Run(fmt.Sprintf("write=%d%%", writePercent), func(b *testing.B) {
+			s := NewThreadSafeSet[int, int](basicHasher[int], basicEquator[int])
+			AddAll[int](s, getSequence(1000)...)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					if i%100 < writePercent {
+						s.Add(i % 1000)
+					} else {
+						s.Has(i % 1000)
+					}
+					i++
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkMapChurn(b *testing.B) {
+	creators := map[string]func() Map[int, int]{
+		"default": func() Map[int, int] { return NewMap[int, int, int](basicHasher[int], basicEquator[int]) },
+		"pooled":  func() Map[int, int] { return NewPooledMap[int, int, int](basicHasher[int], basicEquator[int]) },
+	}
+	for name, create := range creators {
+		b.Run(name, func(b *testing.B) {
+			m := create()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				m.Put(i, i)
+				m.Remove(i)
+			}
+		})
+	}
+}
+
+func BenchmarkConcurrentMapGet(b *testing.B) {
+	m := NewConcurrentMap[string, int, string](16, basicHasher[string], basicEquator[string])
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = fmt.Sprint(i)
+		m.Put(keys[i], i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Get(keys[i%len(keys)])
+	}
+}
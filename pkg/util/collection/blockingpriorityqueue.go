@@ -0,0 +1,139 @@
+package collection
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// BlockingPriorityQueue is a thread-safe priority queue, which can be shared by multiple producers and consumers.
+//  Pop blocks until there is an item, and Add blocks when the queue is full, which can be used for back-pressure.
+type BlockingPriorityQueue[T any] interface {
+	// Add blocks until there is room for the item or ctx is done. If ctx is done, ctx.Err() will be returned.
+	Add(item T, ctx context.Context) error
+	// TryAdd returns false immediately if the queue is full
+	TryAdd(item T) bool
+	// Pop blocks until there is an item or ctx is done, and removes the top item.
+	//  If ctx is done, ctx.Err() will be returned.
+	Pop(ctx context.Context) (T, error)
+	// TryPop returns false immediately if the queue is empty
+	TryPop() (T, bool)
+	TryPeek() (T, bool)
+	Len() int
+	// Capacity returns the maximum number of the items. 0 means no limit.
+	Capacity() int
+}
+
+// NewBlockingPriorityQueue If capacity is 0, the queue is unbounded and Add never blocks.
+func NewBlockingPriorityQueue[T any](capacity int, comparator Comparator[T],
+	equaler Equaler[T]) BlockingPriorityQueue[T] {
+	if capacity < 0 {
+		panic(fmt.Errorf("capacity should be non-negative"))
+	}
+
+	return &blockingPriorityQueue[T]{
+		pq:       NewPriorityQueue[T](comparator, equaler),
+		capacity: capacity,
+		changed:  make(chan struct{}),
+	}
+}
+
+type blockingPriorityQueue[T any] struct {
+	pq       PriorityQueue[T]
+	capacity int
+	l        sync.Mutex
+	// changed is closed and replaced whenever an item is added or popped, to wake up all the waiting goroutines
+	changed chan struct{}
+}
+
+func (b *blockingPriorityQueue[T]) full() bool {
+	return b.capacity > 0 && b.pq.Len() >= b.capacity
+}
+
+// notify should be called with the lock held
+func (b *blockingPriorityQueue[T]) notify() {
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
+
+func (b *blockingPriorityQueue[T]) Add(item T, ctx context.Context) error {
+	for {
+		b.l.Lock()
+		if !b.full() {
+			b.pq.Add(item)
+			b.notify()
+			b.l.Unlock()
+			return nil
+		}
+		changed := b.changed
+		b.l.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+func (b *blockingPriorityQueue[T]) TryAdd(item T) bool {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	if b.full() {
+		return false
+	}
+	b.pq.Add(item)
+	b.notify()
+	return true
+}
+
+func (b *blockingPriorityQueue[T]) Pop(ctx context.Context) (item T, err error) {
+	for {
+		b.l.Lock()
+		if b.pq.Len() > 0 {
+			item, _ = b.pq.TryPop()
+			b.notify()
+			b.l.Unlock()
+			return item, nil
+		}
+		changed := b.changed
+		b.l.Unlock()
+
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		case <-changed:
+		}
+	}
+}
+
+func (b *blockingPriorityQueue[T]) TryPop() (item T, exists bool) {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	item, exists = b.pq.TryPop()
+	if exists {
+		b.notify()
+	}
+	return
+}
+
+func (b *blockingPriorityQueue[T]) TryPeek() (item T, exists bool) {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	return b.pq.TryPeek()
+}
+
+func (b *blockingPriorityQueue[T]) Len() int {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	return b.pq.Len()
+}
+
+func (b *blockingPriorityQueue[T]) Capacity() int {
+	return b.capacity
+}
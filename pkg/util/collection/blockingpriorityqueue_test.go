@@ -0,0 +1,140 @@
+package collection_test
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
+)
+
+var _ = Describe("BlockingPriorityQueue", func() {
+	It("panics for a negative capacity.", func() {
+		Expect(func() {
+			NewBlockingPriorityQueue[int](-1, intAscComparator, basicEquator[int])
+		}).To(Panic())
+	})
+
+	It("pops the items in order.", func() {
+		queue := NewBlockingPriorityQueue[int](0, intAscComparator, basicEquator[int])
+		for _, item := range []int{3, 1, 2} {
+			Expect(queue.Add(item, context.Background())).To(Succeed())
+		}
+		Expect(queue.Len()).To(Equal(3))
+		Expect(queue.Capacity()).To(Equal(0))
+		top, _ := queue.TryPeek()
+		Expect(top).To(Equal(1))
+
+		for _, expected := range []int{1, 2, 3} {
+			item, err := queue.Pop(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(item).To(Equal(expected))
+		}
+		_, exists := queue.TryPop()
+		Expect(exists).To(BeFalse())
+	})
+
+	It("blocks Pop until an item is added.", func() {
+		queue := NewBlockingPriorityQueue[int](0, intAscComparator, basicEquator[int])
+		popped := make(chan int)
+		go func() {
+			defer GinkgoRecover()
+			item, err := queue.Pop(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			popped <- item
+		}()
+
+		Consistently(popped, 50*time.Millisecond).ShouldNot(Receive())
+		Expect(queue.TryAdd(1)).To(BeTrue())
+		Eventually(popped).Should(Receive(Equal(1)))
+	})
+
+	It("blocks Add when it's full.", func() {
+		queue := NewBlockingPriorityQueue[int](2, intAscComparator, basicEquator[int])
+		Expect(queue.TryAdd(2)).To(BeTrue())
+		Expect(queue.TryAdd(3)).To(BeTrue())
+		Expect(queue.TryAdd(1)).To(BeFalse())
+
+		added := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			Expect(queue.Add(1, context.Background())).To(Succeed())
+			close(added)
+		}()
+
+		Consistently(added, 50*time.Millisecond).ShouldNot(BeClosed())
+		item, _ := queue.TryPop()
+		Expect(item).To(Equal(2))
+		Eventually(added).Should(BeClosed())
+		item, _ = queue.TryPop()
+		Expect(item).To(Equal(1))
+	})
+
+	It("returns the error of ctx.", func() {
+		queue := NewBlockingPriorityQueue[int](1, intAscComparator, basicEquator[int])
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err := queue.Pop(ctx)
+		Expect(err).To(Equal(context.DeadlineExceeded))
+
+		Expect(queue.TryAdd(1)).To(BeTrue())
+		ctx, cancel = context.WithCancel(context.Background())
+		cancel()
+		Expect(queue.Add(2, ctx)).To(Equal(context.Canceled))
+		Expect(queue.Len()).To(Equal(1))
+	})
+
+	It("can be shared by multiple producers and consumers.", func() {
+		queue := NewBlockingPriorityQueue[int](10, intAscComparator, basicEquator[int])
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		producers := sync.WaitGroup{}
+		for i := 0; i < 4; i++ {
+			producers.Add(1)
+			i := i
+			go func() {
+				defer GinkgoRecover()
+				defer producers.Done()
+				for j := 0; j < 250; j++ {
+					Expect(queue.Add(i*250+j, ctx)).To(Succeed())
+				}
+			}()
+		}
+
+		lock := sync.Mutex{}
+		popped := []int{}
+		consumers := sync.WaitGroup{}
+		for i := 0; i < 4; i++ {
+			consumers.Add(1)
+			go func() {
+				defer consumers.Done()
+				for {
+					item, err := queue.Pop(ctx)
+					if err != nil {
+						return
+					}
+					lock.Lock()
+					popped = append(popped, item)
+					lock.Unlock()
+				}
+			}()
+		}
+
+		producers.Wait()
+		Eventually(func() int {
+			lock.Lock()
+			defer lock.Unlock()
+			return len(popped)
+		}).Should(Equal(1000))
+		cancel()
+		consumers.Wait()
+
+		sort.Ints(popped)
+		Expect(popped).To(Equal(getSequence(1000)))
+	})
+})
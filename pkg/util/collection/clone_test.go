@@ -0,0 +1,150 @@
+package collection_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/clock"
+
+	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
+)
+
+func popAll[T any](c Collection[T]) []T {
+	result := []T{}
+	for item, exists := c.TryPop(); exists; item, exists = c.TryPop() {
+		result = append(result, item)
+	}
+	return result
+}
+
+var _ = Describe("Clone", func() {
+	for _, mt := range []mapType{defaultMap, threadSafeMap, concurrentMap, lruCache, orderedMap, priorityMap, cowMap} {
+		mt := mt
+		It(fmt.Sprintf("copies %s.", mt), func() {
+			original := createMap[int, int, int](mt, fakeHasher, basicEquator[int], intAscComparator)
+			for i := 0; i < 10; i++ {
+				original.Put(i, i)
+			}
+
+			cloned := CloneMap(original)
+			Expect(cloned.ToArray()).To(ConsistOf(original.ToArray()))
+			Expect(fmt.Sprintf("%T", cloned)).To(Equal(fmt.Sprintf("%T", original)))
+
+			original.Put(0, 100)
+			original.Remove(1)
+			cloned.Put(10, 10)
+			cloned.Remove(2)
+
+			value, _ := cloned.Get(0)
+			Expect(value).To(Equal(0))
+			Expect(cloned.ContainsKey(1)).To(BeTrue())
+			Expect(original.ContainsKey(10)).To(BeFalse())
+			Expect(original.ContainsKey(2)).To(BeTrue())
+			Expect(original.Len()).To(Equal(9))
+			Expect(cloned.Len()).To(Equal(10))
+		})
+	}
+
+	for _, st := range []setType{defaultSet, threadSafeSet, prioritySet, threadSafePrioritySet, cowSet} {
+		st := st
+		It(fmt.Sprintf("copies %s.", st), func() {
+			original := createSet[int, int](st, basicHasher[int], basicEquator[int], intAscComparator)
+			AddAll[int](original, 3, 1, 2)
+
+			cloned := CloneSet(original)
+			Expect(fmt.Sprintf("%T", cloned)).To(Equal(fmt.Sprintf("%T", original)))
+			original.RemoveFirst(1)
+			cloned.Add(4)
+			Expect(original.ToArray()).To(ConsistOf(2, 3))
+			Expect(cloned.ToArray()).To(ConsistOf(1, 2, 3, 4))
+		})
+	}
+
+	It("keeps the order of the collections.", func() {
+		collections := map[string]Collection[int]{
+			"PriorityQueue": NewPriorityQueue[int](intAscComparator, basicEquator[int]),
+			"IndexedPriorityQueue": NewIndexedPriorityQueue[int, int](
+				intAscComparator, basicHasher[int], basicEquator[int]),
+			"ThreadSafePriorityQueue": NewThreadSafePriorityQueue[int](intAscComparator, basicEquator[int]),
+			"SortedDeque":             NewSortedDeque[int](intAscComparator, basicEquator[int]),
+			"Queue":                   NewQueue[int](basicEquator[int]),
+			"Deque":                   NewDeque[int](basicEquator[int]),
+			"RingBuffer":              NewRingBuffer[int](5, OverwriteOldest, basicEquator[int]),
+			"ThreadSafeRingBuffer":    NewThreadSafeRingBuffer[int](5, OverwriteOldest, basicEquator[int]),
+			"ConcurrentSortedSet":     NewConcurrentSortedSet[int](intStrictAscComparator),
+		}
+		for name, original := range collections {
+			for _, item := range []int{0, 1, 2, 3, 4} {
+				original.Add(item)
+			}
+
+			cloned := original.Clone()
+			original.RemoveFirst(2)
+			Expect(cloned.Has(2)).To(BeTrue(), name)
+			Expect(popAll(cloned)).To(Equal([]int{0, 1, 2, 3, 4}), name)
+			Expect(popAll(original)).To(Equal([]int{0, 1, 3, 4}), name)
+		}
+
+		stack := NewStack[int](basicEquator[int])
+		for _, item := range []int{0, 1, 2} {
+			stack.Push(item)
+		}
+		cloned := stack.Clone().(Stack[int])
+		stack.Pop()
+		Expect(popAll[int](cloned)).To(Equal([]int{2, 1, 0}))
+	})
+
+	It("keeps the order of TreeMap and LRUCache.", func() {
+		treeMap := NewTreeMap[int, int](intStrictAscComparator)
+		for _, key := range []int{3, 1, 2} {
+			treeMap.Put(key, key)
+		}
+		clonedTreeMap := treeMap.Clone().(TreeMap[int, int])
+		treeMap.Remove(1)
+		first, _ := clonedTreeMap.First()
+		Expect(first.Key).To(Equal(1))
+		clonedTreeMap.Put(0, 0)
+		first, _ = treeMap.First()
+		Expect(first.Key).To(Equal(2))
+
+		cache := NewLRUCache[int, int, int](2, clock.RealClock{}, nil, basicHasher[int], basicEquator[int])
+		cache.Put(1, 1)
+		cache.Put(2, 2)
+		clonedCache := cache.Clone().(LRUCache[int, int])
+		clonedCache.Put(3, 3)
+		Expect(clonedCache.ContainsKey(1)).To(BeFalse())
+		Expect(cache.ContainsKey(1)).To(BeTrue())
+		Expect(clonedCache.Capacity()).To(Equal(2))
+	})
+})
+
+var _ = Describe("Snapshot", func() {
+	It("returns a non-thread-safe copy.", func() {
+		collections := map[string]Collection[int]{
+			"ThreadSafeSet": NewThreadSafeSet[int, int](basicHasher[int], basicEquator[int]),
+			"ThreadSafePrioritySet": NewThreadSafePrioritySet[int, int](
+				intAscComparator, basicHasher[int], basicEquator[int]),
+			"ThreadSafePriorityQueue": NewThreadSafePriorityQueue[int](intAscComparator, basicEquator[int]),
+			"ThreadSafeRingBuffer":    NewThreadSafeRingBuffer[int](5, OverwriteOldest, basicEquator[int]),
+			"COWSet":                  NewCOWSet[int, int](basicHasher[int], basicEquator[int]),
+		}
+		for name, c := range collections {
+			AddAll[int](c, 1, 2, 3)
+			snapshot := c.(Snapshotter[int]).Snapshot()
+			_, isSnapshotter := snapshot.(Snapshotter[int])
+			Expect(isSnapshotter).To(BeFalse(), name)
+			c.Add(4)
+			Expect(snapshot.ToArray()).To(ConsistOf(1, 2, 3), name)
+		}
+
+		for _, mt := range []mapType{threadSafeMap, cowMap} {
+			m := createMap[int, int, int](mt, basicHasher[int], basicEquator[int], intAscComparator)
+			m.Put(1, 1)
+			snapshot := m.(Snapshotter[Pair[int, int]]).Snapshot().(Map[int, int])
+			m.Put(1, 2)
+			value, _ := snapshot.Get(1)
+			Expect(value).To(Equal(1))
+		}
+	})
+})
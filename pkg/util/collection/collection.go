@@ -1,5 +1,14 @@
 package collection
 
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+// ErrEmpty is wrapped by the errors returned when popping from an empty collection
+var ErrEmpty = errors.New("the collection is empty")
+
 // Collection To avoid Value copy, you may want T to be pointer types.
 //  However, if T is a pointer type, we must make sure that the hash code remains the same.
 type Collection[T any] interface {
@@ -7,10 +16,128 @@ type Collection[T any] interface {
 	Add(item T) (oldItem T, replaced bool)
 	RemoveFirst(item T) bool
 	TryPop() (T, bool)
+	// Pop equals TryPop, but panics if the collection is empty
+	Pop() T
 	Has(item T) bool
+	// Contains equals Has
+	Contains(item T) bool
 	Len() int
 	Clear()
 	ToArray() []T // The order will not be guaranteed
+	// Range calls f for every item until f returns false, without copying all the items like ToArray.
+	//  Like ToArray, the order will not be guaranteed. The collection must not be modified in f.
+	Range(f func(item T) bool)
+	// All returns the sequence of the items, which equals iter.Seq[T]. Since go 1.23, it can be used in a `for range` loop.
+	All() func(yield func(T) bool)
+	// Clone returns a copy with the same configuration, like the hasher and the comparator.
+	//  The internal structure is copied, while the items are copied shallowly.
+	//  The returned Collection has the same type as the original one, e.g. cloning a Map returns a Map.
+	Clone() Collection[T]
+}
+
+// mustPop is the shared implementation of Pop. `name` is the type of the collection in the panic message.
+func mustPop[T any](c Collection[T], name string) T {
+	item, exists := c.TryPop()
+	if !exists {
+		panic(fmt.Sprintf("Pop from an empty %s.", name))
+	}
+	return item
+}
+
+func popOrError[T any](c Collection[T], name string) (item T, err error) {
+	item, exists := c.TryPop()
+	if !exists {
+		err = fmt.Errorf("pop from an empty %s: %w", name, ErrEmpty)
+	}
+	return
+}
+
+// Snapshotter is implemented by the thread-safe collections.
+type Snapshotter[T any] interface {
+	// Snapshot returns a consistent copy, which is taken under a single lock acquisition.
+	//  Unlike Clone, the returned Collection is not thread-safe, so it can be read without any lock.
+	Snapshot() Collection[T]
+}
+
+// OrderedCollection A collection that keeps the order of its items.
+//  For an OrderedCollection, Add equals AddLast and ToArray returns the items in order.
+type OrderedCollection[T any] interface {
+	Collection[T]
+	AddFirst(item T)
+	AddLast(item T)
+}
+
+// Iterator Next returns the next item. If there are no more items, exists will be false.
+//  If an Iterator is shared by multiple goroutines, rely on the result of Next rather than HasNext,
+//  because another goroutine may take the remaining items between the two calls.
+type Iterator[T any] interface {
+	HasNext() bool
+	Next() (item T, exists bool)
+}
+
+// SampleWithoutReplacement returns k distinct items of c chosen uniformly at random by reservoir sampling.
+//  c itself is not modified. An error is returned if k is negative or larger than c.Len().
+func SampleWithoutReplacement[T any](c Collection[T], k int, rng *rand.Rand) ([]T, error) {
+	items := c.ToArray()
+	if k < 0 || k > len(items) {
+		return nil, fmt.Errorf("cannot sample %d items from a collection of size %d", k, len(items))
+	}
+
+	reservoir := make([]T, k)
+	copy(reservoir, items[:k])
+	for i := k; i < len(items); i++ {
+		j := rng.Intn(i + 1)
+		if j < k {
+			reservoir[j] = items[i]
+		}
+	}
+	return reservoir, nil
+}
+
+// ToSlice equals c.ToArray()
+func ToSlice[T any](c Collection[T]) []T {
+	return c.ToArray()
+}
+
+// AddAll adds all the items to c. The items are added in bulk if c is a PriorityQueue or a PrioritySet.
+func AddAll[T any](c Collection[T], items ...T) {
+	if bulk, ok := c.(interface{ AddAll(items []T) }); ok {
+		bulk.AddAll(items)
+		return
+	}
+	for _, item := range items {
+		c.Add(item)
+	}
+}
+
+// Extend adds all the items of src to dst. It iterates a snapshot of src, so dst and src can be the same collection.
+func Extend[T any](dst Collection[T], src Collection[T]) {
+	AddAll(dst, src.ToArray()...)
+}
+
+// RemoveAll removes all the items that equal `item` from c, and returns the number of the removed items
+func RemoveAll[T any](c Collection[T], item T) int {
+	removed := 0
+	for c.RemoveFirst(item) {
+		removed++
+	}
+	return removed
+}
+
+// Filter removes the items that don't match predicate from c, and returns c.
+//  It works in place because there is no way to create an empty collection of the same kind as c.
+func Filter[T any](c Collection[T], predicate func(T) bool) Collection[T] {
+	for _, item := range c.ToArray() {
+		if !predicate(item) {
+			c.RemoveFirst(item)
+		}
+	}
+	return c
+}
+
+// IsEmpty returns true if c has no items
+func IsEmpty[T any](c Collection[T]) bool {
+	return c.Len() == 0
 }
 
 //type CollectionTool[T any] struct {
@@ -22,18 +149,6 @@ type Collection[T any] interface {
 //    // If the collection is empty, panic
 //}
 //
-//func (c *CollectionTool[T]) AddAll(collection Collection[T], items ...T) {
-//
-//}
-//
-//func (c *CollectionTool[T]) Extend(c1, c2 Collection[T]) {
-//
-//}
-//
-//func (c *CollectionTool[T]) RemoveAll(collection Collection[T], item T) {
-//
-//}
-//
 //func (c *CollectionTool[T]) IsSubset(c1, c2 Collection[T]) bool {
 //
 //}
@@ -42,15 +157,6 @@ type Collection[T any] interface {
 //
 //}
 //
-//func (c *CollectionTool[T]) Filter(collection Collection[T], func(T) bool) Collection[T] {
-//
-//}
-//
-//
 //func (c *CollectionTool[T]) Concatenate(c1, c2 Collection[T]) CollectionTool[T] {
 //
 //}
-//
-//func (c *CollectionTool[T]) IsEmpty(collection Collection[T]) bool {
-//
-//}
@@ -0,0 +1,386 @@
+package collection_test
+
+import (
+	"math/rand"
+	"sort"
+	"strconv"
+
+	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func expectContainsEqualsHas[T any](c Collection[T], items ...T) {
+	for _, item := range items {
+		Expect(c.Contains(item)).To(Equal(c.Has(item)))
+	}
+}
+
+var _ = Describe("Contains", func() {
+	It("works like Has for all the collections.", func() {
+		collections := map[string]Collection[int]{
+			"Set":           NewSet[int, int](fakeHasher, basicEquator[int]),
+			"ThreadSafeSet": NewThreadSafeSet[int, int](fakeHasher, basicEquator[int]),
+			"PrioritySet":   NewPrioritySet[int, int](intAscComparator, fakeHasher, basicEquator[int]),
+			"PriorityQueue": NewPriorityQueue[int](intAscComparator, basicEquator[int]),
+			"Deque":         NewDeque[int](basicEquator[int]),
+		}
+
+		for name, c := range collections {
+			By(name)
+			expectContainsEqualsHas(c, 0, 1)
+			Expect(c.Contains(1)).To(BeFalse())
+
+			c.Add(1)
+			// 2 has the same hash code as 1 but is absent
+			expectContainsEqualsHas(c, 0, 1, 2)
+			Expect(c.Contains(1)).To(BeTrue())
+			Expect(c.Contains(2)).To(BeFalse())
+
+			c.RemoveFirst(1)
+			expectContainsEqualsHas(c, 1)
+			Expect(c.Contains(1)).To(BeFalse())
+		}
+	})
+
+	It("works like Has for maps.", func() {
+		for _, m := range []Map[int, int]{
+			NewMap[int, int, int](fakeHasher, basicEquator[int]),
+			NewThreadSafeMap[int, int, int](fakeHasher, basicEquator[int]),
+			NewPriorityMap[int, int, int](intAscComparator, fakeHasher, basicEquator[int]),
+		} {
+			pair := Pair[int, int]{Key: 1, Value: 1}
+			expectContainsEqualsHas[Pair[int, int]](m, pair)
+			Expect(m.Contains(pair)).To(BeFalse())
+
+			m.Add(pair)
+			expectContainsEqualsHas[Pair[int, int]](m, pair, Pair[int, int]{Key: 2, Value: 1})
+			Expect(m.Contains(pair)).To(BeTrue())
+			Expect(m.Contains(Pair[int, int]{Key: 2, Value: 1})).To(BeFalse())
+		}
+	})
+})
+
+var _ = Describe("SampleWithoutReplacement", func() {
+	var set Set[int]
+	var rng *rand.Rand
+
+	BeforeEach(func() {
+		set = NewSet[int, int](basicHasher[int], basicEquator[int])
+		for i := 0; i < 5; i++ {
+			set.Add(i)
+		}
+		rng = rand.New(rand.NewSource(0))
+	})
+
+	It("returns k distinct items from the collection.", func() {
+		for k := 0; k <= 5; k++ {
+			sample, err := SampleWithoutReplacement[int](set, k, rng)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sample).To(HaveLen(k))
+
+			distinct := NewSet[int, int](basicHasher[int], basicEquator[int])
+			for _, item := range sample {
+				Expect(set.Has(item)).To(BeTrue())
+				_, replaced := distinct.Add(item)
+				Expect(replaced).To(BeFalse())
+			}
+		}
+		Expect(set.ToArray()).To(ConsistOf(0, 1, 2, 3, 4))
+	})
+
+	It("returns an error if k is out of range.", func() {
+		_, err := SampleWithoutReplacement[int](set, 6, rng)
+		Expect(err).To(HaveOccurred())
+		_, err = SampleWithoutReplacement[int](set, -1, rng)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("chooses every item with the same probability.", func() {
+		trials := 10000
+		k := 2
+		counts := map[int]int{}
+		for i := 0; i < trials; i++ {
+			sample, err := SampleWithoutReplacement[int](set, k, rng)
+			Expect(err).NotTo(HaveOccurred())
+			for _, item := range sample {
+				counts[item]++
+			}
+		}
+
+		expected := float64(trials*k) / float64(set.Len())
+		chiSquare := 0.0
+		for i := 0; i < set.Len(); i++ {
+			diff := float64(counts[i]) - expected
+			chiSquare += diff * diff / expected
+		}
+		// The critical value of the chi-square distribution with 4 degrees of freedom at p = 0.001
+		Expect(chiSquare).To(BeNumerically("<", 18.467))
+	})
+})
+
+var _ = Describe("Range", func() {
+	collectAll := func(seq func(yield func(int) bool)) []int {
+		result := []int{}
+		seq(func(item int) bool {
+			result = append(result, item)
+			return true
+		})
+		return result
+	}
+
+	It("works for all the collections.", func() {
+		collections := map[string]Collection[int]{
+			"Set":                     NewSet[int, int](basicHasher[int], basicEquator[int]),
+			"ThreadSafeSet":           NewThreadSafeSet[int, int](basicHasher[int], basicEquator[int]),
+			"PrioritySet":             NewPrioritySet[int, int](intAscComparator, basicHasher[int], basicEquator[int]),
+			"PriorityQueue":           NewPriorityQueue[int](intAscComparator, basicEquator[int]),
+			"Deque":                   NewDeque[int](basicEquator[int]),
+			"SortedDeque":             NewSortedDeque[int](intAscComparator, basicEquator[int]),
+			"ThreadSafePriorityQueue": NewThreadSafePriorityQueue[int](intAscComparator, basicEquator[int]),
+			"ThreadSafePrioritySet": NewThreadSafePrioritySet[int, int](
+				intAscComparator, basicHasher[int], basicEquator[int]),
+		}
+
+		for name, c := range collections {
+			By(name)
+			Expect(collectAll(c.Range)).To(BeEmpty())
+			for _, item := range []int{3, 1, 4, 5, 2} {
+				c.Add(item)
+			}
+			Expect(collectAll(c.Range)).To(ConsistOf(3, 1, 4, 5, 2))
+			Expect(collectAll(c.All())).To(ConsistOf(3, 1, 4, 5, 2))
+
+			visited := 0
+			c.Range(func(item int) bool {
+				visited++
+				return visited < 2
+			})
+			Expect(visited).To(Equal(2))
+			Expect(c.Len()).To(Equal(5))
+		}
+	})
+
+	It("works for maps.", func() {
+		for _, m := range []Map[int, string]{
+			NewMap[int, string, int](fakeHasher, basicEquator[int]),
+			NewThreadSafeMap[int, string, int](fakeHasher, basicEquator[int]),
+			NewPriorityMap[int, string, int](intAscComparator, fakeHasher, basicEquator[int]),
+		} {
+			for i := 0; i < 5; i++ {
+				m.Put(i, strconv.Itoa(i))
+			}
+
+			pairs := []Pair[int, string]{}
+			m.All()(func(pair Pair[int, string]) bool {
+				pairs = append(pairs, pair)
+				return true
+			})
+			Expect(pairs).To(ConsistOf(m.ToArray()))
+
+			Expect(collectAll(m.Keys())).To(ConsistOf(0, 1, 2, 3, 4))
+
+			values := []string{}
+			m.Values()(func(value string) bool {
+				values = append(values, value)
+				return len(values) < 3
+			})
+			Expect(values).To(HaveLen(3))
+
+			entries := map[int]string{}
+			m.KeyValues()(func(key int, value string) bool {
+				entries[key] = value
+				return true
+			})
+			Expect(entries).To(Equal(map[int]string{0: "0", 1: "1", 2: "2", 3: "3", 4: "4"}))
+		}
+	})
+})
+
+var _ = Describe("CollectionTool", func() {
+	var deque Deque[int]
+	var set Set[int]
+
+	BeforeEach(func() {
+		deque = NewDeque[int](basicEquator[int])
+		set = NewSet[int, int](basicHasher[int], basicEquator[int])
+	})
+
+	It("can add all the items.", func() {
+		AddAll[int](deque, 1, 2, 2, 3)
+		Expect(deque.ToArray()).To(Equal([]int{1, 2, 2, 3}))
+		AddAll[int](set, 1, 2, 2, 3)
+		Expect(set.ToArray()).To(ConsistOf(1, 2, 3))
+		AddAll[int](set)
+		Expect(set.Len()).To(Equal(3))
+	})
+
+	It("can extend a collection with another one.", func() {
+		AddAll[int](deque, 1, 2)
+		AddAll[int](set, 2, 3)
+		Extend[int](set, deque)
+		Expect(set.ToArray()).To(ConsistOf(1, 2, 3))
+		Extend[int](deque, deque)
+		Expect(deque.ToArray()).To(Equal([]int{1, 2, 1, 2}))
+	})
+
+	It("can remove all the equal items.", func() {
+		AddAll[int](deque, 1, 2, 1, 3, 1)
+		Expect(RemoveAll[int](deque, 1)).To(Equal(3))
+		Expect(deque.ToArray()).To(Equal([]int{2, 3}))
+		Expect(RemoveAll[int](deque, 1)).To(Equal(0))
+	})
+
+	It("can filter a collection in place.", func() {
+		AddAll[int](deque, 1, 2, 3, 4, 5, 6)
+		isEven := func(item int) bool {
+			return item%2 == 0
+		}
+		Expect(Filter[int](deque, isEven)).To(BeIdenticalTo(deque))
+		Expect(deque.ToArray()).To(Equal([]int{2, 4, 6}))
+
+		m := NewMap[int, string, int](basicHasher[int], basicEquator[int])
+		m.Put(1, "a")
+		m.Put(2, "b")
+		Filter[Pair[int, string]](m, func(pair Pair[int, string]) bool {
+			return pair.Value == "b"
+		})
+		Expect(m.ToArray()).To(Equal([]Pair[int, string]{{Key: 2, Value: "b"}}))
+	})
+
+	It("can check if a collection is empty.", func() {
+		Expect(IsEmpty[int](set)).To(BeTrue())
+		set.Add(1)
+		Expect(IsEmpty[int](set)).To(BeFalse())
+	})
+})
+
+var _ = Describe("Bulk constructors", func() {
+	It("can create a set from a slice.", func() {
+		set := NewSetFromSlice[int, int]([]int{3, 1, 3, 2}, basicHasher[int], basicEquator[int])
+		Expect(set.Len()).To(Equal(3))
+		Expect(ToSlice[int](set)).To(ConsistOf(1, 2, 3))
+	})
+
+	It("can convert between Map and native maps.", func() {
+		native := map[string]int{"a": 1, "b": 2}
+		m := NewMapFromNativeMap[string, int, string](native, basicHasher[string], basicEquator[string])
+		Expect(m.Len()).To(Equal(2))
+		value, exists := m.Get("b")
+		Expect(exists).To(BeTrue())
+		Expect(value).To(Equal(2))
+
+		m.Put("c", 3)
+		Expect(ToNativeMap(m)).To(Equal(map[string]int{"a": 1, "b": 2, "c": 3}))
+		Expect(native).To(HaveLen(2))
+	})
+
+	It("can create a valid heap from a slice.", func() {
+		for _, length := range []int{0, 1, 2, 10, 100} {
+			items := getRandomArray(length)
+			queue := NewPriorityQueueFromSlice[int](items, intAscComparator, basicEquator[int])
+			Expect(queue.Len()).To(Equal(length))
+
+			queue.Add(-1)
+			Expect(queue.RemoveFirst(-1)).To(BeTrue())
+			actual := []int{}
+			for item, exists := queue.TryPop(); exists; item, exists = queue.TryPop() {
+				actual = append(actual, item)
+			}
+			sort.Ints(items)
+			Expect(actual).To(Equal(append([]int{}, items...)))
+		}
+	})
+})
+
+var _ = Describe("Pop", func() {
+	creators := map[string]func() Collection[int]{
+		"Deque": func() Collection[int] { return NewDeque[int](basicEquator[int]) },
+		"Queue": func() Collection[int] { return NewQueue[int](basicEquator[int]) },
+		"Stack": func() Collection[int] { return NewStack[int](basicEquator[int]) },
+		"RingBuffer": func() Collection[int] {
+			return NewRingBuffer[int](4, OverwriteOldest, basicEquator[int])
+		},
+		"ConcurrentSortedSet": func() Collection[int] {
+			return NewConcurrentSortedSet[int](intStrictAscComparator)
+		},
+		"PriorityQueue": func() Collection[int] {
+			return NewPriorityQueue[int](intAscComparator, basicEquator[int])
+		},
+		"SortedSlice": func() Collection[int] {
+			return NewSortedSlice[int](intStrictAscComparator)
+		},
+	}
+	for _, st := range []setType{defaultSet, threadSafeSet, prioritySet, threadSafePrioritySet, cowSet} {
+		st := st
+		creators[string(st)] = func() Collection[int] {
+			return createSet[int, int](st, basicHasher[int], basicEquator[int], intAscComparator)
+		}
+	}
+
+	for name, create := range creators {
+		create := create
+		It("pops the item or panics with "+name+".", func() {
+			c := create()
+			c.Add(1)
+			Expect(c.Pop()).To(Equal(1))
+			Expect(c.Len()).To(Equal(0))
+			Expect(func() { c.Pop() }).To(PanicWith(ContainSubstring("Pop from an empty")))
+		})
+	}
+
+	for _, mt := range []mapType{defaultMap, threadSafeMap, concurrentMap, lruCache, orderedMap, priorityMap, cowMap} {
+		mt := mt
+		It("pops the pair or panics with "+string(mt)+".", func() {
+			m := createMap[int, string, int](mt, basicHasher[int], basicEquator[int], intAscComparator)
+			m.Put(1, "a")
+			Expect(m.Pop()).To(Equal(Pair[int, string]{Key: 1, Value: "a"}))
+			Expect(func() { m.Pop() }).To(Panic())
+		})
+	}
+})
+
+var _ = Describe("PopOrError and Peek", func() {
+	creators := map[string]func() PriorityCollection[int]{
+		"PriorityQueue": func() PriorityCollection[int] {
+			return NewPriorityQueue[int](intAscComparator, basicEquator[int])
+		},
+		"IndexedPriorityQueue": func() PriorityCollection[int] {
+			return NewIndexedPriorityQueue[int, int](intAscComparator, basicHasher[int], basicEquator[int])
+		},
+		"PrioritySet": func() PriorityCollection[int] {
+			return NewPrioritySet[int, int](intAscComparator, basicHasher[int], basicEquator[int])
+		},
+		"SortedDeque": func() PriorityCollection[int] {
+			return NewSortedDeque[int](intAscComparator, basicEquator[int]).(PriorityCollection[int])
+		},
+	}
+
+	for name, create := range creators {
+		name, create := name, create
+		It("returns an error instead of panicking with "+name+".", func() {
+			c := create()
+			AddAll[int](c, 2, 1)
+			item, err := c.PopOrError()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(item).To(Equal(1))
+			c.Pop()
+
+			_, err = c.PopOrError()
+			Expect(err).To(MatchError(ErrEmpty))
+			Expect(err.Error()).To(ContainSubstring(name))
+			Expect(func() { c.Peek() }).To(PanicWith("Peek from an empty " + name + "."))
+		})
+	}
+
+	It("delegates to the wrapped collection with the thread-safe ones.", func() {
+		for name, c := range map[string]PriorityCollection[int]{
+			"PriorityQueue": NewThreadSafePriorityQueue[int](intAscComparator, basicEquator[int]),
+			"PrioritySet":   NewThreadSafePrioritySet[int, int](intAscComparator, basicHasher[int], basicEquator[int]),
+		} {
+			_, err := c.PopOrError()
+			Expect(err).To(MatchError(ContainSubstring(name)))
+			Expect(func() { c.Peek() }).To(PanicWith("Peek from an empty " + name + "."))
+		}
+	})
+})
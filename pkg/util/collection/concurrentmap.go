@@ -0,0 +1,291 @@
+package collection
+
+import (
+	"fmt"
+	"hash/maphash"
+	"reflect"
+)
+
+// NewConcurrentMap returns a Map that partitions the keys into `shards` thread-safe maps by the hash codes, so that
+//  the operations on the keys in different shards don't block each other.
+//  The operations on a single key are atomic, while the operations on the whole map, like Len, ToArray and Clear,
+//  visit the shards one by one and are not atomic.
+func NewConcurrentMap[K any, V any, C comparable](shards int, hasher Hasher[K, C], equaler Equaler[K]) Map[K, V] {
+	if shards <= 0 {
+		panic(fmt.Errorf("shards should be positive"))
+	}
+
+	m := &concurrentMap[K, V, C]{
+		shards: make([]Map[K, V], shards),
+		hasher: hasher,
+	}
+	for i := range m.shards {
+		m.shards[i] = NewThreadSafeMap[K, V, C](hasher, equaler)
+	}
+	return m
+}
+
+type concurrentMap[K any, V any, C comparable] struct {
+	shards []Map[K, V]
+	hasher Hasher[K, C]
+}
+
+func (m *concurrentMap[K, V, C]) shard(key K) Map[K, V] {
+	return m.shards[shardIndex(m.hasher(key), len(m.shards))]
+}
+
+// shardIndex We can't do arithmetic on a comparable type parameter, so the common types of hash codes are converted
+//  directly without allocation, and the others are hashed by reflection.
+func shardIndex[C comparable](hash C, shards int) int {
+	var code uint64
+	switch h := any(hash).(type) {
+	case int:
+		code = uint64(h)
+	case int8:
+		code = uint64(h)
+	case int16:
+		code = uint64(h)
+	case int32:
+		code = uint64(h)
+	case int64:
+		code = uint64(h)
+	case uint:
+		code = uint64(h)
+	case uint8:
+		code = uint64(h)
+	case uint16:
+		code = uint64(h)
+	case uint32:
+		code = uint64(h)
+	case uint64:
+		code = h
+	case uintptr:
+		code = uint64(h)
+	case string:
+		code = fnv64a(h)
+	default:
+		code = shardCodeOf(hash)
+	}
+	return int(code % uint64(shards))
+}
+
+// shardSeed makes the shard codes of the same hash code stay the same in a process
+var shardSeed = maphash.MakeSeed()
+
+// shardCodeOf handles the less common types, including the named types like `type ID string`.
+//  The hash codes equal by `==` must get the same code, so they are written in the same way as StructHasher does.
+func shardCodeOf[C comparable](hash C) uint64 {
+	value := reflect.ValueOf(hash)
+	switch value.Kind() {
+	case reflect.Invalid: // A nil interface
+		return 0
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return value.Uint()
+	case reflect.String:
+		return fnv64a(value.String())
+	case reflect.Bool:
+		if value.Bool() {
+			return 1
+		}
+		return 0
+	default:
+		hasher := maphash.Hash{}
+		hasher.SetSeed(shardSeed)
+		writeFieldValue(&hasher, value)
+		return hasher.Sum64()
+	}
+}
+
+// fnv64a equals hashing s with fnv.New64a, but doesn't convert s to []byte
+func fnv64a(s string) uint64 {
+	const (
+		offset64 = 14695981039346656037
+		prime64  = 1099511628211
+	)
+	code := uint64(offset64)
+	for i := 0; i < len(s); i++ {
+		code ^= uint64(s[i])
+		code *= prime64
+	}
+	return code
+}
+
+func (m *concurrentMap[K, V, C]) ToArray() []Pair[K, V] {
+	result := []Pair[K, V]{}
+	for _, shard := range m.shards {
+		result = append(result, shard.ToArray()...)
+	}
+	return result
+}
+
+// Range holds the read lock of a shard while iterating it, so f must not modify m, or it may be deadlocked.
+func (m *concurrentMap[K, V, C]) Range(f func(pair Pair[K, V]) bool) {
+	goNext := true
+	for _, shard := range m.shards {
+		shard.Range(func(pair Pair[K, V]) bool {
+			goNext = f(pair)
+			return goNext
+		})
+		if !goNext {
+			return
+		}
+	}
+}
+
+func (m *concurrentMap[K, V, C]) All() func(yield func(Pair[K, V]) bool) {
+	return m.Range
+}
+
+func (m *concurrentMap[K, V, C]) Keys() func(yield func(K) bool) {
+	return keysOf[K, V](m)
+}
+
+func (m *concurrentMap[K, V, C]) Values() func(yield func(V) bool) {
+	return valuesOf[K, V](m)
+}
+
+func (m *concurrentMap[K, V, C]) KeyValues() func(yield func(K, V) bool) {
+	return keyValuesOf[K, V](m)
+}
+
+func (m *concurrentMap[K, V, C]) Add(pair Pair[K, V]) (oldItem Pair[K, V], replaced bool) {
+	return m.shard(pair.Key).Add(pair)
+}
+
+func (m *concurrentMap[K, V, C]) RemoveFirst(pair Pair[K, V]) bool {
+	return m.shard(pair.Key).RemoveFirst(pair)
+}
+
+func (m *concurrentMap[K, V, C]) Has(pair Pair[K, V]) bool {
+	return m.shard(pair.Key).Has(pair)
+}
+
+func (m *concurrentMap[K, V, C]) Contains(pair Pair[K, V]) bool {
+	return m.Has(pair)
+}
+
+func (m *concurrentMap[K, V, C]) TryPop() (pair Pair[K, V], exists bool) {
+	for _, shard := range m.shards {
+		pair, exists = shard.TryPop()
+		if exists {
+			return
+		}
+	}
+	return
+}
+
+func (m *concurrentMap[K, V, C]) Pop() Pair[K, V] {
+	return mustPop[Pair[K, V]](m, "ConcurrentMap")
+}
+
+func (m *concurrentMap[K, V, C]) Len() int {
+	result := 0
+	for _, shard := range m.shards {
+		result += shard.Len()
+	}
+	return result
+}
+
+func (m *concurrentMap[K, V, C]) Size() int {
+	return m.Len()
+}
+
+func (m *concurrentMap[K, V, C]) Empty() bool {
+	return m.Size() == 0
+}
+
+func (m *concurrentMap[K, V, C]) Clear() {
+	for _, shard := range m.shards {
+		shard.Clear()
+	}
+}
+
+// Clone locks the shards one by one, so the copy is only consistent within each shard
+func (m *concurrentMap[K, V, C]) Clone() Collection[Pair[K, V]] {
+	shards := make([]Map[K, V], len(m.shards))
+	for i, shard := range m.shards {
+		shards[i] = CloneMap(shard)
+	}
+	return &concurrentMap[K, V, C]{
+		shards: shards,
+		hasher: m.hasher,
+	}
+}
+
+func (m *concurrentMap[K, V, C]) Compact() {
+	for _, shard := range m.shards {
+		if compactor, ok := shard.(Compactor); ok {
+			compactor.Compact()
+		}
+	}
+}
+
+func (m *concurrentMap[K, V, C]) ContainsKey(key K) bool {
+	return m.shard(key).ContainsKey(key)
+}
+
+func (m *concurrentMap[K, V, C]) Put(key K, value V) (old V, exists bool) {
+	return m.shard(key).Put(key, value)
+}
+
+func (m *concurrentMap[K, V, C]) Get(key K) (value V, exists bool) {
+	return m.shard(key).Get(key)
+}
+
+func (m *concurrentMap[K, V, C]) Remove(key K) (old V, exists bool) {
+	return m.shard(key).Remove(key)
+}
+
+func (m *concurrentMap[K, V, C]) GetOrPutDefault(key K) (value V, exists bool) {
+	return m.shard(key).GetOrPutDefault(key)
+}
+
+func (m *concurrentMap[K, V, C]) ReplaceIfEqual(key K, expectedOld V, newValue V, valueEqualer Equaler[V]) bool {
+	return m.shard(key).ReplaceIfEqual(key, expectedOld, newValue, valueEqualer)
+}
+
+func (m *concurrentMap[K, V, C]) GetOrPut(key K, f func() V) (value V, exists bool) {
+	return m.shard(key).GetOrPut(key, f)
+}
+
+func (m *concurrentMap[K, V, C]) ComputeIfAbsent(key K, mapping func(key K) V) V {
+	return m.shard(key).ComputeIfAbsent(key, mapping)
+}
+
+func (m *concurrentMap[K, V, C]) ComputeIfPresent(key K,
+	remapping func(key K, old V) (newValue V, keep bool)) (value V, exists bool) {
+	return m.shard(key).ComputeIfPresent(key, remapping)
+}
+
+func (m *concurrentMap[K, V, C]) Merge(key K, value V, remapping func(old V, new V) V) V {
+	return m.shard(key).Merge(key, value, remapping)
+}
+
+func (m *concurrentMap[K, V, C]) PutIfAbsent(key K, value V) (existing V, exists bool) {
+	return m.shard(key).PutIfAbsent(key, value)
+}
+
+func (m *concurrentMap[K, V, C]) RemoveIf(key K, predicate func(value V) bool) bool {
+	return m.shard(key).RemoveIf(key, predicate)
+}
+
+// MarshalBinary encodes the pairs with gob. The hasher and the equaler are not encoded.
+func (m *concurrentMap[K, V, C]) MarshalBinary() ([]byte, error) {
+	return gobEncode(m.ToArray())
+}
+
+// UnmarshalBinary replaces the content of m with the decoded pairs.
+func (m *concurrentMap[K, V, C]) UnmarshalBinary(data []byte) error {
+	var pairs []Pair[K, V]
+	if err := gobDecode(data, &pairs); err != nil {
+		return err
+	}
+
+	m.Clear()
+	for _, pair := range pairs {
+		m.Put(pair.Key, pair.Value)
+	}
+	return nil
+}
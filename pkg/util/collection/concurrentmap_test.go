@@ -0,0 +1,137 @@
+package collection_test
+
+import (
+	"math"
+	"strconv"
+	"sync"
+
+	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type structHash struct {
+	a int
+	b string
+}
+
+var _ = Describe("ConcurrentMap", func() {
+	testMap(concurrentMap)
+
+	It("panics if shards is not positive.", func() {
+		Expect(func() {
+			NewConcurrentMap[int, int, int](0, basicHasher[int], basicEquator[int])
+		}).To(Panic())
+	})
+
+	It("works with any comparable hash codes.", func() {
+		m := NewConcurrentMap[structHash, int, structHash](3, basicHasher[structHash], basicEquator[structHash])
+		for i := 0; i < 10; i++ {
+			m.Put(structHash{a: i, b: "b"}, i)
+		}
+		Expect(m.Len()).To(Equal(10))
+		value, exists := m.Get(structHash{a: 5, b: "b"})
+		Expect(exists).To(BeTrue())
+		Expect(value).To(Equal(5))
+
+		for i := 0; i < 10; i++ {
+			_, exists = m.TryPop()
+			Expect(exists).To(BeTrue())
+		}
+		_, exists = m.TryPop()
+		Expect(exists).To(BeFalse())
+	})
+
+	It("works with the hash codes of named types.", func() {
+		type userID string
+		m := NewConcurrentMap[string, int, userID](4, func(key string) userID {
+			return userID(key)
+		}, basicEquator[string])
+		for i := 0; i < 100; i++ {
+			m.Put(strconv.Itoa(i), i)
+		}
+		Expect(m.Len()).To(Equal(100))
+		for i := 0; i < 100; i++ {
+			value, _ := m.Get(strconv.Itoa(i))
+			Expect(value).To(Equal(i))
+		}
+	})
+
+	It("keeps the pointer hash codes in the same shard when the pointees change.", func() {
+		type node struct {
+			value int
+		}
+		m := NewConcurrentMap[*node, int, *node](16, basicHasher[*node], basicEquator[*node])
+		nodes := make([]*node, 64)
+		for i := range nodes {
+			nodes[i] = &node{value: i}
+			m.Put(nodes[i], i)
+		}
+		for i, n := range nodes {
+			n.value = -i - 1
+			value, exists := m.Get(n)
+			Expect(exists).To(BeTrue())
+			Expect(value).To(Equal(i))
+		}
+	})
+
+	It("puts the equal float hash codes in the same shard.", func() {
+		m := NewConcurrentMap[float64, int, float64](16, basicHasher[float64], basicEquator[float64])
+		m.Put(0.0, 1)
+		value, exists := m.Get(math.Copysign(0, -1))
+		Expect(exists).To(BeTrue())
+		Expect(value).To(Equal(1))
+
+		type point struct {
+			x float64
+			p *int
+		}
+		n := 1
+		first := point{x: 0.0, p: &n}
+		second := point{x: math.Copysign(0, -1), p: &n}
+		pointMap := NewConcurrentMap[point, int, point](16, basicHasher[point], basicEquator[point])
+		pointMap.Put(first, 1)
+		value, exists = pointMap.Get(second)
+		Expect(exists).To(BeTrue())
+		Expect(value).To(Equal(1))
+	})
+
+	It("stops ranging across the shards when f returns false.", func() {
+		m := NewConcurrentMap[int, int, int](4, basicHasher[int], basicEquator[int])
+		for i := 0; i < 100; i++ {
+			m.Put(i, i)
+		}
+		visited := 0
+		m.Range(func(pair Pair[int, int]) bool {
+			visited++
+			return visited < 10
+		})
+		Expect(visited).To(Equal(10))
+	})
+
+	It("can be used by multiple goroutines concurrently.", func() {
+		m := NewConcurrentMap[string, int, string](8, basicHasher[string], basicEquator[string])
+		wait := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wait.Add(1)
+			i := i
+			go func() {
+				defer wait.Done()
+				for j := 0; j < 100; j++ {
+					key := strconv.Itoa(i*100 + j)
+					m.Put(key, j)
+					m.ReplaceIfEqual(key, j, j+1, basicEquator[int])
+					m.GetOrPutDefault("default")
+				}
+				m.Len()
+				m.ToArray()
+			}()
+		}
+		wait.Wait()
+
+		Expect(m.Len()).To(Equal(1001))
+		value, exists := m.Get("999")
+		Expect(exists).To(BeTrue())
+		Expect(value).To(Equal(100))
+	})
+})
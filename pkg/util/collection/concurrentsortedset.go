@@ -0,0 +1,301 @@
+package collection
+
+import (
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// ConcurrentSortedSet A thread-safe set sorted by a comparator, which is backed by a lazy skip list.
+//  Has, Range and ToArray don't take any locks, and Add and RemoveFirst only lock the nodes around the item,
+//  so the operations on different items rarely block each other.
+//  ToArray and Range return the items in the ascending order. They are weakly consistent: the concurrent
+//  modifications may or may not be reflected.
+type ConcurrentSortedSet[T any] interface {
+	Set[T]
+}
+
+const skipListMaxLevel = 32
+
+// NewConcurrentSortedSet The comparator must be a strict order, because two items are regarded as equal if neither of
+//  them is less than the other. Add doesn't replace an existing item, so the returned oldItem is the existing one.
+func NewConcurrentSortedSet[T any](comparator Comparator[T]) ConcurrentSortedSet[T] {
+	return &concurrentSortedSet[T]{
+		head:       newSkipListNode[T](*new(T), skipListMaxLevel-1),
+		comparator: comparator,
+	}
+}
+
+type skipListNode[T any] struct {
+	item     T
+	next     []atomic.Value // *skipListNode[T]
+	topLevel int
+	// marked is 1 if the node is being removed
+	marked int32
+	// fullyLinked is 1 if the node has been linked in all its levels
+	fullyLinked int32
+	l           sync.Mutex
+}
+
+func newSkipListNode[T any](item T, topLevel int) *skipListNode[T] {
+	return &skipListNode[T]{
+		item:     item,
+		next:     make([]atomic.Value, topLevel+1),
+		topLevel: topLevel,
+	}
+}
+
+func (n *skipListNode[T]) loadNext(level int) *skipListNode[T] {
+	next, _ := n.next[level].Load().(*skipListNode[T])
+	return next
+}
+
+func (n *skipListNode[T]) storeNext(level int, next *skipListNode[T]) {
+	n.next[level].Store(next)
+}
+
+func (n *skipListNode[T]) isMarked() bool {
+	return atomic.LoadInt32(&n.marked) == 1
+}
+
+func (n *skipListNode[T]) isFullyLinked() bool {
+	return atomic.LoadInt32(&n.fullyLinked) == 1
+}
+
+type concurrentSortedSet[T any] struct {
+	head       *skipListNode[T]
+	comparator Comparator[T]
+	size       int64
+}
+
+func (s *concurrentSortedSet[T]) equals(first, second T) bool {
+	return !s.comparator(first, second) && !s.comparator(second, first)
+}
+
+// find fills preds and succs of every level, and returns the highest level where the item is found, or -1
+func (s *concurrentSortedSet[T]) find(item T, preds, succs []*skipListNode[T]) int {
+	found := -1
+	pred := s.head
+	for level := skipListMaxLevel - 1; level >= 0; level-- {
+		curr := pred.loadNext(level)
+		for curr != nil && s.comparator(curr.item, item) {
+			pred = curr
+			curr = pred.loadNext(level)
+		}
+		if found == -1 && curr != nil && s.equals(item, curr.item) {
+			found = level
+		}
+		preds[level] = pred
+		succs[level] = curr
+	}
+	return found
+}
+
+// lockPreds locks the distinct preds from level 0 to topLevel, until validate returns false.
+//  The same pred can only appear in consecutive levels, and the preds are locked in the descending order of the items.
+//  It returns the highest locked level and whether all the levels are valid.
+func (s *concurrentSortedSet[T]) lockPreds(preds []*skipListNode[T], topLevel int,
+	validate func(level int) bool) (highestLocked int, valid bool) {
+	highestLocked = -1
+	valid = true
+	var prevPred *skipListNode[T]
+	for level := 0; valid && level <= topLevel; level++ {
+		if preds[level] != prevPred {
+			preds[level].l.Lock()
+			prevPred = preds[level]
+		}
+		highestLocked = level
+		valid = validate(level)
+	}
+	return
+}
+
+func (s *concurrentSortedSet[T]) unlockPreds(preds []*skipListNode[T], highestLocked int) {
+	var prevPred *skipListNode[T]
+	for level := 0; level <= highestLocked; level++ {
+		if preds[level] != prevPred {
+			preds[level].l.Unlock()
+			prevPred = preds[level]
+		}
+	}
+}
+
+func randomLevel() int {
+	level := 0
+	for level < skipListMaxLevel-1 && rand.Intn(2) == 0 {
+		level++
+	}
+	return level
+}
+
+func (s *concurrentSortedSet[T]) Add(item T) (oldItem T, replaced bool) {
+	topLevel := randomLevel()
+	preds := make([]*skipListNode[T], skipListMaxLevel)
+	succs := make([]*skipListNode[T], skipListMaxLevel)
+	for {
+		found := s.find(item, preds, succs)
+		if found != -1 {
+			existing := succs[found]
+			if !existing.isMarked() {
+				for !existing.isFullyLinked() {
+					runtime.Gosched()
+				}
+				return existing.item, true
+			}
+			// The existing one is being removed, so try again
+			runtime.Gosched()
+			continue
+		}
+
+		highestLocked, valid := s.lockPreds(preds, topLevel, func(level int) bool {
+			succ := succs[level]
+			return !preds[level].isMarked() && (succ == nil || !succ.isMarked()) && preds[level].loadNext(level) == succ
+		})
+		if !valid {
+			s.unlockPreds(preds, highestLocked)
+			continue
+		}
+
+		node := newSkipListNode[T](item, topLevel)
+		for level := 0; level <= topLevel; level++ {
+			node.storeNext(level, succs[level])
+		}
+		for level := 0; level <= topLevel; level++ {
+			preds[level].storeNext(level, node)
+		}
+		atomic.StoreInt32(&node.fullyLinked, 1)
+		atomic.AddInt64(&s.size, 1)
+		s.unlockPreds(preds, highestLocked)
+		replaced = false
+		return
+	}
+}
+
+func (s *concurrentSortedSet[T]) RemoveFirst(item T) bool {
+	preds := make([]*skipListNode[T], skipListMaxLevel)
+	succs := make([]*skipListNode[T], skipListMaxLevel)
+	var victim *skipListNode[T]
+	isMarked := false
+	for {
+		found := s.find(item, preds, succs)
+		if !isMarked {
+			if found == -1 {
+				return false
+			}
+			victim = succs[found]
+			// A node that is not fully linked or found below its top level is still being added
+			if !victim.isFullyLinked() || victim.topLevel != found || victim.isMarked() {
+				return false
+			}
+
+			victim.l.Lock()
+			if victim.isMarked() {
+				victim.l.Unlock()
+				return false
+			}
+			atomic.StoreInt32(&victim.marked, 1)
+			isMarked = true
+		}
+
+		highestLocked, valid := s.lockPreds(preds, victim.topLevel, func(level int) bool {
+			return !preds[level].isMarked() && preds[level].loadNext(level) == victim
+		})
+		if !valid {
+			s.unlockPreds(preds, highestLocked)
+			continue
+		}
+
+		for level := victim.topLevel; level >= 0; level-- {
+			preds[level].storeNext(level, victim.loadNext(level))
+		}
+		atomic.AddInt64(&s.size, -1)
+		victim.l.Unlock()
+		s.unlockPreds(preds, highestLocked)
+		return true
+	}
+}
+
+func (s *concurrentSortedSet[T]) Has(item T) bool {
+	preds := make([]*skipListNode[T], skipListMaxLevel)
+	succs := make([]*skipListNode[T], skipListMaxLevel)
+	found := s.find(item, preds, succs)
+	return found != -1 && succs[found].isFullyLinked() && !succs[found].isMarked()
+}
+
+func (s *concurrentSortedSet[T]) Contains(item T) bool {
+	return s.Has(item)
+}
+
+// TryPop removes the least item
+func (s *concurrentSortedSet[T]) TryPop() (item T, exists bool) {
+	for {
+		first, exists := s.first()
+		if !exists {
+			return first, false
+		}
+		if s.RemoveFirst(first) {
+			return first, true
+		}
+		// Another goroutine has removed it, so try the next one
+	}
+}
+
+func (s *concurrentSortedSet[T]) Pop() T {
+	return mustPop[T](s, "ConcurrentSortedSet")
+}
+
+func (s *concurrentSortedSet[T]) first() (item T, exists bool) {
+	for node := s.head.loadNext(0); node != nil; node = node.loadNext(0) {
+		if node.isFullyLinked() && !node.isMarked() {
+			return node.item, true
+		}
+	}
+	exists = false
+	return
+}
+
+func (s *concurrentSortedSet[T]) Len() int {
+	return int(atomic.LoadInt64(&s.size))
+}
+
+// Clear removes the items existing when it is called. The items added concurrently may be kept.
+func (s *concurrentSortedSet[T]) Clear() {
+	for _, item := range s.ToArray() {
+		s.RemoveFirst(item)
+	}
+}
+
+// Clone copies the items that are in s during the iteration, like Range
+func (s *concurrentSortedSet[T]) Clone() Collection[T] {
+	cloned := NewConcurrentSortedSet[T](s.comparator)
+	s.Range(func(item T) bool {
+		cloned.Add(item)
+		return true
+	})
+	return cloned
+}
+
+func (s *concurrentSortedSet[T]) Range(f func(item T) bool) {
+	for node := s.head.loadNext(0); node != nil; node = node.loadNext(0) {
+		if !node.isFullyLinked() || node.isMarked() {
+			continue
+		}
+		if !f(node.item) {
+			return
+		}
+	}
+}
+
+func (s *concurrentSortedSet[T]) All() func(yield func(T) bool) {
+	return s.Range
+}
+
+func (s *concurrentSortedSet[T]) ToArray() []T {
+	result := []T{}
+	s.Range(func(item T) bool {
+		result = append(result, item)
+		return true
+	})
+	return result
+}
@@ -0,0 +1,124 @@
+package collection_test
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+
+	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ConcurrentSortedSet", func() {
+	var set ConcurrentSortedSet[int]
+
+	BeforeEach(func() {
+		set = NewConcurrentSortedSet[int](intStrictAscComparator)
+	})
+
+	It("keeps the items sorted and unique.", func() {
+		expected := map[int]bool{}
+		for _, item := range rand.Perm(100) {
+			_, replaced := set.Add(item % 50)
+			Expect(replaced).To(Equal(expected[item%50]))
+			expected[item%50] = true
+		}
+		Expect(set.Len()).To(Equal(50))
+		Expect(set.ToArray()).To(Equal(getSequence(50)))
+
+		old, replaced := set.Add(10)
+		Expect(replaced).To(BeTrue())
+		Expect(old).To(Equal(10))
+	})
+
+	It("can remove and pop the items.", func() {
+		for _, item := range []int{3, 1, 2} {
+			set.Add(item)
+		}
+		Expect(set.Has(2)).To(BeTrue())
+		Expect(set.RemoveFirst(2)).To(BeTrue())
+		Expect(set.Has(2)).To(BeFalse())
+		Expect(set.RemoveFirst(2)).To(BeFalse())
+		Expect(set.Contains(3)).To(BeTrue())
+
+		item, exists := set.TryPop()
+		Expect(exists).To(BeTrue())
+		Expect(item).To(Equal(1))
+		Expect(set.ToArray()).To(Equal([]int{3}))
+
+		set.Clear()
+		Expect(set.Len()).To(Equal(0))
+		_, exists = set.TryPop()
+		Expect(exists).To(BeFalse())
+	})
+
+	It("stops ranging when f returns false.", func() {
+		for i := 0; i < 10; i++ {
+			set.Add(i)
+		}
+		visited := []int{}
+		set.Range(func(item int) bool {
+			visited = append(visited, item)
+			return len(visited) < 3
+		})
+		Expect(visited).To(Equal([]int{0, 1, 2}))
+	})
+
+	It("can be modified by multiple goroutines concurrently.", func() {
+		wait := sync.WaitGroup{}
+		for i := 0; i < 8; i++ {
+			wait.Add(1)
+			i := i
+			go func() {
+				defer GinkgoRecover()
+				defer wait.Done()
+				// Every goroutine adds [0, 1000) and removes its own odd items, so the goroutines contend on every item
+				for j := 0; j < 1000; j++ {
+					set.Add(j)
+					set.Has(j + 1)
+					if j%8 == i && j%2 == 1 {
+						Expect(set.RemoveFirst(j)).To(BeTrue())
+					}
+				}
+				set.ToArray()
+			}()
+		}
+		wait.Wait()
+
+		items := set.ToArray()
+		Expect(sort.IntsAreSorted(items)).To(BeTrue())
+		Expect(set.Len()).To(Equal(len(items)))
+		for i := 0; i < 1000; i += 2 {
+			Expect(set.Has(i)).To(BeTrue())
+		}
+	})
+
+	It("pops every item exactly once by multiple goroutines.", func() {
+		for i := 0; i < 1000; i++ {
+			set.Add(i)
+		}
+		popped := make([][]int, 4)
+		wait := sync.WaitGroup{}
+		for i := range popped {
+			wait.Add(1)
+			i := i
+			go func() {
+				defer wait.Done()
+				for item, exists := set.TryPop(); exists; item, exists = set.TryPop() {
+					popped[i] = append(popped[i], item)
+				}
+			}()
+		}
+		wait.Wait()
+
+		all := []int{}
+		for _, items := range popped {
+			Expect(sort.IntsAreSorted(items)).To(BeTrue())
+			all = append(all, items...)
+		}
+		sort.Ints(all)
+		Expect(all).To(Equal(getSequence(1000)))
+		Expect(set.Len()).To(Equal(0))
+	})
+})
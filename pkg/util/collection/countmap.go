@@ -0,0 +1,100 @@
+package collection
+
+// CountMap is a multiset, which records how many times each item occurs.
+//  Items whose counts drop to 0 are removed.
+type CountMap[T any] interface {
+	// Add increases the count of the item by `count`
+	Add(item T, count int)
+	// Remove decreases the count of the item by `count`. The count won't be negative.
+	Remove(item T, count int)
+	Count(item T) int
+	// Len returns the number of distinct items
+	Len() int
+	Clear()
+	ToArray() []Pair[T, int] // The order will not be guaranteed
+
+	// IntersectWith keeps the minimum of the counts in both multisets
+	IntersectWith(other CountMap[T])
+	// UnionWith keeps the maximum of the counts in both multisets
+	UnionWith(other CountMap[T])
+	// SubtractWith decreases the counts by the counts in `other`
+	SubtractWith(other CountMap[T])
+	// IsSubmultiset returns true if no item occurs more times than it does in `other`
+	IsSubmultiset(other CountMap[T]) bool
+}
+
+func NewCountMap[T any, C comparable](hasher Hasher[T, C], equaler Equaler[T]) CountMap[T] {
+	return &countMap[T]{
+		counts: NewMap[T, int, C](hasher, equaler),
+	}
+}
+
+type countMap[T any] struct {
+	counts Map[T, int]
+}
+
+func (c *countMap[T]) Add(item T, count int) {
+	c.setCount(item, c.Count(item)+count)
+}
+
+func (c *countMap[T]) Remove(item T, count int) {
+	c.setCount(item, c.Count(item)-count)
+}
+
+func (c *countMap[T]) setCount(item T, count int) {
+	if count <= 0 {
+		c.counts.Remove(item)
+		return
+	}
+
+	c.counts.Put(item, count)
+}
+
+func (c *countMap[T]) Count(item T) int {
+	count, _ := c.counts.Get(item)
+	return count
+}
+
+func (c *countMap[T]) Len() int {
+	return c.counts.Len()
+}
+
+func (c *countMap[T]) Clear() {
+	c.counts.Clear()
+}
+
+func (c *countMap[T]) ToArray() []Pair[T, int] {
+	return c.counts.ToArray()
+}
+
+func (c *countMap[T]) IntersectWith(other CountMap[T]) {
+	for _, pair := range c.counts.ToArray() {
+		otherCount := other.Count(pair.Key)
+		if otherCount < pair.Value {
+			c.setCount(pair.Key, otherCount)
+		}
+	}
+}
+
+func (c *countMap[T]) UnionWith(other CountMap[T]) {
+	for _, pair := range other.ToArray() {
+		if pair.Value > c.Count(pair.Key) {
+			c.setCount(pair.Key, pair.Value)
+		}
+	}
+}
+
+func (c *countMap[T]) SubtractWith(other CountMap[T]) {
+	for _, pair := range other.ToArray() {
+		c.Remove(pair.Key, pair.Value)
+	}
+}
+
+func (c *countMap[T]) IsSubmultiset(other CountMap[T]) bool {
+	for _, pair := range c.counts.ToArray() {
+		if pair.Value > other.Count(pair.Key) {
+			return false
+		}
+	}
+	return true
+}
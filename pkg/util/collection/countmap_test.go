@@ -0,0 +1,77 @@
+package collection_test
+
+import (
+	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func newCountMap(counts map[string]int) CountMap[string] {
+	result := NewCountMap[string, string](basicHasher[string], basicEquator[string])
+	for item, count := range counts {
+		result.Add(item, count)
+	}
+	return result
+}
+
+var _ = Describe("CountMap", func() {
+	var first CountMap[string]
+	var second CountMap[string]
+
+	BeforeEach(func() {
+		first = newCountMap(map[string]int{"a": 2, "b": 3})
+		second = newCountMap(map[string]int{"a": 1, "b": 5})
+	})
+
+	It("can count what it adds.", func() {
+		Expect(first.Count("a")).To(Equal(2))
+		Expect(first.Count("c")).To(Equal(0))
+
+		first.Add("a", 1)
+		Expect(first.Count("a")).To(Equal(3))
+		first.Remove("a", 1)
+		Expect(first.Count("a")).To(Equal(2))
+		Expect(first.Len()).To(Equal(2))
+
+		first.Remove("a", 5)
+		Expect(first.Count("a")).To(Equal(0))
+		Expect(first.Len()).To(Equal(1))
+
+		first.Clear()
+		Expect(first.Len()).To(Equal(0))
+	})
+
+	It("can intersect with another CountMap.", func() {
+		first.IntersectWith(second)
+		Expect(first.ToArray()).To(ConsistOf(
+			Pair[string, int]{Key: "a", Value: 1}, Pair[string, int]{Key: "b", Value: 3}))
+
+		first.IntersectWith(newCountMap(map[string]int{"a": 1}))
+		Expect(first.ToArray()).To(ConsistOf(Pair[string, int]{Key: "a", Value: 1}))
+	})
+
+	It("can union with another CountMap.", func() {
+		first.UnionWith(second)
+		Expect(first.ToArray()).To(ConsistOf(
+			Pair[string, int]{Key: "a", Value: 2}, Pair[string, int]{Key: "b", Value: 5}))
+
+		first.UnionWith(newCountMap(map[string]int{"c": 1}))
+		Expect(first.Count("c")).To(Equal(1))
+	})
+
+	It("can subtract another CountMap.", func() {
+		first.SubtractWith(second)
+		Expect(first.ToArray()).To(ConsistOf(Pair[string, int]{Key: "a", Value: 1}))
+		Expect(first.Count("b")).To(Equal(0))
+	})
+
+	It("can check if it's a submultiset.", func() {
+		Expect(first.IsSubmultiset(second)).To(BeFalse())
+		Expect(second.IsSubmultiset(first)).To(BeFalse())
+
+		Expect(newCountMap(map[string]int{"a": 1, "b": 3}).IsSubmultiset(first)).To(BeTrue())
+		Expect(first.IsSubmultiset(first)).To(BeTrue())
+		Expect(newCountMap(map[string]int{}).IsSubmultiset(first)).To(BeTrue())
+		Expect(newCountMap(map[string]int{"c": 1}).IsSubmultiset(first)).To(BeFalse())
+	})
+})
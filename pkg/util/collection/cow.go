@@ -0,0 +1,338 @@
+package collection
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// NewCOWMap returns a thread-safe copy-on-write Map for the read-heavy workloads.
+//  The readers access an immutable snapshot without any lock, while every write copies the whole map, which takes
+//  O(n) time, and swaps the snapshot atomically. The writes are serialized. The writes that don't change the map,
+//  like a PutIfAbsent with an existing key, don't copy the map.
+//  Range iterates a snapshot, so it never blocks the writers, and the modifications during the iteration are not
+//  reflected.
+func NewCOWMap[K any, V any, C comparable](hasher Hasher[K, C], equaler Equaler[K]) Map[K, V] {
+	result := &cowMap[K, V]{
+		newMap: func(capacity int) Map[K, V] {
+			return NewMapWithCapacity[K, V, C](capacity, hasher, equaler)
+		},
+	}
+	result.snapshot.Store(result.newMap(0))
+	return result
+}
+
+// NewCOWSet returns a thread-safe copy-on-write Set. See NewCOWMap.
+func NewCOWSet[T any, C comparable](hasher Hasher[T, C], equaler Equaler[T]) Set[T] {
+	return &cowSet[T]{
+		set: set[T]{data: NewCOWMap[T, emptyType, C](hasher, equaler)},
+	}
+}
+
+type cowMap[K any, V any] struct {
+	// snapshot The stored Map must not be modified
+	snapshot atomic.Value
+	newMap   func(capacity int) Map[K, V]
+	// writeLock serializes the writers
+	writeLock sync.Mutex
+}
+
+func (c *cowMap[K, V]) load() Map[K, V] {
+	return c.snapshot.Load().(Map[K, V])
+}
+
+func (c *cowMap[K, V]) clone(m Map[K, V]) Map[K, V] {
+	cloned := c.newMap(m.Len())
+	m.Range(func(pair Pair[K, V]) bool {
+		cloned.Put(pair.Key, pair.Value)
+		return true
+	})
+	return cloned
+}
+
+// write calls f with a copy of the current snapshot, and publishes the copy
+func (c *cowMap[K, V]) write(f func(m Map[K, V])) {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	cloned := c.clone(c.load())
+	f(cloned)
+	c.snapshot.Store(cloned)
+}
+
+// writeIf only copies the snapshot if shouldWrite returns true for the current snapshot
+func (c *cowMap[K, V]) writeIf(shouldWrite func(m Map[K, V]) bool, f func(m Map[K, V])) {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	if !shouldWrite(c.load()) {
+		return
+	}
+	cloned := c.clone(c.load())
+	f(cloned)
+	c.snapshot.Store(cloned)
+}
+
+func (c *cowMap[K, V]) ToArray() []Pair[K, V] {
+	return c.load().ToArray()
+}
+
+func (c *cowMap[K, V]) Range(f func(pair Pair[K, V]) bool) {
+	c.load().Range(f)
+}
+
+func (c *cowMap[K, V]) All() func(yield func(Pair[K, V]) bool) {
+	return c.Range
+}
+
+func (c *cowMap[K, V]) Keys() func(yield func(K) bool) {
+	return c.load().Keys()
+}
+
+func (c *cowMap[K, V]) Values() func(yield func(V) bool) {
+	return c.load().Values()
+}
+
+func (c *cowMap[K, V]) KeyValues() func(yield func(K, V) bool) {
+	return c.load().KeyValues()
+}
+
+func (c *cowMap[K, V]) Add(pair Pair[K, V]) (oldItem Pair[K, V], replaced bool) {
+	c.write(func(m Map[K, V]) {
+		oldItem, replaced = m.Add(pair)
+	})
+	return
+}
+
+func (c *cowMap[K, V]) RemoveFirst(pair Pair[K, V]) bool {
+	_, exists := c.Remove(pair.Key)
+	return exists
+}
+
+func (c *cowMap[K, V]) Has(pair Pair[K, V]) bool {
+	return c.load().Has(pair)
+}
+
+func (c *cowMap[K, V]) Contains(pair Pair[K, V]) bool {
+	return c.Has(pair)
+}
+
+func (c *cowMap[K, V]) TryPop() (pair Pair[K, V], exists bool) {
+	c.writeIf(func(m Map[K, V]) bool {
+		return m.Len() > 0
+	}, func(m Map[K, V]) {
+		pair, exists = m.TryPop()
+	})
+	return
+}
+
+func (c *cowMap[K, V]) Pop() Pair[K, V] {
+	return mustPop[Pair[K, V]](c, "COWMap")
+}
+
+func (c *cowMap[K, V]) Len() int {
+	return c.load().Len()
+}
+
+func (c *cowMap[K, V]) Size() int {
+	return c.Len()
+}
+
+func (c *cowMap[K, V]) Empty() bool {
+	return c.Size() == 0
+}
+
+// Clear publishes an empty map without copying
+func (c *cowMap[K, V]) Clear() {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	c.snapshot.Store(c.newMap(0))
+}
+
+// Clone shares the current snapshot, which is immutable, so it takes O(1) time
+func (c *cowMap[K, V]) Clone() Collection[Pair[K, V]] {
+	cloned := &cowMap[K, V]{
+		newMap: c.newMap,
+	}
+	cloned.snapshot.Store(c.load())
+	return cloned
+}
+
+func (c *cowMap[K, V]) Snapshot() Collection[Pair[K, V]] {
+	return c.load().Clone()
+}
+
+func (c *cowMap[K, V]) ContainsKey(key K) bool {
+	return c.load().ContainsKey(key)
+}
+
+func (c *cowMap[K, V]) Put(key K, value V) (old V, exists bool) {
+	c.write(func(m Map[K, V]) {
+		old, exists = m.Put(key, value)
+	})
+	return
+}
+
+func (c *cowMap[K, V]) Get(key K) (value V, exists bool) {
+	return c.load().Get(key)
+}
+
+func (c *cowMap[K, V]) Remove(key K) (old V, exists bool) {
+	c.writeIf(func(m Map[K, V]) bool {
+		return m.ContainsKey(key)
+	}, func(m Map[K, V]) {
+		old, exists = m.Remove(key)
+	})
+	return
+}
+
+func (c *cowMap[K, V]) GetOrPutDefault(key K) (value V, exists bool) {
+	return c.GetOrPut(key, func() V {
+		var zero V
+		return zero
+	})
+}
+
+func (c *cowMap[K, V]) ReplaceIfEqual(key K, expectedOld V, newValue V, valueEqualer Equaler[V]) bool {
+	replaced := false
+	c.writeIf(func(m Map[K, V]) bool {
+		current, exists := m.Get(key)
+		return exists && valueEqualer(expectedOld, current)
+	}, func(m Map[K, V]) {
+		replaced = m.ReplaceIfEqual(key, expectedOld, newValue, valueEqualer)
+	})
+	return replaced
+}
+
+// GetOrPut holds the write lock while calling `f`, so `f` must not modify c, or it will be deadlocked.
+//  The same applies to ComputeIfAbsent, ComputeIfPresent, Merge and RemoveIf.
+func (c *cowMap[K, V]) GetOrPut(key K, f func() V) (value V, exists bool) {
+	c.writeIf(func(m Map[K, V]) bool {
+		value, exists = m.Get(key)
+		return !exists
+	}, func(m Map[K, V]) {
+		value, exists = m.GetOrPut(key, f)
+	})
+	return
+}
+
+func (c *cowMap[K, V]) ComputeIfAbsent(key K, mapping func(key K) V) V {
+	value, _ := c.GetOrPut(key, func() V {
+		return mapping(key)
+	})
+	return value
+}
+
+func (c *cowMap[K, V]) ComputeIfPresent(key K,
+	remapping func(key K, old V) (newValue V, keep bool)) (value V, exists bool) {
+	c.writeIf(func(m Map[K, V]) bool {
+		return m.ContainsKey(key)
+	}, func(m Map[K, V]) {
+		value, exists = m.ComputeIfPresent(key, remapping)
+	})
+	return
+}
+
+func (c *cowMap[K, V]) Merge(key K, value V, remapping func(old V, new V) V) (result V) {
+	c.write(func(m Map[K, V]) {
+		result = m.Merge(key, value, remapping)
+	})
+	return
+}
+
+func (c *cowMap[K, V]) PutIfAbsent(key K, value V) (existing V, exists bool) {
+	c.writeIf(func(m Map[K, V]) bool {
+		existing, exists = m.Get(key)
+		return !exists
+	}, func(m Map[K, V]) {
+		m.Put(key, value)
+	})
+	return
+}
+
+func (c *cowMap[K, V]) RemoveIf(key K, predicate func(value V) bool) bool {
+	removed := false
+	c.writeIf(func(m Map[K, V]) bool {
+		value, exists := m.Get(key)
+		return exists && predicate(value)
+	}, func(m Map[K, V]) {
+		m.Remove(key)
+		removed = true
+	})
+	return removed
+}
+
+// MarshalBinary encodes the pairs of a snapshot with gob. The hasher and the equaler are not encoded.
+func (c *cowMap[K, V]) MarshalBinary() ([]byte, error) {
+	return gobEncode(c.ToArray())
+}
+
+// UnmarshalBinary replaces the content of c with the decoded pairs atomically.
+func (c *cowMap[K, V]) UnmarshalBinary(data []byte) error {
+	var pairs []Pair[K, V]
+	if err := gobDecode(data, &pairs); err != nil {
+		return err
+	}
+
+	c.replaceAll(pairs)
+	return nil
+}
+
+func (c *cowMap[K, V]) replaceAll(pairs []Pair[K, V]) {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	m := c.newMap(len(pairs))
+	for _, pair := range pairs {
+		m.Put(pair.Key, pair.Value)
+	}
+	c.snapshot.Store(m)
+}
+
+// cowSet The methods of set that access data more than once are overridden, so that they use the same snapshot.
+type cowSet[T any] struct {
+	set[T]
+}
+
+func (c *cowSet[T]) Pop() T {
+	return mustPop[T](c, "COWSet")
+}
+
+func (c *cowSet[T]) ToArray() []T {
+	pairs := c.data.ToArray()
+	result := make([]T, len(pairs))
+	for i, pair := range pairs {
+		result[i] = pair.Key
+	}
+	return result
+}
+
+func (c *cowSet[T]) Clone() Collection[T] {
+	return &cowSet[T]{
+		set: set[T]{data: CloneMap(c.data)},
+	}
+}
+
+func (c *cowSet[T]) Snapshot() Collection[T] {
+	return &set[T]{
+		data: c.data.(*cowMap[T, emptyType]).Snapshot().(Map[T, emptyType]),
+	}
+}
+
+func (c *cowSet[T]) MarshalBinary() ([]byte, error) {
+	return gobEncode(c.ToArray())
+}
+
+func (c *cowSet[T]) UnmarshalBinary(data []byte) error {
+	var items []T
+	if err := gobDecode(data, &items); err != nil {
+		return err
+	}
+
+	pairs := make([]Pair[T, emptyType], len(items))
+	for i, item := range items {
+		pairs[i] = Pair[T, emptyType]{Key: item}
+	}
+	c.data.(*cowMap[T, emptyType]).replaceAll(pairs)
+	return nil
+}
@@ -0,0 +1,96 @@
+package collection_test
+
+import (
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
+)
+
+var _ = Describe("COWMap", func() {
+	testMap(cowMap)
+
+	It("iterates a snapshot.", func() {
+		m := NewCOWMap[int, int, int](basicHasher[int], basicEquator[int])
+		for i := 0; i < 10; i++ {
+			m.Put(i, i)
+		}
+
+		visited := 0
+		m.Range(func(pair Pair[int, int]) bool {
+			// It won't be deadlocked, and the modifications are not reflected
+			m.Remove(pair.Key)
+			m.Put(pair.Key+100, pair.Value)
+			visited++
+			return true
+		})
+		Expect(visited).To(Equal(10))
+		Expect(m.Len()).To(Equal(10))
+		Expect(m.ContainsKey(0)).To(BeFalse())
+		Expect(m.ContainsKey(100)).To(BeTrue())
+	})
+
+	It("can be encoded with gob.", func() {
+		src := NewCOWMap[int, string, int](basicHasher[int], basicEquator[int])
+		src.Put(1, "a")
+		dst := NewCOWMap[int, string, int](basicHasher[int], basicEquator[int])
+		dst.Put(2, "b")
+
+		gobRoundTrip(src, dst)
+		Expect(dst.ToArray()).To(Equal([]Pair[int, string]{{Key: 1, Value: "a"}}))
+	})
+
+	It("can be used by multiple goroutines concurrently.", func() {
+		m := NewCOWMap[int, int, int](basicHasher[int], basicEquator[int])
+		wait := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wait.Add(1)
+			i := i
+			go func() {
+				defer wait.Done()
+				for j := 0; j < 100; j++ {
+					m.Put(i*100+j, j)
+					m.Get(j)
+					m.GetOrPutDefault(-1)
+					m.ReplaceIfEqual(i*100+j, j, j+1, basicEquator[int])
+					m.Range(func(pair Pair[int, int]) bool {
+						return true
+					})
+				}
+				m.ToArray()
+			}()
+		}
+		wait.Wait()
+
+		Expect(m.Len()).To(Equal(1001))
+		value, _ := m.Get(999)
+		Expect(value).To(Equal(100))
+	})
+})
+
+var _ = Describe("COWSet", func() {
+	testSet(cowSet)
+
+	It("can be used by multiple goroutines concurrently.", func() {
+		s := NewCOWSet[int, int](basicHasher[int], basicEquator[int])
+		wait := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wait.Add(1)
+			i := i
+			go func() {
+				defer wait.Done()
+				for j := 0; j < 100; j++ {
+					s.Add(i*100 + j)
+					s.Has(j)
+					s.ToArray()
+				}
+			}()
+		}
+		wait.Wait()
+
+		Expect(s.Len()).To(Equal(1000))
+		Expect(s.ToArray()).To(ConsistOf(getSequence(1000)))
+	})
+})
@@ -0,0 +1,39 @@
+package collection
+
+import (
+	"fmt"
+	"hash/maphash"
+)
+
+// IdentityHasher uses the object itself as the hash code, which works for the comparable types
+func IdentityHasher[T comparable](obj T) T {
+	return obj
+}
+
+// DefaultEquals compares the objects with `==`
+func DefaultEquals[T comparable](original, new T) bool {
+	return original == new
+}
+
+var stringerHasherSeed = maphash.MakeSeed()
+
+// StringerHasher hashes the result of String() with maphash. The hash codes differ between processes.
+func StringerHasher[T fmt.Stringer](obj T) uint64 {
+	hash := maphash.Hash{}
+	hash.SetSeed(stringerHasherSeed)
+	hash.WriteString(obj.String())
+	return hash.Sum64()
+}
+
+// StringerEquals compares the results of String()
+func StringerEquals[T fmt.Stringer](original, new T) bool {
+	return original.String() == new.String()
+}
+
+func NewComparableSet[T comparable]() Set[T] {
+	return NewSet[T, T](IdentityHasher[T], DefaultEquals[T])
+}
+
+func NewComparableMap[K comparable, V any]() Map[K, V] {
+	return NewMap[K, V, K](IdentityHasher[K], DefaultEquals[K])
+}
@@ -0,0 +1,37 @@
+package collection_test
+
+import (
+	"time"
+
+	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Default hashers and equalers", func() {
+	It("works with comparable types.", func() {
+		set := NewComparableSet[string]()
+		set.Add("a")
+		set.Add("a")
+		set.Add("b")
+		Expect(set.ToArray()).To(ConsistOf("a", "b"))
+
+		m := NewComparableMap[int, string]()
+		m.Put(1, "a")
+		value, exists := m.Get(1)
+		Expect(exists).To(BeTrue())
+		Expect(value).To(Equal("a"))
+	})
+
+	It("works with fmt.Stringer types.", func() {
+		Expect(StringerHasher(time.Second)).To(Equal(StringerHasher(1000 * time.Millisecond)))
+		Expect(StringerHasher(time.Second)).NotTo(Equal(StringerHasher(time.Minute)))
+		Expect(StringerEquals(time.Second, 1000*time.Millisecond)).To(BeTrue())
+
+		set := NewSet[time.Duration, uint64](StringerHasher[time.Duration], StringerEquals[time.Duration])
+		set.Add(time.Second)
+		set.Add(1000 * time.Millisecond)
+		set.Add(time.Minute)
+		Expect(set.Len()).To(Equal(2))
+	})
+})
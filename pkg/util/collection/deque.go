@@ -0,0 +1,476 @@
+package collection
+
+import "sort"
+
+// Deque A double-ended queue backed by a ring buffer. TryPop pops the first item, so it works as a FIFO queue by default.
+type Deque[T any] interface {
+	OrderedCollection[T]
+	// Prepend equals AddFirst
+	Prepend(item T)
+	// Append equals AddLast
+	Append(item T)
+	PeekFirst() (item T, exists bool)
+	PeekLast() (item T, exists bool)
+	// PopFirst equals TryPop
+	PopFirst() (item T, exists bool)
+	PopLast() (item T, exists bool)
+	// PushFront equals AddFirst
+	PushFront(item T)
+	// PushBack equals AddLast
+	PushBack(item T)
+	// PeekFront equals PeekFirst
+	PeekFront() (item T, exists bool)
+	// PeekBack equals PeekLast
+	PeekBack() (item T, exists bool)
+	// PopFront equals PopFirst
+	PopFront() (item T, exists bool)
+	// PopBack equals PopLast
+	PopBack() (item T, exists bool)
+}
+
+func NewDeque[T any](equaler Equaler[T]) Deque[T] {
+	return &deque[T]{
+		items:   []T{},
+		head:    0,
+		size:    0,
+		equaler: equaler,
+	}
+}
+
+type deque[T any] struct {
+	items   []T
+	head    int
+	size    int
+	equaler Equaler[T]
+}
+
+func (d *deque[T]) index(i int) int {
+	return (d.head + i) % len(d.items)
+}
+
+func (d *deque[T]) grow() {
+	if d.size < len(d.items) {
+		return
+	}
+
+	newCapacity := 2 * len(d.items)
+	if newCapacity == 0 {
+		newCapacity = 1
+	}
+	newItems := make([]T, newCapacity)
+	for i := 0; i < d.size; i++ {
+		newItems[i] = d.items[d.index(i)]
+	}
+	d.items = newItems
+	d.head = 0
+}
+
+func (d *deque[T]) Prepend(item T) {
+	d.grow()
+	d.head = (d.head - 1 + len(d.items)) % len(d.items)
+	d.items[d.head] = item
+	d.size += 1
+}
+
+func (d *deque[T]) Append(item T) {
+	d.grow()
+	d.items[d.index(d.size)] = item
+	d.size += 1
+}
+
+func (d *deque[T]) AddFirst(item T) {
+	d.Prepend(item)
+}
+
+func (d *deque[T]) AddLast(item T) {
+	d.Append(item)
+}
+
+func (d *deque[T]) Add(item T) (oldItem T, replaced bool) {
+	d.Append(item)
+	replaced = false
+	return
+}
+
+func (d *deque[T]) RemoveFirst(item T) bool {
+	for i := 0; i < d.size; i++ {
+		if d.equaler(item, d.items[d.index(i)]) {
+			for j := i; j < d.size-1; j++ {
+				d.items[d.index(j)] = d.items[d.index(j+1)]
+			}
+			var zero T
+			d.items[d.index(d.size-1)] = zero // Don't hold the reference
+			d.size -= 1
+			return true
+		}
+	}
+	return false
+}
+
+func (d *deque[T]) PeekFirst() (item T, exists bool) {
+	if d.size == 0 {
+		exists = false
+		return
+	}
+	return d.items[d.head], true
+}
+
+func (d *deque[T]) PeekLast() (item T, exists bool) {
+	if d.size == 0 {
+		exists = false
+		return
+	}
+	return d.items[d.index(d.size-1)], true
+}
+
+func (d *deque[T]) PopFirst() (item T, exists bool) {
+	return d.TryPop()
+}
+
+func (d *deque[T]) PopLast() (item T, exists bool) {
+	if d.size == 0 {
+		exists = false
+		return
+	}
+
+	last := d.index(d.size - 1)
+	item = d.items[last]
+	var zero T
+	d.items[last] = zero
+	d.size -= 1
+	return item, true
+}
+
+func (d *deque[T]) PushFront(item T) {
+	d.AddFirst(item)
+}
+
+func (d *deque[T]) PushBack(item T) {
+	d.AddLast(item)
+}
+
+func (d *deque[T]) PeekFront() (item T, exists bool) {
+	return d.PeekFirst()
+}
+
+func (d *deque[T]) PeekBack() (item T, exists bool) {
+	return d.PeekLast()
+}
+
+func (d *deque[T]) PopFront() (item T, exists bool) {
+	return d.PopFirst()
+}
+
+func (d *deque[T]) PopBack() (item T, exists bool) {
+	return d.PopLast()
+}
+
+func (d *deque[T]) TryPop() (item T, exists bool) {
+	if d.size == 0 {
+		exists = false
+		return
+	}
+
+	item = d.items[d.head]
+	var zero T
+	d.items[d.head] = zero
+	d.head = d.index(1)
+	d.size -= 1
+	return item, true
+}
+
+func (d *deque[T]) Pop() T {
+	return mustPop[T](d, "Deque")
+}
+
+func (d *deque[T]) Has(item T) bool {
+	for i := 0; i < d.size; i++ {
+		if d.equaler(item, d.items[d.index(i)]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *deque[T]) Contains(item T) bool {
+	return d.Has(item)
+}
+
+func (d *deque[T]) Len() int {
+	return d.size
+}
+
+func (d *deque[T]) Clear() {
+	d.items = []T{}
+	d.head = 0
+	d.size = 0
+}
+
+func (d *deque[T]) clone() *deque[T] {
+	items := make([]T, len(d.items))
+	copy(items, d.items)
+	return &deque[T]{
+		items:   items,
+		head:    d.head,
+		size:    d.size,
+		equaler: d.equaler,
+	}
+}
+
+func (d *deque[T]) Clone() Collection[T] {
+	return d.clone()
+}
+
+func (d *deque[T]) Range(f func(item T) bool) {
+	for i := 0; i < d.size; i++ {
+		if !f(d.items[d.index(i)]) {
+			return
+		}
+	}
+}
+
+func (d *deque[T]) All() func(yield func(T) bool) {
+	return d.Range
+}
+
+func (d *deque[T]) ToArray() []T {
+	result := make([]T, d.size)
+	for i := 0; i < d.size; i++ {
+		result[i] = d.items[d.index(i)]
+	}
+	return result
+}
+
+// NewSortedDeque returns a Deque that keeps its items sorted by comparator, so PopFirst returns the minimum and PopLast
+//  returns the maximum. Every kind of addition inserts the item at its sorted position by a binary search, which costs
+//  O(log n) comparisons but O(n) moves in the worst case.
+//  The returned Deque also implements PriorityCollection, whose top is the minimum.
+func NewSortedDeque[T any](comparator Comparator[T], equaler Equaler[T]) Deque[T] {
+	return &sortedDeque[T]{
+		items:      []T{},
+		comparator: comparator,
+		equaler:    equaler,
+	}
+}
+
+type sortedDeque[T any] struct {
+	items      []T
+	comparator Comparator[T]
+	equaler    Equaler[T]
+}
+
+func (d *sortedDeque[T]) insert(item T) {
+	// Insert after the items that are equal to `item`, so the items with the same order keep the order of addition
+	i := sort.Search(len(d.items), func(i int) bool {
+		return d.comparator(item, d.items[i])
+	})
+	var zero T
+	d.items = append(d.items, zero)
+	copy(d.items[i+1:], d.items[i:])
+	d.items[i] = item
+}
+
+func (d *sortedDeque[T]) remove(i int) T {
+	item := d.items[i]
+	copy(d.items[i:], d.items[i+1:])
+	var zero T
+	d.items[len(d.items)-1] = zero // Don't hold the reference
+	d.items = d.items[:len(d.items)-1]
+	return item
+}
+
+func (d *sortedDeque[T]) Prepend(item T) {
+	d.insert(item)
+}
+
+func (d *sortedDeque[T]) Append(item T) {
+	d.insert(item)
+}
+
+func (d *sortedDeque[T]) AddFirst(item T) {
+	d.insert(item)
+}
+
+func (d *sortedDeque[T]) AddLast(item T) {
+	d.insert(item)
+}
+
+func (d *sortedDeque[T]) Add(item T) (oldItem T, replaced bool) {
+	d.insert(item)
+	replaced = false
+	return
+}
+
+func (d *sortedDeque[T]) PeekFirst() (item T, exists bool) {
+	if len(d.items) == 0 {
+		exists = false
+		return
+	}
+	return d.items[0], true
+}
+
+func (d *sortedDeque[T]) PeekLast() (item T, exists bool) {
+	if len(d.items) == 0 {
+		exists = false
+		return
+	}
+	return d.items[len(d.items)-1], true
+}
+
+func (d *sortedDeque[T]) TryPeek() (item T, exists bool) {
+	return d.PeekFirst()
+}
+
+func (d *sortedDeque[T]) Peek() T {
+	return mustPeek[T](d, "SortedDeque")
+}
+
+// PeekAll returns a copy of all the items, which are sorted
+func (d *sortedDeque[T]) PeekAll() []T {
+	return d.ToArray()
+}
+
+func (d *sortedDeque[T]) PeekN(n int) []T {
+	checkN(n)
+	if n > len(d.items) {
+		n = len(d.items)
+	}
+
+	result := make([]T, n)
+	copy(result, d.items)
+	return result
+}
+
+// PopN removes the items in one pass, instead of shifting the remaining items for each of them
+func (d *sortedDeque[T]) PopN(n int) []T {
+	result := d.PeekN(n)
+	remaining := copy(d.items, d.items[len(result):])
+	var zero T
+	for i := remaining; i < len(d.items); i++ {
+		d.items[i] = zero // Don't hold the reference
+	}
+	d.items = d.items[:remaining]
+	return result
+}
+
+func (d *sortedDeque[T]) DrainTo(dst Collection[T], max int) int {
+	if max < 0 {
+		max = len(d.items)
+	}
+	items := d.PopN(max)
+	for _, item := range items {
+		dst.Add(item)
+	}
+	return len(items)
+}
+
+func (d *sortedDeque[T]) PopFirst() (item T, exists bool) {
+	if len(d.items) == 0 {
+		exists = false
+		return
+	}
+	return d.remove(0), true
+}
+
+func (d *sortedDeque[T]) PopLast() (item T, exists bool) {
+	if len(d.items) == 0 {
+		exists = false
+		return
+	}
+	return d.remove(len(d.items) - 1), true
+}
+
+func (d *sortedDeque[T]) PushFront(item T) {
+	d.AddFirst(item)
+}
+
+func (d *sortedDeque[T]) PushBack(item T) {
+	d.AddLast(item)
+}
+
+func (d *sortedDeque[T]) PeekFront() (item T, exists bool) {
+	return d.PeekFirst()
+}
+
+func (d *sortedDeque[T]) PeekBack() (item T, exists bool) {
+	return d.PeekLast()
+}
+
+func (d *sortedDeque[T]) PopFront() (item T, exists bool) {
+	return d.PopFirst()
+}
+
+func (d *sortedDeque[T]) PopBack() (item T, exists bool) {
+	return d.PopLast()
+}
+
+func (d *sortedDeque[T]) TryPop() (item T, exists bool) {
+	return d.PopFirst()
+}
+
+func (d *sortedDeque[T]) Pop() T {
+	return mustPop[T](d, "SortedDeque")
+}
+
+func (d *sortedDeque[T]) PopOrError() (T, error) {
+	return popOrError[T](d, "SortedDeque")
+}
+
+func (d *sortedDeque[T]) RemoveFirst(item T) bool {
+	for i, existing := range d.items {
+		if d.equaler(item, existing) {
+			d.remove(i)
+			return true
+		}
+	}
+	return false
+}
+
+func (d *sortedDeque[T]) Has(item T) bool {
+	for _, existing := range d.items {
+		if d.equaler(item, existing) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *sortedDeque[T]) Contains(item T) bool {
+	return d.Has(item)
+}
+
+func (d *sortedDeque[T]) Len() int {
+	return len(d.items)
+}
+
+func (d *sortedDeque[T]) Clear() {
+	d.items = []T{}
+}
+
+func (d *sortedDeque[T]) Clone() Collection[T] {
+	items := make([]T, len(d.items))
+	copy(items, d.items)
+	return &sortedDeque[T]{
+		items:      items,
+		comparator: d.comparator,
+		equaler:    d.equaler,
+	}
+}
+
+func (d *sortedDeque[T]) Range(f func(item T) bool) {
+	for _, item := range d.items {
+		if !f(item) {
+			return
+		}
+	}
+}
+
+func (d *sortedDeque[T]) All() func(yield func(T) bool) {
+	return d.Range
+}
+
+func (d *sortedDeque[T]) ToArray() []T {
+	result := make([]T, len(d.items))
+	copy(result, d.items)
+	return result
+}
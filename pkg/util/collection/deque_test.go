@@ -0,0 +1,204 @@
+package collection_test
+
+import (
+	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Deque", func() {
+	var deque Deque[int]
+
+	BeforeEach(func() {
+		deque = NewDeque[int](basicEquator[int])
+	})
+
+	It("implements OrderedCollection.", func() {
+		var _ OrderedCollection[int] = deque
+	})
+
+	It("can prepend items.", func() {
+		deque.Prepend(1)
+		deque.AddFirst(2)
+		Expect(deque.Len()).To(Equal(2))
+		Expect(deque.ToArray()).To(Equal([]int{2, 1}))
+
+		item, exists := deque.TryPop()
+		Expect(exists).To(BeTrue())
+		Expect(item).To(Equal(2))
+	})
+
+	It("can append items.", func() {
+		deque.Append(1)
+		deque.AddLast(2)
+		deque.Add(3)
+		Expect(deque.Len()).To(Equal(3))
+		Expect(deque.ToArray()).To(Equal([]int{1, 2, 3}))
+
+		item, exists := deque.TryPop()
+		Expect(exists).To(BeTrue())
+		Expect(item).To(Equal(1))
+	})
+
+	It("can mix prepending and appending while it grows.", func() {
+		expected := []int{}
+		for i := 0; i < 20; i++ {
+			if i%2 == 0 {
+				deque.Prepend(i)
+				expected = append([]int{i}, expected...)
+			} else {
+				deque.Append(i)
+				expected = append(expected, i)
+			}
+			Expect(deque.Len()).To(Equal(i + 1))
+		}
+		Expect(deque.ToArray()).To(Equal(expected))
+
+		actual := []int{}
+		for value, exists := deque.TryPop(); exists; value, exists = deque.TryPop() {
+			actual = append(actual, value)
+		}
+		Expect(actual).To(Equal(expected))
+		Expect(deque.Len()).To(Equal(0))
+	})
+
+	It("can remove the first matched item.", func() {
+		for _, item := range []int{1, 2, 3, 2} {
+			deque.Append(item)
+		}
+		deque.TryPop()
+		deque.Append(4)
+
+		Expect(deque.RemoveFirst(2)).To(BeTrue())
+		Expect(deque.ToArray()).To(Equal([]int{3, 2, 4}))
+		Expect(deque.RemoveFirst(5)).To(BeFalse())
+		Expect(deque.Has(2)).To(BeTrue())
+		Expect(deque.RemoveFirst(2)).To(BeTrue())
+		Expect(deque.Has(2)).To(BeFalse())
+		Expect(deque.ToArray()).To(Equal([]int{3, 4}))
+	})
+
+	It("can peek and pop both ends.", func() {
+		_, exists := deque.PeekFirst()
+		Expect(exists).To(BeFalse())
+		_, exists = deque.PopLast()
+		Expect(exists).To(BeFalse())
+
+		for _, item := range []int{2, 3} {
+			deque.Append(item)
+		}
+		deque.Prepend(1)
+
+		first, _ := deque.PeekFirst()
+		last, _ := deque.PeekLast()
+		Expect([]int{first, last}).To(Equal([]int{1, 3}))
+		Expect(deque.Len()).To(Equal(3))
+
+		last, _ = deque.PopLast()
+		Expect(last).To(Equal(3))
+		first, _ = deque.PopFirst()
+		Expect(first).To(Equal(1))
+		Expect(deque.ToArray()).To(Equal([]int{2}))
+	})
+
+	It("works as a stack and a queue with the push and pop methods.", func() {
+		deque.PushBack(2)
+		deque.PushBack(3)
+		deque.PushFront(1)
+		front, _ := deque.PeekFront()
+		back, _ := deque.PeekBack()
+		Expect([]int{front, back}).To(Equal([]int{1, 3}))
+
+		back, _ = deque.PopBack()
+		Expect(back).To(Equal(3))
+		front, _ = deque.PopFront()
+		Expect(front).To(Equal(1))
+		Expect(deque.ToArray()).To(Equal([]int{2}))
+
+		deque.PopBack()
+		_, exists := deque.PopFront()
+		Expect(exists).To(BeFalse())
+		_, exists = deque.PeekBack()
+		Expect(exists).To(BeFalse())
+	})
+
+	It("can clear what it adds.", func() {
+		deque.Append(1)
+		deque.Prepend(0)
+		deque.Clear()
+		Expect(deque.Len()).To(Equal(0))
+		Expect(deque.ToArray()).To(BeEmpty())
+		_, exists := deque.TryPop()
+		Expect(exists).To(BeFalse())
+
+		deque.Append(1)
+		Expect(deque.ToArray()).To(Equal([]int{1}))
+	})
+})
+
+var _ = Describe("SortedDeque", func() {
+	var deque Deque[int]
+	items := []int{5, 1, 4, 1, 3, 9, 2, 6}
+
+	BeforeEach(func() {
+		deque = NewSortedDeque[int](intAscComparator, basicEquator[int])
+		for _, item := range items {
+			deque.Add(item)
+		}
+	})
+
+	It("implements PriorityCollection.", func() {
+		priorityCollection, ok := deque.(PriorityCollection[int])
+		Expect(ok).To(BeTrue())
+		Expect(priorityCollection.Peek()).To(Equal(1))
+		Expect(priorityCollection.PeekAll()).To(Equal([]int{1, 1, 2, 3, 4, 5, 6, 9}))
+	})
+
+	It("pops the items in ascending order from the front.", func() {
+		actual := []int{}
+		for value, exists := deque.PopFirst(); exists; value, exists = deque.PopFirst() {
+			actual = append(actual, value)
+		}
+		Expect(actual).To(Equal([]int{1, 1, 2, 3, 4, 5, 6, 9}))
+	})
+
+	It("pops the items in descending order from the back.", func() {
+		actual := []int{}
+		for value, exists := deque.PopLast(); exists; value, exists = deque.PopLast() {
+			actual = append(actual, value)
+		}
+		Expect(actual).To(Equal([]int{9, 6, 5, 4, 3, 2, 1, 1}))
+	})
+
+	It("keeps the items when peeking.", func() {
+		first, exists := deque.PeekFirst()
+		Expect(exists).To(BeTrue())
+		Expect(first).To(Equal(1))
+		last, exists := deque.PeekLast()
+		Expect(exists).To(BeTrue())
+		Expect(last).To(Equal(9))
+		Expect(deque.Len()).To(Equal(len(items)))
+	})
+
+	It("keeps the order no matter where the items are added.", func() {
+		deque.AddFirst(10)
+		deque.AddLast(0)
+		deque.Prepend(7)
+		deque.Append(8)
+		Expect(deque.ToArray()).To(Equal([]int{0, 1, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10}))
+	})
+
+	It("can remove the items.", func() {
+		Expect(deque.RemoveFirst(1)).To(BeTrue())
+		Expect(deque.Has(1)).To(BeTrue())
+		Expect(deque.RemoveFirst(1)).To(BeTrue())
+		Expect(deque.Has(1)).To(BeFalse())
+		Expect(deque.RemoveFirst(7)).To(BeFalse())
+		Expect(deque.ToArray()).To(Equal([]int{2, 3, 4, 5, 6, 9}))
+
+		deque.Clear()
+		Expect(deque.Len()).To(Equal(0))
+		_, exists := deque.PeekLast()
+		Expect(exists).To(BeFalse())
+	})
+})
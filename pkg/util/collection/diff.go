@@ -0,0 +1,96 @@
+package collection
+
+// CollectionEqual returns true if c1 and c2 have the same items, regardless of the order.
+//  The repetitive items are counted.
+//  It compares the items with equaler, so it takes O(n*m) time. For maps, MapEqual is faster.
+func CollectionEqual[T any](c1 Collection[T], c2 Collection[T], equaler Equaler[T]) bool {
+	if c1.Len() != c2.Len() {
+		return false
+	}
+
+	added, removed := Diff(c1, c2, equaler)
+	return len(added) == 0 && len(removed) == 0
+}
+
+// Diff returns the items in `new` but not in `old`, and the items in `old` but not in `new`.
+//  The repetitive items are counted, e.g. the diff between [1] and [1, 1] is added=[1].
+//  It compares the items with equaler, so it takes O(n*m) time. For maps, DiffMaps is faster.
+func Diff[T any](old Collection[T], new Collection[T], equaler Equaler[T]) (added []T, removed []T) {
+	unmatched := new.ToArray()
+	removed = []T{}
+	old.Range(func(item T) bool {
+		for i, candidate := range unmatched {
+			if equaler(item, candidate) {
+				unmatched[i] = unmatched[len(unmatched)-1]
+				unmatched = unmatched[:len(unmatched)-1]
+				return true
+			}
+		}
+		removed = append(removed, item)
+		return true
+	})
+	return unmatched, removed
+}
+
+// ValueChange is the change of the value of a key
+type ValueChange[K any, V any] struct {
+	Key K
+	Old V
+	New V
+}
+
+// MapDiff is the difference between two maps
+type MapDiff[K any, V any] struct {
+	// Added The pairs whose keys are only in the new map
+	Added []Pair[K, V]
+	// Removed The pairs whose keys are only in the old map
+	Removed []Pair[K, V]
+	// Changed The keys in both maps, whose values are different
+	Changed []ValueChange[K, V]
+}
+
+// IsEmpty returns true if the two maps are equal
+func (d MapDiff[K, V]) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffMaps compares the maps by keys, and compares the values of the same key with valueEqualer.
+//  The keys are looked up in the maps, so it takes O(n+m) time for the hash-based maps.
+func DiffMaps[K any, V any](old Map[K, V], new Map[K, V], valueEqualer Equaler[V]) MapDiff[K, V] {
+	result := MapDiff[K, V]{
+		Added:   []Pair[K, V]{},
+		Removed: []Pair[K, V]{},
+		Changed: []ValueChange[K, V]{},
+	}
+	old.Range(func(pair Pair[K, V]) bool {
+		value, exists := new.Get(pair.Key)
+		if !exists {
+			result.Removed = append(result.Removed, pair)
+		} else if !valueEqualer(pair.Value, value) {
+			result.Changed = append(result.Changed, ValueChange[K, V]{Key: pair.Key, Old: pair.Value, New: value})
+		}
+		return true
+	})
+	new.Range(func(pair Pair[K, V]) bool {
+		if !old.ContainsKey(pair.Key) {
+			result.Added = append(result.Added, pair)
+		}
+		return true
+	})
+	return result
+}
+
+// MapEqual returns true if m1 and m2 have the same keys, and the values of every key are equal by valueEqualer
+func MapEqual[K any, V any](m1 Map[K, V], m2 Map[K, V], valueEqualer Equaler[V]) bool {
+	if m1.Len() != m2.Len() {
+		return false
+	}
+
+	equal := true
+	m1.Range(func(pair Pair[K, V]) bool {
+		value, exists := m2.Get(pair.Key)
+		equal = exists && valueEqualer(pair.Value, value)
+		return equal
+	})
+	return equal
+}
@@ -0,0 +1,77 @@
+package collection_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
+)
+
+var _ = Describe("CollectionEqual and Diff", func() {
+	newDeque := func(items ...int) Deque[int] {
+		result := NewDeque[int](basicEquator[int])
+		AddAll[int](result, items...)
+		return result
+	}
+
+	It("CollectionEqual ignores the order but counts the repetitive items.", func() {
+		Expect(CollectionEqual[int](newDeque(1, 2, 3), newDeque(3, 1, 2), basicEquator[int])).To(BeTrue())
+		Expect(CollectionEqual[int](newDeque(), newDeque(), basicEquator[int])).To(BeTrue())
+		Expect(CollectionEqual[int](newDeque(1, 1, 2), newDeque(1, 2, 2), basicEquator[int])).To(BeFalse())
+		Expect(CollectionEqual[int](newDeque(1, 2), newDeque(1, 2, 3), basicEquator[int])).To(BeFalse())
+
+		set := NewSet[int, int](basicHasher[int], basicEquator[int])
+		AddAll[int](set, 2, 1)
+		Expect(CollectionEqual[int](set, newDeque(1, 2), basicEquator[int])).To(BeTrue())
+	})
+
+	It("Diff returns the added and the removed items.", func() {
+		added, removed := Diff[int](newDeque(1, 2, 3, 3), newDeque(2, 3, 4, 5), basicEquator[int])
+		Expect(added).To(ConsistOf(4, 5))
+		Expect(removed).To(ConsistOf(1, 3))
+
+		added, removed = Diff[int](newDeque(1), newDeque(1), basicEquator[int])
+		Expect(added).To(BeEmpty())
+		Expect(removed).To(BeEmpty())
+	})
+})
+
+var _ = Describe("MapEqual and DiffMaps", func() {
+	var old, new Map[string, int]
+
+	BeforeEach(func() {
+		old = NewMap[string, int, string](basicHasher[string], basicEquator[string])
+		new = NewOrderedMap[string, int, string](basicHasher[string], basicEquator[string])
+		for key, value := range map[string]int{"a": 1, "b": 2, "c": 3} {
+			old.Put(key, value)
+			new.Put(key, value)
+		}
+	})
+
+	It("returns an empty diff for the equal maps.", func() {
+		Expect(MapEqual(old, new, basicEquator[int])).To(BeTrue())
+		Expect(DiffMaps(old, new, basicEquator[int]).IsEmpty()).To(BeTrue())
+	})
+
+	It("returns the per-key differences.", func() {
+		new.Remove("a")
+		new.Put("b", 20)
+		new.Put("d", 4)
+		Expect(MapEqual(old, new, basicEquator[int])).To(BeFalse())
+
+		diff := DiffMaps(old, new, basicEquator[int])
+		Expect(diff.IsEmpty()).To(BeFalse())
+		Expect(diff.Added).To(Equal([]Pair[string, int]{{Key: "d", Value: 4}}))
+		Expect(diff.Removed).To(Equal([]Pair[string, int]{{Key: "a", Value: 1}}))
+		Expect(diff.Changed).To(Equal([]ValueChange[string, int]{{Key: "b", Old: 2, New: 20}}))
+	})
+
+	It("MapEqual compares the keys and the values.", func() {
+		new.Put("c", 30)
+		Expect(MapEqual(old, new, basicEquator[int])).To(BeFalse())
+		new.Put("c", 3)
+		new.Remove("c")
+		new.Put("e", 3)
+		Expect(MapEqual(old, new, basicEquator[int])).To(BeFalse())
+	})
+})
@@ -0,0 +1,143 @@
+package collection
+
+// DisjointSet A union-find structure that partitions the items into disjoint groups.
+//  Union and Find take nearly O(1) amortized time with path compression and union by size. It's not thread-safe.
+type DisjointSet[T any] interface {
+	// Add adds the item as a group of its own. It returns false if the item exists.
+	Add(item T) bool
+	Has(item T) bool
+	// Union merges the groups of the two items, adding the items that don't exist.
+	//  It returns false if the items are already in the same group.
+	Union(first, second T) bool
+	// Find returns the representative of the group of the item. It returns false if the item doesn't exist.
+	Find(item T) (root T, exists bool)
+	// SameSet returns true if both items exist and are in the same group
+	SameSet(first, second T) bool
+	// Groups returns all the groups. The order of the groups and the order in a group are not guaranteed.
+	Groups() [][]T
+	// Len returns the number of the items
+	Len() int
+	// GroupLen returns the number of the groups
+	GroupLen() int
+	Clear()
+}
+
+type disjointSetNode[T any] struct {
+	item   T
+	parent *disjointSetNode[T]
+	// size is only valid for the roots
+	size int
+}
+
+func NewDisjointSet[T any, C comparable](hasher Hasher[T, C], equaler Equaler[T]) DisjointSet[T] {
+	return &disjointSet[T]{
+		nodes: NewMap[T, *disjointSetNode[T], C](hasher, equaler),
+	}
+}
+
+type disjointSet[T any] struct {
+	nodes  Map[T, *disjointSetNode[T]]
+	groups int
+}
+
+func (d *disjointSet[T]) Add(item T) bool {
+	if d.nodes.ContainsKey(item) {
+		return false
+	}
+	d.getOrAdd(item)
+	return true
+}
+
+func (d *disjointSet[T]) getOrAdd(item T) *disjointSetNode[T] {
+	node, _ := d.nodes.GetOrPut(item, func() *disjointSetNode[T] {
+		d.groups++
+		node := &disjointSetNode[T]{item: item, size: 1}
+		node.parent = node
+		return node
+	})
+	return node
+}
+
+func (d *disjointSet[T]) Has(item T) bool {
+	return d.nodes.ContainsKey(item)
+}
+
+func (d *disjointSet[T]) find(node *disjointSetNode[T]) *disjointSetNode[T] {
+	root := node
+	for root.parent != root {
+		root = root.parent
+	}
+	// Path compression
+	for node != root {
+		next := node.parent
+		node.parent = root
+		node = next
+	}
+	return root
+}
+
+func (d *disjointSet[T]) Union(first, second T) bool {
+	firstRoot := d.find(d.getOrAdd(first))
+	secondRoot := d.find(d.getOrAdd(second))
+	if firstRoot == secondRoot {
+		return false
+	}
+
+	if firstRoot.size < secondRoot.size {
+		firstRoot, secondRoot = secondRoot, firstRoot
+	}
+	secondRoot.parent = firstRoot
+	firstRoot.size += secondRoot.size
+	d.groups--
+	return true
+}
+
+func (d *disjointSet[T]) Find(item T) (root T, exists bool) {
+	node, exists := d.nodes.Get(item)
+	if !exists {
+		return
+	}
+	return d.find(node).item, true
+}
+
+func (d *disjointSet[T]) SameSet(first, second T) bool {
+	firstNode, exists := d.nodes.Get(first)
+	if !exists {
+		return false
+	}
+	secondNode, exists := d.nodes.Get(second)
+	if !exists {
+		return false
+	}
+	return d.find(firstNode) == d.find(secondNode)
+}
+
+func (d *disjointSet[T]) Groups() [][]T {
+	indexes := map[*disjointSetNode[T]]int{}
+	result := make([][]T, 0, d.groups)
+	d.nodes.Range(func(pair Pair[T, *disjointSetNode[T]]) bool {
+		root := d.find(pair.Value)
+		index, exists := indexes[root]
+		if !exists {
+			index = len(result)
+			indexes[root] = index
+			result = append(result, make([]T, 0, root.size))
+		}
+		result[index] = append(result[index], pair.Key)
+		return true
+	})
+	return result
+}
+
+func (d *disjointSet[T]) Len() int {
+	return d.nodes.Len()
+}
+
+func (d *disjointSet[T]) GroupLen() int {
+	return d.groups
+}
+
+func (d *disjointSet[T]) Clear() {
+	d.nodes.Clear()
+	d.groups = 0
+}
@@ -0,0 +1,77 @@
+package collection_test
+
+import (
+	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DisjointSet", func() {
+	var disjointSet DisjointSet[int]
+
+	BeforeEach(func() {
+		disjointSet = NewDisjointSet[int, int](basicHasher[int], basicEquator[int])
+	})
+
+	It("can add the items as groups of their own.", func() {
+		Expect(disjointSet.Add(1)).To(BeTrue())
+		Expect(disjointSet.Add(1)).To(BeFalse())
+		Expect(disjointSet.Has(1)).To(BeTrue())
+		Expect(disjointSet.Has(2)).To(BeFalse())
+
+		root, exists := disjointSet.Find(1)
+		Expect(exists).To(BeTrue())
+		Expect(root).To(Equal(1))
+		_, exists = disjointSet.Find(2)
+		Expect(exists).To(BeFalse())
+	})
+
+	It("can union the groups.", func() {
+		for i := 0; i < 10; i++ {
+			disjointSet.Add(i)
+		}
+		Expect(disjointSet.GroupLen()).To(Equal(10))
+
+		// Groups by the parity
+		for i := 2; i < 10; i++ {
+			Expect(disjointSet.Union(i, i-2)).To(BeTrue())
+		}
+		Expect(disjointSet.Union(0, 8)).To(BeFalse())
+		Expect(disjointSet.GroupLen()).To(Equal(2))
+		Expect(disjointSet.Len()).To(Equal(10))
+
+		Expect(disjointSet.SameSet(1, 9)).To(BeTrue())
+		Expect(disjointSet.SameSet(1, 8)).To(BeFalse())
+		Expect(disjointSet.SameSet(1, 11)).To(BeFalse())
+		first, _ := disjointSet.Find(3)
+		second, _ := disjointSet.Find(7)
+		Expect(first).To(Equal(second))
+
+		groups := disjointSet.Groups()
+		Expect(groups).To(ConsistOf(ConsistOf(0, 2, 4, 6, 8), ConsistOf(1, 3, 5, 7, 9)))
+
+		Expect(disjointSet.Union(3, 4)).To(BeTrue())
+		Expect(disjointSet.SameSet(1, 8)).To(BeTrue())
+		Expect(disjointSet.Groups()).To(HaveLen(1))
+	})
+
+	It("adds the missing items when unioning.", func() {
+		Expect(disjointSet.Union(1, 2)).To(BeTrue())
+		Expect(disjointSet.Len()).To(Equal(2))
+		Expect(disjointSet.GroupLen()).To(Equal(1))
+		Expect(disjointSet.SameSet(2, 1)).To(BeTrue())
+
+		disjointSet.Clear()
+		Expect(disjointSet.Len()).To(Equal(0))
+		Expect(disjointSet.GroupLen()).To(Equal(0))
+		Expect(disjointSet.Groups()).To(BeEmpty())
+	})
+
+	It("works with a long chain.", func() {
+		for i := 1; i < 1000; i++ {
+			disjointSet.Union(i-1, i)
+		}
+		Expect(disjointSet.GroupLen()).To(Equal(1))
+		Expect(disjointSet.SameSet(0, 999)).To(BeTrue())
+	})
+})
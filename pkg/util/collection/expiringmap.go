@@ -0,0 +1,181 @@
+package collection
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/utils/clock"
+)
+
+// ExpiringMap A thread-safe map whose entries expire after a TTL.
+//  Expired entries are invisible to Get and Range, but they are only removed when EvictExpired is called
+//  or when the map is modified. Call EvictExpired periodically if the expired entries should be removed actively.
+type ExpiringMap[K any, V any] interface {
+	// Put puts the entry and resets its TTL
+	Put(key K, value V)
+	Get(key K) (value V, exists bool)
+	Remove(key K) (old V, exists bool)
+	// Len returns the number of the entries, including the expired ones that haven't been evicted
+	Len() int
+	// EvictExpired removes all the expired entries
+	EvictExpired()
+	// Range calls f for every entry that is not expired, until f returns false
+	Range(f func(key K, value V) bool)
+}
+
+type expiringEntry[K any, V any] struct {
+	key      K
+	value    V
+	deadline time.Time
+}
+
+func NewExpiringMap[K any, V any, C comparable](ttl time.Duration, clock clock.PassiveClock,
+	hasher Hasher[K, C], equaler Equaler[K]) ExpiringMap[K, V] {
+	return NewExpiringMapWithCallback[K, V, C](ttl, clock, nil, hasher, equaler)
+}
+
+// NewExpiringMapWithCallback onExpire is called when an expired entry is evicted, after the lock is released,
+//  so it can access the map. It is not called for Remove or the replaced values. It can be nil.
+func NewExpiringMapWithCallback[K any, V any, C comparable](ttl time.Duration, clock clock.PassiveClock,
+	onExpire func(key K, value V), hasher Hasher[K, C], equaler Equaler[K]) ExpiringMap[K, V] {
+	return &expiringMap[K, V]{
+		ttl:      ttl,
+		clock:    clock,
+		onExpire: onExpire,
+		entries:  NewMap[K, *expiringEntry[K, V], C](hasher, equaler),
+		deadlines: NewPrioritySet[*expiringEntry[K, V], C](
+			func(first, second *expiringEntry[K, V]) bool {
+				return first.deadline.Before(second.deadline)
+			},
+			func(entry *expiringEntry[K, V]) C {
+				return hasher(entry.key)
+			},
+			func(first, second *expiringEntry[K, V]) bool {
+				return equaler(first.key, second.key)
+			}),
+	}
+}
+
+type expiringMap[K any, V any] struct {
+	ttl      time.Duration
+	clock    clock.PassiveClock
+	onExpire func(key K, value V)
+	entries  Map[K, *expiringEntry[K, V]]
+	// deadlines has the same entries as `entries`, ordered by their deadlines
+	deadlines PrioritySet[*expiringEntry[K, V]]
+	l         sync.RWMutex
+}
+
+func (e *expiringMap[K, V]) isExpired(entry *expiringEntry[K, V], now time.Time) bool {
+	return !entry.deadline.After(now)
+}
+
+func (e *expiringMap[K, V]) Put(key K, value V) {
+	_, expired := e.put(key, value)
+	e.notify(expired)
+}
+
+// put returns whether the key exists and is not expired before putting
+func (e *expiringMap[K, V]) put(key K, value V) (exists bool, expired []*expiringEntry[K, V]) {
+	e.l.Lock()
+	defer e.l.Unlock()
+
+	expired = e.evictExpired()
+	deadline := e.clock.Now().Add(e.ttl)
+	if entry, exists := e.entries.Get(key); exists {
+		entry.key = key
+		entry.value = value
+		entry.deadline = deadline
+		e.deadlines.Fix(entry)
+		return true, expired
+	}
+
+	entry := &expiringEntry[K, V]{key: key, value: value, deadline: deadline}
+	e.entries.Put(key, entry)
+	e.deadlines.Add(entry)
+	return false, expired
+}
+
+func (e *expiringMap[K, V]) Get(key K) (value V, exists bool) {
+	e.l.RLock()
+	defer e.l.RUnlock()
+
+	entry, exists := e.entries.Get(key)
+	if !exists || e.isExpired(entry, e.clock.Now()) {
+		exists = false
+		return
+	}
+	return entry.value, true
+}
+
+func (e *expiringMap[K, V]) Remove(key K) (old V, exists bool) {
+	var expired []*expiringEntry[K, V]
+	old, exists, expired = e.remove(key)
+	e.notify(expired)
+	return
+}
+
+func (e *expiringMap[K, V]) remove(key K) (old V, exists bool, expired []*expiringEntry[K, V]) {
+	e.l.Lock()
+	defer e.l.Unlock()
+
+	expired = e.evictExpired()
+	entry, exists := e.entries.Remove(key)
+	if !exists {
+		return
+	}
+	e.deadlines.RemoveFirst(entry)
+	return entry.value, true, expired
+}
+
+func (e *expiringMap[K, V]) Len() int {
+	e.l.RLock()
+	defer e.l.RUnlock()
+
+	return e.entries.Len()
+}
+
+func (e *expiringMap[K, V]) EvictExpired() {
+	e.l.Lock()
+	expired := e.evictExpired()
+	e.l.Unlock()
+
+	e.notify(expired)
+}
+
+// evictExpired should be called with the lock held. It returns the evicted entries.
+func (e *expiringMap[K, V]) evictExpired() (expired []*expiringEntry[K, V]) {
+	now := e.clock.Now()
+	for entry, exists := e.deadlines.TryPeek(); exists && e.isExpired(entry, now); {
+		e.deadlines.TryPop()
+		e.entries.Remove(entry.key)
+		expired = append(expired, entry)
+		entry, exists = e.deadlines.TryPeek()
+	}
+	return
+}
+
+// notify should be called without the lock held
+func (e *expiringMap[K, V]) notify(expired []*expiringEntry[K, V]) {
+	if e.onExpire == nil {
+		return
+	}
+	for _, entry := range expired {
+		e.onExpire(entry.key, entry.value)
+	}
+}
+
+func (e *expiringMap[K, V]) Range(f func(key K, value V) bool) {
+	e.l.RLock()
+	defer e.l.RUnlock()
+
+	now := e.clock.Now()
+	for _, pair := range e.entries.ToArray() {
+		if e.isExpired(pair.Value, now) {
+			continue
+		}
+		if !f(pair.Key, pair.Value.value) {
+			return
+		}
+	}
+}
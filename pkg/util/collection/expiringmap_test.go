@@ -0,0 +1,189 @@
+package collection_test
+
+import (
+	"sync"
+	"time"
+
+	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+var _ = Describe("ExpiringMap", func() {
+	var fakeClock *testingclock.FakePassiveClock
+	var ttl time.Duration
+	var expiringMap ExpiringMap[int, int]
+
+	collect := func() map[int]int {
+		result := map[int]int{}
+		expiringMap.Range(func(key, value int) bool {
+			result[key] = value
+			return true
+		})
+		return result
+	}
+
+	BeforeEach(func() {
+		fakeClock = testingclock.NewFakePassiveClock(time.Now())
+		ttl = time.Minute
+		expiringMap = NewExpiringMap[int, int, int](ttl, fakeClock, basicHasher[int], basicEquator[int])
+	})
+
+	It("can get what it puts before the entries expire.", func() {
+		expiringMap.Put(1, 10)
+		value, exists := expiringMap.Get(1)
+		Expect(exists).To(BeTrue())
+		Expect(value).To(Equal(10))
+
+		fakeClock.SetTime(fakeClock.Now().Add(ttl))
+		_, exists = expiringMap.Get(1)
+		Expect(exists).To(BeFalse())
+	})
+
+	It("resets the TTL when an entry is put again.", func() {
+		expiringMap.Put(1, 10)
+		fakeClock.SetTime(fakeClock.Now().Add(ttl / 2))
+		expiringMap.Put(1, 11)
+		fakeClock.SetTime(fakeClock.Now().Add(ttl / 2))
+
+		value, exists := expiringMap.Get(1)
+		Expect(exists).To(BeTrue())
+		Expect(value).To(Equal(11))
+
+		expiringMap.EvictExpired()
+		Expect(expiringMap.Len()).To(Equal(1))
+	})
+
+	It("can remove what it puts.", func() {
+		expiringMap.Put(1, 10)
+		old, exists := expiringMap.Remove(1)
+		Expect(exists).To(BeTrue())
+		Expect(old).To(Equal(10))
+		_, exists = expiringMap.Remove(1)
+		Expect(exists).To(BeFalse())
+		Expect(expiringMap.Len()).To(Equal(0))
+	})
+
+	Describe("Range", func() {
+		BeforeEach(func() {
+			expiringMap.Put(1, 10)
+			expiringMap.Put(2, 20)
+			fakeClock.SetTime(fakeClock.Now().Add(ttl / 2))
+			expiringMap.Put(3, 30)
+			expiringMap.Put(4, 40)
+			fakeClock.SetTime(fakeClock.Now().Add(ttl / 2))
+		})
+
+		It("visits all the entries that are not expired.", func() {
+			Expect(collect()).To(Equal(map[int]int{3: 30, 4: 40}))
+		})
+
+		It("doesn't remove the expired entries.", func() {
+			collect()
+			Expect(expiringMap.Len()).To(Equal(4))
+
+			expiringMap.EvictExpired()
+			Expect(expiringMap.Len()).To(Equal(2))
+			Expect(collect()).To(Equal(map[int]int{3: 30, 4: 40}))
+		})
+
+		It("stops when f returns false.", func() {
+			visited := 0
+			expiringMap.Range(func(key, value int) bool {
+				visited++
+				return false
+			})
+			Expect(visited).To(Equal(1))
+		})
+
+		It("can be called concurrently with writers.", func() {
+			wait := sync.WaitGroup{}
+			for i := 0; i < 10; i++ {
+				wait.Add(2)
+				tmp := i
+				go func() {
+					defer wait.Done()
+					expiringMap.Put(tmp+10, tmp)
+				}()
+				go func() {
+					defer wait.Done()
+					collect()
+				}()
+			}
+			wait.Wait()
+			Expect(collect()).To(HaveLen(12))
+		})
+	})
+
+	It("calls onExpire for the evicted entries only.", func() {
+		expired := map[int]int{}
+		expiringMap = NewExpiringMapWithCallback[int, int, int](ttl, fakeClock, func(key, value int) {
+			// onExpire is called without the lock, so it can access the map
+			expiringMap.Get(key)
+			expired[key] = value
+		}, basicHasher[int], basicEquator[int])
+		expiringMap.Put(1, 10)
+		expiringMap.Put(2, 20)
+		expiringMap.Put(3, 30)
+		expiringMap.Remove(2)
+		fakeClock.SetTime(fakeClock.Now().Add(ttl / 2))
+		expiringMap.Put(1, 11)
+		Expect(expired).To(BeEmpty())
+
+		fakeClock.SetTime(fakeClock.Now().Add(ttl / 2))
+		expiringMap.EvictExpired()
+		Expect(expired).To(Equal(map[int]int{3: 30}))
+		Expect(expiringMap.Len()).To(Equal(1))
+
+		fakeClock.SetTime(fakeClock.Now().Add(ttl))
+		expiringMap.Put(4, 40)
+		Expect(expired).To(Equal(map[int]int{1: 11, 3: 30}))
+		Expect(expiringMap.Len()).To(Equal(1))
+	})
+})
+
+var _ = Describe("ExpiringSet", func() {
+	var fakeClock *testingclock.FakePassiveClock
+	ttl := time.Minute
+
+	BeforeEach(func() {
+		fakeClock = testingclock.NewFakePassiveClock(time.Now())
+	})
+
+	It("dedupes the items in the TTL window.", func() {
+		expiringSet := NewExpiringSet[string, string](ttl, fakeClock, basicHasher[string], basicEquator[string])
+		Expect(expiringSet.Add("a")).To(BeTrue())
+		Expect(expiringSet.Add("a")).To(BeFalse())
+		Expect(expiringSet.Has("a")).To(BeTrue())
+
+		fakeClock.SetTime(fakeClock.Now().Add(ttl))
+		Expect(expiringSet.Has("a")).To(BeFalse())
+		Expect(expiringSet.Add("a")).To(BeTrue())
+		Expect(expiringSet.Remove("a")).To(BeTrue())
+		Expect(expiringSet.Remove("a")).To(BeFalse())
+		Expect(expiringSet.Len()).To(Equal(0))
+	})
+
+	It("calls onExpire for the evicted items.", func() {
+		expired := []string{}
+		expiringSet := NewExpiringSetWithCallback[string, string](ttl, fakeClock, func(item string) {
+			expired = append(expired, item)
+		}, basicHasher[string], basicEquator[string])
+		expiringSet.Add("a")
+		fakeClock.SetTime(fakeClock.Now().Add(ttl / 2))
+		expiringSet.Add("b")
+
+		items := []string{}
+		expiringSet.Range(func(item string) bool {
+			items = append(items, item)
+			return true
+		})
+		Expect(items).To(ConsistOf("a", "b"))
+
+		fakeClock.SetTime(fakeClock.Now().Add(ttl / 2))
+		expiringSet.EvictExpired()
+		Expect(expired).To(Equal([]string{"a"}))
+		Expect(expiringSet.Len()).To(Equal(1))
+	})
+})
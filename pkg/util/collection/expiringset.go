@@ -0,0 +1,78 @@
+package collection
+
+import (
+	"time"
+
+	"k8s.io/utils/clock"
+)
+
+// ExpiringSet A thread-safe set whose items expire after a TTL, which can be used to dedupe the items in a time
+//  window. Like ExpiringMap, the expired items are invisible, but they are only removed when EvictExpired is called
+//  or when the set is modified.
+type ExpiringSet[T any] interface {
+	// Add adds the item and resets its TTL. It returns false if the item exists and is not expired.
+	Add(item T) bool
+	Has(item T) bool
+	Remove(item T) bool
+	// Len returns the number of the items, including the expired ones that haven't been evicted
+	Len() int
+	// EvictExpired removes all the expired items
+	EvictExpired()
+	// Range calls f for every item that is not expired, until f returns false
+	Range(f func(item T) bool)
+}
+
+func NewExpiringSet[T any, C comparable](ttl time.Duration, clock clock.PassiveClock,
+	hasher Hasher[T, C], equaler Equaler[T]) ExpiringSet[T] {
+	return NewExpiringSetWithCallback[T, C](ttl, clock, nil, hasher, equaler)
+}
+
+// NewExpiringSetWithCallback onExpire is called when an expired item is evicted, after the lock is released.
+//  It is not called for Remove. It can be nil.
+func NewExpiringSetWithCallback[T any, C comparable](ttl time.Duration, clock clock.PassiveClock,
+	onExpire func(item T), hasher Hasher[T, C], equaler Equaler[T]) ExpiringSet[T] {
+	var onEntryExpire func(item T, _ emptyType)
+	if onExpire != nil {
+		onEntryExpire = func(item T, _ emptyType) {
+			onExpire(item)
+		}
+	}
+	return &expiringSet[T]{
+		m: NewExpiringMapWithCallback[T, emptyType, C](
+			ttl, clock, onEntryExpire, hasher, equaler).(*expiringMap[T, emptyType]),
+	}
+}
+
+type expiringSet[T any] struct {
+	m *expiringMap[T, emptyType]
+}
+
+func (e *expiringSet[T]) Add(item T) bool {
+	exists, expired := e.m.put(item, empty)
+	e.m.notify(expired)
+	return !exists
+}
+
+func (e *expiringSet[T]) Has(item T) bool {
+	_, exists := e.m.Get(item)
+	return exists
+}
+
+func (e *expiringSet[T]) Remove(item T) bool {
+	_, exists := e.m.Remove(item)
+	return exists
+}
+
+func (e *expiringSet[T]) Len() int {
+	return e.m.Len()
+}
+
+func (e *expiringSet[T]) EvictExpired() {
+	e.m.EvictExpired()
+}
+
+func (e *expiringSet[T]) Range(f func(item T) bool) {
+	e.m.Range(func(item T, _ emptyType) bool {
+		return f(item)
+	})
+}
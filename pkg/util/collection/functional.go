@@ -0,0 +1,58 @@
+package collection
+
+// The following functions visit the items by Collection.Range, so they don't copy the items like ToArray.
+//  The order is the same as Range, and c must not be modified in the callbacks.
+
+// MapTo returns the results of applying f to every item of c
+func MapTo[T any, R any](c Collection[T], f func(T) R) []R {
+	result := make([]R, 0, c.Len())
+	c.Range(func(item T) bool {
+		result = append(result, f(item))
+		return true
+	})
+	return result
+}
+
+// Reduce accumulates the items of c into `initial` by f
+func Reduce[T any, A any](c Collection[T], initial A, f func(accumulator A, item T) A) A {
+	result := initial
+	c.Range(func(item T) bool {
+		result = f(result, item)
+		return true
+	})
+	return result
+}
+
+// ForEach applies f to every item of c
+func ForEach[T any](c Collection[T], f func(T)) {
+	c.Range(func(item T) bool {
+		f(item)
+		return true
+	})
+}
+
+// Any returns true if any item of c matches predicate. It returns false if c is empty.
+func Any[T any](c Collection[T], predicate func(T) bool) bool {
+	_, found := Find(c, predicate)
+	return found
+}
+
+// All returns true if all the items of c match predicate. It returns true if c is empty.
+func All[T any](c Collection[T], predicate func(T) bool) bool {
+	return !Any(c, func(item T) bool {
+		return !predicate(item)
+	})
+}
+
+// Find returns the first item of c that matches predicate
+func Find[T any](c Collection[T], predicate func(T) bool) (result T, found bool) {
+	c.Range(func(item T) bool {
+		if predicate(item) {
+			result = item
+			found = true
+			return false
+		}
+		return true
+	})
+	return
+}
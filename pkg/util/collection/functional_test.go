@@ -0,0 +1,73 @@
+package collection_test
+
+import (
+	"strconv"
+
+	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Functional helpers", func() {
+	var deque Deque[int]
+	var empty Deque[int]
+	isEven := func(item int) bool {
+		return item%2 == 0
+	}
+
+	BeforeEach(func() {
+		deque = NewDeque[int](basicEquator[int])
+		AddAll[int](deque, 1, 2, 3, 4)
+		empty = NewDeque[int](basicEquator[int])
+	})
+
+	It("can map the items.", func() {
+		Expect(MapTo[int, string](deque, strconv.Itoa)).To(Equal([]string{"1", "2", "3", "4"}))
+		Expect(MapTo[int, string](empty, strconv.Itoa)).To(BeEmpty())
+	})
+
+	It("can reduce the items.", func() {
+		sum := func(accumulator int, item int) int {
+			return accumulator + item
+		}
+		Expect(Reduce[int, int](deque, 0, sum)).To(Equal(10))
+		Expect(Reduce[int, int](empty, 5, sum)).To(Equal(5))
+		Expect(Reduce[int, string](deque, "", func(accumulator string, item int) string {
+			return accumulator + strconv.Itoa(item)
+		})).To(Equal("1234"))
+	})
+
+	It("can visit every item.", func() {
+		visited := []int{}
+		ForEach[int](deque, func(item int) {
+			visited = append(visited, item)
+		})
+		Expect(visited).To(Equal([]int{1, 2, 3, 4}))
+	})
+
+	It("can match the items.", func() {
+		Expect(Any[int](deque, isEven)).To(BeTrue())
+		Expect(All[int](deque, isEven)).To(BeFalse())
+		Expect(Any[int](empty, isEven)).To(BeFalse())
+		Expect(All[int](empty, isEven)).To(BeTrue())
+
+		Filter[int](deque, isEven)
+		Expect(All[int](deque, isEven)).To(BeTrue())
+	})
+
+	It("can find the first matched item.", func() {
+		visited := 0
+		item, found := Find[int](deque, func(item int) bool {
+			visited++
+			return isEven(item)
+		})
+		Expect(found).To(BeTrue())
+		Expect(item).To(Equal(2))
+		Expect(visited).To(Equal(2))
+
+		_, found = Find[int](deque, func(item int) bool {
+			return item > 4
+		})
+		Expect(found).To(BeFalse())
+	})
+})
@@ -0,0 +1,154 @@
+package collection_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"time"
+
+	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+func gobRoundTrip(src any, dst any) {
+	buffer := bytes.Buffer{}
+	Expect(gob.NewEncoder(&buffer).Encode(src)).To(Succeed())
+	Expect(gob.NewDecoder(&buffer).Decode(dst)).To(Succeed())
+}
+
+var _ = Describe("Gob encoding", func() {
+	for _, st := range []setType{defaultSet, threadSafeSet, prioritySet, threadSafePrioritySet, cowSet} {
+		st := st
+		It("works with "+string(st)+".", func() {
+			src := createSet[string, string](st, basicHasher[string], basicEquator[string], stringAscComparator)
+			for _, item := range []string{"b", "a", "c"} {
+				src.Add(item)
+			}
+			dst := createSet[string, string](st, basicHasher[string], basicEquator[string], stringAscComparator)
+			dst.Add("d")
+
+			gobRoundTrip(src, dst)
+			Expect(dst.Len()).To(Equal(3))
+			Expect(dst.ToArray()).To(ConsistOf("a", "b", "c"))
+			Expect(dst.Has("d")).To(BeFalse())
+			Expect(src.Len()).To(Equal(3))
+
+			dst.Add("d")
+			Expect(dst.Has("d")).To(BeTrue())
+			Expect(dst.RemoveFirst("a")).To(BeTrue())
+			Expect(dst.Len()).To(Equal(3))
+		})
+	}
+
+	It("keeps a valid heap for PriorityQueue.", func() {
+		src := NewPriorityQueue[int](intAscComparator, basicEquator[int])
+		for _, item := range []int{5, 1, 4, 1, 3} {
+			src.Add(item)
+		}
+		dst := NewPriorityQueue[int](intAscComparator, basicEquator[int])
+
+		gobRoundTrip(src, dst)
+		Expect(dst.Len()).To(Equal(5))
+		Expect(dst.ToArray()).To(ConsistOf(src.ToArray()))
+		dst.Add(2)
+		actual := []int{}
+		for value, exists := dst.TryPop(); exists; value, exists = dst.TryPop() {
+			actual = append(actual, value)
+		}
+		Expect(actual).To(Equal([]int{1, 1, 2, 3, 4, 5}))
+	})
+
+	It("keeps the buckets of Map.", func() {
+		src := NewMap[int, string, int](fakeHasher, basicEquator[int])
+		src.Put(1, "a")
+		src.Put(2, "b")
+		dst := NewMap[int, string, int](fakeHasher, basicEquator[int])
+
+		gobRoundTrip(src, dst)
+		Expect(dst.Len()).To(Equal(2))
+		Expect(dst.ToArray()).To(ConsistOf(src.ToArray()))
+		value, exists := dst.Get(2)
+		Expect(exists).To(BeTrue())
+		Expect(value).To(Equal("b"))
+		_, exists = dst.Remove(1)
+		Expect(exists).To(BeTrue())
+		Expect(dst.Len()).To(Equal(1))
+	})
+
+	It("works with ThreadSafeMap.", func() {
+		src := NewThreadSafeMap[int, string, int](basicHasher[int], basicEquator[int])
+		src.Put(1, "a")
+		dst := NewThreadSafeMap[int, string, int](basicHasher[int], basicEquator[int])
+		dst.Put(2, "b")
+
+		gobRoundTrip(src, dst)
+		Expect(dst.ToArray()).To(ConsistOf(Pair[int, string]{Key: 1, Value: "a"}))
+	})
+
+	for _, mt := range []mapType{defaultMap, threadSafeMap, concurrentMap, lruCache, orderedMap, priorityMap, cowMap} {
+		mt := mt
+		It("works with "+string(mt)+".", func() {
+			src := createMap[int, string, int](mt, basicHasher[int], basicEquator[int], intAscComparator)
+			src.Put(2, "b")
+			src.Put(1, "a")
+			dst := createMap[int, string, int](mt, basicHasher[int], basicEquator[int], intAscComparator)
+			dst.Put(3, "c")
+
+			gobRoundTrip(src, dst)
+			Expect(dst.ToArray()).To(ConsistOf(src.ToArray()))
+			dst.Put(0, "z")
+			Expect(dst.Len()).To(Equal(3))
+		})
+	}
+
+	It("keeps the order and the deadlines of LRUCache.", func() {
+		fakeClock := testingclock.NewFakePassiveClock(time.Now())
+		src := NewLRUCache[int, string, int](3, fakeClock, nil, basicHasher[int], basicEquator[int])
+		src.Put(1, "a")
+		src.PutWithTTL(2, "b", time.Minute)
+		src.Put(3, "c")
+		src.PutWithTTL(4, "d", time.Second)
+		src.Get(2)
+		fakeClock.SetTime(fakeClock.Now().Add(2 * time.Second))
+		dst := NewLRUCache[int, string, int](3, fakeClock, nil, basicHasher[int], basicEquator[int])
+
+		// 4 is expired and not encoded
+		gobRoundTrip(src, dst)
+		Expect(dst.Len()).To(Equal(2))
+		dst.Put(5, "e")
+		dst.Put(6, "f")
+		// 3 is the least recently used one
+		Expect(dst.ContainsKey(3)).To(BeFalse())
+		Expect(dst.ContainsKey(2)).To(BeTrue())
+		fakeClock.SetTime(fakeClock.Now().Add(time.Minute))
+		Expect(dst.ContainsKey(2)).To(BeFalse())
+	})
+
+	It("works with Encode and Decode.", func() {
+		src := NewPriorityQueue[int](intAscComparator, basicEquator[int])
+		AddAll[int](src, 3, 1, 2)
+		buffer := bytes.Buffer{}
+		Expect(Encode[int](&buffer, src)).To(Succeed())
+
+		dst := NewPriorityQueue[int](intAscComparator, basicEquator[int])
+		Expect(Decode[int](&buffer, dst)).To(Succeed())
+		Expect(popAll[int](dst)).To(Equal([]int{1, 2, 3}))
+
+		Expect(Encode[int](&buffer, NewDeque[int](basicEquator[int]))).To(MatchError(ContainSubstring("encoding")))
+		Expect(Decode[int](&buffer, NewDeque[int](basicEquator[int]))).To(MatchError(ContainSubstring("decoding")))
+	})
+
+	It("works with empty collections.", func() {
+		src := NewMap[int, string, int](basicHasher[int], basicEquator[int])
+		dst := NewMap[int, string, int](basicHasher[int], basicEquator[int])
+		dst.Put(1, "a")
+
+		gobRoundTrip(src, dst)
+		Expect(dst.Len()).To(Equal(0))
+	})
+})
+
+func stringAscComparator(first, second string) bool {
+	return first < second
+}
@@ -0,0 +1,256 @@
+package collection
+
+import (
+	"errors"
+)
+
+// ErrCycle is returned by Graph.TopologicalSort if the graph has a cycle
+var ErrCycle = errors.New("the graph has a cycle")
+
+// Graph A directed graph. The vertices and the edges keep their insertion order, so the traversals are deterministic.
+//  If T is a pointer type, the hash code of a vertex must remain the same while it's in the graph.
+//  Graph is not thread-safe.
+type Graph[T any] interface {
+	// AddVertex returns false if the vertex exists
+	AddVertex(vertex T) bool
+	// AddEdge adds the absent vertices, and returns false if the edge exists
+	AddEdge(from T, to T) bool
+	// RemoveVertex removes the vertex with all its edges, and returns false if the vertex doesn't exist
+	RemoveVertex(vertex T) bool
+	// RemoveEdge returns false if the edge doesn't exist
+	RemoveEdge(from T, to T) bool
+	HasVertex(vertex T) bool
+	HasEdge(from T, to T) bool
+	// Vertices returns the vertices in the order they are added
+	Vertices() []T
+	// Successors returns the vertices that `vertex` has edges to
+	Successors(vertex T) []T
+	// Predecessors returns the vertices that have edges to `vertex`
+	Predecessors(vertex T) []T
+	VertexLen() int
+	EdgeLen() int
+	// BFS visits the vertices reachable from `start` in the breadth-first order, until visit returns false
+	BFS(start T, visit func(vertex T) bool)
+	// DFS visits the vertices reachable from `start` in the depth-first pre-order, until visit returns false
+	DFS(start T, visit func(vertex T) bool)
+	// TopologicalSort returns the vertices in an order where every edge goes from an earlier vertex to a later one.
+	//  ErrCycle will be returned if the graph has a cycle.
+	TopologicalSort() ([]T, error)
+	HasCycle() bool
+}
+
+func NewGraph[T any, C comparable](hasher Hasher[T, C], equaler Equaler[T]) Graph[T] {
+	return &graph[T, C]{
+		vertices: NewOrderedMap[T, *adjacency[T], C](hasher, equaler),
+		hasher:   hasher,
+		equaler:  equaler,
+		edges:    0,
+	}
+}
+
+type adjacency[T any] struct {
+	successors   Map[T, emptyType]
+	predecessors Map[T, emptyType]
+}
+
+type graph[T any, C comparable] struct {
+	vertices Map[T, *adjacency[T]]
+	hasher   Hasher[T, C]
+	equaler  Equaler[T]
+	edges    int
+}
+
+func (g *graph[T, C]) newVertexSet() Map[T, emptyType] {
+	return NewOrderedMap[T, emptyType, C](g.hasher, g.equaler)
+}
+
+func (g *graph[T, C]) AddVertex(vertex T) bool {
+	if g.vertices.ContainsKey(vertex) {
+		return false
+	}
+
+	g.vertices.Put(vertex, &adjacency[T]{
+		successors:   g.newVertexSet(),
+		predecessors: g.newVertexSet(),
+	})
+	return true
+}
+
+func (g *graph[T, C]) AddEdge(from T, to T) bool {
+	g.AddVertex(from)
+	g.AddVertex(to)
+
+	fromAdjacency, _ := g.vertices.Get(from)
+	if _, exists := fromAdjacency.successors.PutIfAbsent(to, empty); exists {
+		return false
+	}
+	toAdjacency, _ := g.vertices.Get(to)
+	toAdjacency.predecessors.Put(from, empty)
+	g.edges++
+	return true
+}
+
+func (g *graph[T, C]) RemoveVertex(vertex T) bool {
+	if !g.HasVertex(vertex) {
+		return false
+	}
+
+	for _, successor := range g.Successors(vertex) {
+		g.RemoveEdge(vertex, successor)
+	}
+	// A self-loop has been removed as a successor, so it won't be removed twice
+	for _, predecessor := range g.Predecessors(vertex) {
+		g.RemoveEdge(predecessor, vertex)
+	}
+	g.vertices.Remove(vertex)
+	return true
+}
+
+func (g *graph[T, C]) RemoveEdge(from T, to T) bool {
+	fromAdjacency, exists := g.vertices.Get(from)
+	if !exists {
+		return false
+	}
+	if _, exists = fromAdjacency.successors.Remove(to); !exists {
+		return false
+	}
+
+	toAdjacency, _ := g.vertices.Get(to)
+	toAdjacency.predecessors.Remove(from)
+	g.edges--
+	return true
+}
+
+func (g *graph[T, C]) HasVertex(vertex T) bool {
+	return g.vertices.ContainsKey(vertex)
+}
+
+func (g *graph[T, C]) HasEdge(from T, to T) bool {
+	adjacency, exists := g.vertices.Get(from)
+	return exists && adjacency.successors.ContainsKey(to)
+}
+
+func (g *graph[T, C]) Vertices() []T {
+	result := make([]T, 0, g.vertices.Len())
+	g.vertices.Range(func(pair Pair[T, *adjacency[T]]) bool {
+		result = append(result, pair.Key)
+		return true
+	})
+	return result
+}
+
+func keysToSlice[T any](m Map[T, emptyType]) []T {
+	result := make([]T, 0, m.Len())
+	m.Range(func(pair Pair[T, emptyType]) bool {
+		result = append(result, pair.Key)
+		return true
+	})
+	return result
+}
+
+func (g *graph[T, C]) Successors(vertex T) []T {
+	adjacency, exists := g.vertices.Get(vertex)
+	if !exists {
+		return []T{}
+	}
+	return keysToSlice(adjacency.successors)
+}
+
+func (g *graph[T, C]) Predecessors(vertex T) []T {
+	adjacency, exists := g.vertices.Get(vertex)
+	if !exists {
+		return []T{}
+	}
+	return keysToSlice(adjacency.predecessors)
+}
+
+func (g *graph[T, C]) VertexLen() int {
+	return g.vertices.Len()
+}
+
+func (g *graph[T, C]) EdgeLen() int {
+	return g.edges
+}
+
+func (g *graph[T, C]) BFS(start T, visit func(vertex T) bool) {
+	if !g.HasVertex(start) {
+		return
+	}
+
+	visited := g.newVertexSet()
+	visited.Put(start, empty)
+	queue := NewQueue[T](g.equaler)
+	queue.Enqueue(start)
+	for vertex, exists := queue.TryPop(); exists; vertex, exists = queue.TryPop() {
+		if !visit(vertex) {
+			return
+		}
+		for _, successor := range g.Successors(vertex) {
+			if _, exists := visited.PutIfAbsent(successor, empty); !exists {
+				queue.Enqueue(successor)
+			}
+		}
+	}
+}
+
+// DFS uses a stack instead of recursion, so it works with deep graphs
+func (g *graph[T, C]) DFS(start T, visit func(vertex T) bool) {
+	if !g.HasVertex(start) {
+		return
+	}
+
+	visited := g.newVertexSet()
+	stack := NewStack[T](g.equaler)
+	stack.Push(start)
+	for vertex, exists := stack.TryPop(); exists; vertex, exists = stack.TryPop() {
+		if _, exists := visited.PutIfAbsent(vertex, empty); exists {
+			continue
+		}
+		if !visit(vertex) {
+			return
+		}
+		successors := g.Successors(vertex)
+		// Push in the reversed order, so the successors are visited in their insertion order
+		for i := len(successors) - 1; i >= 0; i-- {
+			if !visited.ContainsKey(successors[i]) {
+				stack.Push(successors[i])
+			}
+		}
+	}
+}
+
+// TopologicalSort uses Kahn's algorithm. Among the vertices without dependencies, the earlier added ones come first.
+func (g *graph[T, C]) TopologicalSort() ([]T, error) {
+	inDegrees := NewMap[T, int, C](g.hasher, g.equaler)
+	queue := NewQueue[T](g.equaler)
+	g.vertices.Range(func(pair Pair[T, *adjacency[T]]) bool {
+		inDegree := pair.Value.predecessors.Len()
+		inDegrees.Put(pair.Key, inDegree)
+		if inDegree == 0 {
+			queue.Enqueue(pair.Key)
+		}
+		return true
+	})
+
+	result := make([]T, 0, g.vertices.Len())
+	for vertex, exists := queue.TryPop(); exists; vertex, exists = queue.TryPop() {
+		result = append(result, vertex)
+		for _, successor := range g.Successors(vertex) {
+			if inDegrees.Merge(successor, -1, func(old int, new int) int {
+				return old + new
+			}) == 0 {
+				queue.Enqueue(successor)
+			}
+		}
+	}
+
+	if len(result) < g.vertices.Len() {
+		return nil, ErrCycle
+	}
+	return result, nil
+}
+
+func (g *graph[T, C]) HasCycle() bool {
+	_, err := g.TopologicalSort()
+	return err != nil
+}
@@ -0,0 +1,95 @@
+package collection_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
+)
+
+func collectVertices(traverse func(start string, visit func(vertex string) bool), start string, limit int) []string {
+	result := []string{}
+	traverse(start, func(vertex string) bool {
+		result = append(result, vertex)
+		return len(result) < limit
+	})
+	return result
+}
+
+var _ = Describe("Graph", func() {
+	var graph Graph[string]
+
+	BeforeEach(func() {
+		graph = NewGraph[string, string](basicHasher[string], basicEquator[string])
+		//   a -> b -> d
+		//   |         ^
+		//   v         |
+		//   c --------+ -> e
+		for _, edge := range [][2]string{{"a", "b"}, {"a", "c"}, {"b", "d"}, {"c", "d"}, {"c", "e"}} {
+			Expect(graph.AddEdge(edge[0], edge[1])).To(BeTrue())
+		}
+	})
+
+	It("maintains the vertices and the edges.", func() {
+		Expect(graph.AddEdge("a", "b")).To(BeFalse())
+		Expect(graph.AddVertex("a")).To(BeFalse())
+		Expect(graph.AddVertex("f")).To(BeTrue())
+		Expect(graph.Vertices()).To(Equal([]string{"a", "b", "c", "d", "e", "f"}))
+		Expect(graph.VertexLen()).To(Equal(6))
+		Expect(graph.EdgeLen()).To(Equal(5))
+		Expect(graph.HasEdge("a", "b")).To(BeTrue())
+		Expect(graph.HasEdge("b", "a")).To(BeFalse())
+		Expect(graph.Successors("c")).To(Equal([]string{"d", "e"}))
+		Expect(graph.Predecessors("d")).To(Equal([]string{"b", "c"}))
+		Expect(graph.Successors("x")).To(BeEmpty())
+
+		Expect(graph.RemoveEdge("c", "d")).To(BeTrue())
+		Expect(graph.RemoveEdge("c", "d")).To(BeFalse())
+		Expect(graph.Predecessors("d")).To(Equal([]string{"b"}))
+		Expect(graph.EdgeLen()).To(Equal(4))
+
+		Expect(graph.RemoveVertex("b")).To(BeTrue())
+		Expect(graph.RemoveVertex("b")).To(BeFalse())
+		Expect(graph.HasVertex("b")).To(BeFalse())
+		Expect(graph.Successors("a")).To(Equal([]string{"c"}))
+		Expect(graph.Predecessors("d")).To(BeEmpty())
+		Expect(graph.EdgeLen()).To(Equal(2))
+	})
+
+	It("removes the self-loops with the vertex.", func() {
+		graph.AddEdge("d", "d")
+		graph.AddEdge("d", "a")
+		Expect(graph.EdgeLen()).To(Equal(7))
+		Expect(graph.RemoveVertex("d")).To(BeTrue())
+		Expect(graph.EdgeLen()).To(Equal(3))
+		Expect(graph.Predecessors("a")).To(BeEmpty())
+	})
+
+	It("supports BFS and DFS.", func() {
+		Expect(collectVertices(graph.BFS, "a", 100)).To(Equal([]string{"a", "b", "c", "d", "e"}))
+		Expect(collectVertices(graph.DFS, "a", 100)).To(Equal([]string{"a", "b", "d", "c", "e"}))
+		Expect(collectVertices(graph.BFS, "c", 100)).To(Equal([]string{"c", "d", "e"}))
+		Expect(collectVertices(graph.BFS, "a", 2)).To(Equal([]string{"a", "b"}))
+		Expect(collectVertices(graph.DFS, "a", 3)).To(Equal([]string{"a", "b", "d"}))
+		Expect(collectVertices(graph.DFS, "x", 100)).To(BeEmpty())
+
+		graph.AddEdge("d", "a")
+		Expect(collectVertices(graph.DFS, "d", 100)).To(Equal([]string{"d", "a", "b", "c", "e"}))
+	})
+
+	It("supports topological sort.", func() {
+		sorted, err := graph.TopologicalSort()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sorted).To(Equal([]string{"a", "b", "c", "d", "e"}))
+		Expect(graph.HasCycle()).To(BeFalse())
+
+		graph.AddEdge("e", "a")
+		_, err = graph.TopologicalSort()
+		Expect(err).To(Equal(ErrCycle))
+		Expect(graph.HasCycle()).To(BeTrue())
+
+		graph.RemoveEdge("e", "a")
+		graph.AddEdge("f", "f")
+		Expect(graph.HasCycle()).To(BeTrue())
+	})
+})
@@ -0,0 +1,177 @@
+package collection
+
+import (
+	"container/heap"
+)
+
+// NewIndexedPriorityQueue returns a PriorityQueue which maintains an index from the items to their positions in the
+//  heap, so Has and Contains take O(1) time, while RemoveFirst, Update and Fix take O(log n) time.
+//  Like the one returned by NewPriorityQueue, it allows repetitive items. The hash code of an item must remain the same
+//  while it's in the queue.
+func NewIndexedPriorityQueue[T any, C comparable](
+	comparator Comparator[T], hasher Hasher[T, C], equaler Equaler[T]) PriorityQueue[T] {
+	helper := &priorityHelper[T, emptyType]{
+		entries:    []*priorityHelperEntry[T, emptyType]{},
+		comparator: comparator,
+	}
+	heap.Init(helper)
+	return &indexedPriorityQueue[T]{
+		priorityQueue: priorityQueue[T]{
+			helper:  helper,
+			equaler: equaler,
+		},
+		index: NewMap[T, []*priorityHelperEntry[T, emptyType], C](hasher, equaler),
+	}
+}
+
+type indexedPriorityQueue[T any] struct {
+	priorityQueue[T]
+	// index The entries of the equal items
+	index Map[T, []*priorityHelperEntry[T, emptyType]]
+}
+
+func (pq *indexedPriorityQueue[T]) Has(item T) bool {
+	return pq.index.ContainsKey(item)
+}
+
+func (pq *indexedPriorityQueue[T]) Contains(item T) bool {
+	return pq.Has(item)
+}
+
+func (pq *indexedPriorityQueue[T]) Add(item T) (oldItem T, replaced bool) {
+	entry := &priorityHelperEntry[T, emptyType]{key: item}
+	heap.Push(pq.helper, entry)
+	entries, _ := pq.index.Get(item)
+	pq.index.Put(item, append(entries, entry))
+	replaced = false
+	return
+}
+
+func (pq *indexedPriorityQueue[T]) AddAll(items []T) {
+	entries := make([]*priorityHelperEntry[T, emptyType], len(items))
+	for i, item := range items {
+		entries[i] = &priorityHelperEntry[T, emptyType]{key: item}
+		indexed, _ := pq.index.Get(item)
+		pq.index.Put(item, append(indexed, entries[i]))
+	}
+	pq.helper.pushAll(entries)
+}
+
+func (pq *indexedPriorityQueue[T]) TryPop() (item T, exists bool) {
+	if pq.Len() <= 0 {
+		exists = false
+		return
+	}
+
+	entry := heap.Pop(pq.helper).(*priorityHelperEntry[T, emptyType])
+	pq.unindex(entry)
+	return entry.key, true
+}
+
+func (pq *indexedPriorityQueue[T]) Peek() T {
+	return mustPeek[T](pq, "IndexedPriorityQueue")
+}
+
+func (pq *indexedPriorityQueue[T]) Pop() T {
+	return mustPop[T](pq, "IndexedPriorityQueue")
+}
+
+func (pq *indexedPriorityQueue[T]) PopOrError() (T, error) {
+	return popOrError[T](pq, "IndexedPriorityQueue")
+}
+
+func (pq *indexedPriorityQueue[T]) PopN(n int) []T {
+	return popN[T](pq, n)
+}
+
+func (pq *indexedPriorityQueue[T]) DrainTo(dst Collection[T], max int) int {
+	return drainTo[T](pq, dst, max)
+}
+
+// RemoveFirst removes one of the items equal to `e`, which may not be the first one in the heap
+func (pq *indexedPriorityQueue[T]) RemoveFirst(e T) bool {
+	entries, exists := pq.index.Get(e)
+	if !exists {
+		return false
+	}
+
+	entry := entries[len(entries)-1]
+	heap.Remove(pq.helper, entry.index)
+	pq.unindex(entry)
+	return true
+}
+
+// unindex removes the entry from the index. It takes O(k) time, where k is the number of the items equal to the entry.
+func (pq *indexedPriorityQueue[T]) unindex(entry *priorityHelperEntry[T, emptyType]) {
+	entries, _ := pq.index.Get(entry.key)
+	if len(entries) == 1 {
+		pq.index.Remove(entry.key)
+		return
+	}
+
+	for i, e := range entries {
+		if e == entry {
+			entries[i] = entries[len(entries)-1]
+			entries[len(entries)-1] = nil
+			pq.index.Put(entry.key, entries[:len(entries)-1])
+			return
+		}
+	}
+}
+
+// Update replaces one of the items equal to `item` with `item`, and restores the order in O(log n) time
+func (pq *indexedPriorityQueue[T]) Update(item T) bool {
+	return pq.fix(item, true)
+}
+
+// Fix restores the order in O(log n) time after the priority of one of the items equal to `item` is changed in place
+func (pq *indexedPriorityQueue[T]) Fix(item T) bool {
+	return pq.fix(item, false)
+}
+
+func (pq *indexedPriorityQueue[T]) fix(item T, replace bool) bool {
+	entries, exists := pq.index.Get(item)
+	if !exists {
+		return false
+	}
+
+	entry := entries[0]
+	if replace {
+		entry.key = item
+	}
+	heap.Fix(pq.helper, entry.index)
+	return true
+}
+
+func (pq *indexedPriorityQueue[T]) Clear() {
+	pq.priorityQueue.Clear()
+	pq.index.Clear()
+}
+
+func (pq *indexedPriorityQueue[T]) Clone() Collection[T] {
+	cloned := &indexedPriorityQueue[T]{
+		priorityQueue: *pq.priorityQueue.clone(),
+		index:         CloneMap(pq.index),
+	}
+	// Point the index to the copied entries
+	cloned.index.Clear()
+	for _, entry := range cloned.helper.entries {
+		entries, _ := cloned.index.Get(entry.key)
+		cloned.index.Put(entry.key, append(entries, entry))
+	}
+	return cloned
+}
+
+// UnmarshalBinary replaces the content of pq with the decoded items.
+func (pq *indexedPriorityQueue[T]) UnmarshalBinary(data []byte) error {
+	var items []T
+	if err := gobDecode(data, &items); err != nil {
+		return err
+	}
+
+	pq.Clear()
+	for _, item := range items {
+		pq.Add(item)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package collection_test
+
+import (
+	"math/rand"
+	"sort"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
+)
+
+var _ = Describe("IndexedPriorityQueue", func() {
+	It("can pop the element in order.", func() {
+		for i := 0; i < 10; i++ {
+			for _, length := range []int{0, 1, 2, 10, 30} {
+				testCollection[int](
+					NewIndexedPriorityQueue[int, int](intAscComparator, basicHasher[int], basicEquator[int]),
+					getRandomArray(length), intComparator, true, fakeUniquer[int])
+				testCollection[int](
+					NewIndexedPriorityQueue[int, int](intDescComparator, basicHasher[int], basicEquator[int]),
+					getRandomArray(length), intComparator, false, fakeUniquer[int])
+			}
+		}
+	})
+
+	It("keeps the repetitive items.", func() {
+		pq := NewIndexedPriorityQueue[int, int](intAscComparator, basicHasher[int], basicEquator[int])
+		for _, item := range []int{3, 1, 3, 2, 3} {
+			pq.Add(item)
+		}
+		Expect(pq.Len()).To(Equal(5))
+		Expect(pq.Has(3)).To(BeTrue())
+		Expect(pq.RemoveFirst(3)).To(BeTrue())
+		Expect(pq.RemoveFirst(3)).To(BeTrue())
+		Expect(pq.Contains(3)).To(BeTrue())
+		Expect(pq.RemoveFirst(3)).To(BeTrue())
+		Expect(pq.Has(3)).To(BeFalse())
+		Expect(pq.RemoveFirst(3)).To(BeFalse())
+		Expect(pq.ToArray()).To(ConsistOf(1, 2))
+	})
+
+	It("is consistent with the items after random operations.", func() {
+		pq := NewIndexedPriorityQueue[int, int](intAscComparator, basicHasher[int], basicEquator[int])
+		expected := []int{}
+		for i := 0; i < 1000; i++ {
+			item := rand.Intn(50)
+			if rand.Intn(3) == 0 {
+				removed := pq.RemoveFirst(item)
+				index := sort.SearchInts(expected, item)
+				Expect(removed).To(Equal(index < len(expected) && expected[index] == item))
+				if removed {
+					expected = append(expected[:index], expected[index+1:]...)
+				}
+			} else {
+				pq.Add(item)
+				expected = append(expected, item)
+				sort.Ints(expected)
+			}
+			Expect(pq.Has(item)).To(Equal(sort.SearchInts(expected, item) < len(expected) &&
+				expected[sort.SearchInts(expected, item)] == item))
+		}
+
+		actual := []int{}
+		for item, exists := pq.TryPop(); exists; item, exists = pq.TryPop() {
+			actual = append(actual, item)
+		}
+		Expect(actual).To(Equal(expected))
+		Expect(pq.Has(expected[0])).To(BeFalse())
+	})
+
+	It("can be cleared and encoded.", func() {
+		src := NewIndexedPriorityQueue[int, int](intAscComparator, basicHasher[int], basicEquator[int])
+		for _, item := range []int{3, 1, 2, 1} {
+			src.Add(item)
+		}
+		dst := NewIndexedPriorityQueue[int, int](intAscComparator, basicHasher[int], basicEquator[int])
+		dst.Add(100)
+		gobRoundTrip(src, dst)
+		Expect(dst.Has(100)).To(BeFalse())
+		Expect(dst.Has(1)).To(BeTrue())
+		Expect(dst.PeekAll()).To(ConsistOf(1, 1, 2, 3))
+
+		dst.Clear()
+		Expect(dst.Len()).To(Equal(0))
+		Expect(dst.Has(1)).To(BeFalse())
+	})
+})
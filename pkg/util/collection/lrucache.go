@@ -0,0 +1,353 @@
+package collection
+
+import (
+	"container/list"
+	"fmt"
+	"time"
+
+	"k8s.io/utils/clock"
+)
+
+// LRUCache A Map with a fixed capacity. When it is full, putting a new key evicts the least recently used entry.
+//  Get, GetOrPutDefault and the puts mark the entry as the most recently used one,
+//  while ContainsKey, Has, Range and ToArray don't.
+//  An entry put by PutWithTTL expires after the TTL. The expired entries are invisible, but they are only removed when
+//  they are accessed, when EvictExpired is called, or when they are the least recently used ones, so Len may include them.
+//  LRUCache is not thread-safe.
+type LRUCache[K any, V any] interface {
+	Map[K, V]
+	// PutWithTTL equals Put, but the entry expires after ttl
+	PutWithTTL(key K, value V, ttl time.Duration) (old V, exists bool)
+	// EvictExpired removes all the expired entries
+	EvictExpired()
+	Capacity() int
+}
+
+// NewLRUCache onEvict is called when an entry is evicted because the cache is full or the entry is expired.
+//  It is not called for Remove, Clear or the replaced values. It can be nil.
+func NewLRUCache[K any, V any, C comparable](capacity int, clock clock.PassiveClock, onEvict func(key K, value V),
+	hasher Hasher[K, C], equaler Equaler[K]) LRUCache[K, V] {
+	if capacity <= 0 {
+		panic(fmt.Errorf("capacity should be positive"))
+	}
+
+	return &lruCache[K, V]{
+		capacity: capacity,
+		clock:    clock,
+		onEvict:  onEvict,
+		elements: NewMap[K, *list.Element, C](hasher, equaler),
+		order:    list.New(),
+	}
+}
+
+type lruEntry[K any, V any] struct {
+	key   K
+	value V
+	// deadline is zero if the entry never expires
+	deadline time.Time
+}
+
+type lruCache[K any, V any] struct {
+	capacity int
+	clock    clock.PassiveClock
+	onEvict  func(key K, value V)
+	elements Map[K, *list.Element]
+	// order The front is the most recently used entry
+	order *list.List
+}
+
+func (l *lruCache[K, V]) entryOf(element *list.Element) *lruEntry[K, V] {
+	return element.Value.(*lruEntry[K, V])
+}
+
+func (l *lruCache[K, V]) isExpired(entry *lruEntry[K, V], now time.Time) bool {
+	return !entry.deadline.IsZero() && !entry.deadline.After(now)
+}
+
+func (l *lruCache[K, V]) remove(element *list.Element) *lruEntry[K, V] {
+	entry := l.entryOf(element)
+	l.order.Remove(element)
+	l.elements.Remove(entry.key)
+	return entry
+}
+
+func (l *lruCache[K, V]) evict(element *list.Element) {
+	entry := l.remove(element)
+	if l.onEvict != nil {
+		l.onEvict(entry.key, entry.value)
+	}
+}
+
+// lookup returns the element of the key if it exists and is not expired. The expired one will be evicted.
+func (l *lruCache[K, V]) lookup(key K) (*list.Element, bool) {
+	element, exists := l.elements.Get(key)
+	if !exists {
+		return nil, false
+	}
+	if l.isExpired(l.entryOf(element), l.clock.Now()) {
+		l.evict(element)
+		return nil, false
+	}
+	return element, true
+}
+
+func (l *lruCache[K, V]) put(key K, value V, deadline time.Time) (old V, exists bool) {
+	element, exists := l.lookup(key)
+	if exists {
+		entry := l.entryOf(element)
+		old = entry.value
+		entry.value = value
+		entry.deadline = deadline
+		l.order.MoveToFront(element)
+		return
+	}
+
+	l.elements.Put(key, l.order.PushFront(&lruEntry[K, V]{key: key, value: value, deadline: deadline}))
+	for l.order.Len() > l.capacity {
+		l.evict(l.order.Back())
+	}
+	return
+}
+
+func (l *lruCache[K, V]) Put(key K, value V) (old V, exists bool) {
+	return l.put(key, value, time.Time{})
+}
+
+func (l *lruCache[K, V]) PutWithTTL(key K, value V, ttl time.Duration) (old V, exists bool) {
+	return l.put(key, value, l.clock.Now().Add(ttl))
+}
+
+func (l *lruCache[K, V]) Get(key K) (value V, exists bool) {
+	element, exists := l.lookup(key)
+	if !exists {
+		return
+	}
+	l.order.MoveToFront(element)
+	return l.entryOf(element).value, true
+}
+
+func (l *lruCache[K, V]) GetOrPutDefault(key K) (value V, exists bool) {
+	value, exists = l.Get(key)
+	if !exists {
+		l.Put(key, value)
+	}
+	return
+}
+
+func (l *lruCache[K, V]) ReplaceIfEqual(key K, expectedOld V, newValue V, valueEqualer Equaler[V]) bool {
+	element, exists := l.lookup(key)
+	if !exists || !valueEqualer(expectedOld, l.entryOf(element).value) {
+		return false
+	}
+
+	l.entryOf(element).value = newValue
+	l.order.MoveToFront(element)
+	return true
+}
+
+func (l *lruCache[K, V]) GetOrPut(key K, f func() V) (value V, exists bool) {
+	return getOrPut[K, V](l, key, f)
+}
+
+func (l *lruCache[K, V]) ComputeIfAbsent(key K, mapping func(key K) V) V {
+	return computeIfAbsent[K, V](l, key, mapping)
+}
+
+func (l *lruCache[K, V]) ComputeIfPresent(key K,
+	remapping func(key K, old V) (newValue V, keep bool)) (value V, exists bool) {
+	return computeIfPresent[K, V](l, key, remapping)
+}
+
+func (l *lruCache[K, V]) Merge(key K, value V, remapping func(old V, new V) V) V {
+	return merge[K, V](l, key, value, remapping)
+}
+
+func (l *lruCache[K, V]) PutIfAbsent(key K, value V) (existing V, exists bool) {
+	return putIfAbsent[K, V](l, key, value)
+}
+
+func (l *lruCache[K, V]) RemoveIf(key K, predicate func(value V) bool) bool {
+	return removeIf[K, V](l, key, predicate)
+}
+
+func (l *lruCache[K, V]) Remove(key K) (old V, exists bool) {
+	element, exists := l.lookup(key)
+	if !exists {
+		return
+	}
+	return l.remove(element).value, true
+}
+
+func (l *lruCache[K, V]) ContainsKey(key K) bool {
+	element, exists := l.elements.Get(key)
+	return exists && !l.isExpired(l.entryOf(element), l.clock.Now())
+}
+
+func (l *lruCache[K, V]) EvictExpired() {
+	now := l.clock.Now()
+	for element := l.order.Front(); element != nil; {
+		next := element.Next()
+		if l.isExpired(l.entryOf(element), now) {
+			l.evict(element)
+		}
+		element = next
+	}
+}
+
+func (l *lruCache[K, V]) Capacity() int {
+	return l.capacity
+}
+
+// Range visits the entries from the most recently used one to the least recently used one
+func (l *lruCache[K, V]) Range(f func(pair Pair[K, V]) bool) {
+	now := l.clock.Now()
+	for element := l.order.Front(); element != nil; element = element.Next() {
+		entry := l.entryOf(element)
+		if l.isExpired(entry, now) {
+			continue
+		}
+		if !f(Pair[K, V]{Key: entry.key, Value: entry.value}) {
+			return
+		}
+	}
+}
+
+func (l *lruCache[K, V]) All() func(yield func(Pair[K, V]) bool) {
+	return l.Range
+}
+
+func (l *lruCache[K, V]) Keys() func(yield func(K) bool) {
+	return keysOf[K, V](l)
+}
+
+func (l *lruCache[K, V]) Values() func(yield func(V) bool) {
+	return valuesOf[K, V](l)
+}
+
+func (l *lruCache[K, V]) KeyValues() func(yield func(K, V) bool) {
+	return keyValuesOf[K, V](l)
+}
+
+// ToArray returns the entries from the most recently used one to the least recently used one
+func (l *lruCache[K, V]) ToArray() []Pair[K, V] {
+	result := []Pair[K, V]{}
+	l.Range(func(pair Pair[K, V]) bool {
+		result = append(result, pair)
+		return true
+	})
+	return result
+}
+
+func (l *lruCache[K, V]) Add(pair Pair[K, V]) (oldItem Pair[K, V], replaced bool) {
+	oldValue, replaced := l.Put(pair.Key, pair.Value)
+	if replaced {
+		oldItem.Key = pair.Key
+		oldItem.Value = oldValue
+	}
+	return
+}
+
+func (l *lruCache[K, V]) RemoveFirst(pair Pair[K, V]) bool {
+	_, exists := l.Remove(pair.Key)
+	return exists
+}
+
+func (l *lruCache[K, V]) Has(pair Pair[K, V]) bool {
+	return l.ContainsKey(pair.Key)
+}
+
+func (l *lruCache[K, V]) Contains(pair Pair[K, V]) bool {
+	return l.Has(pair)
+}
+
+// TryPop removes the least recently used entry
+func (l *lruCache[K, V]) TryPop() (pair Pair[K, V], exists bool) {
+	now := l.clock.Now()
+	for element := l.order.Back(); element != nil; element = l.order.Back() {
+		if l.isExpired(l.entryOf(element), now) {
+			l.evict(element)
+			continue
+		}
+
+		entry := l.remove(element)
+		return Pair[K, V]{Key: entry.key, Value: entry.value}, true
+	}
+	return
+}
+
+func (l *lruCache[K, V]) Pop() Pair[K, V] {
+	return mustPop[Pair[K, V]](l, "LRUCache")
+}
+
+func (l *lruCache[K, V]) Len() int {
+	return l.order.Len()
+}
+
+func (l *lruCache[K, V]) Size() int {
+	return l.Len()
+}
+
+func (l *lruCache[K, V]) Empty() bool {
+	return l.Size() == 0
+}
+
+func (l *lruCache[K, V]) Clear() {
+	l.elements.Clear()
+	l.order.Init()
+}
+
+// Clone keeps the order of the entries and their deadlines
+func (l *lruCache[K, V]) Clone() Collection[Pair[K, V]] {
+	cloned := &lruCache[K, V]{
+		capacity: l.capacity,
+		clock:    l.clock,
+		onEvict:  l.onEvict,
+		elements: CloneMap(l.elements),
+		order:    list.New(),
+	}
+	for element := l.order.Front(); element != nil; element = element.Next() {
+		copied := *l.entryOf(element)
+		cloned.elements.Put(copied.key, cloned.order.PushBack(&copied))
+	}
+	return cloned
+}
+
+// encodedLRUEntry The fields are exported for gob
+type encodedLRUEntry[K any, V any] struct {
+	Key      K
+	Value    V
+	Deadline time.Time
+}
+
+// MarshalBinary encodes the unexpired entries from the least recently used one to the most recently used one,
+//  with their deadlines. The clock, onEvict, the hasher and the equaler are not encoded.
+func (l *lruCache[K, V]) MarshalBinary() ([]byte, error) {
+	now := l.clock.Now()
+	entries := make([]encodedLRUEntry[K, V], 0, l.order.Len())
+	for element := l.order.Back(); element != nil; element = element.Prev() {
+		entry := l.entryOf(element)
+		if !l.isExpired(entry, now) {
+			entries = append(entries, encodedLRUEntry[K, V]{
+				Key:      entry.key,
+				Value:    entry.value,
+				Deadline: entry.deadline,
+			})
+		}
+	}
+	return gobEncode(entries)
+}
+
+// UnmarshalBinary replaces the content of l with the decoded entries, keeping their order and deadlines.
+//  If there are more entries than the capacity, the least recently used ones are evicted.
+func (l *lruCache[K, V]) UnmarshalBinary(data []byte) error {
+	var entries []encodedLRUEntry[K, V]
+	if err := gobDecode(data, &entries); err != nil {
+		return err
+	}
+
+	l.Clear()
+	for _, entry := range entries {
+		l.put(entry.Key, entry.Value, entry.Deadline)
+	}
+	return nil
+}
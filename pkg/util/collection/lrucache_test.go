@@ -0,0 +1,133 @@
+package collection_test
+
+import (
+	"time"
+
+	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+var _ = Describe("LRUCache", func() {
+	testMap(lruCache)
+
+	var fakeClock *testingclock.FakePassiveClock
+	var evicted []Pair[int, int]
+	var cache LRUCache[int, int]
+
+	keys := func() []int {
+		result := []int{}
+		cache.Keys()(func(key int) bool {
+			result = append(result, key)
+			return true
+		})
+		return result
+	}
+
+	BeforeEach(func() {
+		fakeClock = testingclock.NewFakePassiveClock(time.Now())
+		evicted = []Pair[int, int]{}
+		cache = NewLRUCache[int, int, int](3, fakeClock, func(key int, value int) {
+			evicted = append(evicted, Pair[int, int]{Key: key, Value: value})
+		}, basicHasher[int], basicEquator[int])
+	})
+
+	It("panics if capacity is not positive.", func() {
+		Expect(func() {
+			NewLRUCache[int, int, int](0, fakeClock, nil, basicHasher[int], basicEquator[int])
+		}).To(Panic())
+	})
+
+	It("evicts the least recently used entry when it is full.", func() {
+		for i := 1; i <= 3; i++ {
+			cache.Put(i, i*10)
+		}
+		Expect(keys()).To(Equal([]int{3, 2, 1}))
+
+		value, exists := cache.Get(1)
+		Expect(exists).To(BeTrue())
+		Expect(value).To(Equal(10))
+		Expect(keys()).To(Equal([]int{1, 3, 2}))
+
+		cache.Put(4, 40)
+		Expect(evicted).To(Equal([]Pair[int, int]{{Key: 2, Value: 20}}))
+		Expect(keys()).To(Equal([]int{4, 1, 3}))
+		Expect(cache.Len()).To(Equal(cache.Capacity()))
+	})
+
+	It("doesn't change the order when checking the keys.", func() {
+		cache.Put(1, 10)
+		cache.Put(2, 20)
+		Expect(cache.ContainsKey(1)).To(BeTrue())
+		Expect(cache.Has(Pair[int, int]{Key: 1})).To(BeTrue())
+		Expect(keys()).To(Equal([]int{2, 1}))
+	})
+
+	It("doesn't call onEvict for removed or replaced entries.", func() {
+		cache.Put(1, 10)
+		cache.Put(1, 11)
+		cache.Put(2, 20)
+		cache.Remove(2)
+		cache.Put(3, 30)
+		cache.Clear()
+		Expect(evicted).To(BeEmpty())
+	})
+
+	It("pops the least recently used entry.", func() {
+		cache.Put(1, 10)
+		cache.Put(2, 20)
+		cache.Get(1)
+		pair, exists := cache.TryPop()
+		Expect(exists).To(BeTrue())
+		Expect(pair).To(Equal(Pair[int, int]{Key: 2, Value: 20}))
+		Expect(evicted).To(BeEmpty())
+	})
+
+	Describe("TTL", func() {
+		var ttl time.Duration
+
+		BeforeEach(func() {
+			ttl = time.Minute
+			cache.PutWithTTL(1, 10, ttl)
+			cache.Put(2, 20)
+			cache.PutWithTTL(3, 30, 2*ttl)
+			fakeClock.SetTime(fakeClock.Now().Add(ttl))
+		})
+
+		It("hides the expired entries.", func() {
+			Expect(cache.ContainsKey(1)).To(BeFalse())
+			Expect(keys()).To(Equal([]int{3, 2}))
+			Expect(cache.Len()).To(Equal(3))
+		})
+
+		It("evicts the expired entries when they are accessed.", func() {
+			_, exists := cache.Get(1)
+			Expect(exists).To(BeFalse())
+			Expect(evicted).To(Equal([]Pair[int, int]{{Key: 1, Value: 10}}))
+			Expect(cache.Len()).To(Equal(2))
+		})
+
+		It("evicts all the expired entries by EvictExpired.", func() {
+			fakeClock.SetTime(fakeClock.Now().Add(ttl))
+			cache.EvictExpired()
+			Expect(evicted).To(ConsistOf(Pair[int, int]{Key: 1, Value: 10}, Pair[int, int]{Key: 3, Value: 30}))
+			Expect(keys()).To(Equal([]int{2}))
+		})
+
+		It("removes the TTL when an entry is put again without TTL.", func() {
+			cache.Put(3, 31)
+			fakeClock.SetTime(fakeClock.Now().Add(ttl))
+			value, exists := cache.Get(3)
+			Expect(exists).To(BeTrue())
+			Expect(value).To(Equal(31))
+		})
+
+		It("skips the expired entries when popping.", func() {
+			pair, exists := cache.TryPop()
+			Expect(exists).To(BeTrue())
+			Expect(pair.Key).To(Equal(2))
+			Expect(evicted).To(Equal([]Pair[int, int]{{Key: 1, Value: 10}}))
+		})
+	})
+})
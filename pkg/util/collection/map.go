@@ -1,5 +1,15 @@
 package collection
 
+import (
+	"bytes"
+	"container/list"
+	"encoding"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"sync"
+)
+
 type Equaler[T any] func(original, new T) bool
 type Hasher[T any, C comparable] func(obj T) C
 
@@ -19,14 +29,253 @@ type Map[K any, V any] interface {
 	Put(key K, value V) (old V, exists bool)
 	Get(key K) (value V, exists bool)
 	Remove(key K) (old V, exists bool)
+	// GetOrPutDefault returns the value of the key if it exists.
+	//  Otherwise, it puts a zero value for the key and returns the zero value with exists=false.
+	GetOrPutDefault(key K) (value V, exists bool)
+	// ReplaceIfEqual replaces the value of the key with `newValue` only if the current value equals `expectedOld`
+	ReplaceIfEqual(key K, expectedOld V, newValue V, valueEqualer Equaler[V]) bool
+	// GetOrPut returns the value of the key if it exists.
+	//  Otherwise, it puts the value returned by `f` for the key and returns it with exists=false.
+	GetOrPut(key K, f func() V) (value V, exists bool)
+	// ComputeIfAbsent puts the value computed by `mapping` if the key is absent.
+	//  It returns the current value of the key.
+	ComputeIfAbsent(key K, mapping func(key K) V) V
+	// ComputeIfPresent replaces the value of the key with the one computed by `remapping` if the key exists.
+	//  If `remapping` returns keep=false, the key is removed.
+	//  It returns the new value and if the key still exists.
+	ComputeIfPresent(key K, remapping func(key K, old V) (newValue V, keep bool)) (value V, exists bool)
+	// Merge puts `value` if the key is absent. Otherwise, it replaces the value of the key with remapping(old, value).
+	//  It returns the new value of the key.
+	Merge(key K, value V, remapping func(old V, new V) V) V
+	// PutIfAbsent puts the value only if the key is absent.
+	//  If the key exists, it returns the existing value with exists=true and leaves it unchanged.
+	PutIfAbsent(key K, value V) (existing V, exists bool)
+	// RemoveIf removes the key only if `predicate` returns true for its value. It returns true if the key is removed.
+	RemoveIf(key K, predicate func(value V) bool) bool
+	// Size equals Len
+	Size() int
+	// Empty returns true if Size() == 0
+	Empty() bool
+	// Keys returns the sequence of the keys, which equals iter.Seq[K]
+	Keys() func(yield func(K) bool)
+	// Values returns the sequence of the values, which equals iter.Seq[V]
+	Values() func(yield func(V) bool)
+	// KeyValues returns the sequence of the entries, which equals iter.Seq2[K, V].
+	//  It can't be named as `All`, which is taken by Collection and returns the sequence of the pairs.
+	KeyValues() func(yield func(K, V) bool)
+}
+
+// Compactor is implemented by the maps that can release the memory kept after removals,
+//  including the maps created by NewMap, NewMapWithCapacity, NewThreadSafeMap and NewConcurrentMap.
+type Compactor interface {
+	// Compact releases the memory that is no longer used. It takes O(n) time.
+	Compact()
+}
+
+// NewMap accepts the Options WithCapacity, WithThreadSafety, WithStableOrdering, WithValueEqualer and WithPooling
+func NewMap[K any, V any, C comparable](hasher Hasher[K, C], equaler Equaler[K], opts ...Option) Map[K, V] {
+	return newMap[K, V, C](newOptions(opts), hasher, equaler)
+}
+
+func newMap[K any, V any, C comparable](o *options, hasher Hasher[K, C], equaler Equaler[K]) Map[K, V] {
+	var result Map[K, V]
+	if o.stableOrdering {
+		elementsOptions := &options{capacity: o.capacity, pooled: o.pooled}
+		result = &orderedMap[K, V]{
+			elements:     newMap[K, *list.Element, C](elementsOptions, hasher, equaler),
+			order:        list.New(),
+			valueEqualer: valueEqualerOf[V](o),
+		}
+	} else {
+		m := NewMapWithCapacity[K, V, C](o.capacity, hasher, equaler).(*mapImpl[K, V, C])
+		m.valueEqualer = valueEqualerOf[V](o)
+		if o.pooled {
+			m.pool = &sync.Pool{
+				New: func() any {
+					return &Pair[K, V]{}
+				},
+			}
+		}
+		result = m
+	}
+
+	if o.threadSafe {
+		result = &threadSafeMap[K, V]{m: result}
+	}
+	return result
 }
 
-func NewMap[K any, V any, C comparable](hasher Hasher[K, C], equaler Equaler[K]) Map[K, V] {
+// NewMapWithCapacity preallocates the space for `capacity` keys. The capacity is also used by Clear and Compact.
+func NewMapWithCapacity[K any, V any, C comparable](capacity int, hasher Hasher[K, C], equaler Equaler[K]) Map[K, V] {
+	if capacity < 0 {
+		panic(fmt.Errorf("capacity should be non-negative"))
+	}
+
 	return &mapImpl[K, V, C]{
-		data:    map[C][]*Pair[K, V]{},
-		hasher:  hasher,
-		equaler: equaler,
-		size:    0,
+		data:     make(map[C][]*Pair[K, V], capacity),
+		hasher:   hasher,
+		equaler:  equaler,
+		size:     0,
+		capacity: capacity,
+	}
+}
+
+// NewMapWithValueEquality returns a Map whose RemoveFirst, Has and Contains compare both the key and the value.
+//  The Map created by NewMap only compares the key. It equals NewMap with WithValueEqualer.
+func NewMapWithValueEquality[K any, V any, C comparable](
+	hasher Hasher[K, C], keyEqualer Equaler[K], valueEqualer Equaler[V]) Map[K, V] {
+	return NewMap[K, V, C](hasher, keyEqualer, WithValueEqualer(valueEqualer))
+}
+
+// NewPooledMap recycles the internal entries with a sync.Pool, which reduces the GC pressure when the keys are put
+//  and removed frequently. Otherwise, it's the same as the Map created by NewMap. It equals NewMap with WithPooling.
+func NewPooledMap[K any, V any, C comparable](hasher Hasher[K, C], equaler Equaler[K]) Map[K, V] {
+	return NewMap[K, V, C](hasher, equaler, WithPooling())
+}
+
+// NewMapFromNativeMap copies the entries of a native map
+func NewMapFromNativeMap[K comparable, V any, C comparable](m map[K]V, hasher Hasher[K, C],
+	equaler Equaler[K]) Map[K, V] {
+	result := NewMap[K, V, C](hasher, equaler)
+	for key, value := range m {
+		result.Put(key, value)
+	}
+	return result
+}
+
+// CloneMap equals m.Clone(), but returns a Map
+func CloneMap[K any, V any](m Map[K, V]) Map[K, V] {
+	return m.Clone().(Map[K, V])
+}
+
+// ToNativeMap copies the entries of m to a native map
+func ToNativeMap[K comparable, V any](m Map[K, V]) map[K]V {
+	result := make(map[K]V, m.Len())
+	m.Range(func(pair Pair[K, V]) bool {
+		result[pair.Key] = pair.Value
+		return true
+	})
+	return result
+}
+
+// AtomicMap is implemented by the maps created by NewThreadSafeMap.
+//  The batch operations acquire the lock only once, so other goroutines see either none or all of the changes.
+type AtomicMap[K any, V any] interface {
+	// PutAll puts the pairs in order and returns the number of the keys that didn't exist
+	PutAll(pairs []Pair[K, V]) int
+	// RemoveAll removes the keys and returns the number of the removed keys
+	RemoveAll(keys []K) int
+	// DoAtomically calls f with the underlying map under the write lock.
+	//  f must not keep the map after it returns, and must not call the methods of the thread-safe map.
+	DoAtomically(f func(m Map[K, V]))
+}
+
+// NewThreadSafeMap equals NewMap with WithThreadSafety
+func NewThreadSafeMap[K any, V any, C comparable](hasher Hasher[K, C], equaler Equaler[K]) Map[K, V] {
+	return NewMap[K, V, C](hasher, equaler, WithThreadSafety())
+}
+
+// ContainsAll returns true if m contains all the keys. It returns true when no key is given.
+func ContainsAll[K any, V any](m Map[K, V], keys ...K) bool {
+	for _, key := range keys {
+		if !m.ContainsKey(key) {
+			return false
+		}
+	}
+	return true
+}
+
+// FindAll returns all the pairs in m that match predicate. The returned pairs are copies.
+func FindAll[K any, V any](m Map[K, V], predicate func(K, V) bool) []Pair[K, V] {
+	result := []Pair[K, V]{}
+	for _, pair := range m.ToArray() {
+		if predicate(pair.Key, pair.Value) {
+			result = append(result, pair)
+		}
+	}
+	return result
+}
+
+func getOrPut[K any, V any](m Map[K, V], key K, f func() V) (value V, exists bool) {
+	value, exists = m.Get(key)
+	if !exists {
+		value = f()
+		m.Put(key, value)
+	}
+	return
+}
+
+func computeIfAbsent[K any, V any](m Map[K, V], key K, mapping func(key K) V) V {
+	value, _ := getOrPut(m, key, func() V {
+		return mapping(key)
+	})
+	return value
+}
+
+func computeIfPresent[K any, V any](m Map[K, V], key K,
+	remapping func(key K, old V) (newValue V, keep bool)) (value V, exists bool) {
+	old, exists := m.Get(key)
+	if !exists {
+		return
+	}
+
+	value, exists = remapping(key, old)
+	if !exists {
+		m.Remove(key)
+		var zero V
+		return zero, false
+	}
+	m.Put(key, value)
+	return
+}
+
+func merge[K any, V any](m Map[K, V], key K, value V, remapping func(old V, new V) V) V {
+	if old, exists := m.Get(key); exists {
+		value = remapping(old, value)
+	}
+	m.Put(key, value)
+	return value
+}
+
+func putIfAbsent[K any, V any](m Map[K, V], key K, value V) (existing V, exists bool) {
+	existing, exists = m.Get(key)
+	if !exists {
+		m.Put(key, value)
+	}
+	return
+}
+
+func removeIf[K any, V any](m Map[K, V], key K, predicate func(value V) bool) bool {
+	value, exists := m.Get(key)
+	if !exists || !predicate(value) {
+		return false
+	}
+
+	m.Remove(key)
+	return true
+}
+
+func keysOf[K any, V any](m Map[K, V]) func(yield func(K) bool) {
+	return func(yield func(K) bool) {
+		m.Range(func(pair Pair[K, V]) bool {
+			return yield(pair.Key)
+		})
+	}
+}
+
+func valuesOf[K any, V any](m Map[K, V]) func(yield func(V) bool) {
+	return func(yield func(V) bool) {
+		m.Range(func(pair Pair[K, V]) bool {
+			return yield(pair.Value)
+		})
+	}
+}
+
+func keyValuesOf[K any, V any](m Map[K, V]) func(yield func(K, V) bool) {
+	return func(yield func(K, V) bool) {
+		m.Range(func(pair Pair[K, V]) bool {
+			return yield(pair.Key, pair.Value)
+		})
 	}
 }
 
@@ -34,7 +283,31 @@ type mapImpl[K any, V any, C comparable] struct {
 	data    map[C][]*Pair[K, V]
 	hasher  Hasher[K, C]
 	equaler Equaler[K]
-	size    int
+	// valueEqualer is nil if the values are ignored by RemoveFirst and Has
+	valueEqualer Equaler[V]
+	size         int
+	capacity     int
+	// pool is nil if the pairs are not recycled
+	pool *sync.Pool
+}
+
+func (m *mapImpl[K, V, C]) newPair(key K, value V) *Pair[K, V] {
+	if m.pool == nil {
+		return &Pair[K, V]{Key: key, Value: value}
+	}
+	pair := m.pool.Get().(*Pair[K, V])
+	pair.Key = key
+	pair.Value = value
+	return pair
+}
+
+func (m *mapImpl[K, V, C]) releasePair(pair *Pair[K, V]) {
+	if m.pool == nil {
+		return
+	}
+	// Don't keep the key and the value reachable
+	*pair = Pair[K, V]{}
+	m.pool.Put(pair)
 }
 
 func (m *mapImpl[K, V, C]) ToArray() []Pair[K, V] {
@@ -49,6 +322,32 @@ func (m *mapImpl[K, V, C]) ToArray() []Pair[K, V] {
 	return result
 }
 
+func (m *mapImpl[K, V, C]) Range(f func(pair Pair[K, V]) bool) {
+	for _, pairs := range m.data {
+		for _, pair := range pairs {
+			if !f(*pair) {
+				return
+			}
+		}
+	}
+}
+
+func (m *mapImpl[K, V, C]) All() func(yield func(Pair[K, V]) bool) {
+	return m.Range
+}
+
+func (m *mapImpl[K, V, C]) Keys() func(yield func(K) bool) {
+	return keysOf[K, V](m)
+}
+
+func (m *mapImpl[K, V, C]) Values() func(yield func(V) bool) {
+	return valuesOf[K, V](m)
+}
+
+func (m *mapImpl[K, V, C]) KeyValues() func(yield func(K, V) bool) {
+	return keyValuesOf[K, V](m)
+}
+
 func (m *mapImpl[K, V, C]) Add(pair Pair[K, V]) (oldItem Pair[K, V], replaced bool) {
 	oldValue, replaced := m.Put(pair.Key, pair.Value)
 	if replaced {
@@ -61,14 +360,29 @@ func (m *mapImpl[K, V, C]) Add(pair Pair[K, V]) (oldItem Pair[K, V], replaced bo
 }
 
 func (m *mapImpl[K, V, C]) RemoveFirst(pair Pair[K, V]) bool {
+	if m.valueEqualer != nil {
+		return m.RemoveIf(pair.Key, func(value V) bool {
+			return m.valueEqualer(value, pair.Value)
+		})
+	}
+
 	_, exsiting := m.Remove(pair.Key)
 	return exsiting
 }
 
 func (m *mapImpl[K, V, C]) Has(pair Pair[K, V]) bool {
+	if m.valueEqualer != nil {
+		value, exists := m.Get(pair.Key)
+		return exists && m.valueEqualer(value, pair.Value)
+	}
+
 	return m.ContainsKey(pair.Key)
 }
 
+func (m *mapImpl[K, V, C]) Contains(pair Pair[K, V]) bool {
+	return m.Has(pair)
+}
+
 func (m *mapImpl[K, V, C]) TryPop() (pair Pair[K, V], exists bool) {
 	for _, pairs := range m.data {
 		pair = *pairs[len(pairs)-1]
@@ -81,6 +395,10 @@ func (m *mapImpl[K, V, C]) TryPop() (pair Pair[K, V], exists bool) {
 	return
 }
 
+func (m *mapImpl[K, V, C]) Pop() Pair[K, V] {
+	return mustPop[Pair[K, V]](m, "Map")
+}
+
 func (m *mapImpl[K, V, C]) Put(key K, value V) (old V, exists bool) {
 	hash := m.hasher(key)
 	pairs, exists := m.data[hash]
@@ -93,19 +411,13 @@ func (m *mapImpl[K, V, C]) Put(key K, value V) (old V, exists bool) {
 				return old, true
 			}
 		}
-		pairs = append(pairs, &Pair[K, V]{
-			Key:   key,
-			Value: value,
-		})
+		pairs = append(pairs, m.newPair(key, value))
 		m.data[hash] = pairs
 		m.size += 1
 		exists = false
 		return
 	} else {
-		m.data[hash] = []*Pair[K, V]{{
-			Key:   key,
-			Value: value,
-		}}
+		m.data[hash] = []*Pair[K, V]{m.newPair(key, value)}
 		m.size += 1
 		exists = false
 		return
@@ -128,10 +440,61 @@ func (m *mapImpl[K, V, C]) Get(key K) (value V, exists bool) {
 	return
 }
 
+func (m *mapImpl[K, V, C]) GetOrPutDefault(key K) (value V, exists bool) {
+	value, exists = m.Get(key)
+	if !exists {
+		m.Put(key, value)
+	}
+	return
+}
+
+func (m *mapImpl[K, V, C]) ReplaceIfEqual(key K, expectedOld V, newValue V, valueEqualer Equaler[V]) bool {
+	current, exists := m.Get(key)
+	if !exists || !valueEqualer(expectedOld, current) {
+		return false
+	}
+
+	m.Put(key, newValue)
+	return true
+}
+
+func (m *mapImpl[K, V, C]) GetOrPut(key K, f func() V) (value V, exists bool) {
+	return getOrPut[K, V](m, key, f)
+}
+
+func (m *mapImpl[K, V, C]) ComputeIfAbsent(key K, mapping func(key K) V) V {
+	return computeIfAbsent[K, V](m, key, mapping)
+}
+
+func (m *mapImpl[K, V, C]) ComputeIfPresent(key K,
+	remapping func(key K, old V) (newValue V, keep bool)) (value V, exists bool) {
+	return computeIfPresent[K, V](m, key, remapping)
+}
+
+func (m *mapImpl[K, V, C]) Merge(key K, value V, remapping func(old V, new V) V) V {
+	return merge[K, V](m, key, value, remapping)
+}
+
+func (m *mapImpl[K, V, C]) PutIfAbsent(key K, value V) (existing V, exists bool) {
+	return putIfAbsent[K, V](m, key, value)
+}
+
+func (m *mapImpl[K, V, C]) RemoveIf(key K, predicate func(value V) bool) bool {
+	return removeIf[K, V](m, key, predicate)
+}
+
 func (m *mapImpl[K, V, C]) Len() int {
 	return m.size
 }
 
+func (m *mapImpl[K, V, C]) Size() int {
+	return m.Len()
+}
+
+func (m *mapImpl[K, V, C]) Empty() bool {
+	return m.Size() == 0
+}
+
 func (m *mapImpl[K, V, C]) ContainsKey(key K) bool {
 	_, exists := m.Get(key)
 	return exists
@@ -153,10 +516,14 @@ func (m *mapImpl[K, V, C]) Remove(key K) (old V, exists bool) {
 			} else {
 				newPairs := pairs[:i]
 				newPairs = append(newPairs, pairs[i+1:]...)
-				m.data[hash] = newPairs
+				// Release the removed pair, which is still referenced by the backing array
+				pairs[len(pairs)-1] = nil
+				m.data[hash] = shrinkPairs(newPairs)
 			}
 			m.size -= 1
-			return kvPair.Value, true
+			old = kvPair.Value
+			m.releasePair(kvPair)
+			return old, true
 		}
 	}
 
@@ -164,7 +531,354 @@ func (m *mapImpl[K, V, C]) Remove(key K) (old V, exists bool) {
 	return
 }
 
+// MarshalBinary encodes the pairs with gob. The hasher and the equaler are not encoded.
+func (m *mapImpl[K, V, C]) MarshalBinary() ([]byte, error) {
+	return gobEncode(m.ToArray())
+}
+
+// UnmarshalBinary replaces the content of m with the decoded pairs.
+func (m *mapImpl[K, V, C]) UnmarshalBinary(data []byte) error {
+	var pairs []Pair[K, V]
+	if err := gobDecode(data, &pairs); err != nil {
+		return err
+	}
+
+	m.Clear()
+	for _, pair := range pairs {
+		m.Put(pair.Key, pair.Value)
+	}
+	return nil
+}
+
 func (m *mapImpl[K, V, C]) Clear() {
-	m.data = map[C][]*Pair[K, V]{}
+	m.data = make(map[C][]*Pair[K, V], m.capacity)
 	m.size = 0
 }
+
+func (m *mapImpl[K, V, C]) Clone() Collection[Pair[K, V]] {
+	data := make(map[C][]*Pair[K, V], len(m.data))
+	for hash, pairs := range m.data {
+		cloned := make([]*Pair[K, V], len(pairs))
+		for i, pair := range pairs {
+			copied := *pair
+			cloned[i] = &copied
+		}
+		data[hash] = cloned
+	}
+	return &mapImpl[K, V, C]{
+		data:         data,
+		hasher:       m.hasher,
+		equaler:      m.equaler,
+		valueEqualer: m.valueEqualer,
+		size:         m.size,
+		capacity:     m.capacity,
+		pool:         m.pool,
+	}
+}
+
+// Compact rebuilds the native map, because the native map never shrinks after deletions.
+func (m *mapImpl[K, V, C]) Compact() {
+	capacity := m.capacity
+	if m.size > capacity {
+		capacity = m.size
+	}
+
+	data := make(map[C][]*Pair[K, V], capacity)
+	for hash, pairs := range m.data {
+		data[hash] = append([]*Pair[K, V](nil), pairs...)
+	}
+	m.data = data
+}
+
+// shrinkPairs copies pairs to a smaller slice if most of its capacity is unused
+func shrinkPairs[K any, V any](pairs []*Pair[K, V]) []*Pair[K, V] {
+	if cap(pairs) < 8 || len(pairs)*4 > cap(pairs) {
+		return pairs
+	}
+	return append(make([]*Pair[K, V], 0, len(pairs)*2), pairs...)
+}
+
+type threadSafeMap[K any, V any] struct {
+	m Map[K, V]
+	l sync.RWMutex
+}
+
+func (t *threadSafeMap[K, V]) ToArray() []Pair[K, V] {
+	t.l.RLock()
+	defer t.l.RUnlock()
+
+	return t.m.ToArray()
+}
+
+// Range holds the read lock during the iteration, so f must not modify t, or it will be deadlocked.
+func (t *threadSafeMap[K, V]) Range(f func(pair Pair[K, V]) bool) {
+	t.l.RLock()
+	defer t.l.RUnlock()
+
+	t.m.Range(f)
+}
+
+func (t *threadSafeMap[K, V]) All() func(yield func(Pair[K, V]) bool) {
+	return t.Range
+}
+
+func (t *threadSafeMap[K, V]) Keys() func(yield func(K) bool) {
+	return keysOf[K, V](t)
+}
+
+func (t *threadSafeMap[K, V]) Values() func(yield func(V) bool) {
+	return valuesOf[K, V](t)
+}
+
+func (t *threadSafeMap[K, V]) KeyValues() func(yield func(K, V) bool) {
+	return keyValuesOf[K, V](t)
+}
+
+func (t *threadSafeMap[K, V]) Add(pair Pair[K, V]) (oldItem Pair[K, V], replaced bool) {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.m.Add(pair)
+}
+
+func (t *threadSafeMap[K, V]) RemoveFirst(pair Pair[K, V]) bool {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.m.RemoveFirst(pair)
+}
+
+func (t *threadSafeMap[K, V]) Has(pair Pair[K, V]) bool {
+	t.l.RLock()
+	defer t.l.RUnlock()
+
+	return t.m.Has(pair)
+}
+
+func (t *threadSafeMap[K, V]) Contains(pair Pair[K, V]) bool {
+	return t.Has(pair)
+}
+
+func (t *threadSafeMap[K, V]) TryPop() (pair Pair[K, V], exists bool) {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.m.TryPop()
+}
+
+func (t *threadSafeMap[K, V]) Pop() Pair[K, V] {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.m.Pop()
+}
+
+func (t *threadSafeMap[K, V]) Len() int {
+	t.l.RLock()
+	defer t.l.RUnlock()
+
+	return t.m.Len()
+}
+
+func (t *threadSafeMap[K, V]) Size() int {
+	return t.Len()
+}
+
+func (t *threadSafeMap[K, V]) Empty() bool {
+	return t.Size() == 0
+}
+
+func (t *threadSafeMap[K, V]) Clear() {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	t.m.Clear()
+}
+
+func (t *threadSafeMap[K, V]) Clone() Collection[Pair[K, V]] {
+	return &threadSafeMap[K, V]{
+		m: t.Snapshot().(Map[K, V]),
+	}
+}
+
+func (t *threadSafeMap[K, V]) Snapshot() Collection[Pair[K, V]] {
+	t.l.RLock()
+	defer t.l.RUnlock()
+
+	return t.m.Clone()
+}
+
+func (t *threadSafeMap[K, V]) PutAll(pairs []Pair[K, V]) int {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	added := 0
+	for _, pair := range pairs {
+		if _, exists := t.m.Put(pair.Key, pair.Value); !exists {
+			added++
+		}
+	}
+	return added
+}
+
+func (t *threadSafeMap[K, V]) RemoveAll(keys []K) int {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	removed := 0
+	for _, key := range keys {
+		if _, exists := t.m.Remove(key); exists {
+			removed++
+		}
+	}
+	return removed
+}
+
+func (t *threadSafeMap[K, V]) DoAtomically(f func(m Map[K, V])) {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	f(t.m)
+}
+
+func (t *threadSafeMap[K, V]) ContainsKey(key K) bool {
+	t.l.RLock()
+	defer t.l.RUnlock()
+
+	return t.m.ContainsKey(key)
+}
+
+func (t *threadSafeMap[K, V]) Put(key K, value V) (old V, exists bool) {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.m.Put(key, value)
+}
+
+func (t *threadSafeMap[K, V]) Get(key K) (value V, exists bool) {
+	t.l.RLock()
+	defer t.l.RUnlock()
+
+	return t.m.Get(key)
+}
+
+func (t *threadSafeMap[K, V]) Remove(key K) (old V, exists bool) {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.m.Remove(key)
+}
+
+func (t *threadSafeMap[K, V]) GetOrPutDefault(key K) (value V, exists bool) {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.m.GetOrPutDefault(key)
+}
+
+func (t *threadSafeMap[K, V]) ReplaceIfEqual(key K, expectedOld V, newValue V, valueEqualer Equaler[V]) bool {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.m.ReplaceIfEqual(key, expectedOld, newValue, valueEqualer)
+}
+
+// GetOrPut holds the write lock while calling `f`, so `f` must not access t, or it will be deadlocked.
+//  The same applies to ComputeIfAbsent, ComputeIfPresent and Merge.
+func (t *threadSafeMap[K, V]) GetOrPut(key K, f func() V) (value V, exists bool) {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.m.GetOrPut(key, f)
+}
+
+func (t *threadSafeMap[K, V]) ComputeIfAbsent(key K, mapping func(key K) V) V {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.m.ComputeIfAbsent(key, mapping)
+}
+
+func (t *threadSafeMap[K, V]) ComputeIfPresent(key K,
+	remapping func(key K, old V) (newValue V, keep bool)) (value V, exists bool) {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.m.ComputeIfPresent(key, remapping)
+}
+
+func (t *threadSafeMap[K, V]) Merge(key K, value V, remapping func(old V, new V) V) V {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.m.Merge(key, value, remapping)
+}
+
+func (t *threadSafeMap[K, V]) PutIfAbsent(key K, value V) (existing V, exists bool) {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.m.PutIfAbsent(key, value)
+}
+
+// RemoveIf holds the write lock while calling `predicate`, so `predicate` must not access t.
+func (t *threadSafeMap[K, V]) RemoveIf(key K, predicate func(value V) bool) bool {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.m.RemoveIf(key, predicate)
+}
+
+func (t *threadSafeMap[K, V]) Compact() {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	if compactor, ok := t.m.(Compactor); ok {
+		compactor.Compact()
+	}
+}
+
+func (t *threadSafeMap[K, V]) MarshalBinary() ([]byte, error) {
+	t.l.RLock()
+	defer t.l.RUnlock()
+
+	return t.m.(encoding.BinaryMarshaler).MarshalBinary()
+}
+
+func (t *threadSafeMap[K, V]) UnmarshalBinary(data []byte) error {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.m.(encoding.BinaryUnmarshaler).UnmarshalBinary(data)
+}
+
+// Encode writes c to w with gob, e.g. to checkpoint c to a file. c must implement encoding.BinaryMarshaler.
+//  The configuration of c, like the hasher and the comparator, is not encoded.
+func Encode[T any](w io.Writer, c Collection[T]) error {
+	if _, ok := c.(encoding.BinaryMarshaler); !ok {
+		return fmt.Errorf("%T doesn't support encoding", c)
+	}
+	return gob.NewEncoder(w).Encode(c)
+}
+
+// Decode replaces the content of c with the one read from r, which is written by Encode.
+//  c must implement encoding.BinaryUnmarshaler,
+//  and it should be created with the same configuration as the encoded one.
+func Decode[T any](r io.Reader, c Collection[T]) error {
+	if _, ok := c.(encoding.BinaryUnmarshaler); !ok {
+		return fmt.Errorf("%T doesn't support decoding", c)
+	}
+	return gob.NewDecoder(r).Decode(c)
+}
+
+func gobEncode(value any) ([]byte, error) {
+	buffer := bytes.Buffer{}
+	if err := gob.NewEncoder(&buffer).Encode(value); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func gobDecode(data []byte, value any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(value)
+}
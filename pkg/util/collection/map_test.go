@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"sync"
+	"sync/atomic"
 
 	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"k8s.io/utils/clock"
 )
 
 func basicHasher[K comparable](value K) K {
@@ -153,6 +156,31 @@ func testBasicTypesForMap[T comparable](mapType mapType, convert fromInt[T]) {
 			mapForTest.Remove(convert(0))
 			Expect(mapForTest.Len()).To(Equal(0))
 		})
+
+		It("can return its size.", func() {
+			expectSize := func(size int) {
+				Expect(mapForTest.Size()).To(Equal(size))
+				Expect(mapForTest.Size()).To(Equal(mapForTest.Len()))
+				Expect(mapForTest.Empty()).To(Equal(size == 0))
+			}
+
+			expectSize(0)
+			mapForTest.Put(convert(1), convert(2))
+			expectSize(1)
+			mapForTest.Put(convert(1), convert(0))
+			expectSize(1)
+			mapForTest.Put(convert(0), convert(3))
+			expectSize(2)
+			mapForTest.Remove(convert(1))
+			expectSize(1)
+			mapForTest.Remove(convert(3))
+			expectSize(1)
+			mapForTest.Remove(convert(0))
+			expectSize(0)
+			mapForTest.Put(convert(0), convert(3))
+			mapForTest.Clear()
+			expectSize(0)
+		})
 	})
 }
 
@@ -176,16 +204,34 @@ func (i *idValue) lessThan(other *idValue) bool {
 type mapType string
 
 const (
-	defaultMap  = "defaultMap"
-	priorityMap = "priorityMap"
+	defaultMap    = "defaultMap"
+	threadSafeMap = "threadSafeMap"
+	concurrentMap = "concurrentMap"
+	lruCache      = "lruCache"
+	orderedMap    = "orderedMap"
+	priorityMap   = "priorityMap"
+	cowMap        = "cowMap"
+	pooledMap     = "pooledMap"
 )
 
 func createMap[K any, V any, C comparable](mapType mapType, hasher Hasher[K, C],
 	equaler Equaler[K], comparator Comparator[K]) Map[K, V] {
 	if mapType == defaultMap {
 		return NewMap[K, V, C](hasher, equaler)
+	} else if mapType == threadSafeMap {
+		return NewThreadSafeMap[K, V, C](hasher, equaler)
+	} else if mapType == concurrentMap {
+		return NewConcurrentMap[K, V, C](4, hasher, equaler)
+	} else if mapType == orderedMap {
+		return NewOrderedMap[K, V, C](hasher, equaler)
+	} else if mapType == lruCache {
+		return NewLRUCache[K, V, C](1000, clock.RealClock{}, nil, hasher, equaler)
 	} else if mapType == priorityMap {
 		return NewPriorityMap[K, V, C](comparator, hasher, equaler)
+	} else if mapType == cowMap {
+		return NewCOWMap[K, V, C](hasher, equaler)
+	} else if mapType == pooledMap {
+		return NewPooledMap[K, V, C](hasher, equaler)
 	}
 
 	panic("Unsupported set type: " + mapType)
@@ -443,3 +489,520 @@ func testMap(mapType mapType) {
 var _ = Describe("DefaultMap", func() {
 	testMap(defaultMap)
 })
+
+var _ = Describe("ThreadSafeMap", func() {
+	testMap(threadSafeMap)
+
+	It("can be used by multiple goroutines concurrently.", func() {
+		m := NewThreadSafeMap[int, int, int](basicHasher[int], basicEquator[int])
+		wait := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wait.Add(1)
+			i := i
+			go func() {
+				defer wait.Done()
+				for j := 0; j < 100; j++ {
+					m.Put(i*100+j, j)
+					m.Get(j)
+					m.GetOrPutDefault(-1)
+					m.ReplaceIfEqual(i*100+j, j, j+1, basicEquator[int])
+				}
+				m.ToArray()
+			}()
+		}
+		wait.Wait()
+
+		Expect(m.Len()).To(Equal(1001))
+		value, _ := m.Get(999)
+		Expect(value).To(Equal(100))
+	})
+
+	It("supports the batch operations.", func() {
+		m := NewThreadSafeMap[int, string, int](basicHasher[int], basicEquator[int])
+		atomicMap := m.(AtomicMap[int, string])
+		Expect(atomicMap.PutAll([]Pair[int, string]{{Key: 1, Value: "a"}, {Key: 2, Value: "b"}, {Key: 1, Value: "c"}})).
+			To(Equal(2))
+		value, _ := m.Get(1)
+		Expect(value).To(Equal("c"))
+		Expect(atomicMap.RemoveAll([]int{2, 3})).To(Equal(1))
+		Expect(m.Len()).To(Equal(1))
+
+		atomicMap.DoAtomically(func(m Map[int, string]) {
+			old, _ := m.Get(1)
+			m.Put(1, old+"d")
+		})
+		value, _ = m.Get(1)
+		Expect(value).To(Equal("cd"))
+	})
+})
+
+var _ = Describe("PooledMap", func() {
+	testMap(pooledMap)
+
+	It("works like a native map after the pairs are recycled.", func() {
+		m := NewPooledMap[int, int, int](fakeHasher, basicEquator[int])
+		expected := map[int]int{}
+		for i, key := range getRandomArray(1000) {
+			key %= 20
+			if i%3 == 0 {
+				_, exists := m.Remove(key)
+				_, expectedExists := expected[key]
+				Expect(exists).To(Equal(expectedExists))
+				delete(expected, key)
+			} else {
+				m.Put(key, i)
+				expected[key] = i
+			}
+		}
+		Expect(ToNativeMap[int, int](m)).To(Equal(expected))
+	})
+})
+
+var _ = Describe("ContainsAll", func() {
+	for _, mt := range []mapType{defaultMap, threadSafeMap, concurrentMap, lruCache, orderedMap, priorityMap, cowMap} {
+		mt := mt
+		Describe(fmt.Sprintf("works with %s.", mt), func() {
+			var mapForTest Map[int, int]
+
+			BeforeEach(func() {
+				mapForTest = createMap[int, int, int](mt, basicHasher[int], basicEquator[int], intAscComparator)
+				mapForTest.Put(1, 10)
+				mapForTest.Put(2, 20)
+				mapForTest.Put(3, 30)
+			})
+
+			It("returns true if all the keys exist.", func() {
+				Expect(ContainsAll(mapForTest, 1, 2, 3)).To(BeTrue())
+				Expect(ContainsAll(mapForTest, 2)).To(BeTrue())
+			})
+
+			It("returns false if any key is absent.", func() {
+				Expect(ContainsAll(mapForTest, 1, 4, 3)).To(BeFalse())
+				Expect(ContainsAll(mapForTest, 10)).To(BeFalse())
+			})
+
+			It("returns true if no key is given.", func() {
+				Expect(ContainsAll(mapForTest)).To(BeTrue())
+				mapForTest.Clear()
+				Expect(ContainsAll(mapForTest)).To(BeTrue())
+			})
+		})
+	}
+})
+
+var _ = Describe("FindAll", func() {
+	for _, mt := range []mapType{defaultMap, threadSafeMap, concurrentMap, lruCache, orderedMap, priorityMap, cowMap} {
+		mt := mt
+		Describe(fmt.Sprintf("works with %s.", mt), func() {
+			var mapForTest Map[int, int]
+
+			BeforeEach(func() {
+				mapForTest = createMap[int, int, int](mt, basicHasher[int], basicEquator[int], intAscComparator)
+				for i := 0; i < 5; i++ {
+					mapForTest.Put(i, i*10)
+				}
+			})
+
+			It("returns the matched pairs.", func() {
+				Expect(FindAll(mapForTest, func(key, value int) bool {
+					return key%2 == 0
+				})).To(ConsistOf(
+					Pair[int, int]{Key: 0, Value: 0},
+					Pair[int, int]{Key: 2, Value: 20},
+					Pair[int, int]{Key: 4, Value: 40}))
+			})
+
+			It("returns an empty slice if nothing matches.", func() {
+				result := FindAll(mapForTest, func(key, value int) bool {
+					return false
+				})
+				Expect(result).NotTo(BeNil())
+				Expect(result).To(BeEmpty())
+			})
+
+			It("returns all the pairs if everything matches.", func() {
+				Expect(FindAll(mapForTest, func(key, value int) bool {
+					return true
+				})).To(HaveLen(mapForTest.Len()))
+			})
+
+			It("returns copies of the pairs.", func() {
+				result := FindAll(mapForTest, func(key, value int) bool {
+					return key == 1
+				})
+				Expect(result).To(HaveLen(1))
+				result[0].Value = 100
+				value, _ := mapForTest.Get(1)
+				Expect(value).To(Equal(10))
+			})
+		})
+	}
+})
+
+var _ = Describe("GetOrPutDefault", func() {
+	for _, mt := range []mapType{defaultMap, threadSafeMap, concurrentMap, lruCache, orderedMap, priorityMap, cowMap} {
+		mt := mt
+		Describe(fmt.Sprintf("works with %s.", mt), func() {
+			var mapForTest Map[int, int]
+
+			BeforeEach(func() {
+				mapForTest = createMap[int, int, int](mt, basicHasher[int], basicEquator[int], intAscComparator)
+				mapForTest.Put(1, 10)
+			})
+
+			It("returns the existing value.", func() {
+				value, exists := mapForTest.GetOrPutDefault(1)
+				Expect(exists).To(BeTrue())
+				Expect(value).To(Equal(10))
+				Expect(mapForTest.Len()).To(Equal(1))
+			})
+
+			It("puts a zero value if the key is absent.", func() {
+				value, exists := mapForTest.GetOrPutDefault(2)
+				Expect(exists).To(BeFalse())
+				Expect(value).To(Equal(0))
+				Expect(mapForTest.Len()).To(Equal(2))
+
+				mapForTest.Put(2, 20)
+				value, exists = mapForTest.GetOrPutDefault(2)
+				Expect(exists).To(BeTrue())
+				Expect(value).To(Equal(20))
+				Expect(mapForTest.Len()).To(Equal(2))
+			})
+
+			It("returns the inserted zero value when it's called again.", func() {
+				mapForTest.GetOrPutDefault(2)
+				value, exists := mapForTest.GetOrPutDefault(2)
+				Expect(exists).To(BeTrue())
+				Expect(value).To(Equal(0))
+			})
+		})
+	}
+
+	It("keeps the order of PriorityMap.", func() {
+		priorityMap := NewPriorityMap[int, int, int](intAscComparator, basicHasher[int], basicEquator[int])
+		priorityMap.Put(5, 5)
+		priorityMap.Put(3, 3)
+		priorityMap.GetOrPutDefault(0)
+		Expect(priorityMap.Peek()).To(Equal(Pair[int, int]{Key: 0, Value: 0}))
+		priorityMap.GetOrPutDefault(4)
+		for _, expected := range []int{0, 3, 4, 5} {
+			pair, exists := priorityMap.TryPop()
+			Expect(exists).To(BeTrue())
+			Expect(pair.Key).To(Equal(expected))
+		}
+	})
+})
+
+var _ = Describe("ReplaceIfEqual", func() {
+	for _, mt := range []mapType{defaultMap, threadSafeMap, concurrentMap, lruCache, orderedMap, priorityMap, cowMap} {
+		mt := mt
+		Describe(fmt.Sprintf("works with %s.", mt), func() {
+			var mapForTest Map[int, int]
+
+			BeforeEach(func() {
+				mapForTest = createMap[int, int, int](mt, basicHasher[int], basicEquator[int], intAscComparator)
+				mapForTest.Put(1, 10)
+				mapForTest.Put(2, 20)
+			})
+
+			It("replaces the value if it equals the expected one.", func() {
+				Expect(mapForTest.ReplaceIfEqual(1, 10, 11, basicEquator[int])).To(BeTrue())
+				value, _ := mapForTest.Get(1)
+				Expect(value).To(Equal(11))
+				Expect(mapForTest.Len()).To(Equal(2))
+			})
+
+			It("doesn't replace the value if it doesn't equal the expected one.", func() {
+				Expect(mapForTest.ReplaceIfEqual(1, 20, 11, basicEquator[int])).To(BeFalse())
+				value, _ := mapForTest.Get(1)
+				Expect(value).To(Equal(10))
+			})
+
+			It("returns false if the key is absent.", func() {
+				Expect(mapForTest.ReplaceIfEqual(3, 0, 30, basicEquator[int])).To(BeFalse())
+				Expect(mapForTest.ContainsKey(3)).To(BeFalse())
+				Expect(mapForTest.Len()).To(Equal(2))
+			})
+		})
+	}
+
+	It("keeps the order of PriorityMap.", func() {
+		priorityMap := NewPriorityMap[int, int, int](intAscComparator, basicHasher[int], basicEquator[int])
+		for _, key := range []int{3, 1, 2} {
+			priorityMap.Put(key, key)
+		}
+		Expect(priorityMap.ReplaceIfEqual(1, 1, 100, basicEquator[int])).To(BeTrue())
+		Expect(priorityMap.Peek()).To(Equal(Pair[int, int]{Key: 1, Value: 100}))
+		for _, expected := range []int{1, 2, 3} {
+			pair, _ := priorityMap.TryPop()
+			Expect(pair.Key).To(Equal(expected))
+		}
+	})
+})
+
+var _ = Describe("GetOrPut, ComputeIfAbsent, ComputeIfPresent and Merge", func() {
+	for _, mt := range []mapType{defaultMap, threadSafeMap, concurrentMap, lruCache, orderedMap, priorityMap, cowMap} {
+		mt := mt
+		Describe(fmt.Sprintf("work with %s.", mt), func() {
+			var mapForTest Map[int, int]
+
+			BeforeEach(func() {
+				mapForTest = createMap[int, int, int](mt, basicHasher[int], basicEquator[int], intAscComparator)
+				mapForTest.Put(1, 10)
+			})
+
+			It("GetOrPut only calls f if the key is absent.", func() {
+				called := 0
+				f := func() int {
+					called++
+					return 20
+				}
+				value, exists := mapForTest.GetOrPut(1, f)
+				Expect(exists).To(BeTrue())
+				Expect(value).To(Equal(10))
+				Expect(called).To(Equal(0))
+
+				value, exists = mapForTest.GetOrPut(2, f)
+				Expect(exists).To(BeFalse())
+				Expect(value).To(Equal(20))
+				Expect(called).To(Equal(1))
+				value, _ = mapForTest.Get(2)
+				Expect(value).To(Equal(20))
+			})
+
+			It("ComputeIfAbsent returns the current value.", func() {
+				mapping := func(key int) int {
+					return key * 100
+				}
+				Expect(mapForTest.ComputeIfAbsent(1, mapping)).To(Equal(10))
+				Expect(mapForTest.ComputeIfAbsent(2, mapping)).To(Equal(200))
+				Expect(mapForTest.Len()).To(Equal(2))
+			})
+
+			It("ComputeIfPresent replaces or removes the existing value.", func() {
+				value, exists := mapForTest.ComputeIfPresent(2, func(key int, old int) (int, bool) {
+					Fail("remapping shouldn't be called for an absent key")
+					return 0, true
+				})
+				Expect(exists).To(BeFalse())
+				Expect(mapForTest.ContainsKey(2)).To(BeFalse())
+
+				value, exists = mapForTest.ComputeIfPresent(1, func(key int, old int) (int, bool) {
+					return old + key, true
+				})
+				Expect(exists).To(BeTrue())
+				Expect(value).To(Equal(11))
+				value, _ = mapForTest.Get(1)
+				Expect(value).To(Equal(11))
+
+				value, exists = mapForTest.ComputeIfPresent(1, func(key int, old int) (int, bool) {
+					return 0, false
+				})
+				Expect(exists).To(BeFalse())
+				Expect(value).To(Equal(0))
+				Expect(mapForTest.Len()).To(Equal(0))
+			})
+
+			It("Merge puts the value or the remapped one.", func() {
+				sum := func(old int, new int) int {
+					return old + new
+				}
+				Expect(mapForTest.Merge(1, 5, sum)).To(Equal(15))
+				Expect(mapForTest.Merge(2, 5, sum)).To(Equal(5))
+				value, _ := mapForTest.Get(1)
+				Expect(value).To(Equal(15))
+				value, _ = mapForTest.Get(2)
+				Expect(value).To(Equal(5))
+			})
+		})
+	}
+
+	for _, mt := range []mapType{threadSafeMap, concurrentMap, cowMap} {
+		mt := mt
+		It(fmt.Sprintf("are atomic for %s.", mt), func() {
+			m := createMap[int, int, int](mt, basicHasher[int], basicEquator[int], intAscComparator)
+			wait := sync.WaitGroup{}
+			for i := 0; i < 10; i++ {
+				wait.Add(1)
+				go func() {
+					defer wait.Done()
+					for j := 0; j < 100; j++ {
+						m.Merge(j%10, 1, func(old int, new int) int {
+							return old + new
+						})
+					}
+				}()
+			}
+			wait.Wait()
+
+			for i := 0; i < 10; i++ {
+				value, _ := m.Get(i)
+				Expect(value).To(Equal(100))
+			}
+		})
+	}
+})
+
+var _ = Describe("PutIfAbsent and RemoveIf", func() {
+	for _, mt := range []mapType{defaultMap, threadSafeMap, concurrentMap, lruCache, orderedMap, priorityMap, cowMap} {
+		mt := mt
+		Describe(fmt.Sprintf("work with %s.", mt), func() {
+			var mapForTest Map[int, int]
+
+			BeforeEach(func() {
+				mapForTest = createMap[int, int, int](mt, basicHasher[int], basicEquator[int], intAscComparator)
+				mapForTest.Put(1, 10)
+			})
+
+			It("PutIfAbsent doesn't replace the existing value.", func() {
+				existing, exists := mapForTest.PutIfAbsent(1, 11)
+				Expect(exists).To(BeTrue())
+				Expect(existing).To(Equal(10))
+				value, _ := mapForTest.Get(1)
+				Expect(value).To(Equal(10))
+
+				existing, exists = mapForTest.PutIfAbsent(2, 20)
+				Expect(exists).To(BeFalse())
+				Expect(existing).To(Equal(0))
+				value, _ = mapForTest.Get(2)
+				Expect(value).To(Equal(20))
+				Expect(mapForTest.Len()).To(Equal(2))
+			})
+
+			It("RemoveIf only removes the key if the predicate is true.", func() {
+				isEven := func(value int) bool {
+					return value%2 == 0
+				}
+				Expect(mapForTest.RemoveIf(2, isEven)).To(BeFalse())
+				mapForTest.Put(2, 21)
+				Expect(mapForTest.RemoveIf(2, isEven)).To(BeFalse())
+				Expect(mapForTest.ContainsKey(2)).To(BeTrue())
+				Expect(mapForTest.RemoveIf(1, isEven)).To(BeTrue())
+				Expect(mapForTest.ContainsKey(1)).To(BeFalse())
+				Expect(mapForTest.Len()).To(Equal(1))
+			})
+		})
+	}
+
+	for _, mt := range []mapType{threadSafeMap, concurrentMap, cowMap} {
+		mt := mt
+		It(fmt.Sprintf("only let one goroutine win for %s.", mt), func() {
+			m := createMap[int, int, int](mt, basicHasher[int], basicEquator[int], intAscComparator)
+			var winners int32
+			wait := sync.WaitGroup{}
+			for i := 0; i < 10; i++ {
+				wait.Add(1)
+				i := i
+				go func() {
+					defer wait.Done()
+					if _, exists := m.PutIfAbsent(0, i); !exists {
+						atomic.AddInt32(&winners, 1)
+					}
+				}()
+			}
+			wait.Wait()
+			Expect(winners).To(Equal(int32(1)))
+
+			winners = 0
+			for i := 0; i < 10; i++ {
+				wait.Add(1)
+				go func() {
+					defer wait.Done()
+					if m.RemoveIf(0, func(int) bool { return true }) {
+						atomic.AddInt32(&winners, 1)
+					}
+				}()
+			}
+			wait.Wait()
+			Expect(winners).To(Equal(int32(1)))
+		})
+	}
+})
+
+var _ = Describe("NewMapWithCapacity and Compact", func() {
+	It("panics for a negative capacity.", func() {
+		Expect(func() {
+			NewMapWithCapacity[int, int, int](-1, basicHasher[int], basicEquator[int])
+		}).To(Panic())
+	})
+
+	It("works like NewMap.", func() {
+		m := NewMapWithCapacity[int, int, int](100, basicHasher[int], basicEquator[int])
+		for i := 0; i < 200; i++ {
+			m.Put(i, i)
+		}
+		Expect(m.Len()).To(Equal(200))
+		m.Clear()
+		Expect(m.Len()).To(Equal(0))
+		m.Put(1, 1)
+		Expect(m.ToArray()).To(Equal([]Pair[int, int]{{Key: 1, Value: 1}}))
+	})
+
+	It("keeps the colliding keys after they are removed.", func() {
+		m := NewMap[int, int, int](fakeHasher, basicEquator[int])
+		for i := 0; i < 100; i++ {
+			m.Put(i, i)
+		}
+		for i := 0; i < 100; i += 3 {
+			m.Remove(i)
+		}
+		for i := 0; i < 100; i++ {
+			_, exists := m.Get(i)
+			Expect(exists).To(Equal(i%3 != 0))
+		}
+	})
+
+	for _, mt := range []mapType{defaultMap, threadSafeMap, concurrentMap} {
+		mt := mt
+		It(fmt.Sprintf("keeps the entries of %s.", mt), func() {
+			m := createMap[int, int, int](mt, basicHasher[int], basicEquator[int], intAscComparator)
+			for i := 0; i < 1000; i++ {
+				m.Put(i, i)
+			}
+			for i := 0; i < 990; i++ {
+				m.Remove(i)
+			}
+			m.(Compactor).Compact()
+
+			Expect(m.Len()).To(Equal(10))
+			for i := 990; i < 1000; i++ {
+				value, _ := m.Get(i)
+				Expect(value).To(Equal(i))
+			}
+			m.Put(0, 0)
+			Expect(m.Len()).To(Equal(11))
+		})
+	}
+})
+
+var _ = Describe("NewMapWithValueEquality", func() {
+	It("compares both the key and the value.", func() {
+		m := NewMapWithValueEquality[int, string, int](basicHasher[int], basicEquator[int], basicEquator[string])
+		m.Put(1, "a")
+
+		Expect(m.Has(Pair[int, string]{Key: 1, Value: "a"})).To(BeTrue())
+		Expect(m.Contains(Pair[int, string]{Key: 1, Value: "b"})).To(BeFalse())
+		Expect(m.Has(Pair[int, string]{Key: 2, Value: "a"})).To(BeFalse())
+
+		Expect(m.RemoveFirst(Pair[int, string]{Key: 1, Value: "b"})).To(BeFalse())
+		Expect(m.Len()).To(Equal(1))
+		Expect(m.RemoveFirst(Pair[int, string]{Key: 1, Value: "a"})).To(BeTrue())
+		Expect(m.Len()).To(Equal(0))
+	})
+
+	It("keeps the value equality after being cloned.", func() {
+		m := NewMapWithValueEquality[int, string, int](basicHasher[int], basicEquator[int], basicEquator[string])
+		m.Put(1, "a")
+
+		cloned := CloneMap[int, string](m)
+		Expect(cloned.Has(Pair[int, string]{Key: 1, Value: "b"})).To(BeFalse())
+		Expect(cloned.Has(Pair[int, string]{Key: 1, Value: "a"})).To(BeTrue())
+	})
+
+	It("is different from the Map created by NewMap.", func() {
+		m := NewMap[int, string, int](basicHasher[int], basicEquator[int])
+		m.Put(1, "a")
+		Expect(m.Has(Pair[int, string]{Key: 1, Value: "b"})).To(BeTrue())
+	})
+})
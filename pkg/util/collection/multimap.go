@@ -0,0 +1,106 @@
+package collection
+
+// MultiMap A map whose key can be mapped to multiple values. The values of a key keep the order of insertion,
+//  and the same value can be put multiple times.
+type MultiMap[K any, V any] interface {
+	// Put adds the value to the values of the key
+	Put(key K, value V)
+	// GetAll returns a copy of the values of the key. It returns an empty slice if the key doesn't exist.
+	GetAll(key K) []V
+	// RemoveValue removes the first value of the key that equals `value`
+	RemoveValue(key K, value V) bool
+	// RemoveAll removes the key and returns all its values
+	RemoveAll(key K) (values []V, exists bool)
+	ContainsKey(key K) bool
+	// Len returns the number of all the values
+	Len() int
+	// KeyLen returns the number of the distinct keys
+	KeyLen() int
+	Clear()
+	ToArray() []Pair[K, V] // The order of the keys will not be guaranteed
+}
+
+func NewMultiMap[K any, V any, C comparable](hasher Hasher[K, C], equaler Equaler[K],
+	valueEqualer Equaler[V]) MultiMap[K, V] {
+	return &multiMap[K, V]{
+		data:         NewMap[K, []V, C](hasher, equaler),
+		valueEqualer: valueEqualer,
+		size:         0,
+	}
+}
+
+type multiMap[K any, V any] struct {
+	data         Map[K, []V]
+	valueEqualer Equaler[V]
+	size         int
+}
+
+func (m *multiMap[K, V]) Put(key K, value V) {
+	values, _ := m.data.Get(key)
+	m.data.Put(key, append(values, value))
+	m.size += 1
+}
+
+func (m *multiMap[K, V]) GetAll(key K) []V {
+	values, _ := m.data.Get(key)
+	result := make([]V, len(values))
+	copy(result, values)
+	return result
+}
+
+func (m *multiMap[K, V]) RemoveValue(key K, value V) bool {
+	values, exists := m.data.Get(key)
+	if !exists {
+		return false
+	}
+
+	for i, existing := range values {
+		if m.valueEqualer(value, existing) {
+			m.size -= 1
+			if len(values) == 1 {
+				m.data.Remove(key)
+				return true
+			}
+
+			newValues := make([]V, 0, len(values)-1)
+			newValues = append(newValues, values[:i]...)
+			newValues = append(newValues, values[i+1:]...)
+			m.data.Put(key, newValues)
+			return true
+		}
+	}
+	return false
+}
+
+func (m *multiMap[K, V]) RemoveAll(key K) (values []V, exists bool) {
+	values, exists = m.data.Remove(key)
+	m.size -= len(values)
+	return
+}
+
+func (m *multiMap[K, V]) ContainsKey(key K) bool {
+	return m.data.ContainsKey(key)
+}
+
+func (m *multiMap[K, V]) Len() int {
+	return m.size
+}
+
+func (m *multiMap[K, V]) KeyLen() int {
+	return m.data.Len()
+}
+
+func (m *multiMap[K, V]) Clear() {
+	m.data.Clear()
+	m.size = 0
+}
+
+func (m *multiMap[K, V]) ToArray() []Pair[K, V] {
+	result := make([]Pair[K, V], 0, m.size)
+	for _, pair := range m.data.ToArray() {
+		for _, value := range pair.Value {
+			result = append(result, Pair[K, V]{Key: pair.Key, Value: value})
+		}
+	}
+	return result
+}
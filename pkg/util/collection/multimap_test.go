@@ -0,0 +1,69 @@
+package collection_test
+
+import (
+	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MultiMap", func() {
+	var multiMap MultiMap[int, string]
+
+	BeforeEach(func() {
+		multiMap = NewMultiMap[int, string, int](fakeHasher, basicEquator[int], basicEquator[string])
+		multiMap.Put(1, "a")
+		multiMap.Put(1, "b")
+		multiMap.Put(1, "a")
+		multiMap.Put(2, "c")
+	})
+
+	It("can get all the values of a key in order.", func() {
+		Expect(multiMap.GetAll(1)).To(Equal([]string{"a", "b", "a"}))
+		Expect(multiMap.GetAll(2)).To(Equal([]string{"c"}))
+		Expect(multiMap.GetAll(3)).To(BeEmpty())
+		Expect(multiMap.Len()).To(Equal(4))
+		Expect(multiMap.KeyLen()).To(Equal(2))
+		Expect(multiMap.ContainsKey(2)).To(BeTrue())
+		Expect(multiMap.ContainsKey(3)).To(BeFalse())
+	})
+
+	It("returns a copy of the values.", func() {
+		values := multiMap.GetAll(1)
+		values[0] = "z"
+		Expect(multiMap.GetAll(1)).To(Equal([]string{"a", "b", "a"}))
+	})
+
+	It("can remove a single value.", func() {
+		Expect(multiMap.RemoveValue(1, "a")).To(BeTrue())
+		Expect(multiMap.GetAll(1)).To(Equal([]string{"b", "a"}))
+		Expect(multiMap.RemoveValue(1, "c")).To(BeFalse())
+		Expect(multiMap.RemoveValue(3, "a")).To(BeFalse())
+		Expect(multiMap.Len()).To(Equal(3))
+
+		Expect(multiMap.RemoveValue(2, "c")).To(BeTrue())
+		Expect(multiMap.ContainsKey(2)).To(BeFalse())
+		Expect(multiMap.KeyLen()).To(Equal(1))
+	})
+
+	It("can remove all the values of a key.", func() {
+		values, exists := multiMap.RemoveAll(1)
+		Expect(exists).To(BeTrue())
+		Expect(values).To(Equal([]string{"a", "b", "a"}))
+		Expect(multiMap.Len()).To(Equal(1))
+
+		_, exists = multiMap.RemoveAll(1)
+		Expect(exists).To(BeFalse())
+	})
+
+	It("can return all the pairs.", func() {
+		Expect(multiMap.ToArray()).To(ConsistOf(
+			Pair[int, string]{Key: 1, Value: "a"},
+			Pair[int, string]{Key: 1, Value: "b"},
+			Pair[int, string]{Key: 1, Value: "a"},
+			Pair[int, string]{Key: 2, Value: "c"}))
+
+		multiMap.Clear()
+		Expect(multiMap.ToArray()).To(BeEmpty())
+		Expect(multiMap.Len()).To(Equal(0))
+	})
+})
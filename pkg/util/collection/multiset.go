@@ -0,0 +1,135 @@
+package collection
+
+// MultiSet A bag which allows the same item to be added multiple times, and keeps the count of each item.
+//  Unlike CountMap, which works with counts, MultiSet works with items one at a time.
+type MultiSet[T any] interface {
+	Add(item T)
+	// Count returns how many times the item occurs
+	Count(item T) int
+	// RemoveOne removes one occurrence of the item
+	RemoveOne(item T) bool
+	// RemoveAll removes all the occurrences of the item, and returns how many occurrences are removed
+	RemoveAll(item T) int
+	// Distinct returns the distinct items. The order will not be guaranteed.
+	Distinct() []T
+	// Counts returns the distinct items with their counts. The order will not be guaranteed.
+	Counts() []Pair[T, int]
+	// Len returns the number of all the occurrences
+	Len() int
+	Clear()
+
+	// IntersectWith keeps the minimum of the counts in both multisets
+	IntersectWith(other MultiSet[T])
+	// UnionWith keeps the maximum of the counts in both multisets
+	UnionWith(other MultiSet[T])
+	// SubtractWith decreases the counts by the counts in `other`
+	SubtractWith(other MultiSet[T])
+	// IsSubmultiset returns true if no item occurs more times than it does in `other`
+	IsSubmultiset(other MultiSet[T]) bool
+}
+
+func NewMultiSet[T any, C comparable](hasher Hasher[T, C], equaler Equaler[T]) MultiSet[T] {
+	return &multiSet[T]{
+		counts: NewCountMap[T, C](hasher, equaler),
+		size:   0,
+	}
+}
+
+type multiSet[T any] struct {
+	counts CountMap[T]
+	size   int
+}
+
+func (m *multiSet[T]) setCount(item T, count int) {
+	current := m.counts.Count(item)
+	if count > current {
+		m.counts.Add(item, count-current)
+	} else {
+		m.counts.Remove(item, current-count)
+	}
+	m.size += count - current
+}
+
+func (m *multiSet[T]) Add(item T) {
+	m.counts.Add(item, 1)
+	m.size += 1
+}
+
+func (m *multiSet[T]) Count(item T) int {
+	return m.counts.Count(item)
+}
+
+func (m *multiSet[T]) RemoveOne(item T) bool {
+	if m.counts.Count(item) == 0 {
+		return false
+	}
+
+	m.counts.Remove(item, 1)
+	m.size -= 1
+	return true
+}
+
+func (m *multiSet[T]) RemoveAll(item T) int {
+	count := m.counts.Count(item)
+	m.setCount(item, 0)
+	return count
+}
+
+func (m *multiSet[T]) Distinct() []T {
+	pairs := m.counts.ToArray()
+	result := make([]T, len(pairs))
+	for i, pair := range pairs {
+		result[i] = pair.Key
+	}
+	return result
+}
+
+func (m *multiSet[T]) Counts() []Pair[T, int] {
+	return m.counts.ToArray()
+}
+
+func (m *multiSet[T]) Len() int {
+	return m.size
+}
+
+func (m *multiSet[T]) Clear() {
+	m.counts.Clear()
+	m.size = 0
+}
+
+func (m *multiSet[T]) IntersectWith(other MultiSet[T]) {
+	for _, pair := range m.counts.ToArray() {
+		otherCount := other.Count(pair.Key)
+		if otherCount < pair.Value {
+			m.setCount(pair.Key, otherCount)
+		}
+	}
+}
+
+func (m *multiSet[T]) UnionWith(other MultiSet[T]) {
+	for _, pair := range other.Counts() {
+		if pair.Value > m.counts.Count(pair.Key) {
+			m.setCount(pair.Key, pair.Value)
+		}
+	}
+}
+
+func (m *multiSet[T]) SubtractWith(other MultiSet[T]) {
+	for _, pair := range other.Counts() {
+		current := m.counts.Count(pair.Key)
+		if pair.Value >= current {
+			m.setCount(pair.Key, 0)
+		} else {
+			m.setCount(pair.Key, current-pair.Value)
+		}
+	}
+}
+
+func (m *multiSet[T]) IsSubmultiset(other MultiSet[T]) bool {
+	for _, pair := range m.counts.ToArray() {
+		if pair.Value > other.Count(pair.Key) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+package collection_test
+
+import (
+	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func newMultiSet(items ...string) MultiSet[string] {
+	result := NewMultiSet[string, string](basicHasher[string], basicEquator[string])
+	for _, item := range items {
+		result.Add(item)
+	}
+	return result
+}
+
+var _ = Describe("MultiSet", func() {
+	var first MultiSet[string]
+	var second MultiSet[string]
+
+	BeforeEach(func() {
+		first = newMultiSet("a", "a", "b", "b", "b")
+		second = newMultiSet("a", "b", "b", "b", "b", "b")
+	})
+
+	It("can count what it adds.", func() {
+		Expect(first.Count("a")).To(Equal(2))
+		Expect(first.Count("c")).To(Equal(0))
+		Expect(first.Len()).To(Equal(5))
+		Expect(first.Distinct()).To(ConsistOf("a", "b"))
+		Expect(first.Counts()).To(ConsistOf(Pair[string, int]{Key: "a", Value: 2}, Pair[string, int]{Key: "b", Value: 3}))
+	})
+
+	It("can remove the occurrences.", func() {
+		Expect(first.RemoveOne("a")).To(BeTrue())
+		Expect(first.Count("a")).To(Equal(1))
+		Expect(first.RemoveOne("c")).To(BeFalse())
+		Expect(first.Len()).To(Equal(4))
+
+		Expect(first.RemoveAll("b")).To(Equal(3))
+		Expect(first.RemoveAll("b")).To(Equal(0))
+		Expect(first.Distinct()).To(ConsistOf("a"))
+		Expect(first.Len()).To(Equal(1))
+
+		first.Clear()
+		Expect(first.Len()).To(Equal(0))
+		Expect(first.Distinct()).To(BeEmpty())
+	})
+
+	It("can intersect with another multiset.", func() {
+		first.IntersectWith(second)
+		Expect(first.Count("a")).To(Equal(1))
+		Expect(first.Count("b")).To(Equal(3))
+		Expect(first.Len()).To(Equal(4))
+	})
+
+	It("can union with another multiset.", func() {
+		first.UnionWith(newMultiSet("b", "b", "b", "b", "b", "c"))
+		Expect(first.Count("a")).To(Equal(2))
+		Expect(first.Count("b")).To(Equal(5))
+		Expect(first.Count("c")).To(Equal(1))
+		Expect(first.Len()).To(Equal(8))
+	})
+
+	It("can subtract another multiset.", func() {
+		first.SubtractWith(second)
+		Expect(first.Count("a")).To(Equal(1))
+		Expect(first.Count("b")).To(Equal(0))
+		Expect(first.Distinct()).To(ConsistOf("a"))
+		Expect(first.Len()).To(Equal(1))
+	})
+
+	It("can check the submultiset relationship.", func() {
+		Expect(newMultiSet("a", "b").IsSubmultiset(first)).To(BeTrue())
+		Expect(first.IsSubmultiset(second)).To(BeFalse())
+		Expect(newMultiSet().IsSubmultiset(first)).To(BeTrue())
+	})
+})
@@ -0,0 +1,83 @@
+package collection
+
+import (
+	"fmt"
+)
+
+// Option configures the collections created by NewMap, NewSet and NewPriorityQueue.
+//  A constructor panics if it doesn't support a given Option.
+type Option func(o *options)
+
+type options struct {
+	capacity       int
+	threadSafe     bool
+	stableOrdering bool
+	// valueEqualer is an Equaler[V], where V is the value type of the Map
+	valueEqualer any
+	pooled       bool
+}
+
+func newOptions(opts []Option) *options {
+	result := &options{}
+	for _, opt := range opts {
+		opt(result)
+	}
+	return result
+}
+
+// WithCapacity preallocates the space for `capacity` items. It panics if capacity is negative.
+func WithCapacity(capacity int) Option {
+	if capacity < 0 {
+		panic(fmt.Errorf("capacity should be non-negative"))
+	}
+	return func(o *options) {
+		o.capacity = capacity
+	}
+}
+
+// WithThreadSafety guards the collection with a sync.RWMutex, like NewThreadSafeMap and NewThreadSafeSet do.
+func WithThreadSafety() Option {
+	return func(o *options) {
+		o.threadSafe = true
+	}
+}
+
+// WithStableOrdering makes the order deterministic. Maps and sets iterate the items in the insertion order,
+//  like NewOrderedMap does, and priority queues pop the items with the same priority in the insertion order.
+func WithStableOrdering() Option {
+	return func(o *options) {
+		o.stableOrdering = true
+	}
+}
+
+// WithValueEqualer makes RemoveFirst, Has and Contains of a Map compare both the key and the value,
+//  like NewMapWithValueEquality does. It's only supported by NewMap, and V must be the value type of the Map.
+func WithValueEqualer[V any](valueEqualer Equaler[V]) Option {
+	return func(o *options) {
+		o.valueEqualer = valueEqualer
+	}
+}
+
+// WithPooling recycles the internal entries with a sync.Pool, like NewPooledMap and NewPooledPriorityQueue do.
+func WithPooling() Option {
+	return func(o *options) {
+		o.pooled = true
+	}
+}
+
+func (o *options) checkNoValueEqualer(constructor string) {
+	if o.valueEqualer != nil {
+		panic(fmt.Errorf("WithValueEqualer is not supported by %s", constructor))
+	}
+}
+
+func valueEqualerOf[V any](o *options) Equaler[V] {
+	if o.valueEqualer == nil {
+		return nil
+	}
+	valueEqualer, ok := o.valueEqualer.(Equaler[V])
+	if !ok {
+		panic(fmt.Errorf("the value type of WithValueEqualer should be %T", *new(V)))
+	}
+	return valueEqualer
+}
@@ -0,0 +1,122 @@
+package collection_test
+
+import (
+	"strconv"
+
+	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type prioritizedItem struct {
+	priority int
+	name     string
+}
+
+func prioritizedItemComparator(first, second prioritizedItem) bool {
+	return first.priority <= second.priority
+}
+
+var _ = Describe("Options", func() {
+	Describe("NewMap", func() {
+		It("keeps the insertion order WithStableOrdering.", func() {
+			m := NewMap[int, string, int](basicHasher[int], basicEquator[int], WithStableOrdering(), WithCapacity(8))
+			for _, key := range []int{3, 1, 2} {
+				m.Put(key, strconv.Itoa(key))
+			}
+			m.Put(1, "one")
+			Expect(m.ToArray()).To(Equal([]Pair[int, string]{{3, "3"}, {1, "one"}, {2, "2"}}))
+		})
+
+		It("compares the values WithValueEqualer.", func() {
+			for _, opts := range [][]Option{
+				{WithValueEqualer(basicEquator[string])},
+				{WithValueEqualer(basicEquator[string]), WithStableOrdering()},
+				{WithValueEqualer(basicEquator[string]), WithThreadSafety(), WithPooling()},
+			} {
+				m := NewMap[int, string, int](basicHasher[int], basicEquator[int], opts...)
+				m.Put(1, "a")
+				Expect(m.Has(Pair[int, string]{Key: 1, Value: "b"})).To(BeFalse())
+				Expect(m.RemoveFirst(Pair[int, string]{Key: 1, Value: "b"})).To(BeFalse())
+				Expect(m.RemoveFirst(Pair[int, string]{Key: 1, Value: "a"})).To(BeTrue())
+			}
+		})
+
+		It("is thread-safe WithThreadSafety.", func() {
+			m := NewMap[int, string, int](basicHasher[int], basicEquator[int], WithThreadSafety())
+			_, ok := m.(AtomicMap[int, string])
+			Expect(ok).To(BeTrue())
+			_, ok = NewMap[int, string, int](basicHasher[int], basicEquator[int]).(AtomicMap[int, string])
+			Expect(ok).To(BeFalse())
+		})
+
+		It("panics with the wrong value type of WithValueEqualer.", func() {
+			Expect(func() {
+				NewMap[int, string, int](basicHasher[int], basicEquator[int], WithValueEqualer(basicEquator[int]))
+			}).To(Panic())
+		})
+	})
+
+	Describe("NewSet", func() {
+		It("accepts the options.", func() {
+			s := NewSet[int, int](basicHasher[int], basicEquator[int],
+				WithStableOrdering(), WithThreadSafety(), WithCapacity(4), WithPooling())
+			AddAll[int](s, 3, 1, 2, 1)
+			Expect(s.ToArray()).To(Equal([]int{3, 1, 2}))
+			_, ok := s.(AtomicSet[int])
+			Expect(ok).To(BeTrue())
+		})
+
+		It("doesn't support WithValueEqualer.", func() {
+			Expect(func() {
+				NewSet[int, int](basicHasher[int], basicEquator[int], WithValueEqualer(basicEquator[int]))
+			}).To(Panic())
+		})
+	})
+
+	Describe("NewPriorityQueue", func() {
+		It("pops the items with the same priority in the insertion order WithStableOrdering.", func() {
+			for _, comparator := range []Comparator[prioritizedItem]{
+				prioritizedItemComparator,
+				func(first, second prioritizedItem) bool {
+					return first.priority < second.priority
+				},
+			} {
+				pq := NewPriorityQueue[prioritizedItem](comparator, basicEquator[prioritizedItem], WithStableOrdering())
+				expected := []prioritizedItem{}
+				for i := 0; i < 100; i++ {
+					item := prioritizedItem{priority: i % 3, name: strconv.Itoa(i)}
+					pq.Add(item)
+				}
+				for priority := 0; priority < 3; priority++ {
+					for i := priority; i < 100; i += 3 {
+						expected = append(expected, prioritizedItem{priority: priority, name: strconv.Itoa(i)})
+					}
+				}
+
+				Expect(pq.PeekN(100)).To(Equal(expected))
+				Expect(pq.Clone().(PriorityQueue[prioritizedItem]).PopN(100)).To(Equal(expected))
+				Expect(popAll[prioritizedItem](pq)).To(Equal(expected))
+			}
+		})
+
+		It("accepts the other options.", func() {
+			pq := NewPriorityQueue[int](intAscComparator, basicEquator[int],
+				WithThreadSafety(), WithCapacity(4), WithPooling())
+			AddAll[int](pq, 3, 1, 2)
+			Expect(popAll[int](pq)).To(Equal([]int{1, 2, 3}))
+			_, ok := pq.(Snapshotter[int])
+			Expect(ok).To(BeTrue())
+		})
+
+		It("doesn't support WithValueEqualer.", func() {
+			Expect(func() {
+				NewPriorityQueue[int](intAscComparator, basicEquator[int], WithValueEqualer(basicEquator[int]))
+			}).To(Panic())
+		})
+	})
+
+	It("panics with a negative capacity.", func() {
+		Expect(func() { WithCapacity(-1) }).To(Panic())
+	})
+})
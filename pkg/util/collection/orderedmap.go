@@ -0,0 +1,239 @@
+package collection
+
+import (
+	"container/list"
+)
+
+// NewOrderedMap returns a Map that keeps the insertion order of the keys.
+//  ToArray and Range return the pairs in the insertion order, and TryPop removes the earliest inserted pair.
+//  Putting an existing key replaces the value without changing its position.
+func NewOrderedMap[K any, V any, C comparable](hasher Hasher[K, C], equaler Equaler[K]) Map[K, V] {
+	return &orderedMap[K, V]{
+		elements: NewMap[K, *list.Element, C](hasher, equaler),
+		order:    list.New(),
+	}
+}
+
+type orderedMap[K any, V any] struct {
+	elements Map[K, *list.Element]
+	// order The value of each element is a *Pair[K, V]
+	order *list.List
+	// valueEqualer is nil if the values are ignored by RemoveFirst and Has
+	valueEqualer Equaler[V]
+}
+
+func (o *orderedMap[K, V]) pairOf(element *list.Element) *Pair[K, V] {
+	return element.Value.(*Pair[K, V])
+}
+
+func (o *orderedMap[K, V]) Put(key K, value V) (old V, exists bool) {
+	element, exists := o.elements.Get(key)
+	if exists {
+		pair := o.pairOf(element)
+		old = pair.Value
+		pair.Value = value
+		return
+	}
+
+	o.elements.Put(key, o.order.PushBack(&Pair[K, V]{Key: key, Value: value}))
+	return
+}
+
+func (o *orderedMap[K, V]) Get(key K) (value V, exists bool) {
+	element, exists := o.elements.Get(key)
+	if !exists {
+		return
+	}
+	return o.pairOf(element).Value, true
+}
+
+func (o *orderedMap[K, V]) GetOrPutDefault(key K) (value V, exists bool) {
+	value, exists = o.Get(key)
+	if !exists {
+		o.Put(key, value)
+	}
+	return
+}
+
+func (o *orderedMap[K, V]) ReplaceIfEqual(key K, expectedOld V, newValue V, valueEqualer Equaler[V]) bool {
+	element, exists := o.elements.Get(key)
+	if !exists || !valueEqualer(expectedOld, o.pairOf(element).Value) {
+		return false
+	}
+
+	o.pairOf(element).Value = newValue
+	return true
+}
+
+func (o *orderedMap[K, V]) GetOrPut(key K, f func() V) (value V, exists bool) {
+	return getOrPut[K, V](o, key, f)
+}
+
+func (o *orderedMap[K, V]) ComputeIfAbsent(key K, mapping func(key K) V) V {
+	return computeIfAbsent[K, V](o, key, mapping)
+}
+
+func (o *orderedMap[K, V]) ComputeIfPresent(key K,
+	remapping func(key K, old V) (newValue V, keep bool)) (value V, exists bool) {
+	return computeIfPresent[K, V](o, key, remapping)
+}
+
+func (o *orderedMap[K, V]) Merge(key K, value V, remapping func(old V, new V) V) V {
+	return merge[K, V](o, key, value, remapping)
+}
+
+func (o *orderedMap[K, V]) PutIfAbsent(key K, value V) (existing V, exists bool) {
+	return putIfAbsent[K, V](o, key, value)
+}
+
+func (o *orderedMap[K, V]) RemoveIf(key K, predicate func(value V) bool) bool {
+	return removeIf[K, V](o, key, predicate)
+}
+
+func (o *orderedMap[K, V]) Remove(key K) (old V, exists bool) {
+	element, exists := o.elements.Remove(key)
+	if !exists {
+		return
+	}
+	o.order.Remove(element)
+	return o.pairOf(element).Value, true
+}
+
+func (o *orderedMap[K, V]) ContainsKey(key K) bool {
+	return o.elements.ContainsKey(key)
+}
+
+// Range visits the pairs in the insertion order
+func (o *orderedMap[K, V]) Range(f func(pair Pair[K, V]) bool) {
+	for element := o.order.Front(); element != nil; element = element.Next() {
+		if !f(*o.pairOf(element)) {
+			return
+		}
+	}
+}
+
+func (o *orderedMap[K, V]) All() func(yield func(Pair[K, V]) bool) {
+	return o.Range
+}
+
+func (o *orderedMap[K, V]) Keys() func(yield func(K) bool) {
+	return keysOf[K, V](o)
+}
+
+func (o *orderedMap[K, V]) Values() func(yield func(V) bool) {
+	return valuesOf[K, V](o)
+}
+
+func (o *orderedMap[K, V]) KeyValues() func(yield func(K, V) bool) {
+	return keyValuesOf[K, V](o)
+}
+
+// ToArray returns the pairs in the insertion order
+func (o *orderedMap[K, V]) ToArray() []Pair[K, V] {
+	result := make([]Pair[K, V], 0, o.Len())
+	o.Range(func(pair Pair[K, V]) bool {
+		result = append(result, pair)
+		return true
+	})
+	return result
+}
+
+func (o *orderedMap[K, V]) Add(pair Pair[K, V]) (oldItem Pair[K, V], replaced bool) {
+	oldValue, replaced := o.Put(pair.Key, pair.Value)
+	if replaced {
+		oldItem.Key = pair.Key
+		oldItem.Value = oldValue
+	}
+	return
+}
+
+func (o *orderedMap[K, V]) RemoveFirst(pair Pair[K, V]) bool {
+	if o.valueEqualer != nil {
+		return o.RemoveIf(pair.Key, func(value V) bool {
+			return o.valueEqualer(value, pair.Value)
+		})
+	}
+
+	_, exists := o.Remove(pair.Key)
+	return exists
+}
+
+func (o *orderedMap[K, V]) Has(pair Pair[K, V]) bool {
+	if o.valueEqualer != nil {
+		value, exists := o.Get(pair.Key)
+		return exists && o.valueEqualer(value, pair.Value)
+	}
+
+	return o.ContainsKey(pair.Key)
+}
+
+func (o *orderedMap[K, V]) Contains(pair Pair[K, V]) bool {
+	return o.Has(pair)
+}
+
+// TryPop removes the earliest inserted pair
+func (o *orderedMap[K, V]) TryPop() (pair Pair[K, V], exists bool) {
+	element := o.order.Front()
+	if element == nil {
+		exists = false
+		return
+	}
+
+	pair = *o.pairOf(element)
+	o.order.Remove(element)
+	o.elements.Remove(pair.Key)
+	return pair, true
+}
+
+func (o *orderedMap[K, V]) Pop() Pair[K, V] {
+	return mustPop[Pair[K, V]](o, "OrderedMap")
+}
+
+func (o *orderedMap[K, V]) Len() int {
+	return o.order.Len()
+}
+
+func (o *orderedMap[K, V]) Size() int {
+	return o.Len()
+}
+
+func (o *orderedMap[K, V]) Empty() bool {
+	return o.Size() == 0
+}
+
+func (o *orderedMap[K, V]) Clear() {
+	o.elements.Clear()
+	o.order.Init()
+}
+
+func (o *orderedMap[K, V]) Clone() Collection[Pair[K, V]] {
+	cloned := &orderedMap[K, V]{
+		elements:     CloneMap(o.elements),
+		order:        list.New(),
+		valueEqualer: o.valueEqualer,
+	}
+	for element := o.order.Front(); element != nil; element = element.Next() {
+		copied := *o.pairOf(element)
+		cloned.elements.Put(copied.Key, cloned.order.PushBack(&copied))
+	}
+	return cloned
+}
+
+// MarshalBinary encodes the pairs in the insertion order with gob. The hasher and the equaler are not encoded.
+func (o *orderedMap[K, V]) MarshalBinary() ([]byte, error) {
+	return gobEncode(o.ToArray())
+}
+
+// UnmarshalBinary replaces the content of o with the decoded pairs, keeping their order.
+func (o *orderedMap[K, V]) UnmarshalBinary(data []byte) error {
+	var pairs []Pair[K, V]
+	if err := gobDecode(data, &pairs); err != nil {
+		return err
+	}
+
+	o.Clear()
+	for _, pair := range pairs {
+		o.Put(pair.Key, pair.Value)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package collection_test
+
+import (
+	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OrderedMap", func() {
+	testMap(orderedMap)
+
+	var m Map[int, string]
+
+	keys := func() []int {
+		result := []int{}
+		m.Keys()(func(key int) bool {
+			result = append(result, key)
+			return true
+		})
+		return result
+	}
+
+	BeforeEach(func() {
+		m = NewOrderedMap[int, string, int](fakeHasher, basicEquator[int])
+		for _, key := range []int{3, 1, 4, 5, 2} {
+			m.Put(key, "")
+		}
+	})
+
+	It("keeps the insertion order.", func() {
+		Expect(keys()).To(Equal([]int{3, 1, 4, 5, 2}))
+		Expect(m.ToArray()[0]).To(Equal(Pair[int, string]{Key: 3, Value: ""}))
+	})
+
+	It("keeps the position when a key is put again.", func() {
+		m.Put(4, "a")
+		m.ReplaceIfEqual(3, "", "b", basicEquator[string])
+		Expect(keys()).To(Equal([]int{3, 1, 4, 5, 2}))
+		value, _ := m.Get(4)
+		Expect(value).To(Equal("a"))
+	})
+
+	It("moves a key to the end when it is removed and put again.", func() {
+		m.Remove(1)
+		m.Put(1, "")
+		m.GetOrPutDefault(6)
+		Expect(keys()).To(Equal([]int{3, 4, 5, 2, 1, 6}))
+	})
+
+	It("pops the earliest inserted pair.", func() {
+		actual := []int{}
+		for pair, exists := m.TryPop(); exists; pair, exists = m.TryPop() {
+			actual = append(actual, pair.Key)
+		}
+		Expect(actual).To(Equal([]int{3, 1, 4, 5, 2}))
+		Expect(m.Empty()).To(BeTrue())
+	})
+
+	It("keeps the order after gob encoding.", func() {
+		dst := NewOrderedMap[int, string, int](fakeHasher, basicEquator[int])
+		gobRoundTrip(m, dst)
+		Expect(dst.ToArray()).To(Equal(m.ToArray()))
+	})
+})
@@ -2,6 +2,10 @@ package collection
 
 import (
 	"container/heap"
+	"encoding"
+	"fmt"
+	"math/bits"
+	"sync"
 )
 
 // Comparator If `first` is less than `second`, then return true
@@ -11,25 +15,101 @@ type PriorityCollection[T any] interface {
 	Collection[T]
 	Peek() T
 	TryPeek() (T, bool)
+	// PeekAll returns a copy of all the items in the order they are stored in the heap, which is not sorted
+	PeekAll() []T
+	// PopOrError equals Pop, but returns an error wrapping ErrEmpty instead of panicking if the collection is empty
+	PopOrError() (T, error)
+	// PopN pops at most n items in the order of priority. It panics if n is negative.
+	PopN(n int) []T
+	// PeekN returns at most n items in the order of priority without removing them. It panics if n is negative.
+	PeekN(n int) []T
+	// DrainTo pops at most `max` items and adds them to dst in the order of priority.
+	//  It returns the number of the drained items. If max is negative, all the items are drained.
+	DrainTo(dst Collection[T], max int) int
 }
 
 type PriorityQueue[T any] interface {
 	PriorityCollection[T]
+	// Update replaces the first item equal to `item` with `item`, and restores the order, which is useful when the
+	//  priority of the item is changed. It returns false if no item equals `item`.
+	//  Finding the item takes O(n) time, but restoring the order only takes O(log n) time.
+	Update(item T) bool
+	// Fix restores the order after the priority of the item equal to `item` is changed in place.
+	//  It's useful when T is a pointer type. It returns false if no item equals `item`. It takes O(n) time.
+	Fix(item T) bool
+	// AddAll adds the items and restores the order at once, which takes O(n+k) time for k items,
+	//  instead of O(k log(n+k)) time by adding the items one by one.
+	AddAll(items []T)
 }
 
 type PriorityMap[K any, V any] interface {
 	PriorityCollection[Pair[K, V]]
 	Map[K, V]
+	// PopMin equals TryPop, which removes the pair with the minimum key according to the comparator
+	PopMin() (Pair[K, V], bool)
+	// PopMax removes the pair with the maximum key according to the comparator.
+	//  It takes O(n) time because the heap is only ordered by the minimum. If you need PopMax frequently,
+	//  consider maintaining another PriorityMap with the reversed comparator, which takes O(log n) time.
+	PopMax() (Pair[K, V], bool)
 }
 
 type PrioritySet[T any] interface {
 	PriorityCollection[T]
 	Set[T]
+	// Update replaces the item equal to `item` with `item`, and restores the order in O(log n) time.
+	//  The hash code of the item must remain the same. It returns false if no item equals `item`.
+	Update(item T) bool
+	// Fix restores the order in O(log n) time after the priority of the item equal to `item` is changed in place.
+	//  It's useful when T is a pointer type. It returns false if no item equals `item`.
+	Fix(item T) bool
+	// AddAll adds the items like Add, and restores the order at once like PriorityQueue.AddAll
+	AddAll(items []T)
 }
 
-func NewPriorityQueue[T any](comparator Comparator[T], equaler Equaler[T]) PriorityQueue[T] {
+// NewPriorityQueue accepts the Options WithCapacity, WithThreadSafety, WithStableOrdering and WithPooling
+func NewPriorityQueue[T any](comparator Comparator[T], equaler Equaler[T], opts ...Option) PriorityQueue[T] {
+	o := newOptions(opts)
+	o.checkNoValueEqualer("NewPriorityQueue")
+
 	helper := &priorityHelper[T, emptyType]{
-		entries:    []*priorityHelperEntry[T, emptyType]{},
+		entries:    make([]*priorityHelperEntry[T, emptyType], 0, o.capacity),
+		comparator: comparator,
+		stable:     o.stableOrdering,
+	}
+	heap.Init(helper)
+	pq := &priorityQueue[T]{
+		helper:  helper,
+		equaler: equaler,
+	}
+	if o.pooled {
+		pq.pool = &sync.Pool{
+			New: func() any {
+				return &priorityHelperEntry[T, emptyType]{}
+			},
+		}
+	}
+
+	if o.threadSafe {
+		return &threadSafePriorityCollection[T]{c: pq}
+	}
+	return pq
+}
+
+// NewPooledPriorityQueue recycles the internal entries with a sync.Pool, which reduces the GC pressure when the items
+//  are added and popped frequently. Otherwise, it's the same as the PriorityQueue created by NewPriorityQueue.
+//  It equals NewPriorityQueue with WithPooling.
+func NewPooledPriorityQueue[T any](comparator Comparator[T], equaler Equaler[T]) PriorityQueue[T] {
+	return NewPriorityQueue[T](comparator, equaler, WithPooling())
+}
+
+// NewPriorityQueueFromSlice builds the heap in O(n) time, instead of O(n log n) time by adding the items one by one
+func NewPriorityQueueFromSlice[T any](items []T, comparator Comparator[T], equaler Equaler[T]) PriorityQueue[T] {
+	entries := make([]*priorityHelperEntry[T, emptyType], len(items))
+	for i, item := range items {
+		entries[i] = &priorityHelperEntry[T, emptyType]{key: item, index: i}
+	}
+	helper := &priorityHelper[T, emptyType]{
+		entries:    entries,
 		comparator: comparator,
 	}
 	heap.Init(helper)
@@ -60,15 +140,88 @@ func NewPrioritySet[T any, C comparable](
 	}
 }
 
+// NewThreadSafePriorityQueue equals NewPriorityQueue with WithThreadSafety
+func NewThreadSafePriorityQueue[T any](comparator Comparator[T], equaler Equaler[T]) PriorityQueue[T] {
+	return NewPriorityQueue[T](comparator, equaler, WithThreadSafety())
+}
+
+func NewThreadSafePrioritySet[T any, C comparable](
+	comparator Comparator[T], hasher Hasher[T, C], equaler Equaler[T]) PrioritySet[T] {
+	return &threadSafePriorityCollection[T]{
+		c: NewPrioritySet[T, C](comparator, hasher, equaler),
+	}
+}
+
+// MergePriorityQueues returns a new PriorityQueue with the items of both a and b, which has the same configuration
+//  as a, like the comparator and the thread safety. a and b are not modified.
+func MergePriorityQueues[T any](a PriorityQueue[T], b PriorityQueue[T]) PriorityQueue[T] {
+	merged := a.Clone().(PriorityQueue[T])
+	merged.AddAll(b.ToArray())
+	return merged
+}
+
+// DrainInto pops all the items from src and adds them to dst in the order of priority. src will be empty.
+func DrainInto[T any](src PriorityCollection[T], dst Collection[T]) {
+	for item, exists := src.TryPop(); exists; item, exists = src.TryPop() {
+		dst.Add(item)
+	}
+}
+
+// mustPeek is the shared implementation of Peek. `name` is the type of the collection in the panic message.
+func mustPeek[T any](c PriorityCollection[T], name string) T {
+	top, exists := c.TryPeek()
+	if !exists {
+		panic(fmt.Sprintf("Peek from an empty %s.", name))
+	}
+	return top
+}
+
+func checkN(n int) {
+	if n < 0 {
+		panic(fmt.Errorf("n should be non-negative"))
+	}
+}
+
+func popN[T any](c PriorityCollection[T], n int) []T {
+	checkN(n)
+	if n > c.Len() {
+		n = c.Len()
+	}
+
+	result := make([]T, 0, n)
+	for len(result) < n {
+		item, _ := c.TryPop()
+		result = append(result, item)
+	}
+	return result
+}
+
+func drainTo[T any](c PriorityCollection[T], dst Collection[T], max int) int {
+	drained := 0
+	for ; max < 0 || drained < max; drained++ {
+		item, exists := c.TryPop()
+		if !exists {
+			break
+		}
+		dst.Add(item)
+	}
+	return drained
+}
+
 type priorityHelperEntry[K any, V any] struct {
 	key   K
 	value V
 	index int
+	// seq is the insertion order, which starts from 1. It's only set when the helper is stable.
+	seq uint64
 }
 
 type priorityHelper[K any, V any] struct {
 	entries    []*priorityHelperEntry[K, V]
 	comparator Comparator[K]
+	// stable breaks the ties with the insertion order
+	stable  bool
+	lastSeq uint64
 }
 
 func (p *priorityHelper[T, V]) Len() int {
@@ -76,7 +229,16 @@ func (p *priorityHelper[T, V]) Len() int {
 }
 
 func (p *priorityHelper[T, V]) Less(i, j int) bool {
-	return p.comparator(p.entries[i].key, p.entries[j].key)
+	if !p.stable {
+		return p.comparator(p.entries[i].key, p.entries[j].key)
+	}
+
+	// Works with both the "less" and the "less or equal" comparators
+	less := p.comparator(p.entries[i].key, p.entries[j].key)
+	if less != p.comparator(p.entries[j].key, p.entries[i].key) {
+		return less
+	}
+	return p.entries[i].seq < p.entries[j].seq
 }
 
 func (p *priorityHelper[T, V]) Swap(i, j int) {
@@ -85,10 +247,67 @@ func (p *priorityHelper[T, V]) Swap(i, j int) {
 	p.entries[j].index = j
 }
 
+// peekN returns at most n entries in the order of priority. It visits the heap from the top with another heap of the
+//  candidates, so it takes O(n log n) time regardless of the size of the heap.
+func (p *priorityHelper[T, V]) peekN(n int) []*priorityHelperEntry[T, V] {
+	checkN(n)
+	if n > len(p.entries) {
+		n = len(p.entries)
+	}
+
+	result := make([]*priorityHelperEntry[T, V], 0, n)
+	if n == 0 {
+		return result
+	}
+
+	// The value of a candidate is its index in p.entries
+	candidates := &priorityHelper[T, int]{comparator: p.comparator, stable: p.stable}
+	heap.Push(candidates, &priorityHelperEntry[T, int]{key: p.entries[0].key, value: 0, seq: p.entries[0].seq})
+	for len(result) < n {
+		candidate := heap.Pop(candidates).(*priorityHelperEntry[T, int])
+		result = append(result, p.entries[candidate.value])
+		for _, child := range []int{candidate.value*2 + 1, candidate.value*2 + 2} {
+			if child < len(p.entries) {
+				heap.Push(candidates,
+					&priorityHelperEntry[T, int]{key: p.entries[child].key, value: child, seq: p.entries[child].seq})
+			}
+		}
+	}
+	return result
+}
+
+// pushAll adds the entries and restores the order. If there are only a few entries, pushing them one by one is faster
+//  than heapifying all the entries.
+func (p *priorityHelper[T, V]) pushAll(entries []*priorityHelperEntry[T, V]) {
+	total := len(p.entries) + len(entries)
+	if len(entries)*bits.Len(uint(total)) < total {
+		for _, entry := range entries {
+			heap.Push(p, entry)
+		}
+		return
+	}
+
+	for _, entry := range entries {
+		if p.stable && entry.seq == 0 {
+			p.lastSeq++
+			entry.seq = p.lastSeq
+		}
+		entry.index = len(p.entries)
+		p.entries = append(p.entries, entry)
+	}
+	heap.Init(p)
+}
+
 // Push adds an item to the helper. Push should not be called directly; instead,
 // use `heap.Push`.
 func (p *priorityHelper[T, V]) Push(x any) {
-	p.entries = append(p.entries, x.(*priorityHelperEntry[T, V]))
+	entry := x.(*priorityHelperEntry[T, V])
+	if p.stable && entry.seq == 0 {
+		p.lastSeq++
+		entry.seq = p.lastSeq
+	}
+	entry.index = len(p.entries)
+	p.entries = append(p.entries, entry)
 }
 
 // Pop removes an item from the helper. Pop should not be called directly;
@@ -104,6 +323,26 @@ func (p *priorityHelper[T, V]) Pop() any {
 type priorityQueue[T any] struct {
 	helper  *priorityHelper[T, emptyType]
 	equaler Equaler[T]
+	// pool is nil if the entries are not recycled
+	pool *sync.Pool
+}
+
+func (pq *priorityQueue[T]) newEntry(item T) *priorityHelperEntry[T, emptyType] {
+	if pq.pool == nil {
+		return &priorityHelperEntry[T, emptyType]{key: item}
+	}
+	entry := pq.pool.Get().(*priorityHelperEntry[T, emptyType])
+	entry.key = item
+	return entry
+}
+
+func (pq *priorityQueue[T]) releaseEntry(entry *priorityHelperEntry[T, emptyType]) {
+	if pq.pool == nil {
+		return
+	}
+	// Don't keep the item reachable
+	*entry = priorityHelperEntry[T, emptyType]{}
+	pq.pool.Put(entry)
 }
 
 func (pq *priorityQueue[T]) ToArray() []T {
@@ -123,6 +362,10 @@ func (pq *priorityQueue[T]) Has(item T) bool {
 	return false
 }
 
+func (pq *priorityQueue[T]) Contains(item T) bool {
+	return pq.Has(item)
+}
+
 func (pq *priorityQueue[T]) TryPeek() (top T, exists bool) {
 	if len(pq.helper.entries) == 0 {
 		exists = false
@@ -132,11 +375,23 @@ func (pq *priorityQueue[T]) TryPeek() (top T, exists bool) {
 }
 
 func (pq *priorityQueue[T]) Peek() T {
-	top, exists := pq.TryPeek()
-	if !exists {
-		panic("Peek from an empty PriorityCollection.")
+	return mustPeek[T](pq, "PriorityQueue")
+}
+
+func (pq *priorityQueue[T]) PeekAll() []T {
+	return pq.ToArray()
+}
+
+func (pq *priorityQueue[T]) Range(f func(item T) bool) {
+	for _, entry := range pq.helper.entries {
+		if !f(entry.key) {
+			return
+		}
 	}
-	return top
+}
+
+func (pq *priorityQueue[T]) All() func(yield func(T) bool) {
+	return pq.Range
 }
 
 func (pq *priorityQueue[T]) Len() int {
@@ -144,24 +399,82 @@ func (pq *priorityQueue[T]) Len() int {
 }
 
 func (pq *priorityQueue[T]) Add(item T) (oldItem T, replaced bool) {
-	heap.Push(pq.helper, &priorityHelperEntry[T, emptyType]{key: item})
+	heap.Push(pq.helper, pq.newEntry(item))
 	replaced = false
 	return
 }
 
+func (pq *priorityQueue[T]) AddAll(items []T) {
+	entries := make([]*priorityHelperEntry[T, emptyType], len(items))
+	for i, item := range items {
+		entries[i] = pq.newEntry(item)
+	}
+	pq.helper.pushAll(entries)
+}
+
 func (pq *priorityQueue[T]) TryPop() (item T, exists bool) {
 	if pq.Len() <= 0 {
 		exists = false
 		return
 	}
 
-	return heap.Pop(pq.helper).(*priorityHelperEntry[T, emptyType]).key, true
+	entry := heap.Pop(pq.helper).(*priorityHelperEntry[T, emptyType])
+	item = entry.key
+	pq.releaseEntry(entry)
+	return item, true
+}
+
+func (pq *priorityQueue[T]) Pop() T {
+	return mustPop[T](pq, "PriorityQueue")
+}
+
+func (pq *priorityQueue[T]) PopOrError() (T, error) {
+	return popOrError[T](pq, "PriorityQueue")
 }
 
 func (pq *priorityQueue[T]) RemoveFirst(e T) bool {
 	for i, entry := range pq.helper.entries {
 		if pq.equaler(e, entry.key) {
 			heap.Remove(pq.helper, i)
+			pq.releaseEntry(entry)
+			return true
+		}
+	}
+	return false
+}
+
+func (pq *priorityQueue[T]) PopN(n int) []T {
+	return popN[T](pq, n)
+}
+
+func (pq *priorityQueue[T]) PeekN(n int) []T {
+	entries := pq.helper.peekN(n)
+	result := make([]T, len(entries))
+	for i, entry := range entries {
+		result[i] = entry.key
+	}
+	return result
+}
+
+func (pq *priorityQueue[T]) DrainTo(dst Collection[T], max int) int {
+	return drainTo[T](pq, dst, max)
+}
+
+func (pq *priorityQueue[T]) Update(item T) bool {
+	return pq.fix(item, true)
+}
+
+func (pq *priorityQueue[T]) Fix(item T) bool {
+	return pq.fix(item, false)
+}
+
+func (pq *priorityQueue[T]) fix(item T, replace bool) bool {
+	for _, entry := range pq.helper.entries {
+		if pq.equaler(item, entry.key) {
+			if replace {
+				entry.key = item
+			}
+			heap.Fix(pq.helper, entry.index)
 			return true
 		}
 	}
@@ -172,6 +485,47 @@ func (pq *priorityQueue[T]) Clear() {
 	pq.helper.entries = []*priorityHelperEntry[T, emptyType]{}
 }
 
+func (pq *priorityQueue[T]) clone() *priorityQueue[T] {
+	entries := make([]*priorityHelperEntry[T, emptyType], len(pq.helper.entries))
+	for i, entry := range pq.helper.entries {
+		copied := *entry
+		entries[i] = &copied
+	}
+	return &priorityQueue[T]{
+		helper: &priorityHelper[T, emptyType]{
+			entries:    entries,
+			comparator: pq.helper.comparator,
+			stable:     pq.helper.stable,
+			lastSeq:    pq.helper.lastSeq,
+		},
+		equaler: pq.equaler,
+		pool:    pq.pool,
+	}
+}
+
+func (pq *priorityQueue[T]) Clone() Collection[T] {
+	return pq.clone()
+}
+
+// MarshalBinary encodes the items with gob. The comparator and the equaler are not encoded.
+func (pq *priorityQueue[T]) MarshalBinary() ([]byte, error) {
+	return gobEncode(pq.ToArray())
+}
+
+// UnmarshalBinary replaces the content of pq with the decoded items.
+func (pq *priorityQueue[T]) UnmarshalBinary(data []byte) error {
+	var items []T
+	if err := gobDecode(data, &items); err != nil {
+		return err
+	}
+
+	pq.Clear()
+	for _, item := range items {
+		pq.Add(item)
+	}
+	return nil
+}
+
 type priorityMap[K any, V any] struct {
 	helper       *priorityHelper[K, V]
 	knownEntries Map[K, *priorityHelperEntry[K, V]]
@@ -208,6 +562,100 @@ func (p *priorityMap[K, V]) Get(key K) (value V, exists bool) {
 	return
 }
 
+func (p *priorityMap[K, V]) GetOrPutDefault(key K) (value V, exists bool) {
+	value, exists = p.Get(key)
+	if !exists {
+		p.Put(key, value)
+	}
+	return
+}
+
+func (p *priorityMap[K, V]) ReplaceIfEqual(key K, expectedOld V, newValue V, valueEqualer Equaler[V]) bool {
+	current, exists := p.Get(key)
+	if !exists || !valueEqualer(expectedOld, current) {
+		return false
+	}
+
+	p.Put(key, newValue)
+	return true
+}
+
+func (p *priorityMap[K, V]) GetOrPut(key K, f func() V) (value V, exists bool) {
+	return getOrPut[K, V](p, key, f)
+}
+
+func (p *priorityMap[K, V]) ComputeIfAbsent(key K, mapping func(key K) V) V {
+	return computeIfAbsent[K, V](p, key, mapping)
+}
+
+func (p *priorityMap[K, V]) ComputeIfPresent(key K,
+	remapping func(key K, old V) (newValue V, keep bool)) (value V, exists bool) {
+	return computeIfPresent[K, V](p, key, remapping)
+}
+
+func (p *priorityMap[K, V]) Merge(key K, value V, remapping func(old V, new V) V) V {
+	return merge[K, V](p, key, value, remapping)
+}
+
+func (p *priorityMap[K, V]) PutIfAbsent(key K, value V) (existing V, exists bool) {
+	return putIfAbsent[K, V](p, key, value)
+}
+
+func (p *priorityMap[K, V]) RemoveIf(key K, predicate func(value V) bool) bool {
+	return removeIf[K, V](p, key, predicate)
+}
+
+func (p *priorityMap[K, V]) PopN(n int) []Pair[K, V] {
+	return popN[Pair[K, V]](p, n)
+}
+
+func (p *priorityMap[K, V]) PeekN(n int) []Pair[K, V] {
+	entries := p.helper.peekN(n)
+	result := make([]Pair[K, V], len(entries))
+	for i, entry := range entries {
+		result[i] = Pair[K, V]{Key: entry.key, Value: entry.value}
+	}
+	return result
+}
+
+func (p *priorityMap[K, V]) DrainTo(dst Collection[Pair[K, V]], max int) int {
+	return drainTo[Pair[K, V]](p, dst, max)
+}
+
+// MarshalBinary encodes the pairs with gob. The comparator, the hasher and the equaler are not encoded.
+func (p *priorityMap[K, V]) MarshalBinary() ([]byte, error) {
+	return gobEncode(p.ToArray())
+}
+
+// UnmarshalBinary replaces the content of p with the decoded pairs.
+func (p *priorityMap[K, V]) UnmarshalBinary(data []byte) error {
+	var pairs []Pair[K, V]
+	if err := gobDecode(data, &pairs); err != nil {
+		return err
+	}
+
+	p.Clear()
+	for _, pair := range pairs {
+		p.Put(pair.Key, pair.Value)
+	}
+	return nil
+}
+
+// fix restores the order of the entry of the key. If replaceKey is true, the stored key is replaced with `key`.
+func (p *priorityMap[K, V]) fix(key K, replaceKey bool) bool {
+	helperEntry, exists := p.knownEntries.Get(key)
+	if !exists {
+		return false
+	}
+
+	if replaceKey {
+		helperEntry.key = key
+		p.knownEntries.Put(key, helperEntry)
+	}
+	heap.Fix(p.helper, helperEntry.index)
+	return true
+}
+
 func (p *priorityMap[K, V]) Remove(key K) (old V, exists bool) {
 	helperEntry, exists := p.knownEntries.Remove(key)
 	if exists {
@@ -242,6 +690,40 @@ func (p *priorityMap[K, V]) TryPop() (item Pair[K, V], exists bool) {
 	return item, true
 }
 
+func (p *priorityMap[K, V]) Pop() Pair[K, V] {
+	return mustPop[Pair[K, V]](p, "PriorityMap")
+}
+
+func (p *priorityMap[K, V]) PopOrError() (Pair[K, V], error) {
+	return popOrError[Pair[K, V]](p, "PriorityMap")
+}
+
+func (p *priorityMap[K, V]) PopMin() (Pair[K, V], bool) {
+	return p.TryPop()
+}
+
+func (p *priorityMap[K, V]) PopMax() (item Pair[K, V], exists bool) {
+	n := p.Len()
+	if n <= 0 {
+		exists = false
+		return
+	}
+
+	// The maximum must be a leaf of the heap
+	maxIndex := n / 2
+	for i := maxIndex + 1; i < n; i++ {
+		if p.helper.comparator(p.helper.entries[maxIndex].key, p.helper.entries[i].key) {
+			maxIndex = i
+		}
+	}
+
+	entry := heap.Remove(p.helper, maxIndex).(*priorityHelperEntry[K, V])
+	p.knownEntries.Remove(entry.key)
+	item.Key = entry.key
+	item.Value = entry.value
+	return item, true
+}
+
 func (p *priorityMap[K, V]) TryPeek() (item Pair[K, V], exists bool) {
 	if len(p.helper.entries) == 0 {
 		exists = false
@@ -254,21 +736,33 @@ func (p *priorityMap[K, V]) TryPeek() (item Pair[K, V], exists bool) {
 }
 
 func (pq *priorityMap[K, V]) Peek() Pair[K, V] {
-	top, exists := pq.TryPeek()
-	if !exists {
-		panic("Peek from an empty PriorityCollection.")
-	}
-	return top
+	return mustPeek[Pair[K, V]](pq, "PriorityMap")
+}
+
+func (p *priorityMap[K, V]) PeekAll() []Pair[K, V] {
+	return p.ToArray()
 }
 
 func (p *priorityMap[K, V]) Len() int {
 	return p.helper.Len()
 }
 
+func (p *priorityMap[K, V]) Size() int {
+	return p.Len()
+}
+
+func (p *priorityMap[K, V]) Empty() bool {
+	return p.Size() == 0
+}
+
 func (p *priorityMap[K, V]) Has(item Pair[K, V]) bool {
 	return p.knownEntries.ContainsKey(item.Key)
 }
 
+func (p *priorityMap[K, V]) Contains(item Pair[K, V]) bool {
+	return p.Has(item)
+}
+
 func (p *priorityMap[K, V]) RemoveFirst(item Pair[K, V]) bool {
 	_, exsiting := p.Remove(item.Key)
 	return exsiting
@@ -283,11 +777,122 @@ func (p *priorityMap[K, V]) ToArray() []Pair[K, V] {
 	return result
 }
 
+func (p *priorityMap[K, V]) Range(f func(pair Pair[K, V]) bool) {
+	for _, entry := range p.helper.entries {
+		if !f(Pair[K, V]{Key: entry.key, Value: entry.value}) {
+			return
+		}
+	}
+}
+
+func (p *priorityMap[K, V]) All() func(yield func(Pair[K, V]) bool) {
+	return p.Range
+}
+
+func (p *priorityMap[K, V]) Keys() func(yield func(K) bool) {
+	return keysOf[K, V](p)
+}
+
+func (p *priorityMap[K, V]) Values() func(yield func(V) bool) {
+	return valuesOf[K, V](p)
+}
+
+func (p *priorityMap[K, V]) KeyValues() func(yield func(K, V) bool) {
+	return keyValuesOf[K, V](p)
+}
+
 func (pq *priorityMap[K, V]) Clear() {
 	pq.helper.entries = []*priorityHelperEntry[K, V]{}
 	pq.knownEntries.Clear()
 }
 
+func (pq *priorityMap[K, V]) clone() *priorityMap[K, V] {
+	entries := make([]*priorityHelperEntry[K, V], len(pq.helper.entries))
+	knownEntries := CloneMap(pq.knownEntries)
+	for i, entry := range pq.helper.entries {
+		copied := *entry
+		entries[i] = &copied
+		knownEntries.Put(copied.key, &copied)
+	}
+	return &priorityMap[K, V]{
+		helper: &priorityHelper[K, V]{
+			entries:    entries,
+			comparator: pq.helper.comparator,
+		},
+		knownEntries: knownEntries,
+	}
+}
+
+func (pq *priorityMap[K, V]) Clone() Collection[Pair[K, V]] {
+	return pq.clone()
+}
+
+// PrioritizedQueue is a priority queue whose items are ordered by separate priorities
+// instead of by the items themselves.
+type PrioritizedQueue[T any, P any] interface {
+	Add(item T, priority P)
+	TryPop() (T, bool)
+	TryPopWithPriority() (T, P, bool)
+	// UpdatePriority changes the priority of the first item that equals `item`.
+	// Returns false if there is no such item.
+	UpdatePriority(item T, equaler Equaler[T], newPriority P) bool
+	Len() int
+	Clear()
+}
+
+func NewPrioritizedQueue[T any, P any](comparator Comparator[P]) PrioritizedQueue[T, P] {
+	helper := &priorityHelper[P, T]{
+		entries:    []*priorityHelperEntry[P, T]{},
+		comparator: comparator,
+	}
+	heap.Init(helper)
+	return &prioritizedQueue[T, P]{
+		helper: helper,
+	}
+}
+
+type prioritizedQueue[T any, P any] struct {
+	helper *priorityHelper[P, T]
+}
+
+func (pq *prioritizedQueue[T, P]) Add(item T, priority P) {
+	heap.Push(pq.helper, &priorityHelperEntry[P, T]{key: priority, value: item})
+}
+
+func (pq *prioritizedQueue[T, P]) TryPop() (item T, exists bool) {
+	item, _, exists = pq.TryPopWithPriority()
+	return
+}
+
+func (pq *prioritizedQueue[T, P]) TryPopWithPriority() (item T, priority P, exists bool) {
+	if pq.Len() <= 0 {
+		exists = false
+		return
+	}
+
+	entry := heap.Pop(pq.helper).(*priorityHelperEntry[P, T])
+	return entry.value, entry.key, true
+}
+
+func (pq *prioritizedQueue[T, P]) UpdatePriority(item T, equaler Equaler[T], newPriority P) bool {
+	for _, entry := range pq.helper.entries {
+		if equaler(item, entry.value) {
+			entry.key = newPriority
+			heap.Fix(pq.helper, entry.index)
+			return true
+		}
+	}
+	return false
+}
+
+func (pq *prioritizedQueue[T, P]) Len() int {
+	return pq.helper.Len()
+}
+
+func (pq *prioritizedQueue[T, P]) Clear() {
+	pq.helper.entries = []*priorityHelperEntry[P, T]{}
+}
+
 type prioritySet[T any] struct {
 	set[T]
 }
@@ -297,8 +902,16 @@ func (s *prioritySet[T]) ToArray() []T {
 }
 
 func (s *prioritySet[T]) Peek() T {
+	return mustPeek[T](s, "PrioritySet")
+}
+
+func (s *prioritySet[T]) PeekAll() []T {
 	priorityMap := s.set.data.(*priorityMap[T, emptyType])
-	return priorityMap.Peek().Key
+	result := make([]T, priorityMap.Len())
+	for i, entry := range priorityMap.helper.entries {
+		result[i] = entry.key
+	}
+	return result
 }
 
 func (s *prioritySet[T]) TryPeek() (item T, exists bool) {
@@ -306,3 +919,239 @@ func (s *prioritySet[T]) TryPeek() (item T, exists bool) {
 	top, exists := priorityMap.TryPeek()
 	return top.Key, exists
 }
+
+func (s *prioritySet[T]) Pop() T {
+	return mustPop[T](s, "PrioritySet")
+}
+
+func (s *prioritySet[T]) PopOrError() (T, error) {
+	return popOrError[T](s, "PrioritySet")
+}
+
+func (s *prioritySet[T]) PopN(n int) []T {
+	return popN[T](s, n)
+}
+
+func (s *prioritySet[T]) PeekN(n int) []T {
+	priorityMap := s.set.data.(*priorityMap[T, emptyType])
+	entries := priorityMap.helper.peekN(n)
+	result := make([]T, len(entries))
+	for i, entry := range entries {
+		result[i] = entry.key
+	}
+	return result
+}
+
+func (s *prioritySet[T]) DrainTo(dst Collection[T], max int) int {
+	return drainTo[T](s, dst, max)
+}
+
+func (s *prioritySet[T]) Clone() Collection[T] {
+	return &prioritySet[T]{
+		set: set[T]{data: s.set.data.(*priorityMap[T, emptyType]).clone()},
+	}
+}
+
+func (s *prioritySet[T]) AddAll(items []T) {
+	priorityMap := s.set.data.(*priorityMap[T, emptyType])
+	entries := make([]*priorityHelperEntry[T, emptyType], 0, len(items))
+	for _, item := range items {
+		if entry, exists := priorityMap.knownEntries.Get(item); exists {
+			entry.key = item
+			priorityMap.knownEntries.Put(item, entry)
+			// The index of a new entry is -1 until it's pushed
+			if entry.index >= 0 {
+				heap.Fix(priorityMap.helper, entry.index)
+			}
+			continue
+		}
+		entry := &priorityHelperEntry[T, emptyType]{key: item, index: -1}
+		priorityMap.knownEntries.Put(item, entry)
+		entries = append(entries, entry)
+	}
+	priorityMap.helper.pushAll(entries)
+}
+
+func (s *prioritySet[T]) Update(item T) bool {
+	return s.set.data.(*priorityMap[T, emptyType]).fix(item, true)
+}
+
+func (s *prioritySet[T]) Fix(item T) bool {
+	return s.set.data.(*priorityMap[T, emptyType]).fix(item, false)
+}
+
+// threadSafePriorityCollection PriorityQueue and PrioritySet have the same methods, so they share this wrapper
+type threadSafePriorityCollection[T any] struct {
+	c PriorityQueue[T]
+	l sync.RWMutex
+}
+
+func (t *threadSafePriorityCollection[T]) ToArray() []T {
+	t.l.RLock()
+	defer t.l.RUnlock()
+
+	return t.c.ToArray()
+}
+
+// Range holds the read lock during the iteration, so f must not modify t, or it will be deadlocked.
+func (t *threadSafePriorityCollection[T]) Range(f func(item T) bool) {
+	t.l.RLock()
+	defer t.l.RUnlock()
+
+	t.c.Range(f)
+}
+
+func (t *threadSafePriorityCollection[T]) All() func(yield func(T) bool) {
+	return t.Range
+}
+
+func (t *threadSafePriorityCollection[T]) Add(item T) (oldItem T, replaced bool) {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.c.Add(item)
+}
+
+func (t *threadSafePriorityCollection[T]) AddAll(items []T) {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	t.c.AddAll(items)
+}
+
+func (t *threadSafePriorityCollection[T]) RemoveFirst(item T) bool {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.c.RemoveFirst(item)
+}
+
+func (t *threadSafePriorityCollection[T]) TryPop() (item T, exists bool) {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.c.TryPop()
+}
+
+func (t *threadSafePriorityCollection[T]) Pop() T {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.c.Pop()
+}
+
+func (t *threadSafePriorityCollection[T]) PopOrError() (T, error) {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.c.PopOrError()
+}
+
+func (t *threadSafePriorityCollection[T]) Has(item T) bool {
+	t.l.RLock()
+	defer t.l.RUnlock()
+
+	return t.c.Has(item)
+}
+
+func (t *threadSafePriorityCollection[T]) Contains(item T) bool {
+	return t.Has(item)
+}
+
+func (t *threadSafePriorityCollection[T]) Len() int {
+	t.l.RLock()
+	defer t.l.RUnlock()
+
+	return t.c.Len()
+}
+
+func (t *threadSafePriorityCollection[T]) Clear() {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	t.c.Clear()
+}
+
+func (t *threadSafePriorityCollection[T]) Clone() Collection[T] {
+	return &threadSafePriorityCollection[T]{
+		c: t.Snapshot().(PriorityQueue[T]),
+	}
+}
+
+func (t *threadSafePriorityCollection[T]) Snapshot() Collection[T] {
+	t.l.RLock()
+	defer t.l.RUnlock()
+
+	return t.c.Clone()
+}
+
+func (t *threadSafePriorityCollection[T]) Peek() T {
+	t.l.RLock()
+	defer t.l.RUnlock()
+
+	return t.c.Peek()
+}
+
+func (t *threadSafePriorityCollection[T]) TryPeek() (item T, exists bool) {
+	t.l.RLock()
+	defer t.l.RUnlock()
+
+	return t.c.TryPeek()
+}
+
+func (t *threadSafePriorityCollection[T]) PeekAll() []T {
+	t.l.RLock()
+	defer t.l.RUnlock()
+
+	return t.c.PeekAll()
+}
+
+func (t *threadSafePriorityCollection[T]) PopN(n int) []T {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.c.PopN(n)
+}
+
+func (t *threadSafePriorityCollection[T]) PeekN(n int) []T {
+	t.l.RLock()
+	defer t.l.RUnlock()
+
+	return t.c.PeekN(n)
+}
+
+// DrainTo holds the lock of t while adding the items to dst, so dst must not be t.
+func (t *threadSafePriorityCollection[T]) DrainTo(dst Collection[T], max int) int {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.c.DrainTo(dst, max)
+}
+
+func (t *threadSafePriorityCollection[T]) Update(item T) bool {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.c.Update(item)
+}
+
+func (t *threadSafePriorityCollection[T]) Fix(item T) bool {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.c.Fix(item)
+}
+
+func (t *threadSafePriorityCollection[T]) MarshalBinary() ([]byte, error) {
+	t.l.RLock()
+	defer t.l.RUnlock()
+
+	return t.c.(encoding.BinaryMarshaler).MarshalBinary()
+}
+
+func (t *threadSafePriorityCollection[T]) UnmarshalBinary(data []byte) error {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.c.(encoding.BinaryUnmarshaler).UnmarshalBinary(data)
+}
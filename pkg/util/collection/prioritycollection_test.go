@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math/rand"
 	"sort"
+	"sync"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -194,6 +195,8 @@ var _ = Describe("PriorityCollection should be tested multiple times.", func() {
 					for _, length := range arrayLengths {
 						testCollection[int](NewPriorityQueue[int](intAscComparator, basicEquator[int]),
 							getRandomArray(length), intComparator, true, fakeUniquer[int])
+						testCollection[int](NewPooledPriorityQueue[int](intAscComparator, basicEquator[int]),
+							getRandomArray(length), intComparator, true, fakeUniquer[int])
 						testCollection[int](NewPriorityQueue[int](intDescComparator, basicEquator[int]),
 							getRandomArray(length), intComparator, false, fakeUniquer[int])
 					}
@@ -401,3 +404,564 @@ var _ = Describe("PriorityCollection should be tested multiple times.", func() {
 		})
 	})
 })
+
+func testPeekAll[T any](c PriorityCollection[T], items []T) {
+	Expect(c.PeekAll()).NotTo(BeNil())
+	Expect(c.PeekAll()).To(BeEmpty())
+
+	for _, item := range items {
+		c.Add(item)
+	}
+
+	all := c.PeekAll()
+	Expect(all).To(HaveLen(c.Len()))
+	Expect(all).To(ConsistOf(c.ToArray()))
+
+	// Modifying the returned slice won't affect the collection
+	top := c.Peek()
+	for i := range all {
+		all[i] = items[len(items)-1]
+	}
+	Expect(c.Peek()).To(Equal(top))
+	Expect(c.PeekAll()).To(ConsistOf(items))
+}
+
+var _ = Describe("PeekAll", func() {
+	It("works with PriorityQueue.", func() {
+		testPeekAll[int](NewPriorityQueue[int](intAscComparator, basicEquator[int]), []int{3, 1, 2, 1})
+	})
+
+	It("works with PrioritySet.", func() {
+		testPeekAll[int](NewPrioritySet[int, int](intAscComparator, basicHasher[int], basicEquator[int]),
+			[]int{3, 1, 2})
+	})
+
+	It("works with PriorityMap.", func() {
+		testPeekAll[Pair[int, int]](NewPriorityMap[int, int, int](intAscComparator, basicHasher[int], basicEquator[int]),
+			intToPair([]int{3, 1, 2}))
+	})
+})
+
+var _ = Describe("PrioritizedQueue", func() {
+	var queue PrioritizedQueue[string, int]
+
+	BeforeEach(func() {
+		queue = NewPrioritizedQueue[string, int](intAscComparator)
+	})
+
+	It("pops the items in the order of their priorities.", func() {
+		queue.Add("c", 3)
+		queue.Add("a", 1)
+		queue.Add("d", 4)
+		queue.Add("b", 2)
+		Expect(queue.Len()).To(Equal(4))
+
+		for i, expected := range []string{"a", "b", "c", "d"} {
+			item, priority, exists := queue.TryPopWithPriority()
+			Expect(exists).To(BeTrue())
+			Expect(item).To(Equal(expected))
+			Expect(priority).To(Equal(i + 1))
+		}
+
+		_, exists := queue.TryPop()
+		Expect(exists).To(BeFalse())
+		_, _, exists = queue.TryPopWithPriority()
+		Expect(exists).To(BeFalse())
+	})
+
+	It("can hold items with the same priority.", func() {
+		queue.Add("a", 1)
+		queue.Add("b", 1)
+		Expect(queue.Len()).To(Equal(2))
+	})
+
+	It("can update the priorities.", func() {
+		queue.Add("a", 1)
+		queue.Add("b", 2)
+		queue.Add("c", 3)
+
+		Expect(queue.UpdatePriority("c", basicEquator[string], 0)).To(BeTrue())
+		Expect(queue.UpdatePriority("a", basicEquator[string], 5)).To(BeTrue())
+		Expect(queue.UpdatePriority("d", basicEquator[string], 0)).To(BeFalse())
+
+		for _, expected := range []string{"c", "b", "a"} {
+			item, exists := queue.TryPop()
+			Expect(exists).To(BeTrue())
+			Expect(item).To(Equal(expected))
+		}
+	})
+
+	It("can be used in Dijkstra's algorithm.", func() {
+		graph := map[string]map[string]int{
+			"s": {"a": 7, "b": 2},
+			"a": {"t": 1},
+			"b": {"a": 3, "c": 8},
+			"c": {"t": 1},
+			"t": {},
+		}
+		distances := map[string]int{"s": 0}
+		queue.Add("s", 0)
+
+		order := []string{}
+		for node, distance, exists := queue.TryPopWithPriority(); exists; node, distance, exists =
+			queue.TryPopWithPriority() {
+			order = append(order, node)
+			for next, weight := range graph[node] {
+				known, visited := distances[next]
+				if visited && known <= distance+weight {
+					continue
+				}
+				distances[next] = distance + weight
+				if !visited || !queue.UpdatePriority(next, basicEquator[string], distance+weight) {
+					queue.Add(next, distance+weight)
+				}
+			}
+		}
+
+		Expect(order).To(Equal([]string{"s", "b", "a", "t", "c"}))
+		Expect(distances).To(Equal(map[string]int{"s": 0, "a": 5, "b": 2, "c": 10, "t": 6}))
+	})
+
+	It("can clear what it adds.", func() {
+		queue.Add("a", 1)
+		queue.Clear()
+		Expect(queue.Len()).To(Equal(0))
+	})
+})
+
+var _ = Describe("PriorityMap", func() {
+	It("removes the right entry after pushing without reordering.", func() {
+		priorityMap := NewPriorityMap[int, int, int](intAscComparator, basicHasher[int], basicEquator[int])
+		priorityMap.Put(1, 1)
+		priorityMap.Put(2, 2)
+		priorityMap.Remove(2)
+		Expect(priorityMap.ToArray()).To(ConsistOf(Pair[int, int]{Key: 1, Value: 1}))
+	})
+
+	It("can pop the minimum and the maximum.", func() {
+		priorityMap := NewPriorityMap[int, int, int](intAscComparator, basicHasher[int], basicEquator[int])
+		_, exists := priorityMap.PopMax()
+		Expect(exists).To(BeFalse())
+		_, exists = priorityMap.PopMin()
+		Expect(exists).To(BeFalse())
+
+		for _, key := range rand.Perm(20) {
+			priorityMap.Put(key, -key)
+		}
+		minimums := []int{}
+		maximums := []int{}
+		for priorityMap.Len() > 0 {
+			length := priorityMap.Len()
+			pair, exists := priorityMap.PopMin()
+			Expect(exists).To(BeTrue())
+			Expect(pair.Value).To(Equal(-pair.Key))
+			Expect(priorityMap.ContainsKey(pair.Key)).To(BeFalse())
+			Expect(priorityMap.Len()).To(Equal(length - 1))
+			minimums = append(minimums, pair.Key)
+
+			pair, exists = priorityMap.PopMax()
+			Expect(exists).To(BeTrue())
+			Expect(pair.Value).To(Equal(-pair.Key))
+			Expect(priorityMap.ContainsKey(pair.Key)).To(BeFalse())
+			Expect(priorityMap.Len()).To(Equal(length - 2))
+			maximums = append(maximums, pair.Key)
+		}
+		Expect(minimums).To(Equal([]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}))
+		Expect(maximums).To(Equal([]int{19, 18, 17, 16, 15, 14, 13, 12, 11, 10}))
+	})
+})
+
+var _ = Describe("DrainInto", func() {
+	var src PriorityQueue[int]
+	var dst Deque[int]
+
+	BeforeEach(func() {
+		src = NewPriorityQueue[int](intAscComparator, basicEquator[int])
+		dst = NewDeque[int](basicEquator[int])
+	})
+
+	It("moves all the items in the order of priority.", func() {
+		for _, item := range []int{3, 1, 4, 1, 5} {
+			src.Add(item)
+		}
+		dst.Add(0)
+
+		DrainInto[int](src, dst)
+		Expect(src.Len()).To(Equal(0))
+		Expect(dst.Len()).To(Equal(6))
+		Expect(dst.ToArray()).To(Equal([]int{0, 1, 1, 3, 4, 5}))
+	})
+
+	It("works with maps.", func() {
+		priorityMap := NewPriorityMap[int, int, int](intDescComparator, basicHasher[int], basicEquator[int])
+		for _, pair := range intToPair([]int{1, 3, 2}) {
+			priorityMap.Add(pair)
+		}
+		pairs := NewDeque[Pair[int, int]](func(first, second Pair[int, int]) bool {
+			return first == second
+		})
+
+		DrainInto[Pair[int, int]](priorityMap, pairs)
+		Expect(priorityMap.Len()).To(Equal(0))
+		Expect(pairs.ToArray()).To(Equal(intToPair([]int{3, 2, 1})))
+	})
+
+	It("does nothing if src is empty.", func() {
+		dst.Add(0)
+		DrainInto[int](src, dst)
+		Expect(src.Len()).To(Equal(0))
+		Expect(dst.ToArray()).To(Equal([]int{0}))
+	})
+})
+
+var _ = Describe("ThreadSafePriorityCollection", func() {
+	It("can pop the element in order.", func() {
+		for _, length := range []int{0, 1, 2, 10, 30} {
+			testCollection[int](NewThreadSafePriorityQueue[int](intAscComparator, basicEquator[int]),
+				getRandomArray(length), intComparator, true, fakeUniquer[int])
+			testCollection[int](
+				NewThreadSafePrioritySet[int, int](intDescComparator, basicHasher[int], basicEquator[int]),
+				getRandomArray(length), intComparator, false, intUniquer)
+		}
+	})
+
+	It("can be shared by multiple producers and consumers.", func() {
+		for _, c := range []PriorityCollection[int]{
+			NewThreadSafePriorityQueue[int](intAscComparator, basicEquator[int]),
+			NewThreadSafePrioritySet[int, int](intAscComparator, basicHasher[int], basicEquator[int]),
+		} {
+			producers := sync.WaitGroup{}
+			for i := 0; i < 10; i++ {
+				producers.Add(1)
+				i := i
+				go func() {
+					defer producers.Done()
+					for j := 0; j < 100; j++ {
+						c.Add(i*100 + j)
+						c.TryPeek()
+						c.PeekAll()
+					}
+				}()
+			}
+			producers.Wait()
+			Expect(c.Len()).To(Equal(1000))
+
+			consumers := sync.WaitGroup{}
+			popped := make([][]int, 4)
+			for i := range popped {
+				consumers.Add(1)
+				i := i
+				go func() {
+					defer consumers.Done()
+					for item, exists := c.TryPop(); exists; item, exists = c.TryPop() {
+						popped[i] = append(popped[i], item)
+					}
+				}()
+			}
+			consumers.Wait()
+
+			all := []int{}
+			for _, items := range popped {
+				Expect(sort.IntsAreSorted(items)).To(BeTrue())
+				all = append(all, items...)
+			}
+			sort.Ints(all)
+			Expect(all).To(Equal(getSequence(1000)))
+		}
+	})
+
+	testSet(threadSafePrioritySet)
+})
+
+type task struct {
+	id       int
+	priority int
+}
+
+func taskComparator(first, second *task) bool {
+	return first.priority < second.priority
+}
+
+func taskHasher(t *task) int {
+	return t.id
+}
+
+func taskEquator(first, second *task) bool {
+	return first.id == second.id
+}
+
+var _ = Describe("Update and Fix", func() {
+	type updatable interface {
+		PriorityCollection[*task]
+		Update(item *task) bool
+		Fix(item *task) bool
+	}
+
+	creators := map[string]func() updatable{
+		"PriorityQueue": func() updatable {
+			return NewPriorityQueue[*task](taskComparator, taskEquator)
+		},
+		"PrioritySet": func() updatable {
+			return NewPrioritySet[*task, int](taskComparator, taskHasher, taskEquator)
+		},
+		"IndexedPriorityQueue": func() updatable {
+			return NewIndexedPriorityQueue[*task, int](taskComparator, taskHasher, taskEquator)
+		},
+		"ThreadSafePriorityQueue": func() updatable {
+			return NewThreadSafePriorityQueue[*task](taskComparator, taskEquator)
+		},
+		"ThreadSafePrioritySet": func() updatable {
+			return NewThreadSafePrioritySet[*task, int](taskComparator, taskHasher, taskEquator)
+		},
+	}
+
+	for name, create := range creators {
+		create := create
+		Describe(fmt.Sprintf("work with %s.", name), func() {
+			var c updatable
+			var tasks []*task
+
+			BeforeEach(func() {
+				c = create()
+				tasks = nil
+				for i, priority := range rand.Perm(20) {
+					t := &task{id: i, priority: priority}
+					tasks = append(tasks, t)
+					c.Add(t)
+				}
+			})
+
+			popIds := func() (result []int) {
+				for item, exists := c.TryPop(); exists; item, exists = c.TryPop() {
+					result = append(result, item.id)
+				}
+				return
+			}
+
+			It("Update replaces the item and restores the order.", func() {
+				Expect(c.Update(&task{id: 5, priority: -1})).To(BeTrue())
+				Expect(c.Peek().id).To(Equal(5))
+				Expect(c.Peek().priority).To(Equal(-1))
+				Expect(c.Update(&task{id: 5, priority: 100})).To(BeTrue())
+				Expect(c.Update(&task{id: 100, priority: 0})).To(BeFalse())
+				Expect(c.Len()).To(Equal(20))
+
+				ids := popIds()
+				Expect(ids).To(HaveLen(20))
+				Expect(ids[19]).To(Equal(5))
+			})
+
+			It("Fix restores the order after the priority is changed in place.", func() {
+				for _, t := range tasks {
+					t.priority = -t.id
+					Expect(c.Fix(t)).To(BeTrue())
+				}
+				Expect(c.Fix(&task{id: 100})).To(BeFalse())
+
+				ids := popIds()
+				for i, id := range ids {
+					Expect(id).To(Equal(19 - i))
+				}
+			})
+		})
+	}
+})
+
+var _ = Describe("PopN, PeekN and DrainTo", func() {
+	creators := map[string]func() PriorityCollection[int]{
+		"PriorityQueue": func() PriorityCollection[int] {
+			return NewPriorityQueue[int](intAscComparator, basicEquator[int])
+		},
+		"PooledPriorityQueue": func() PriorityCollection[int] {
+			return NewPooledPriorityQueue[int](intAscComparator, basicEquator[int])
+		},
+		"IndexedPriorityQueue": func() PriorityCollection[int] {
+			return NewIndexedPriorityQueue[int, int](intAscComparator, basicHasher[int], basicEquator[int])
+		},
+		"PrioritySet": func() PriorityCollection[int] {
+			return NewPrioritySet[int, int](intAscComparator, basicHasher[int], basicEquator[int])
+		},
+		"ThreadSafePriorityQueue": func() PriorityCollection[int] {
+			return NewThreadSafePriorityQueue[int](intAscComparator, basicEquator[int])
+		},
+		"ThreadSafePrioritySet": func() PriorityCollection[int] {
+			return NewThreadSafePrioritySet[int, int](intAscComparator, basicHasher[int], basicEquator[int])
+		},
+		"SortedDeque": func() PriorityCollection[int] {
+			return NewSortedDeque[int](intAscComparator, basicEquator[int]).(PriorityCollection[int])
+		},
+	}
+
+	for name, create := range creators {
+		create := create
+		Describe(fmt.Sprintf("work with %s.", name), func() {
+			var c PriorityCollection[int]
+
+			BeforeEach(func() {
+				c = create()
+				for _, item := range rand.Perm(100) {
+					c.Add(item)
+				}
+			})
+
+			It("PeekN returns the top items without removing them.", func() {
+				Expect(c.PeekN(0)).To(BeEmpty())
+				Expect(c.PeekN(10)).To(Equal(getSequence(10)))
+				Expect(c.PeekN(200)).To(Equal(getSequence(100)))
+				Expect(c.Len()).To(Equal(100))
+				Expect(func() { c.PeekN(-1) }).To(Panic())
+			})
+
+			It("PopN pops the top items.", func() {
+				Expect(c.PopN(0)).To(BeEmpty())
+				Expect(c.PopN(10)).To(Equal(getSequence(100)[:10]))
+				Expect(c.Len()).To(Equal(90))
+				Expect(c.Peek()).To(Equal(10))
+				Expect(c.PopN(200)).To(Equal(getSequence(100)[10:]))
+				Expect(c.Len()).To(Equal(0))
+				Expect(c.PopN(1)).To(BeEmpty())
+				Expect(func() { c.PopN(-1) }).To(Panic())
+
+				c.Add(1)
+				Expect(c.Has(1)).To(BeTrue())
+				Expect(c.Has(0)).To(BeFalse())
+			})
+
+			It("DrainTo adds the popped items to dst.", func() {
+				dst := NewDeque[int](basicEquator[int])
+				Expect(c.DrainTo(dst, 0)).To(Equal(0))
+				Expect(c.DrainTo(dst, 30)).To(Equal(30))
+				Expect(dst.ToArray()).To(Equal(getSequence(30)))
+				Expect(c.Len()).To(Equal(70))
+				Expect(c.DrainTo(dst, -1)).To(Equal(70))
+				Expect(dst.ToArray()).To(Equal(getSequence(100)))
+				Expect(c.Len()).To(Equal(0))
+				Expect(c.DrainTo(dst, -1)).To(Equal(0))
+			})
+		})
+	}
+
+	It("works with PriorityMap.", func() {
+		priorityMap := NewPriorityMap[int, int, int](intDescComparator, basicHasher[int], basicEquator[int])
+		for _, key := range rand.Perm(10) {
+			priorityMap.Put(key, key*10)
+		}
+		Expect(priorityMap.PeekN(2)).To(Equal([]Pair[int, int]{{Key: 9, Value: 90}, {Key: 8, Value: 80}}))
+		Expect(priorityMap.PopN(2)).To(Equal([]Pair[int, int]{{Key: 9, Value: 90}, {Key: 8, Value: 80}}))
+		Expect(priorityMap.ContainsKey(9)).To(BeFalse())
+
+		dst := NewMap[int, int, int](basicHasher[int], basicEquator[int])
+		Expect(priorityMap.DrainTo(dst, 3)).To(Equal(3))
+		Expect(ToNativeMap(dst)).To(Equal(map[int]int{7: 70, 6: 60, 5: 50}))
+		Expect(priorityMap.Len()).To(Equal(5))
+	})
+})
+
+var _ = Describe("AddAll and MergePriorityQueues", func() {
+	type bulkAddable interface {
+		PriorityCollection[int]
+		AddAll(items []int)
+	}
+
+	creators := map[string]func() bulkAddable{
+		"PriorityQueue": func() bulkAddable {
+			return NewPriorityQueue[int](intAscComparator, basicEquator[int])
+		},
+		"PooledPriorityQueue": func() bulkAddable {
+			return NewPooledPriorityQueue[int](intAscComparator, basicEquator[int])
+		},
+		"IndexedPriorityQueue": func() bulkAddable {
+			return NewIndexedPriorityQueue[int, int](intAscComparator, basicHasher[int], basicEquator[int])
+		},
+		"PrioritySet": func() bulkAddable {
+			return NewPrioritySet[int, int](intAscComparator, basicHasher[int], basicEquator[int])
+		},
+		"ThreadSafePriorityQueue": func() bulkAddable {
+			return NewThreadSafePriorityQueue[int](intAscComparator, basicEquator[int])
+		},
+		"ThreadSafePrioritySet": func() bulkAddable {
+			return NewThreadSafePrioritySet[int, int](intAscComparator, basicHasher[int], basicEquator[int])
+		},
+	}
+
+	for name, create := range creators {
+		create := create
+		Describe(fmt.Sprintf("work with %s.", name), func() {
+			var c bulkAddable
+
+			BeforeEach(func() {
+				c = create()
+				c.AddAll(rand.Perm(100))
+			})
+
+			It("AddAll adds many items at once.", func() {
+				items := rand.Perm(100)
+				for i := range items {
+					items[i] += 100
+				}
+				c.AddAll(items)
+				Expect(c.Len()).To(Equal(200))
+				for _, item := range items {
+					Expect(c.Has(item)).To(BeTrue())
+				}
+				Expect(c.RemoveFirst(150)).To(BeTrue())
+				Expect(c.Has(150)).To(BeFalse())
+
+				for i := 0; i < 200; i++ {
+					if i != 150 {
+						Expect(c.Pop()).To(Equal(i))
+					}
+				}
+			})
+
+			It("AddAll adds a few items.", func() {
+				c.AddAll([]int{-2, 200, -1})
+				Expect(c.Len()).To(Equal(103))
+				Expect(c.PopN(3)).To(Equal([]int{-2, -1, 0}))
+			})
+		})
+	}
+
+	It("AddAll keeps the items unique in a PrioritySet.", func() {
+		set := NewPrioritySet[int, int](intAscComparator, basicHasher[int], basicEquator[int])
+		set.AddAll([]int{3, 1})
+		set.AddAll([]int{2, 1, 2, 0, 3})
+		Expect(set.PopN(5)).To(Equal([]int{0, 1, 2, 3}))
+	})
+
+	It("AddAll keeps the insertion order with WithStableOrdering.", func() {
+		pq := NewPriorityQueue[*task](taskComparator, taskEquator, WithStableOrdering())
+		tasks := make([]*task, 100)
+		for i := range tasks {
+			tasks[i] = &task{id: i, priority: i % 2}
+		}
+		pq.Add(&task{id: -1, priority: 1})
+		pq.AddAll(tasks)
+
+		var ids []int
+		for item, exists := pq.TryPop(); exists; item, exists = pq.TryPop() {
+			ids = append(ids, item.id)
+		}
+		Expect(ids[:3]).To(Equal([]int{0, 2, 4}))
+		Expect(ids[50:53]).To(Equal([]int{-1, 1, 3}))
+	})
+
+	It("AddAll is used by the function AddAll.", func() {
+		pq := NewPriorityQueue[int](intAscComparator, basicEquator[int])
+		AddAll[int](pq, 3, 1, 2)
+		Expect(pq.PopN(3)).To(Equal([]int{1, 2, 3}))
+	})
+
+	It("MergePriorityQueues returns a new queue.", func() {
+		a := NewThreadSafePriorityQueue[int](intAscComparator, basicEquator[int])
+		a.AddAll([]int{4, 0, 2})
+		b := NewIndexedPriorityQueue[int, int](intAscComparator, basicHasher[int], basicEquator[int])
+		b.AddAll([]int{1, 3, 2})
+
+		merged := MergePriorityQueues(a, b)
+		Expect(merged).To(BeAssignableToTypeOf(a))
+		Expect(merged.PopN(6)).To(Equal([]int{0, 1, 2, 2, 3, 4}))
+		Expect(a.Len()).To(Equal(3))
+		Expect(b.Len()).To(Equal(3))
+	})
+})
@@ -0,0 +1,58 @@
+package collection
+
+// Queue A FIFO collection backed by a ring buffer. Add equals Enqueue, and TryPop pops the head item.
+//  ToArray and Range return the items from the head to the tail.
+type Queue[T any] interface {
+	Collection[T]
+	Enqueue(item T)
+	// Dequeue panics if the queue is empty
+	Dequeue() T
+	// Peek panics if the queue is empty
+	Peek() T
+	TryPeek() (item T, exists bool)
+}
+
+func NewQueue[T any](equaler Equaler[T]) Queue[T] {
+	return &queue[T]{
+		deque: NewDeque[T](equaler).(*deque[T]),
+	}
+}
+
+type queue[T any] struct {
+	*deque[T]
+}
+
+func (q *queue[T]) Enqueue(item T) {
+	q.deque.Append(item)
+}
+
+func (q *queue[T]) Dequeue() T {
+	item, exists := q.TryPop()
+	if !exists {
+		panic("Dequeue from an empty Queue.")
+	}
+	return item
+}
+
+// Pop equals Dequeue
+func (q *queue[T]) Pop() T {
+	return mustPop[T](q, "Queue")
+}
+
+func (q *queue[T]) TryPeek() (item T, exists bool) {
+	return q.deque.PeekFirst()
+}
+
+func (q *queue[T]) Peek() T {
+	item, exists := q.TryPeek()
+	if !exists {
+		panic("Peek from an empty Queue.")
+	}
+	return item
+}
+
+func (q *queue[T]) Clone() Collection[T] {
+	return &queue[T]{
+		deque: q.deque.clone(),
+	}
+}
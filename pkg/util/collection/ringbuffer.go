@@ -0,0 +1,324 @@
+package collection
+
+import (
+	"fmt"
+	"sync"
+)
+
+type RingBufferMode int
+
+const (
+	// OverwriteOldest makes a full RingBuffer overwrite the oldest item when a new one is added
+	OverwriteOldest RingBufferMode = iota
+	// RejectWhenFull makes a full RingBuffer reject the new items
+	RejectWhenFull
+)
+
+// RingBuffer A Collection with a fixed capacity, which keeps the items in the order they are added.
+//  TryPop removes the oldest item, and ToArray and Range iterate from the oldest item to the newest one.
+type RingBuffer[T any] interface {
+	Collection[T]
+	// Add With OverwriteOldest, it returns the overwritten item with replaced=true if the buffer is full.
+	//  With RejectWhenFull, Add does nothing if the buffer is full, and returns replaced=false.
+	//  Use Offer to tell if the item is rejected.
+	Add(item T) (oldItem T, replaced bool)
+	// Offer adds the item and returns true, unless the buffer is full with RejectWhenFull
+	Offer(item T) bool
+	// PeekOldest returns the item that will be popped next
+	PeekOldest() (T, bool)
+	// PeekNewest returns the item that is added most recently
+	PeekNewest() (T, bool)
+	Capacity() int
+	IsFull() bool
+}
+
+func NewRingBuffer[T any](capacity int, mode RingBufferMode, equaler Equaler[T]) RingBuffer[T] {
+	if capacity <= 0 {
+		panic(fmt.Errorf("capacity should be positive"))
+	}
+
+	return &ringBuffer[T]{
+		items:   make([]T, capacity),
+		head:    0,
+		size:    0,
+		mode:    mode,
+		equaler: equaler,
+	}
+}
+
+func NewThreadSafeRingBuffer[T any](capacity int, mode RingBufferMode, equaler Equaler[T]) RingBuffer[T] {
+	return &threadSafeRingBuffer[T]{
+		r: NewRingBuffer[T](capacity, mode, equaler),
+	}
+}
+
+type ringBuffer[T any] struct {
+	items []T
+	// head is the index of the oldest item
+	head    int
+	size    int
+	mode    RingBufferMode
+	equaler Equaler[T]
+}
+
+func (r *ringBuffer[T]) index(i int) int {
+	return (r.head + i) % len(r.items)
+}
+
+func (r *ringBuffer[T]) Add(item T) (oldItem T, replaced bool) {
+	if r.IsFull() {
+		if r.mode == RejectWhenFull {
+			return
+		}
+		oldItem = r.items[r.head]
+		r.items[r.head] = item
+		r.head = r.index(1)
+		return oldItem, true
+	}
+
+	r.items[r.index(r.size)] = item
+	r.size++
+	replaced = false
+	return
+}
+
+func (r *ringBuffer[T]) Offer(item T) bool {
+	if r.IsFull() && r.mode == RejectWhenFull {
+		return false
+	}
+	r.Add(item)
+	return true
+}
+
+func (r *ringBuffer[T]) PeekOldest() (item T, exists bool) {
+	if r.size == 0 {
+		exists = false
+		return
+	}
+	return r.items[r.head], true
+}
+
+func (r *ringBuffer[T]) PeekNewest() (item T, exists bool) {
+	if r.size == 0 {
+		exists = false
+		return
+	}
+	return r.items[r.index(r.size-1)], true
+}
+
+func (r *ringBuffer[T]) TryPop() (item T, exists bool) {
+	item, exists = r.PeekOldest()
+	if !exists {
+		return
+	}
+
+	var zero T
+	r.items[r.head] = zero // Don't hold the reference
+	r.head = r.index(1)
+	r.size--
+	return
+}
+
+func (r *ringBuffer[T]) Pop() T {
+	return mustPop[T](r, "RingBuffer")
+}
+
+// RemoveFirst removes the oldest item that equals `item`. The newer items are moved forward.
+func (r *ringBuffer[T]) RemoveFirst(item T) bool {
+	for i := 0; i < r.size; i++ {
+		if r.equaler(item, r.items[r.index(i)]) {
+			for ; i < r.size-1; i++ {
+				r.items[r.index(i)] = r.items[r.index(i+1)]
+			}
+			var zero T
+			r.items[r.index(r.size-1)] = zero
+			r.size--
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ringBuffer[T]) Has(item T) bool {
+	for i := 0; i < r.size; i++ {
+		if r.equaler(item, r.items[r.index(i)]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ringBuffer[T]) Contains(item T) bool {
+	return r.Has(item)
+}
+
+func (r *ringBuffer[T]) Len() int {
+	return r.size
+}
+
+func (r *ringBuffer[T]) Capacity() int {
+	return len(r.items)
+}
+
+func (r *ringBuffer[T]) IsFull() bool {
+	return r.size == len(r.items)
+}
+
+func (r *ringBuffer[T]) Clear() {
+	r.items = make([]T, len(r.items))
+	r.head = 0
+	r.size = 0
+}
+
+func (r *ringBuffer[T]) Clone() Collection[T] {
+	items := make([]T, len(r.items))
+	copy(items, r.items)
+	return &ringBuffer[T]{
+		items:   items,
+		head:    r.head,
+		size:    r.size,
+		mode:    r.mode,
+		equaler: r.equaler,
+	}
+}
+
+// ToArray returns the items from the oldest one to the newest one
+func (r *ringBuffer[T]) ToArray() []T {
+	result := make([]T, r.size)
+	for i := range result {
+		result[i] = r.items[r.index(i)]
+	}
+	return result
+}
+
+// Range iterates from the oldest item to the newest one
+func (r *ringBuffer[T]) Range(f func(item T) bool) {
+	for i := 0; i < r.size; i++ {
+		if !f(r.items[r.index(i)]) {
+			return
+		}
+	}
+}
+
+func (r *ringBuffer[T]) All() func(yield func(T) bool) {
+	return r.Range
+}
+
+type threadSafeRingBuffer[T any] struct {
+	r RingBuffer[T]
+	l sync.RWMutex
+}
+
+func (t *threadSafeRingBuffer[T]) Add(item T) (oldItem T, replaced bool) {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.r.Add(item)
+}
+
+func (t *threadSafeRingBuffer[T]) Offer(item T) bool {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.r.Offer(item)
+}
+
+func (t *threadSafeRingBuffer[T]) PeekOldest() (item T, exists bool) {
+	t.l.RLock()
+	defer t.l.RUnlock()
+
+	return t.r.PeekOldest()
+}
+
+func (t *threadSafeRingBuffer[T]) PeekNewest() (item T, exists bool) {
+	t.l.RLock()
+	defer t.l.RUnlock()
+
+	return t.r.PeekNewest()
+}
+
+func (t *threadSafeRingBuffer[T]) TryPop() (item T, exists bool) {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.r.TryPop()
+}
+
+func (t *threadSafeRingBuffer[T]) Pop() T {
+	return mustPop[T](t, "RingBuffer")
+}
+
+func (t *threadSafeRingBuffer[T]) RemoveFirst(item T) bool {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.r.RemoveFirst(item)
+}
+
+func (t *threadSafeRingBuffer[T]) Has(item T) bool {
+	t.l.RLock()
+	defer t.l.RUnlock()
+
+	return t.r.Has(item)
+}
+
+func (t *threadSafeRingBuffer[T]) Contains(item T) bool {
+	return t.Has(item)
+}
+
+func (t *threadSafeRingBuffer[T]) Len() int {
+	t.l.RLock()
+	defer t.l.RUnlock()
+
+	return t.r.Len()
+}
+
+func (t *threadSafeRingBuffer[T]) Capacity() int {
+	return t.r.Capacity()
+}
+
+func (t *threadSafeRingBuffer[T]) IsFull() bool {
+	t.l.RLock()
+	defer t.l.RUnlock()
+
+	return t.r.IsFull()
+}
+
+func (t *threadSafeRingBuffer[T]) Clear() {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	t.r.Clear()
+}
+
+func (t *threadSafeRingBuffer[T]) Clone() Collection[T] {
+	return &threadSafeRingBuffer[T]{
+		r: t.Snapshot().(RingBuffer[T]),
+	}
+}
+
+func (t *threadSafeRingBuffer[T]) Snapshot() Collection[T] {
+	t.l.RLock()
+	defer t.l.RUnlock()
+
+	return t.r.Clone()
+}
+
+func (t *threadSafeRingBuffer[T]) ToArray() []T {
+	t.l.RLock()
+	defer t.l.RUnlock()
+
+	return t.r.ToArray()
+}
+
+// Range holds the read lock during the iteration, so f must not modify t, or it will be deadlocked.
+func (t *threadSafeRingBuffer[T]) Range(f func(item T) bool) {
+	t.l.RLock()
+	defer t.l.RUnlock()
+
+	t.r.Range(f)
+}
+
+func (t *threadSafeRingBuffer[T]) All() func(yield func(T) bool) {
+	return t.Range
+}
@@ -0,0 +1,127 @@
+package collection_test
+
+import (
+	"fmt"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
+)
+
+var _ = Describe("RingBuffer", func() {
+	It("panics for a non-positive capacity.", func() {
+		Expect(func() { NewRingBuffer[int](0, OverwriteOldest, basicEquator[int]) }).To(Panic())
+	})
+
+	creators := map[string]func(capacity int, mode RingBufferMode) RingBuffer[int]{
+		"RingBuffer": func(capacity int, mode RingBufferMode) RingBuffer[int] {
+			return NewRingBuffer[int](capacity, mode, basicEquator[int])
+		},
+		"ThreadSafeRingBuffer": func(capacity int, mode RingBufferMode) RingBuffer[int] {
+			return NewThreadSafeRingBuffer[int](capacity, mode, basicEquator[int])
+		},
+	}
+
+	for name, create := range creators {
+		create := create
+		Describe(fmt.Sprintf("%s works.", name), func() {
+			It("overwrites the oldest item.", func() {
+				buffer := create(3, OverwriteOldest)
+				for i := 0; i < 3; i++ {
+					_, replaced := buffer.Add(i)
+					Expect(replaced).To(BeFalse())
+				}
+				Expect(buffer.IsFull()).To(BeTrue())
+				oldItem, replaced := buffer.Add(3)
+				Expect(replaced).To(BeTrue())
+				Expect(oldItem).To(Equal(0))
+				Expect(buffer.Offer(4)).To(BeTrue())
+
+				Expect(buffer.ToArray()).To(Equal([]int{2, 3, 4}))
+				Expect(buffer.Len()).To(Equal(3))
+				Expect(buffer.Capacity()).To(Equal(3))
+				oldest, _ := buffer.PeekOldest()
+				Expect(oldest).To(Equal(2))
+				newest, _ := buffer.PeekNewest()
+				Expect(newest).To(Equal(4))
+			})
+
+			It("rejects the new items when it's full.", func() {
+				buffer := create(2, RejectWhenFull)
+				Expect(buffer.Offer(1)).To(BeTrue())
+				Expect(buffer.Offer(2)).To(BeTrue())
+				Expect(buffer.Offer(3)).To(BeFalse())
+				oldItem, replaced := buffer.Add(3)
+				Expect(oldItem).To(Equal(0))
+				Expect(replaced).To(BeFalse())
+				AddAll[int](buffer, 4, 5)
+				Expect(buffer.ToArray()).To(Equal([]int{1, 2}))
+
+				item, _ := buffer.TryPop()
+				Expect(item).To(Equal(1))
+				Expect(buffer.Offer(3)).To(BeTrue())
+				Expect(buffer.ToArray()).To(Equal([]int{2, 3}))
+			})
+
+			It("supports Collection interface.", func() {
+				buffer := create(4, OverwriteOldest)
+				for i := 0; i < 6; i++ {
+					buffer.Add(i)
+				}
+				Expect(buffer.Has(1)).To(BeFalse())
+				Expect(buffer.Contains(3)).To(BeTrue())
+				Expect(buffer.RemoveFirst(3)).To(BeTrue())
+				Expect(buffer.RemoveFirst(3)).To(BeFalse())
+				Expect(buffer.ToArray()).To(Equal([]int{2, 4, 5}))
+				buffer.Add(6)
+				buffer.Add(7)
+				Expect(buffer.ToArray()).To(Equal([]int{4, 5, 6, 7}))
+
+				ranged := []int{}
+				buffer.Range(func(item int) bool {
+					ranged = append(ranged, item)
+					return item < 5
+				})
+				Expect(ranged).To(Equal([]int{4, 5}))
+
+				popped := []int{}
+				for item, exists := buffer.TryPop(); exists; item, exists = buffer.TryPop() {
+					popped = append(popped, item)
+				}
+				Expect(popped).To(Equal([]int{4, 5, 6, 7}))
+				_, exists := buffer.PeekOldest()
+				Expect(exists).To(BeFalse())
+				_, exists = buffer.PeekNewest()
+				Expect(exists).To(BeFalse())
+
+				buffer.Add(1)
+				buffer.Clear()
+				Expect(buffer.Len()).To(Equal(0))
+				Expect(buffer.ToArray()).To(BeEmpty())
+			})
+		})
+	}
+
+	It("ThreadSafeRingBuffer can be used by multiple goroutines concurrently.", func() {
+		buffer := NewThreadSafeRingBuffer[int](100, OverwriteOldest, basicEquator[int])
+		wait := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wait.Add(1)
+			i := i
+			go func() {
+				defer wait.Done()
+				for j := 0; j < 100; j++ {
+					buffer.Add(i*100 + j)
+					buffer.PeekNewest()
+					buffer.ToArray()
+				}
+			}()
+		}
+		wait.Wait()
+
+		Expect(buffer.Len()).To(Equal(100))
+		Expect(buffer.IsFull()).To(BeTrue())
+	})
+})
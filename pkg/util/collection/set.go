@@ -1,6 +1,11 @@
 package collection
 
-import "sync"
+import (
+	"encoding"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
 
 // Set To avoid Value copy, you may want T to be pointer types.
 //  However, if T is a pointer type, we must make sure that the hash code remains the same.
@@ -8,22 +13,102 @@ type Set[T any] interface {
 	Collection[T]
 }
 
+// AtomicSet is implemented by the sets created by NewThreadSafeSet.
+//  The batch operations acquire the lock only once, so other goroutines see either none or all of the changes.
+type AtomicSet[T any] interface {
+	// AddAll adds the items and returns the number of the items that didn't exist
+	AddAll(items []T) int
+	// RemoveAll removes the items and returns the number of the removed items
+	RemoveAll(items []T) int
+	// DoAtomically calls f with the underlying set under the write lock.
+	//  f must not keep the set after it returns, and must not call the methods of the thread-safe set.
+	DoAtomically(f func(s Set[T]))
+}
+
 type emptyType struct{}
 
 var empty emptyType
 
-func NewSet[T any, C comparable](hasher Hasher[T, C], equaler Equaler[T]) Set[T] {
-	return &set[T]{
-		data: NewMap[T, emptyType, C](hasher, equaler),
+// NewSet accepts the Options WithCapacity, WithThreadSafety, WithStableOrdering and WithPooling
+func NewSet[T any, C comparable](hasher Hasher[T, C], equaler Equaler[T], opts ...Option) Set[T] {
+	o := newOptions(opts)
+	o.checkNoValueEqualer("NewSet")
+
+	var result Set[T] = &set[T]{
+		data: newMap[T, emptyType, C](&options{capacity: o.capacity, stableOrdering: o.stableOrdering,
+			pooled: o.pooled}, hasher, equaler),
 	}
+	if o.threadSafe {
+		result = &threadSafeSet[T]{s: result}
+	}
+	return result
 }
 
+// NewThreadSafeSet equals NewSet with WithThreadSafety
 func NewThreadSafeSet[T any, C comparable](hasher Hasher[T, C], equaler Equaler[T]) Set[T] {
-	return &threadSafeSet[T]{
-		s: NewSet(hasher, equaler),
+	return NewSet[T, C](hasher, equaler, WithThreadSafety())
+}
+
+func NewSetFromSlice[T any, C comparable](items []T, hasher Hasher[T, C], equaler Equaler[T]) Set[T] {
+	result := NewSet[T, C](hasher, equaler)
+	AddAll[T](result, items...)
+	return result
+}
+
+// CloneSet equals s.Clone(), but returns a Set
+func CloneSet[T any](s Set[T]) Set[T] {
+	return s.Clone().(Set[T])
+}
+
+// ToSortedSlice returns the items of s sorted by comparator. s itself is not modified.
+func ToSortedSlice[T any](s Set[T], comparator Comparator[T]) []T {
+	result := s.ToArray()
+	sort.Slice(result, func(i, j int) bool {
+		return comparator(result[i], result[j])
+	})
+	return result
+}
+
+// AddAllFrom adds all the items of src to dst, and returns the number of the items that are not in dst before.
+// It iterates a snapshot of src, so dst and src can be the same set.
+func AddAllFrom[T any](dst Set[T], src Set[T]) int {
+	added := 0
+	for _, item := range src.ToArray() {
+		if _, replaced := dst.Add(item); !replaced {
+			added++
+		}
+	}
+	return added
+}
+
+// NewSnapshotIterator returns an Iterator over a snapshot of s. The snapshot is taken once by s.ToArray(), so for a
+//  thread-safe set, the lock is only held while taking the snapshot. The later modifications of s are not reflected.
+//  The returned Iterator can be shared by multiple goroutines, and every item will be returned exactly once.
+func NewSnapshotIterator[T any](s Set[T]) Iterator[T] {
+	return &snapshotIterator[T]{
+		items: s.ToArray(),
+		next:  0,
 	}
 }
 
+type snapshotIterator[T any] struct {
+	items []T
+	next  int64
+}
+
+func (s *snapshotIterator[T]) HasNext() bool {
+	return atomic.LoadInt64(&s.next) < int64(len(s.items))
+}
+
+func (s *snapshotIterator[T]) Next() (item T, exists bool) {
+	i := atomic.AddInt64(&s.next, 1) - 1
+	if i >= int64(len(s.items)) {
+		exists = false
+		return
+	}
+	return s.items[i], true
+}
+
 type set[T any] struct {
 	data Map[T, emptyType]
 }
@@ -37,6 +122,16 @@ func (s *set[T]) ToArray() []T {
 	return result
 }
 
+func (s *set[T]) Range(f func(item T) bool) {
+	s.data.Range(func(pair Pair[T, emptyType]) bool {
+		return f(pair.Key)
+	})
+}
+
+func (s *set[T]) All() func(yield func(T) bool) {
+	return s.Range
+}
+
 func (s *set[T]) Add(item T) (oldItem T, replaced bool) {
 	_, replaced = s.data.Put(item, empty)
 	if !replaced {
@@ -54,6 +149,10 @@ func (s *set[T]) Has(item T) bool {
 	return s.data.ContainsKey(item)
 }
 
+func (s *set[T]) Contains(item T) bool {
+	return s.Has(item)
+}
+
 func (s *set[T]) TryPop() (item T, exists bool) {
 	pair, exists := s.data.TryPop()
 	if !exists {
@@ -63,6 +162,10 @@ func (s *set[T]) TryPop() (item T, exists bool) {
 	return pair.Key, exists
 }
 
+func (s *set[T]) Pop() T {
+	return mustPop[T](s, "Set")
+}
+
 func (s *set[T]) Len() int {
 	return s.data.Len()
 }
@@ -71,6 +174,31 @@ func (s *set[T]) Clear() {
 	s.data.Clear()
 }
 
+func (s *set[T]) Clone() Collection[T] {
+	return &set[T]{
+		data: CloneMap(s.data),
+	}
+}
+
+// MarshalBinary encodes the items with gob. The hasher and the equaler are not encoded.
+func (s *set[T]) MarshalBinary() ([]byte, error) {
+	return gobEncode(s.ToArray())
+}
+
+// UnmarshalBinary replaces the content of s with the decoded items.
+func (s *set[T]) UnmarshalBinary(data []byte) error {
+	var items []T
+	if err := gobDecode(data, &items); err != nil {
+		return err
+	}
+
+	s.Clear()
+	for _, item := range items {
+		s.Add(item)
+	}
+	return nil
+}
+
 type threadSafeSet[T any] struct {
 	s Set[T]
 	l sync.RWMutex
@@ -83,6 +211,19 @@ func (t *threadSafeSet[T]) ToArray() []T {
 	return t.s.ToArray()
 }
 
+// Range holds the read lock during the iteration, so f must not modify t, or it will be deadlocked.
+//  Use NewSnapshotIterator if the iteration shouldn't block the writers.
+func (t *threadSafeSet[T]) Range(f func(item T) bool) {
+	t.l.RLock()
+	defer t.l.RUnlock()
+
+	t.s.Range(f)
+}
+
+func (t *threadSafeSet[T]) All() func(yield func(T) bool) {
+	return t.Range
+}
+
 func (t *threadSafeSet[T]) Add(item T) (oldItem T, replaced bool) {
 	t.l.Lock()
 	defer t.l.Unlock()
@@ -104,6 +245,10 @@ func (t *threadSafeSet[T]) Has(item T) bool {
 	return t.s.Has(item)
 }
 
+func (t *threadSafeSet[T]) Contains(item T) bool {
+	return t.Has(item)
+}
+
 func (t *threadSafeSet[T]) TryPop() (item T, exists bool) {
 	t.l.Lock()
 	defer t.l.Unlock()
@@ -111,6 +256,13 @@ func (t *threadSafeSet[T]) TryPop() (item T, exists bool) {
 	return t.s.TryPop()
 }
 
+func (t *threadSafeSet[T]) Pop() T {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.s.Pop()
+}
+
 func (t *threadSafeSet[T]) Len() int {
 	t.l.RLock()
 	defer t.l.RUnlock()
@@ -124,3 +276,63 @@ func (t *threadSafeSet[T]) Clear() {
 
 	t.s.Clear()
 }
+
+func (t *threadSafeSet[T]) Clone() Collection[T] {
+	return &threadSafeSet[T]{
+		s: t.Snapshot().(Set[T]),
+	}
+}
+
+func (t *threadSafeSet[T]) Snapshot() Collection[T] {
+	t.l.RLock()
+	defer t.l.RUnlock()
+
+	return t.s.Clone()
+}
+
+func (t *threadSafeSet[T]) AddAll(items []T) int {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	added := 0
+	for _, item := range items {
+		if _, replaced := t.s.Add(item); !replaced {
+			added++
+		}
+	}
+	return added
+}
+
+func (t *threadSafeSet[T]) RemoveAll(items []T) int {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	removed := 0
+	for _, item := range items {
+		if t.s.RemoveFirst(item) {
+			removed++
+		}
+	}
+	return removed
+}
+
+func (t *threadSafeSet[T]) DoAtomically(f func(s Set[T])) {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	f(t.s)
+}
+
+func (t *threadSafeSet[T]) MarshalBinary() ([]byte, error) {
+	t.l.RLock()
+	defer t.l.RUnlock()
+
+	return t.s.(encoding.BinaryMarshaler).MarshalBinary()
+}
+
+func (t *threadSafeSet[T]) UnmarshalBinary(data []byte) error {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	return t.s.(encoding.BinaryUnmarshaler).UnmarshalBinary(data)
+}
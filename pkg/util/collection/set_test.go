@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"sort"
 	"strconv"
+	"sync"
 
 	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
 	. "github.com/onsi/ginkgo/v2"
@@ -115,9 +116,11 @@ func testBasicTypesForSet[T comparable](setType setType, convert fromInt[T]) {
 type setType string
 
 const (
-	defaultSet    = "defaultSet"
-	prioritySet   = "prioritySet"
-	threadSafeSet = "threadSafeSet"
+	defaultSet            = "defaultSet"
+	prioritySet           = "prioritySet"
+	threadSafeSet         = "threadSafeSet"
+	threadSafePrioritySet = "threadSafePrioritySet"
+	cowSet                = "cowSet"
 )
 
 func createSet[T any, C comparable](setType setType, hasher Hasher[T, C],
@@ -128,6 +131,10 @@ func createSet[T any, C comparable](setType setType, hasher Hasher[T, C],
 		return NewPrioritySet[T, C](comparator, hasher, equaler)
 	} else if setType == threadSafeSet {
 		return NewThreadSafeSet[T, C](hasher, equaler)
+	} else if setType == threadSafePrioritySet {
+		return NewThreadSafePrioritySet[T, C](comparator, hasher, equaler)
+	} else if setType == cowSet {
+		return NewCOWSet[T, C](hasher, equaler)
 	}
 
 	panic("Unsupported set type: " + setType)
@@ -293,4 +300,197 @@ var _ = Describe("ThreadSafeSet", func() {
 
 		Eventually(setForTest.Len).Should(Equal(0))
 	})
+
+	It("supports the batch operations.", func() {
+		atomicSet := setForTest.(AtomicSet[int])
+		Expect(atomicSet.AddAll([]int{1, 2, 3, 2})).To(Equal(3))
+		Expect(atomicSet.RemoveAll([]int{2, 4})).To(Equal(1))
+		Expect(setForTest.ToArray()).To(ConsistOf(1, 3))
+
+		atomicSet.DoAtomically(func(s Set[int]) {
+			if !s.Has(4) {
+				s.Add(4)
+				s.RemoveFirst(1)
+			}
+		})
+		Expect(setForTest.ToArray()).To(ConsistOf(3, 4))
+	})
+
+	It("applies the batch operations atomically.", func() {
+		atomicSet := setForTest.(AtomicSet[int])
+		inconsistent := false
+		wait := sync.WaitGroup{}
+		for i := 0; i < concurrentLevel; i++ {
+			wait.Add(2)
+			go func() {
+				defer wait.Done()
+				atomicSet.AddAll([]int{1, 2})
+				atomicSet.RemoveAll([]int{1, 2})
+			}()
+			go func() {
+				defer wait.Done()
+				atomicSet.DoAtomically(func(s Set[int]) {
+					// 1 and 2 are always added and removed together
+					if s.Has(1) != s.Has(2) {
+						inconsistent = true
+					}
+				})
+			}()
+		}
+		wait.Wait()
+		Expect(inconsistent).To(BeFalse())
+		Expect(setForTest.Len()).To(Equal(0))
+	})
+})
+
+var _ = Describe("ToSortedSlice", func() {
+	var data []int
+
+	BeforeEach(func() {
+		data = []int{5, 3, 8, 1, 9, 2}
+	})
+
+	for _, st := range []setType{defaultSet, threadSafeSet, prioritySet, threadSafePrioritySet} {
+		st := st
+		It(fmt.Sprintf("can sort the items of a %s.", st), func() {
+			setForTest := createSet[int, int](st, basicHasher[int], basicEquator[int], intAscComparator)
+			for _, datum := range data {
+				setForTest.Add(datum)
+			}
+
+			Expect(ToSortedSlice(setForTest, intAscComparator)).To(Equal([]int{1, 2, 3, 5, 8, 9}))
+			Expect(ToSortedSlice(setForTest, intDescComparator)).To(Equal([]int{9, 8, 5, 3, 2, 1}))
+			Expect(setForTest.Len()).To(Equal(len(data)))
+		})
+	}
+
+	It("returns the same order as popping a PrioritySet with the same comparator.", func() {
+		prioritySetForTest := NewPrioritySet[int, int](intAscComparator, basicHasher[int], basicEquator[int])
+		for _, datum := range data {
+			prioritySetForTest.Add(datum)
+		}
+
+		sorted := ToSortedSlice[int](prioritySetForTest, intAscComparator)
+		popped := []int{}
+		for value, exists := prioritySetForTest.TryPop(); exists; value, exists = prioritySetForTest.TryPop() {
+			popped = append(popped, value)
+		}
+		Expect(sorted).To(Equal(popped))
+	})
+
+	It("returns an empty slice for an empty set.", func() {
+		setForTest := NewSet[int, int](basicHasher[int], basicEquator[int])
+		Expect(ToSortedSlice(setForTest, intAscComparator)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("AddAllFrom", func() {
+	for _, st := range []setType{defaultSet, threadSafeSet, prioritySet, threadSafePrioritySet} {
+		st := st
+		Describe(fmt.Sprintf("works with %s.", st), func() {
+			var dst Set[int]
+			var src Set[int]
+
+			BeforeEach(func() {
+				dst = createSet[int, int](st, basicHasher[int], basicEquator[int], intAscComparator)
+				src = createSet[int, int](st, basicHasher[int], basicEquator[int], intAscComparator)
+				for _, item := range []int{1, 2} {
+					dst.Add(item)
+				}
+				for _, item := range []int{2, 3, 4} {
+					src.Add(item)
+				}
+			})
+
+			It("adds all the items from src.", func() {
+				Expect(AddAllFrom(dst, src)).To(Equal(2))
+				Expect(dst.ToArray()).To(ConsistOf(1, 2, 3, 4))
+				Expect(src.ToArray()).To(ConsistOf(2, 3, 4))
+			})
+
+			It("works when dst and src are the same set.", func() {
+				Expect(AddAllFrom(src, src)).To(Equal(0))
+				Expect(src.ToArray()).To(ConsistOf(2, 3, 4))
+			})
+
+			It("adds nothing from an empty set.", func() {
+				src.Clear()
+				Expect(AddAllFrom(dst, src)).To(Equal(0))
+				Expect(dst.ToArray()).To(ConsistOf(1, 2))
+			})
+		})
+	}
+})
+
+var _ = Describe("SnapshotIterator", func() {
+	var set Set[int]
+
+	BeforeEach(func() {
+		set = NewThreadSafeSet[int, int](basicHasher[int], basicEquator[int])
+		for i := 0; i < 100; i++ {
+			set.Add(i)
+		}
+	})
+
+	collect := func(iterator Iterator[int]) []int {
+		result := []int{}
+		for item, exists := iterator.Next(); exists; item, exists = iterator.Next() {
+			result = append(result, item)
+		}
+		return result
+	}
+
+	It("iterates all the items in the snapshot.", func() {
+		iterator := NewSnapshotIterator(set)
+		Expect(iterator.HasNext()).To(BeTrue())
+		items := collect(iterator)
+		Expect(iterator.HasNext()).To(BeFalse())
+		Expect(items).To(HaveLen(set.Len()))
+		Expect(items).To(ConsistOf(set.ToArray()))
+
+		_, exists := NewSnapshotIterator(NewSet[int, int](basicHasher[int], basicEquator[int])).Next()
+		Expect(exists).To(BeFalse())
+	})
+
+	It("doesn't reflect the modifications during the iteration.", func() {
+		iterator := NewSnapshotIterator(set)
+		first, exists := iterator.Next()
+		Expect(exists).To(BeTrue())
+
+		set.Add(100)
+		set.RemoveFirst((first + 1) % 100)
+		items := append(collect(iterator), first)
+		Expect(items).To(HaveLen(100))
+		Expect(items).NotTo(ContainElement(100))
+		Expect(items).To(ContainElement((first + 1) % 100))
+	})
+
+	It("can be used by multiple goroutines concurrently.", func() {
+		iterator := NewSnapshotIterator(set)
+		results := make([][]int, 4)
+		wait := sync.WaitGroup{}
+		for i := range results {
+			wait.Add(1)
+			i := i
+			go func() {
+				defer wait.Done()
+				results[i] = collect(iterator)
+			}()
+		}
+		for i := 100; i < 200; i++ {
+			set.Add(i)
+		}
+		wait.Wait()
+
+		items := []int{}
+		for _, result := range results {
+			items = append(items, result...)
+		}
+		expected := make([]int, 100)
+		for i := range expected {
+			expected[i] = i
+		}
+		sort.Ints(items)
+		Expect(items).To(Equal(expected))
+	})
 })
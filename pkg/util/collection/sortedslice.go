@@ -0,0 +1,146 @@
+package collection
+
+import (
+	"sort"
+)
+
+// SortedSlice A collection that keeps its items sorted in a slice. Has and IndexOf take O(log n) time by binary
+//  search, while Add and RemoveFirst take O(n) time to shift the items. Unlike a PriorityQueue, it can be iterated in
+//  order and queried by range without being consumed. Equal items are allowed, and they are kept in the order of Add.
+//  ToArray and Range return the items in the ascending order, and TryPop removes the first item.
+type SortedSlice[T any] interface {
+	Collection[T]
+	// Get returns the item at the index in the ascending order. It panics if the index is out of range.
+	Get(index int) T
+	// IndexOf returns the index of the first item equal to `item`, or -1 if there is no such item
+	IndexOf(item T) int
+	// RangeBetween calls f for the items in [from, to) in the ascending order, until f returns false
+	RangeBetween(from T, to T, f func(item T) bool)
+}
+
+// NewSortedSlice The comparator must be a strict order, because two items are regarded as equal if neither of them
+//  is less than the other. For example, use `first < second` instead of `first <= second`.
+func NewSortedSlice[T any](comparator Comparator[T]) SortedSlice[T] {
+	return &sortedSlice[T]{
+		comparator: comparator,
+	}
+}
+
+type sortedSlice[T any] struct {
+	items      []T
+	comparator Comparator[T]
+}
+
+// lowerBound returns the index of the first item that is not less than `item`
+func (s *sortedSlice[T]) lowerBound(item T) int {
+	return sort.Search(len(s.items), func(i int) bool {
+		return !s.comparator(s.items[i], item)
+	})
+}
+
+// upperBound returns the index of the first item that is greater than `item`
+func (s *sortedSlice[T]) upperBound(item T) int {
+	return sort.Search(len(s.items), func(i int) bool {
+		return s.comparator(item, s.items[i])
+	})
+}
+
+// Add never replaces any item, because the equal items are kept together
+func (s *sortedSlice[T]) Add(item T) (oldItem T, replaced bool) {
+	index := s.upperBound(item)
+	s.items = append(s.items, item)
+	copy(s.items[index+1:], s.items[index:])
+	s.items[index] = item
+	return
+}
+
+func (s *sortedSlice[T]) removeAt(index int) T {
+	item := s.items[index]
+	copy(s.items[index:], s.items[index+1:])
+	// Release the reference of the last item
+	s.items[len(s.items)-1] = *new(T)
+	s.items = s.items[:len(s.items)-1]
+	return item
+}
+
+func (s *sortedSlice[T]) RemoveFirst(item T) bool {
+	index := s.IndexOf(item)
+	if index < 0 {
+		return false
+	}
+	s.removeAt(index)
+	return true
+}
+
+// TryPop removes the least item
+func (s *sortedSlice[T]) TryPop() (item T, exists bool) {
+	if len(s.items) == 0 {
+		return
+	}
+	return s.removeAt(0), true
+}
+
+func (s *sortedSlice[T]) Pop() T {
+	return mustPop[T](s, "SortedSlice")
+}
+
+func (s *sortedSlice[T]) Get(index int) T {
+	return s.items[index]
+}
+
+func (s *sortedSlice[T]) IndexOf(item T) int {
+	index := s.lowerBound(item)
+	if index < len(s.items) && !s.comparator(item, s.items[index]) {
+		return index
+	}
+	return -1
+}
+
+func (s *sortedSlice[T]) Has(item T) bool {
+	return s.IndexOf(item) >= 0
+}
+
+func (s *sortedSlice[T]) Contains(item T) bool {
+	return s.Has(item)
+}
+
+func (s *sortedSlice[T]) Len() int {
+	return len(s.items)
+}
+
+func (s *sortedSlice[T]) Clear() {
+	s.items = nil
+}
+
+// ToArray returns the items in the ascending order
+func (s *sortedSlice[T]) ToArray() []T {
+	return append([]T{}, s.items...)
+}
+
+// Range visits the items in the ascending order
+func (s *sortedSlice[T]) Range(f func(item T) bool) {
+	for _, item := range s.items {
+		if !f(item) {
+			return
+		}
+	}
+}
+
+func (s *sortedSlice[T]) RangeBetween(from T, to T, f func(item T) bool) {
+	for i := s.lowerBound(from); i < len(s.items) && s.comparator(s.items[i], to); i++ {
+		if !f(s.items[i]) {
+			return
+		}
+	}
+}
+
+func (s *sortedSlice[T]) All() func(yield func(T) bool) {
+	return s.Range
+}
+
+func (s *sortedSlice[T]) Clone() Collection[T] {
+	return &sortedSlice[T]{
+		items:      s.ToArray(),
+		comparator: s.comparator,
+	}
+}
@@ -0,0 +1,96 @@
+package collection_test
+
+import (
+	"sort"
+
+	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SortedSlice", func() {
+	var sortedSlice SortedSlice[int]
+
+	BeforeEach(func() {
+		sortedSlice = NewSortedSlice[int](intStrictAscComparator)
+	})
+
+	rangeBetween := func(from, to int) []int {
+		result := []int{}
+		sortedSlice.RangeBetween(from, to, func(item int) bool {
+			result = append(result, item)
+			return true
+		})
+		return result
+	}
+
+	It("keeps the items sorted.", func() {
+		items := getRandomArray(100)
+		AddAll[int](sortedSlice, items...)
+		sort.Ints(items)
+		Expect(sortedSlice.Len()).To(Equal(100))
+		Expect(sortedSlice.ToArray()).To(Equal(items))
+		for i, item := range items {
+			Expect(sortedSlice.Get(i)).To(Equal(item))
+		}
+	})
+
+	It("keeps the equal items.", func() {
+		for _, item := range []int{2, 1, 2, 3, 2} {
+			_, replaced := sortedSlice.Add(item)
+			Expect(replaced).To(BeFalse())
+		}
+		Expect(sortedSlice.ToArray()).To(Equal([]int{1, 2, 2, 2, 3}))
+		Expect(sortedSlice.IndexOf(2)).To(Equal(1))
+		Expect(sortedSlice.RemoveFirst(2)).To(BeTrue())
+		Expect(sortedSlice.ToArray()).To(Equal([]int{1, 2, 2, 3}))
+	})
+
+	It("finds the items by binary search.", func() {
+		AddAll[int](sortedSlice, 5, 1, 3)
+		Expect(sortedSlice.IndexOf(1)).To(Equal(0))
+		Expect(sortedSlice.IndexOf(5)).To(Equal(2))
+		Expect(sortedSlice.IndexOf(0)).To(Equal(-1))
+		Expect(sortedSlice.IndexOf(4)).To(Equal(-1))
+		Expect(sortedSlice.IndexOf(6)).To(Equal(-1))
+		Expect(sortedSlice.Has(3)).To(BeTrue())
+		Expect(sortedSlice.Has(2)).To(BeFalse())
+		Expect(sortedSlice.RemoveFirst(2)).To(BeFalse())
+	})
+
+	It("ranges between the bounds.", func() {
+		AddAll[int](sortedSlice, 5, 1, 3, 7, 3)
+		Expect(rangeBetween(3, 7)).To(Equal([]int{3, 3, 5}))
+		Expect(rangeBetween(2, 6)).To(Equal([]int{3, 3, 5}))
+		Expect(rangeBetween(0, 100)).To(Equal([]int{1, 3, 3, 5, 7}))
+		Expect(rangeBetween(4, 4)).To(BeEmpty())
+		Expect(rangeBetween(8, 10)).To(BeEmpty())
+
+		result := []int{}
+		sortedSlice.RangeBetween(0, 100, func(item int) bool {
+			result = append(result, item)
+			return item < 3
+		})
+		Expect(result).To(Equal([]int{1, 3}))
+	})
+
+	It("pops the least item.", func() {
+		AddAll[int](sortedSlice, 2, 1)
+		Expect(sortedSlice.Pop()).To(Equal(1))
+		item, exists := sortedSlice.TryPop()
+		Expect(item).To(Equal(2))
+		Expect(exists).To(BeTrue())
+		_, exists = sortedSlice.TryPop()
+		Expect(exists).To(BeFalse())
+		Expect(func() { sortedSlice.Pop() }).To(PanicWith(ContainSubstring("Pop from an empty")))
+	})
+
+	It("can be cloned and cleared.", func() {
+		AddAll[int](sortedSlice, 2, 1)
+		cloned := sortedSlice.Clone().(SortedSlice[int])
+		sortedSlice.Clear()
+		Expect(sortedSlice.Len()).To(Equal(0))
+		cloned.Add(0)
+		Expect(cloned.ToArray()).To(Equal([]int{0, 1, 2}))
+	})
+})
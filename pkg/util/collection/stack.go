@@ -0,0 +1,57 @@
+package collection
+
+// Stack A LIFO collection backed by a ring buffer. Add equals Push, and TryPop pops the top item.
+//  ToArray and Range return the items from the bottom to the top.
+type Stack[T any] interface {
+	Collection[T]
+	Push(item T)
+	// Pop panics if the stack is empty
+	Pop() T
+	// Peek panics if the stack is empty
+	Peek() T
+	TryPeek() (item T, exists bool)
+}
+
+func NewStack[T any](equaler Equaler[T]) Stack[T] {
+	return &stack[T]{
+		deque: NewDeque[T](equaler).(*deque[T]),
+	}
+}
+
+type stack[T any] struct {
+	*deque[T]
+}
+
+func (s *stack[T]) Push(item T) {
+	s.deque.Append(item)
+}
+
+func (s *stack[T]) TryPop() (item T, exists bool) {
+	return s.deque.PopLast()
+}
+
+func (s *stack[T]) Pop() T {
+	item, exists := s.TryPop()
+	if !exists {
+		panic("Pop from an empty Stack.")
+	}
+	return item
+}
+
+func (s *stack[T]) TryPeek() (item T, exists bool) {
+	return s.deque.PeekLast()
+}
+
+func (s *stack[T]) Peek() T {
+	item, exists := s.TryPeek()
+	if !exists {
+		panic("Peek from an empty Stack.")
+	}
+	return item
+}
+
+func (s *stack[T]) Clone() Collection[T] {
+	return &stack[T]{
+		deque: s.deque.clone(),
+	}
+}
@@ -0,0 +1,92 @@
+package collection_test
+
+import (
+	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Stack", func() {
+	var stack Stack[int]
+
+	BeforeEach(func() {
+		stack = NewStack[int](basicEquator[int])
+	})
+
+	It("pops the items in the LIFO order.", func() {
+		for i := 0; i < 10; i++ {
+			if i%2 == 0 {
+				stack.Push(i)
+			} else {
+				stack.Add(i)
+			}
+		}
+		Expect(stack.ToArray()).To(Equal(getSequence(10)))
+		Expect(stack.Peek()).To(Equal(9))
+
+		actual := []int{}
+		for stack.Len() > 1 {
+			actual = append(actual, stack.Pop())
+		}
+		item, exists := stack.TryPop()
+		Expect(exists).To(BeTrue())
+		actual = append(actual, item)
+		Expect(actual).To(Equal([]int{9, 8, 7, 6, 5, 4, 3, 2, 1, 0}))
+	})
+
+	It("panics when popping or peeking an empty stack.", func() {
+		Expect(func() { stack.Pop() }).To(Panic())
+		Expect(func() { stack.Peek() }).To(Panic())
+		_, exists := stack.TryPeek()
+		Expect(exists).To(BeFalse())
+		_, exists = stack.TryPop()
+		Expect(exists).To(BeFalse())
+	})
+
+	It("supports the other Collection methods.", func() {
+		stack.Push(1)
+		stack.Push(2)
+		Expect(stack.Has(1)).To(BeTrue())
+		Expect(stack.RemoveFirst(1)).To(BeTrue())
+		Expect(stack.Contains(1)).To(BeFalse())
+		Expect(stack.Len()).To(Equal(1))
+		stack.Clear()
+		Expect(stack.ToArray()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Queue", func() {
+	var queue Queue[int]
+
+	BeforeEach(func() {
+		queue = NewQueue[int](basicEquator[int])
+	})
+
+	It("dequeues the items in the FIFO order.", func() {
+		for i := 0; i < 10; i++ {
+			if i%2 == 0 {
+				queue.Enqueue(i)
+			} else {
+				queue.Add(i)
+			}
+		}
+		Expect(queue.ToArray()).To(Equal(getSequence(10)))
+		Expect(queue.Peek()).To(Equal(0))
+
+		actual := []int{}
+		for queue.Len() > 1 {
+			actual = append(actual, queue.Dequeue())
+		}
+		item, exists := queue.TryPop()
+		Expect(exists).To(BeTrue())
+		actual = append(actual, item)
+		Expect(actual).To(Equal(getSequence(10)))
+	})
+
+	It("panics when dequeuing or peeking an empty queue.", func() {
+		Expect(func() { queue.Dequeue() }).To(Panic())
+		Expect(func() { queue.Peek() }).To(Panic())
+		_, exists := queue.TryPeek()
+		Expect(exists).To(BeFalse())
+	})
+})
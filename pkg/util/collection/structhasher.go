@@ -0,0 +1,144 @@
+package collection
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/maphash"
+	"math"
+	"reflect"
+)
+
+// StructHasher returns a Hasher and an Equaler which only consider the specified fields of a struct.
+//  T can be a struct type or a pointer to a struct type. Two nil pointers are equal.
+//  The fields are resolved by reflection once when StructHasher is called, and it panics if a field doesn't exist,
+//  is unexported, or is not comparable. Unexported fields can't be read by reflection.
+//  The fields are compared with `==`, and the hash codes differ between processes.
+func StructHasher[T any](fields ...string) (Hasher[T, uint64], Equaler[T]) {
+	structType := reflect.TypeOf((*T)(nil)).Elem()
+	isPointer := structType.Kind() == reflect.Pointer
+	if isPointer {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		panic(fmt.Errorf("%s is not a struct type", structType))
+	}
+
+	plan := make([][]int, len(fields))
+	for i, name := range fields {
+		field, exists := structType.FieldByName(name)
+		if !exists {
+			panic(fmt.Errorf("%s has no field %s", structType, name))
+		}
+		if !field.IsExported() {
+			panic(fmt.Errorf("the field %s of %s is unexported", name, structType))
+		}
+		if !field.Type.Comparable() {
+			panic(fmt.Errorf("the field %s of %s is not comparable", name, structType))
+		}
+		plan[i] = field.Index
+	}
+
+	seed := maphash.MakeSeed()
+	// structValue returns false if obj is a nil pointer
+	structValue := func(obj T) (reflect.Value, bool) {
+		value := reflect.ValueOf(obj)
+		if isPointer {
+			if value.IsNil() {
+				return value, false
+			}
+			value = value.Elem()
+		}
+		return value, true
+	}
+
+	hasher := func(obj T) uint64 {
+		value, ok := structValue(obj)
+		if !ok {
+			return 0
+		}
+
+		hash := maphash.Hash{}
+		hash.SetSeed(seed)
+		for _, index := range plan {
+			writeFieldValue(&hash, value.FieldByIndex(index))
+		}
+		return hash.Sum64()
+	}
+
+	equaler := func(original, new T) bool {
+		originalValue, originalOk := structValue(original)
+		newValue, newOk := structValue(new)
+		if !originalOk || !newOk {
+			return originalOk == newOk
+		}
+
+		for _, index := range plan {
+			if originalValue.FieldByIndex(index).Interface() != newValue.FieldByIndex(index).Interface() {
+				return false
+			}
+		}
+		return true
+	}
+
+	return hasher, equaler
+}
+
+// writeFieldValue The values equal by `==` must be written in the same way
+func writeFieldValue(hash *maphash.Hash, value reflect.Value) {
+	buffer := make([]byte, 8)
+	writeFloat := func(f float64) {
+		if f == 0 { // -0 == +0
+			f = 0
+		}
+		binary.LittleEndian.PutUint64(buffer, math.Float64bits(f))
+		hash.Write(buffer)
+	}
+
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		binary.LittleEndian.PutUint64(buffer, uint64(value.Int()))
+		hash.Write(buffer)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		binary.LittleEndian.PutUint64(buffer, value.Uint())
+		hash.Write(buffer)
+	case reflect.Float32, reflect.Float64:
+		writeFloat(value.Float())
+	case reflect.Complex64, reflect.Complex128:
+		c := value.Complex()
+		writeFloat(real(c))
+		writeFloat(imag(c))
+	case reflect.Bool:
+		if value.Bool() {
+			hash.WriteByte(1)
+		} else {
+			hash.WriteByte(0)
+		}
+	case reflect.String:
+		hash.WriteString(value.String())
+		hash.WriteByte(0) // Separate the adjacent strings
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		binary.LittleEndian.PutUint64(buffer, uint64(value.Pointer()))
+		hash.Write(buffer)
+	case reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			writeFieldValue(hash, value.Index(i))
+		}
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			writeFieldValue(hash, value.Field(i))
+		}
+	case reflect.Interface:
+		if value.IsNil() {
+			hash.WriteByte(0)
+			return
+		}
+		// The equal interfaces have the same dynamic type
+		hash.WriteByte(1)
+		hash.WriteString(value.Elem().Type().String())
+		hash.WriteByte(0)
+		writeFieldValue(hash, value.Elem())
+	default:
+		// `==` panics for the other kinds, so they can't be in the equal values
+		panic(fmt.Errorf("%s is not comparable", value.Type()))
+	}
+}
@@ -0,0 +1,103 @@
+package collection_test
+
+import (
+	"math"
+
+	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type user struct {
+	ID      int
+	Name    string
+	Score   float64
+	Tags    []string
+	Address struct {
+		City string
+	}
+}
+
+var _ = Describe("StructHasher", func() {
+	It("only considers the specified fields.", func() {
+		hasher, equaler := StructHasher[user]("ID", "Name")
+		first := user{ID: 1, Name: "a", Tags: []string{"x"}}
+		second := user{ID: 1, Name: "a", Score: 2}
+		third := user{ID: 1, Name: "b"}
+
+		Expect(equaler(first, second)).To(BeTrue())
+		Expect(hasher(first)).To(Equal(hasher(second)))
+		Expect(equaler(first, third)).To(BeFalse())
+		Expect(hasher(first)).NotTo(Equal(hasher(third)))
+
+		m := NewMap[user, int, uint64](hasher, equaler)
+		m.Put(first, 1)
+		m.Put(second, 2)
+		m.Put(third, 3)
+		Expect(m.Len()).To(Equal(2))
+		value, _ := m.Get(user{ID: 1, Name: "a"})
+		Expect(value).To(Equal(2))
+	})
+
+	It("works with pointers.", func() {
+		hasher, equaler := StructHasher[*user]("ID", "Address")
+		first := &user{ID: 1}
+		first.Address.City = "x"
+		second := &user{ID: 1, Name: "b"}
+		second.Address.City = "x"
+
+		Expect(equaler(first, second)).To(BeTrue())
+		Expect(hasher(first)).To(Equal(hasher(second)))
+		Expect(equaler(first, nil)).To(BeFalse())
+		Expect(equaler(nil, nil)).To(BeTrue())
+		Expect(hasher(nil)).To(Equal(uint64(0)))
+
+		second.Address.City = "y"
+		Expect(equaler(first, second)).To(BeFalse())
+	})
+
+	It("gives the same hash code to the equal floats.", func() {
+		hasher, equaler := StructHasher[user]("Score")
+		first := user{Score: 0}
+		second := user{Score: math.Copysign(0, -1)}
+		Expect(equaler(first, second)).To(BeTrue())
+		Expect(hasher(first)).To(Equal(hasher(second)))
+	})
+
+	It("gives the same hash code to the equal floats in arrays and interfaces.", func() {
+		type point struct {
+			A [1]float64
+			I any
+		}
+		hasher, equaler := StructHasher[point]("A", "I")
+		first := point{A: [1]float64{0}, I: struct{ X float64 }{0}}
+		second := point{A: [1]float64{math.Copysign(0, -1)}, I: struct{ X float64 }{math.Copysign(0, -1)}}
+		Expect(equaler(first, second)).To(BeTrue())
+		Expect(hasher(first)).To(Equal(hasher(second)))
+
+		m := NewMap[point, int, uint64](hasher, equaler)
+		m.Put(first, 1)
+		value, exists := m.Get(second)
+		Expect(exists).To(BeTrue())
+		Expect(value).To(Equal(1))
+
+		third := point{A: [1]float64{0}, I: 0.0}
+		Expect(equaler(first, third)).To(BeFalse())
+		Expect(hasher(first)).NotTo(Equal(hasher(third)))
+	})
+
+	It("panics for the invalid fields.", func() {
+		Expect(func() { StructHasher[user]("Nonexistent") }).To(Panic())
+		Expect(func() { StructHasher[user]("Tags") }).To(Panic())
+		Expect(func() { StructHasher[int]() }).To(Panic())
+	})
+
+	It("panics for the unexported fields when it's called.", func() {
+		type account struct {
+			name string
+			id   int
+		}
+		Expect(func() { StructHasher[account]("name") }).To(PanicWith(MatchError(ContainSubstring("unexported"))))
+		Expect(func() { StructHasher[*account]("id") }).To(PanicWith(MatchError(ContainSubstring("unexported"))))
+	})
+})
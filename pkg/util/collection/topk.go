@@ -0,0 +1,155 @@
+package collection
+
+import (
+	"fmt"
+	"sort"
+)
+
+// TopK tracks the K most frequent items in a stream. The frequencies are estimated by a count-min sketch, so the
+//  memory doesn't grow with the number of distinct items. The estimated counts never underestimate the real counts,
+//  and the error is at most 2N/width with probability 1-(1/2)^depth, where N is the number of the offered items.
+//  It's not thread-safe.
+type TopK[T any] interface {
+	// Offer records one occurrence of the item and returns its estimated count
+	Offer(item T) uint64
+	// Estimate returns the estimated count of the item, even if the item is not in the top K
+	Estimate(item T) uint64
+	// Top returns the tracked items with their estimated counts, ordered from the most frequent one
+	Top() []Pair[T, uint64]
+	// Len returns the number of the tracked items, which is at most K
+	Len() int
+	K() int
+	Clear()
+}
+
+type topKEntry[T any] struct {
+	item  T
+	count uint64
+}
+
+// NewTopK `width` and `depth` are the dimensions of the count-min sketch. A larger width means a smaller error,
+//  and a larger depth means a higher probability to keep the error under the bound.
+func NewTopK[T any](k int, width int, depth int, hasher Hasher[T, uint64], equaler Equaler[T]) TopK[T] {
+	if k <= 0 || width <= 0 || depth <= 0 {
+		panic(fmt.Errorf("k, width and depth should be positive"))
+	}
+
+	sketch := make([][]uint64, depth)
+	for i := range sketch {
+		sketch[i] = make([]uint64, width)
+	}
+	return &topK[T]{
+		k:       k,
+		sketch:  sketch,
+		hasher:  hasher,
+		entries: NewMap[T, *topKEntry[T], uint64](hasher, equaler),
+		heap: NewPrioritySet[*topKEntry[T], uint64](
+			func(first, second *topKEntry[T]) bool {
+				return first.count < second.count
+			},
+			func(entry *topKEntry[T]) uint64 {
+				return hasher(entry.item)
+			},
+			func(first, second *topKEntry[T]) bool {
+				return equaler(first.item, second.item)
+			}),
+	}
+}
+
+type topK[T any] struct {
+	k       int
+	sketch  [][]uint64
+	hasher  Hasher[T, uint64]
+	entries Map[T, *topKEntry[T]]
+	// heap has the same entries as `entries`, and the least frequent one is on the top
+	heap PrioritySet[*topKEntry[T]]
+}
+
+// mix64 is the finalizer of splitmix64, which spreads the bits of poor hash codes, like the ones of small integers
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// indexes derives the indexes in all the rows from two hash codes, as described in
+// "Less Hashing, Same Performance: Building a Better Bloom Filter"
+func (t *topK[T]) indexes(item T, f func(row int, column int)) {
+	h1 := mix64(t.hasher(item))
+	h2 := mix64(h1) | 1
+	width := uint64(len(t.sketch[0]))
+	for row := range t.sketch {
+		f(row, int((h1+uint64(row)*h2)%width))
+	}
+}
+
+func (t *topK[T]) Offer(item T) uint64 {
+	estimate := ^uint64(0)
+	t.indexes(item, func(row int, column int) {
+		t.sketch[row][column]++
+		if t.sketch[row][column] < estimate {
+			estimate = t.sketch[row][column]
+		}
+	})
+
+	if entry, exists := t.entries.Get(item); exists {
+		entry.count = estimate
+		t.heap.Fix(entry)
+		return estimate
+	}
+
+	if t.heap.Len() >= t.k {
+		if t.heap.Peek().count >= estimate {
+			return estimate
+		}
+		least, _ := t.heap.TryPop()
+		t.entries.Remove(least.item)
+	}
+	entry := &topKEntry[T]{item: item, count: estimate}
+	t.entries.Put(item, entry)
+	t.heap.Add(entry)
+	return estimate
+}
+
+func (t *topK[T]) Estimate(item T) uint64 {
+	estimate := ^uint64(0)
+	t.indexes(item, func(row int, column int) {
+		if t.sketch[row][column] < estimate {
+			estimate = t.sketch[row][column]
+		}
+	})
+	return estimate
+}
+
+func (t *topK[T]) Top() []Pair[T, uint64] {
+	entries := t.heap.ToArray()
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].count > entries[j].count
+	})
+	result := make([]Pair[T, uint64], len(entries))
+	for i, entry := range entries {
+		result[i] = Pair[T, uint64]{Key: entry.item, Value: entry.count}
+	}
+	return result
+}
+
+func (t *topK[T]) Len() int {
+	return t.heap.Len()
+}
+
+func (t *topK[T]) K() int {
+	return t.k
+}
+
+func (t *topK[T]) Clear() {
+	for _, row := range t.sketch {
+		for i := range row {
+			row[i] = 0
+		}
+	}
+	t.entries.Clear()
+	t.heap.Clear()
+}
@@ -0,0 +1,71 @@
+package collection_test
+
+import (
+	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func uint64Hasher(value int) uint64 {
+	return uint64(value)
+}
+
+var _ = Describe("TopK", func() {
+	var topK TopK[int]
+
+	BeforeEach(func() {
+		topK = NewTopK[int](3, 1024, 4, uint64Hasher, basicEquator[int])
+	})
+
+	It("tracks the most frequent items.", func() {
+		// The noise items occur at most 20 times
+		for _, item := range getRandomArray(20) {
+			topK.Offer(item)
+		}
+		for i := 0; i < 50; i++ {
+			topK.Offer(100)
+			if i < 40 {
+				topK.Offer(101)
+			}
+			if i < 30 {
+				topK.Offer(102)
+			}
+		}
+
+		Expect(topK.Len()).To(Equal(3))
+		Expect(topK.K()).To(Equal(3))
+		top := topK.Top()
+		Expect(top).To(HaveLen(3))
+		Expect([]int{top[0].Key, top[1].Key, top[2].Key}).To(Equal([]int{100, 101, 102}))
+		Expect(top[0].Value).To(BeNumerically(">=", 50))
+		Expect(top[1].Value).To(BeNumerically(">=", 40))
+		Expect(top[2].Value).To(BeNumerically(">=", 30))
+	})
+
+	It("never underestimates the counts.", func() {
+		counts := map[int]uint64{}
+		for _, item := range getRandomArray(1000) {
+			counts[item]++
+			Expect(topK.Offer(item)).To(BeNumerically(">=", counts[item]))
+		}
+		for item, count := range counts {
+			Expect(topK.Estimate(item)).To(BeNumerically(">=", count))
+		}
+		Expect(topK.Estimate(-1)).To(BeNumerically("<", 1000))
+	})
+
+	It("can be cleared.", func() {
+		topK.Offer(1)
+		topK.Offer(2)
+		topK.Clear()
+		Expect(topK.Len()).To(Equal(0))
+		Expect(topK.Estimate(1)).To(Equal(uint64(0)))
+		Expect(topK.Top()).To(BeEmpty())
+	})
+
+	It("panics with invalid arguments.", func() {
+		Expect(func() { NewTopK[int](0, 1, 1, uint64Hasher, basicEquator[int]) }).To(Panic())
+		Expect(func() { NewTopK[int](1, 0, 1, uint64Hasher, basicEquator[int]) }).To(Panic())
+		Expect(func() { NewTopK[int](1, 1, 0, uint64Hasher, basicEquator[int]) }).To(Panic())
+	})
+})
@@ -0,0 +1,472 @@
+package collection
+
+// TreeMap A Map sorted by the keys, which is backed by an AVL tree. Get, Put and Remove take O(log n) time.
+//  ToArray and Range return the pairs in the ascending order of the keys, and TryPop removes the first pair.
+type TreeMap[K any, V any] interface {
+	Map[K, V]
+	// First returns the pair with the least key
+	First() (pair Pair[K, V], exists bool)
+	// Last returns the pair with the greatest key
+	Last() (pair Pair[K, V], exists bool)
+	// Floor returns the pair with the greatest key less than or equal to `key`
+	Floor(key K) (pair Pair[K, V], exists bool)
+	// Ceiling returns the pair with the least key greater than or equal to `key`
+	Ceiling(key K) (pair Pair[K, V], exists bool)
+	// RangeBetween calls f for the pairs whose keys are in [from, to) in the ascending order, until f returns false
+	RangeBetween(from K, to K, f func(pair Pair[K, V]) bool)
+}
+
+// NewTreeMap The comparator must be a strict order, because two keys are regarded as equal if neither of them is
+//  less than the other. For example, use `first < second` instead of `first <= second`.
+func NewTreeMap[K any, V any](comparator Comparator[K]) TreeMap[K, V] {
+	return &treeMap[K, V]{
+		root:       nil,
+		size:       0,
+		comparator: comparator,
+	}
+}
+
+type treeNode[K any, V any] struct {
+	key    K
+	value  V
+	left   *treeNode[K, V]
+	right  *treeNode[K, V]
+	height int
+}
+
+func (n *treeNode[K, V]) clone() *treeNode[K, V] {
+	if n == nil {
+		return nil
+	}
+
+	return &treeNode[K, V]{
+		key:    n.key,
+		value:  n.value,
+		left:   n.left.clone(),
+		right:  n.right.clone(),
+		height: n.height,
+	}
+}
+
+func (n *treeNode[K, V]) pair() Pair[K, V] {
+	return Pair[K, V]{Key: n.key, Value: n.value}
+}
+
+func heightOf[K any, V any](node *treeNode[K, V]) int {
+	if node == nil {
+		return 0
+	}
+	return node.height
+}
+
+func (n *treeNode[K, V]) update() {
+	n.height = heightOf(n.left)
+	if heightOf(n.right) > n.height {
+		n.height = heightOf(n.right)
+	}
+	n.height += 1
+}
+
+func (n *treeNode[K, V]) balanceFactor() int {
+	return heightOf(n.left) - heightOf(n.right)
+}
+
+func (n *treeNode[K, V]) rotateRight() *treeNode[K, V] {
+	left := n.left
+	n.left = left.right
+	left.right = n
+	n.update()
+	left.update()
+	return left
+}
+
+func (n *treeNode[K, V]) rotateLeft() *treeNode[K, V] {
+	right := n.right
+	n.right = right.left
+	right.left = n
+	n.update()
+	right.update()
+	return right
+}
+
+// rebalance returns the new root of the subtree
+func (n *treeNode[K, V]) rebalance() *treeNode[K, V] {
+	n.update()
+	switch factor := n.balanceFactor(); {
+	case factor > 1:
+		if n.left.balanceFactor() < 0 {
+			n.left = n.left.rotateLeft()
+		}
+		return n.rotateRight()
+	case factor < -1:
+		if n.right.balanceFactor() > 0 {
+			n.right = n.right.rotateRight()
+		}
+		return n.rotateLeft()
+	default:
+		return n
+	}
+}
+
+type treeMap[K any, V any] struct {
+	root       *treeNode[K, V]
+	size       int
+	comparator Comparator[K]
+}
+
+func (t *treeMap[K, V]) compare(first, second K) int {
+	if t.comparator(first, second) {
+		return -1
+	}
+	if t.comparator(second, first) {
+		return 1
+	}
+	return 0
+}
+
+func (t *treeMap[K, V]) find(key K) *treeNode[K, V] {
+	node := t.root
+	for node != nil {
+		switch cmp := t.compare(key, node.key); {
+		case cmp < 0:
+			node = node.left
+		case cmp > 0:
+			node = node.right
+		default:
+			return node
+		}
+	}
+	return nil
+}
+
+// put returns the new root of the subtree. `old` and `exists` are set if the key exists.
+func (t *treeMap[K, V]) put(node *treeNode[K, V], key K, value V, old *V, exists *bool) *treeNode[K, V] {
+	if node == nil {
+		t.size += 1
+		return &treeNode[K, V]{key: key, value: value, height: 1}
+	}
+
+	switch cmp := t.compare(key, node.key); {
+	case cmp < 0:
+		node.left = t.put(node.left, key, value, old, exists)
+	case cmp > 0:
+		node.right = t.put(node.right, key, value, old, exists)
+	default:
+		*old = node.value
+		*exists = true
+		node.key = key
+		node.value = value
+		return node
+	}
+	return node.rebalance()
+}
+
+// removeMin returns the new root of the subtree and the removed node with the least key
+func (t *treeMap[K, V]) removeMin(node *treeNode[K, V]) (root *treeNode[K, V], min *treeNode[K, V]) {
+	if node.left == nil {
+		return node.right, node
+	}
+	node.left, min = t.removeMin(node.left)
+	return node.rebalance(), min
+}
+
+// remove returns the new root of the subtree. `old` and `exists` are set if the key exists.
+func (t *treeMap[K, V]) remove(node *treeNode[K, V], key K, old *V, exists *bool) *treeNode[K, V] {
+	if node == nil {
+		return nil
+	}
+
+	switch cmp := t.compare(key, node.key); {
+	case cmp < 0:
+		node.left = t.remove(node.left, key, old, exists)
+	case cmp > 0:
+		node.right = t.remove(node.right, key, old, exists)
+	default:
+		*old = node.value
+		*exists = true
+		t.size -= 1
+		if node.left == nil {
+			return node.right
+		}
+		if node.right == nil {
+			return node.left
+		}
+
+		// Replace the node with its successor
+		right, successor := t.removeMin(node.right)
+		successor.left = node.left
+		successor.right = right
+		return successor.rebalance()
+	}
+	return node.rebalance()
+}
+
+func (t *treeMap[K, V]) Put(key K, value V) (old V, exists bool) {
+	t.root = t.put(t.root, key, value, &old, &exists)
+	return
+}
+
+func (t *treeMap[K, V]) Get(key K) (value V, exists bool) {
+	node := t.find(key)
+	if node == nil {
+		exists = false
+		return
+	}
+	return node.value, true
+}
+
+func (t *treeMap[K, V]) GetOrPutDefault(key K) (value V, exists bool) {
+	value, exists = t.Get(key)
+	if !exists {
+		t.Put(key, value)
+	}
+	return
+}
+
+func (t *treeMap[K, V]) ReplaceIfEqual(key K, expectedOld V, newValue V, valueEqualer Equaler[V]) bool {
+	node := t.find(key)
+	if node == nil || !valueEqualer(expectedOld, node.value) {
+		return false
+	}
+
+	node.value = newValue
+	return true
+}
+
+func (t *treeMap[K, V]) GetOrPut(key K, f func() V) (value V, exists bool) {
+	return getOrPut[K, V](t, key, f)
+}
+
+func (t *treeMap[K, V]) ComputeIfAbsent(key K, mapping func(key K) V) V {
+	return computeIfAbsent[K, V](t, key, mapping)
+}
+
+func (t *treeMap[K, V]) ComputeIfPresent(key K,
+	remapping func(key K, old V) (newValue V, keep bool)) (value V, exists bool) {
+	return computeIfPresent[K, V](t, key, remapping)
+}
+
+func (t *treeMap[K, V]) Merge(key K, value V, remapping func(old V, new V) V) V {
+	return merge[K, V](t, key, value, remapping)
+}
+
+func (t *treeMap[K, V]) PutIfAbsent(key K, value V) (existing V, exists bool) {
+	return putIfAbsent[K, V](t, key, value)
+}
+
+func (t *treeMap[K, V]) RemoveIf(key K, predicate func(value V) bool) bool {
+	return removeIf[K, V](t, key, predicate)
+}
+
+func (t *treeMap[K, V]) Remove(key K) (old V, exists bool) {
+	t.root = t.remove(t.root, key, &old, &exists)
+	return
+}
+
+func (t *treeMap[K, V]) ContainsKey(key K) bool {
+	return t.find(key) != nil
+}
+
+func (t *treeMap[K, V]) First() (pair Pair[K, V], exists bool) {
+	node := t.root
+	if node == nil {
+		exists = false
+		return
+	}
+	for node.left != nil {
+		node = node.left
+	}
+	return node.pair(), true
+}
+
+func (t *treeMap[K, V]) Last() (pair Pair[K, V], exists bool) {
+	node := t.root
+	if node == nil {
+		exists = false
+		return
+	}
+	for node.right != nil {
+		node = node.right
+	}
+	return node.pair(), true
+}
+
+func (t *treeMap[K, V]) Floor(key K) (pair Pair[K, V], exists bool) {
+	var candidate *treeNode[K, V]
+	node := t.root
+	for node != nil {
+		switch cmp := t.compare(key, node.key); {
+		case cmp < 0:
+			node = node.left
+		case cmp > 0:
+			candidate = node
+			node = node.right
+		default:
+			return node.pair(), true
+		}
+	}
+
+	if candidate == nil {
+		exists = false
+		return
+	}
+	return candidate.pair(), true
+}
+
+func (t *treeMap[K, V]) Ceiling(key K) (pair Pair[K, V], exists bool) {
+	var candidate *treeNode[K, V]
+	node := t.root
+	for node != nil {
+		switch cmp := t.compare(key, node.key); {
+		case cmp < 0:
+			candidate = node
+			node = node.left
+		case cmp > 0:
+			node = node.right
+		default:
+			return node.pair(), true
+		}
+	}
+
+	if candidate == nil {
+		exists = false
+		return
+	}
+	return candidate.pair(), true
+}
+
+// rangeNode visits the nodes whose keys are in [from, to) in order. A nil bound means unbounded.
+//  It returns false if f returns false.
+func (t *treeMap[K, V]) rangeNode(node *treeNode[K, V], from *K, to *K, f func(pair Pair[K, V]) bool) bool {
+	if node == nil {
+		return true
+	}
+
+	// The keys in the left subtree are less than node.key, and the keys in the right subtree are greater than it
+	afterFrom := from == nil || t.compare(node.key, *from) >= 0
+	beforeTo := to == nil || t.compare(node.key, *to) < 0
+	if afterFrom && !t.rangeNode(node.left, from, to, f) {
+		return false
+	}
+	if afterFrom && beforeTo && !f(node.pair()) {
+		return false
+	}
+	if beforeTo {
+		return t.rangeNode(node.right, from, to, f)
+	}
+	return true
+}
+
+func (t *treeMap[K, V]) RangeBetween(from K, to K, f func(pair Pair[K, V]) bool) {
+	t.rangeNode(t.root, &from, &to, f)
+}
+
+// Range visits the pairs in the ascending order of the keys
+func (t *treeMap[K, V]) Range(f func(pair Pair[K, V]) bool) {
+	t.rangeNode(t.root, nil, nil, f)
+}
+
+func (t *treeMap[K, V]) All() func(yield func(Pair[K, V]) bool) {
+	return t.Range
+}
+
+func (t *treeMap[K, V]) Keys() func(yield func(K) bool) {
+	return keysOf[K, V](t)
+}
+
+func (t *treeMap[K, V]) Values() func(yield func(V) bool) {
+	return valuesOf[K, V](t)
+}
+
+func (t *treeMap[K, V]) KeyValues() func(yield func(K, V) bool) {
+	return keyValuesOf[K, V](t)
+}
+
+// ToArray returns the pairs in the ascending order of the keys
+func (t *treeMap[K, V]) ToArray() []Pair[K, V] {
+	result := make([]Pair[K, V], 0, t.size)
+	t.Range(func(pair Pair[K, V]) bool {
+		result = append(result, pair)
+		return true
+	})
+	return result
+}
+
+func (t *treeMap[K, V]) Add(pair Pair[K, V]) (oldItem Pair[K, V], replaced bool) {
+	oldValue, replaced := t.Put(pair.Key, pair.Value)
+	if replaced {
+		oldItem.Key = pair.Key
+		oldItem.Value = oldValue
+	}
+	return
+}
+
+func (t *treeMap[K, V]) RemoveFirst(pair Pair[K, V]) bool {
+	_, exists := t.Remove(pair.Key)
+	return exists
+}
+
+func (t *treeMap[K, V]) Has(pair Pair[K, V]) bool {
+	return t.ContainsKey(pair.Key)
+}
+
+func (t *treeMap[K, V]) Contains(pair Pair[K, V]) bool {
+	return t.Has(pair)
+}
+
+// TryPop removes the pair with the least key
+func (t *treeMap[K, V]) TryPop() (pair Pair[K, V], exists bool) {
+	pair, exists = t.First()
+	if exists {
+		t.Remove(pair.Key)
+	}
+	return
+}
+
+func (t *treeMap[K, V]) Pop() Pair[K, V] {
+	return mustPop[Pair[K, V]](t, "TreeMap")
+}
+
+func (t *treeMap[K, V]) Len() int {
+	return t.size
+}
+
+func (t *treeMap[K, V]) Size() int {
+	return t.Len()
+}
+
+func (t *treeMap[K, V]) Empty() bool {
+	return t.Size() == 0
+}
+
+func (t *treeMap[K, V]) Clear() {
+	t.root = nil
+	t.size = 0
+}
+
+func (t *treeMap[K, V]) Clone() Collection[Pair[K, V]] {
+	return &treeMap[K, V]{
+		root:       t.root.clone(),
+		size:       t.size,
+		comparator: t.comparator,
+	}
+}
+
+// MarshalBinary encodes the pairs with gob. The comparator is not encoded.
+func (t *treeMap[K, V]) MarshalBinary() ([]byte, error) {
+	return gobEncode(t.ToArray())
+}
+
+// UnmarshalBinary replaces the content of t with the decoded pairs.
+func (t *treeMap[K, V]) UnmarshalBinary(data []byte) error {
+	var pairs []Pair[K, V]
+	if err := gobDecode(data, &pairs); err != nil {
+		return err
+	}
+
+	t.Clear()
+	for _, pair := range pairs {
+		t.Put(pair.Key, pair.Value)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package collection_test
+
+import (
+	"math/rand"
+	"sort"
+
+	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func intStrictAscComparator(first, second int) bool {
+	return first < second
+}
+
+var _ = Describe("TreeMap", func() {
+	var treeMap TreeMap[int, int]
+
+	keys := func() []int {
+		result := []int{}
+		treeMap.Keys()(func(key int) bool {
+			result = append(result, key)
+			return true
+		})
+		return result
+	}
+
+	BeforeEach(func() {
+		treeMap = NewTreeMap[int, int](intStrictAscComparator)
+	})
+
+	It("keeps the keys sorted under random puts and removals.", func() {
+		expected := map[int]int{}
+		for i := 0; i < 2000; i++ {
+			key := rand.Intn(300)
+			if rand.Intn(3) == 0 {
+				old, exists := treeMap.Remove(key)
+				expectedOld, expectedExists := expected[key]
+				Expect(exists).To(Equal(expectedExists))
+				Expect(old).To(Equal(expectedOld))
+				delete(expected, key)
+			} else {
+				old, exists := treeMap.Put(key, i)
+				expectedOld, expectedExists := expected[key]
+				Expect(exists).To(Equal(expectedExists))
+				Expect(old).To(Equal(expectedOld))
+				expected[key] = i
+			}
+			Expect(treeMap.Len()).To(Equal(len(expected)))
+		}
+
+		expectedKeys := []int{}
+		for key, value := range expected {
+			expectedKeys = append(expectedKeys, key)
+			actual, exists := treeMap.Get(key)
+			Expect(exists).To(BeTrue())
+			Expect(actual).To(Equal(value))
+		}
+		sort.Ints(expectedKeys)
+		Expect(keys()).To(Equal(expectedKeys))
+	})
+
+	Describe("navigation", func() {
+		BeforeEach(func() {
+			for _, key := range []int{50, 10, 40, 20, 30} {
+				treeMap.Put(key, key*10)
+			}
+		})
+
+		It("returns the first and the last pairs.", func() {
+			first, exists := treeMap.First()
+			Expect(exists).To(BeTrue())
+			Expect(first).To(Equal(Pair[int, int]{Key: 10, Value: 100}))
+			last, exists := treeMap.Last()
+			Expect(exists).To(BeTrue())
+			Expect(last).To(Equal(Pair[int, int]{Key: 50, Value: 500}))
+
+			treeMap.Clear()
+			_, exists = treeMap.First()
+			Expect(exists).To(BeFalse())
+			_, exists = treeMap.Last()
+			Expect(exists).To(BeFalse())
+		})
+
+		It("returns the floor and the ceiling.", func() {
+			floor, exists := treeMap.Floor(35)
+			Expect(exists).To(BeTrue())
+			Expect(floor.Key).To(Equal(30))
+			floor, _ = treeMap.Floor(30)
+			Expect(floor.Key).To(Equal(30))
+			_, exists = treeMap.Floor(5)
+			Expect(exists).To(BeFalse())
+
+			ceiling, exists := treeMap.Ceiling(35)
+			Expect(exists).To(BeTrue())
+			Expect(ceiling.Key).To(Equal(40))
+			ceiling, _ = treeMap.Ceiling(40)
+			Expect(ceiling.Key).To(Equal(40))
+			_, exists = treeMap.Ceiling(55)
+			Expect(exists).To(BeFalse())
+		})
+
+		It("ranges between two keys.", func() {
+			collect := func(from, to int) []int {
+				result := []int{}
+				treeMap.RangeBetween(from, to, func(pair Pair[int, int]) bool {
+					result = append(result, pair.Key)
+					return true
+				})
+				return result
+			}
+			Expect(collect(20, 50)).To(Equal([]int{20, 30, 40}))
+			Expect(collect(15, 45)).To(Equal([]int{20, 30, 40}))
+			Expect(collect(0, 100)).To(Equal([]int{10, 20, 30, 40, 50}))
+			Expect(collect(30, 30)).To(BeEmpty())
+
+			visited := 0
+			treeMap.RangeBetween(0, 100, func(pair Pair[int, int]) bool {
+				visited++
+				return visited < 2
+			})
+			Expect(visited).To(Equal(2))
+		})
+
+		It("pops the pairs in the ascending order.", func() {
+			actual := []int{}
+			for pair, exists := treeMap.TryPop(); exists; pair, exists = treeMap.TryPop() {
+				actual = append(actual, pair.Key)
+			}
+			Expect(actual).To(Equal([]int{10, 20, 30, 40, 50}))
+			Expect(treeMap.Empty()).To(BeTrue())
+		})
+	})
+
+	It("supports the other Map methods.", func() {
+		value, exists := treeMap.GetOrPutDefault(1)
+		Expect(exists).To(BeFalse())
+		Expect(value).To(Equal(0))
+		Expect(treeMap.ReplaceIfEqual(1, 0, 10, basicEquator[int])).To(BeTrue())
+		Expect(treeMap.ReplaceIfEqual(1, 0, 11, basicEquator[int])).To(BeFalse())
+
+		_, replaced := treeMap.Add(Pair[int, int]{Key: 2, Value: 20})
+		Expect(replaced).To(BeFalse())
+		Expect(treeMap.Has(Pair[int, int]{Key: 2})).To(BeTrue())
+		Expect(treeMap.ToArray()).To(Equal([]Pair[int, int]{{Key: 1, Value: 10}, {Key: 2, Value: 20}}))
+
+		Expect(treeMap.RemoveFirst(Pair[int, int]{Key: 1})).To(BeTrue())
+		Expect(treeMap.ContainsKey(1)).To(BeFalse())
+		Expect(treeMap.Size()).To(Equal(1))
+
+		dst := NewTreeMap[int, int](intStrictAscComparator)
+		gobRoundTrip(treeMap, dst)
+		Expect(dst.ToArray()).To(Equal(treeMap.ToArray()))
+	})
+})
@@ -0,0 +1,164 @@
+package collection
+
+import (
+	"fmt"
+)
+
+// AsCollection returns m as a Collection of its pairs. Every Map is already a Collection, so it only makes the
+//  conversion explicit, e.g. when the type parameters can't be inferred.
+func AsCollection[K any, V any](m Map[K, V]) Collection[Pair[K, V]] {
+	return m
+}
+
+// KeysView returns a live view of the keys of m, rather than a copy. The changes of m are reflected in the view,
+//  and removing a key from the view removes it from m. Add panics, because there is no value for the key.
+//  Clone returns a view of a clone of m. The view is thread-safe if m is thread-safe.
+func KeysView[K any, V any](m Map[K, V]) Set[K] {
+	return &keysView[K, V]{m: m}
+}
+
+// ValuesView returns a live view of the values of m, rather than a copy. The changes of m are reflected in the view,
+//  and removing a value from the view removes its key from m. Add panics, because there is no key for the value.
+//  Has and RemoveFirst compare the values with valueEqualer, which take O(n) time.
+//  Clone returns a view of a clone of m. Every method except RemoveFirst is thread-safe if m is thread-safe.
+func ValuesView[K any, V any](m Map[K, V], valueEqualer Equaler[V]) Collection[V] {
+	return &valuesView[K, V]{m: m, valueEqualer: valueEqualer}
+}
+
+type keysView[K any, V any] struct {
+	m Map[K, V]
+}
+
+func (k *keysView[K, V]) Add(key K) (oldItem K, replaced bool) {
+	panic(fmt.Errorf("can't add a key to KeysView"))
+}
+
+func (k *keysView[K, V]) RemoveFirst(key K) bool {
+	_, exists := k.m.Remove(key)
+	return exists
+}
+
+func (k *keysView[K, V]) TryPop() (key K, exists bool) {
+	pair, exists := k.m.TryPop()
+	return pair.Key, exists
+}
+
+func (k *keysView[K, V]) Pop() K {
+	return mustPop[K](k, "KeysView")
+}
+
+func (k *keysView[K, V]) Has(key K) bool {
+	return k.m.ContainsKey(key)
+}
+
+func (k *keysView[K, V]) Contains(key K) bool {
+	return k.Has(key)
+}
+
+func (k *keysView[K, V]) Len() int {
+	return k.m.Len()
+}
+
+func (k *keysView[K, V]) Clear() {
+	k.m.Clear()
+}
+
+func (k *keysView[K, V]) ToArray() []K {
+	pairs := k.m.ToArray()
+	result := make([]K, len(pairs))
+	for i, pair := range pairs {
+		result[i] = pair.Key
+	}
+	return result
+}
+
+func (k *keysView[K, V]) Range(f func(key K) bool) {
+	k.m.Range(func(pair Pair[K, V]) bool {
+		return f(pair.Key)
+	})
+}
+
+func (k *keysView[K, V]) All() func(yield func(K) bool) {
+	return k.Range
+}
+
+func (k *keysView[K, V]) Clone() Collection[K] {
+	return KeysView(CloneMap(k.m))
+}
+
+type valuesView[K any, V any] struct {
+	m            Map[K, V]
+	valueEqualer Equaler[V]
+}
+
+func (v *valuesView[K, V]) Add(value V) (oldItem V, replaced bool) {
+	panic(fmt.Errorf("can't add a value to ValuesView"))
+}
+
+func (v *valuesView[K, V]) RemoveFirst(value V) bool {
+	var key K
+	found := false
+	v.m.Range(func(pair Pair[K, V]) bool {
+		if v.valueEqualer(pair.Value, value) {
+			key = pair.Key
+			found = true
+		}
+		return !found
+	})
+	return found && v.m.RemoveIf(key, func(current V) bool {
+		return v.valueEqualer(current, value)
+	})
+}
+
+func (v *valuesView[K, V]) TryPop() (value V, exists bool) {
+	pair, exists := v.m.TryPop()
+	return pair.Value, exists
+}
+
+func (v *valuesView[K, V]) Pop() V {
+	return mustPop[V](v, "ValuesView")
+}
+
+func (v *valuesView[K, V]) Has(value V) bool {
+	found := false
+	v.m.Range(func(pair Pair[K, V]) bool {
+		found = v.valueEqualer(pair.Value, value)
+		return !found
+	})
+	return found
+}
+
+func (v *valuesView[K, V]) Contains(value V) bool {
+	return v.Has(value)
+}
+
+func (v *valuesView[K, V]) Len() int {
+	return v.m.Len()
+}
+
+func (v *valuesView[K, V]) Clear() {
+	v.m.Clear()
+}
+
+func (v *valuesView[K, V]) ToArray() []V {
+	pairs := v.m.ToArray()
+	result := make([]V, len(pairs))
+	for i, pair := range pairs {
+		result[i] = pair.Value
+	}
+	return result
+}
+
+func (v *valuesView[K, V]) Range(f func(value V) bool) {
+	v.m.Range(func(pair Pair[K, V]) bool {
+		return f(pair.Value)
+	})
+}
+
+func (v *valuesView[K, V]) All() func(yield func(V) bool) {
+	return v.Range
+}
+
+func (v *valuesView[K, V]) Clone() Collection[V] {
+	return ValuesView(CloneMap(v.m), v.valueEqualer)
+}
@@ -0,0 +1,122 @@
+package collection_test
+
+import (
+	"sort"
+
+	. "github.com/linxiaokun528/go-kit/pkg/util/collection"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Views of Map", func() {
+	var m Map[int, string]
+
+	BeforeEach(func() {
+		m = NewMap[int, string, int](basicHasher[int], basicEquator[int])
+		m.Put(1, "a")
+		m.Put(2, "b")
+		m.Put(3, "a")
+	})
+
+	sortedStrings := func(items []string) []string {
+		sort.Strings(items)
+		return items
+	}
+
+	It("AsCollection returns the map itself.", func() {
+		c := AsCollection(m)
+		Expect(c.Len()).To(Equal(3))
+		Expect(c.Has(Pair[int, string]{Key: 1, Value: "a"})).To(BeTrue())
+	})
+
+	Describe("KeysView", func() {
+		It("reflects the changes of the map.", func() {
+			keys := KeysView(m)
+			Expect(ToSortedSlice(keys, intAscComparator)).To(Equal([]int{1, 2, 3}))
+
+			m.Put(4, "c")
+			m.Remove(1)
+			Expect(keys.Len()).To(Equal(3))
+			Expect(keys.Has(4)).To(BeTrue())
+			Expect(keys.Contains(1)).To(BeFalse())
+			Expect(ToSortedSlice(keys, intAscComparator)).To(Equal([]int{2, 3, 4}))
+		})
+
+		It("removes the keys from the map.", func() {
+			keys := KeysView(m)
+			Expect(keys.RemoveFirst(2)).To(BeTrue())
+			Expect(keys.RemoveFirst(2)).To(BeFalse())
+			Expect(m.ContainsKey(2)).To(BeFalse())
+
+			key := keys.Pop()
+			Expect(m.ContainsKey(key)).To(BeFalse())
+			Expect(m.Len()).To(Equal(1))
+
+			keys.Clear()
+			Expect(m.Len()).To(Equal(0))
+			Expect(func() { keys.Pop() }).To(PanicWith(ContainSubstring("Pop from an empty KeysView")))
+		})
+
+		It("can't add keys.", func() {
+			Expect(func() { KeysView(m).Add(4) }).To(Panic())
+		})
+
+		It("works with the functions of Set.", func() {
+			set := NewSetFromSlice[int, int]([]int{3, 4}, basicHasher[int], basicEquator[int])
+			Expect(AddAllFrom(set, KeysView(m))).To(Equal(2))
+			Expect(ToSortedSlice(set, intAscComparator)).To(Equal([]int{1, 2, 3, 4}))
+		})
+
+		It("clones a view of a clone of the map.", func() {
+			cloned := KeysView(m).Clone()
+			m.Remove(1)
+			Expect(cloned.Has(1)).To(BeTrue())
+			Expect(cloned.RemoveFirst(2)).To(BeTrue())
+			Expect(m.ContainsKey(2)).To(BeTrue())
+		})
+	})
+
+	Describe("ValuesView", func() {
+		It("reflects the changes of the map.", func() {
+			values := ValuesView(m, basicEquator[string])
+			Expect(sortedStrings(values.ToArray())).To(Equal([]string{"a", "a", "b"}))
+
+			m.Put(2, "c")
+			Expect(values.Has("b")).To(BeFalse())
+			Expect(values.Contains("c")).To(BeTrue())
+
+			var ranged []string
+			values.All()(func(value string) bool {
+				ranged = append(ranged, value)
+				return true
+			})
+			Expect(sortedStrings(ranged)).To(Equal([]string{"a", "a", "c"}))
+		})
+
+		It("removes the first key with the value from the map.", func() {
+			values := ValuesView(m, basicEquator[string])
+			Expect(values.RemoveFirst("a")).To(BeTrue())
+			Expect(m.Len()).To(Equal(2))
+			Expect(values.RemoveFirst("a")).To(BeTrue())
+			Expect(values.RemoveFirst("a")).To(BeFalse())
+			Expect(m.ToArray()).To(Equal([]Pair[int, string]{{Key: 2, Value: "b"}}))
+
+			Expect(values.Pop()).To(Equal("b"))
+			Expect(m.Len()).To(Equal(0))
+			_, exists := values.TryPop()
+			Expect(exists).To(BeFalse())
+		})
+
+		It("can't add values.", func() {
+			Expect(func() { ValuesView(m, basicEquator[string]).Add("d") }).To(Panic())
+		})
+
+		It("clones a view of a clone of the map.", func() {
+			values := ValuesView(m, basicEquator[string])
+			cloned := values.Clone()
+			values.Clear()
+			Expect(m.Len()).To(Equal(0))
+			Expect(cloned.Len()).To(Equal(3))
+		})
+	})
+})
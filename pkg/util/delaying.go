@@ -1,8 +1,9 @@
 package util
 
 import (
-	"reflect"
-	"runtime"
+	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -13,18 +14,177 @@ import (
 
 // adapted from k8s.io/client-go@v0.22.2/util/workqueue/delaying_queue.go
 
+// ErrShutDown is returned by TryExecuteAfter if the DelayingExecutor has been shut down
+var ErrShutDown = errors.New("the executor has been shut down")
+
+// ErrExecutorFull is returned by TryExecuteAfter if the backlog is full and the policy is RejectOnOverflow
+var ErrExecutorFull = errors.New("the backlog of the executor is full")
+
+// ErrChannelClosed is returned by DelayingChannel.GetWithContext after the channel is closed and all the items are got
+var ErrChannelClosed = errors.New("the delaying channel has been closed")
+
+// OverflowPolicy decides what ExecuteAfter does when the backlog of a DelayingExecutor is full.
+// The backlog holds the tasks that are accepted but not scheduled by the executor yet.
+type OverflowPolicy int
+
+const (
+	// BlockOnOverflow blocks until there is room in the backlog
+	BlockOnOverflow OverflowPolicy = iota
+	// RejectOnOverflow returns ErrExecutorFull
+	RejectOnOverflow
+	// DropOldestOnOverflow cancels the oldest task in the backlog to make room
+	DropOldestOnOverflow
+	// GrowOnOverflow grows the backlog without limit
+	GrowOnOverflow
+)
+
+// DelayingOption configures a DelayingExecutor
+type DelayingOption func(*DelayingExecutor)
+
+// TaskError is passed to the TaskErrorHandler when a task of a DelayingExecutor panics
+type TaskError struct {
+	// ID is the same as TaskHandle.ID
+	ID uint64
+	// Label is the label given by ExecuteAfterWithLabel, or empty
+	Label string
+	// ReadyAt is when the task is scheduled to be executed
+	ReadyAt time.Time
+	// Value is what the task panics with
+	Value any
+}
+
+func (t *TaskError) Error() string {
+	if t.Label == "" {
+		return fmt.Sprintf("task %d scheduled at %v panicked: %v", t.ID, t.ReadyAt, t.Value)
+	}
+	return fmt.Sprintf("task %d (%s) scheduled at %v panicked: %v", t.ID, t.Label, t.ReadyAt, t.Value)
+}
+
+// Unwrap returns Value if it's an error
+func (t *TaskError) Unwrap() error {
+	err, _ := t.Value.(error)
+	return err
+}
+
+// TaskErrorHandler handles the panics of the tasks of a DelayingExecutor
+type TaskErrorHandler func(err *TaskError)
+
+// WithExecutorPanicHandler makes the executor invoke panicHandler with what a task panics with.
+// By default, the panics are ignored.
+func WithExecutorPanicHandler(panicHandler PanicHandler) DelayingOption {
+	return func(d *DelayingExecutor) {
+		d.panicHandler = panicHandler
+	}
+}
+
+// WithTaskErrorHandler makes the executor invoke errorHandler when a task panics.
+// Unlike WithExecutorPanicHandler, the error tells which task panics.
+func WithTaskErrorHandler(errorHandler TaskErrorHandler) DelayingOption {
+	return func(d *DelayingExecutor) {
+		d.errorHandler = errorHandler
+	}
+}
+
+// WithWorkers makes the executor execute the ready tasks with workerNum workers, instead of a new goroutine for
+// every task. The ready tasks are dispatched to the workers in the order of their readyAt.
+func WithWorkers(workerNum int) DelayingOption {
+	if workerNum <= 0 {
+		panic(fmt.Errorf("workerNum should be positive"))
+	}
+
+	return func(d *DelayingExecutor) {
+		d.workerNum = workerNum
+	}
+}
+
+// WithClock makes the executor use clock instead of the real clock, e.g. a fake clock in the tests
+func WithClock(clock clock.Clock) DelayingOption {
+	return func(d *DelayingExecutor) {
+		d.clock = clock
+	}
+}
+
+// WithOverflowPolicy sets what to do when the backlog is full. The default is BlockOnOverflow.
+func WithOverflowPolicy(policy OverflowPolicy) DelayingOption {
+	return func(d *DelayingExecutor) {
+		d.overflowPolicy = policy
+	}
+}
+
 type executableFunc func()
 
+const (
+	taskPending uint32 = iota
+	taskExecuted
+	taskCanceled
+)
+
 // waitFor holds the executee to add and the time it should be executed
 type waitFor struct {
+	id       uint64
 	function executableFunc
-	readyAt  time.Time
+	// label is only used in TaskError
+	label string
+	// onCancel is called if the task is canceled by TaskHandle.Cancel or dropped by DropOldestOnOverflow,
+	// but not if it's dropped by ShutDownFast. It can be nil.
+	onCancel func()
+	// periodic is true for the executions of ExecuteEvery, which are never rejected or dropped by the overflow
+	// policies, or the periodic task would stop silently
+	periodic bool
+	// state is one of taskPending, taskExecuted and taskCanceled
+	state uint32
+	// readyAt, inQueue and scheduled are guarded by queueLock of the executor
+	readyAt time.Time
+	// inQueue is true if the entry is in the priority queue
+	inQueue bool
+	// scheduled is true if the entry has been taken from waitingForAddCh
+	scheduled bool
 }
 
 func waitForComparator(first, second *waitFor) bool {
 	return first.readyAt.Before(second.readyAt)
 }
 
+// ExecutorHooks Callbacks for the lifecycle events of the tasks in a DelayingExecutor.
+//  All the hooks are called synchronously from the goroutine of the DelayingExecutor, so they should return quickly.
+//  A nil hook is a no-op.
+type ExecutorHooks struct {
+	// OnSchedule is called when a task is accepted by the executor
+	OnSchedule func(id uint64, readyAt time.Time)
+	// OnExecute is called when a task is about to be executed
+	OnExecute func(id uint64)
+	// OnCancel is called when a pending task is dropped by ShutDownFast,
+	//  or in the goroutine of TaskHandle.Cancel when the task is canceled,
+	//  or in the goroutine of ExecuteAfter when the task is dropped by DropOldestOnOverflow
+	OnCancel func(id uint64)
+	// OnShutdown is called when the executor stops
+	OnShutdown func()
+}
+
+func (h ExecutorHooks) onSchedule(id uint64, readyAt time.Time) {
+	if h.OnSchedule != nil {
+		h.OnSchedule(id, readyAt)
+	}
+}
+
+func (h ExecutorHooks) onExecute(id uint64) {
+	if h.OnExecute != nil {
+		h.OnExecute(id)
+	}
+}
+
+func (h ExecutorHooks) onCancel(id uint64) {
+	if h.OnCancel != nil {
+		h.OnCancel(id)
+	}
+}
+
+func (h ExecutorHooks) onShutdown() {
+	if h.OnShutdown != nil {
+		h.OnShutdown()
+	}
+}
+
 type DelayingExecutor struct {
 	// waitingForAddCh is a buffered channel that feeds waitingForAdd
 	waitingForAddCh          chan *waitFor
@@ -35,16 +195,48 @@ type DelayingExecutor struct {
 	closeStopChOnce          sync.Once
 	closeSlowStopChOnce      sync.Once
 	closeWaitingForAddChOnce sync.Once
+	lastID                   uint64
+	hooks                    atomic.Value // ExecutorHooks
+
+	// queueLock guards priorityQueue and the fields of its entries, because TaskHandle and the shutdown can change
+	// them outside the waitingLoop.
+	queueLock sync.Mutex
+	// wakeCh wakes up the waitingLoop when the first entry may have changed
+	wakeCh chan struct{}
+	// pending is the number of the tasks that are neither executed nor canceled
+	pending int64
+	metrics atomic.Value // metricsHolder
+	// addLock makes sure no tasks are sent to waitingForAddCh after it's closed
+	addLock sync.RWMutex
+	// isDraining is guarded by addLock. It's true after ShutDownWithDrain is called.
+	isDraining bool
+
+	overflowPolicy OverflowPolicy
+	// overflow holds the tasks that can't be put into waitingForAddCh with GrowOnOverflow
+	overflow     []*waitFor
+	overflowLock sync.Mutex
+
+	// workerNum is 0 if every task is executed in a new goroutine
+	workerNum int
+	// readyQueue holds the ready tasks for the workers
+	readyQueue collection.BlockingPriorityQueue[*waitFor]
+
+	panicHandler PanicHandler
+	errorHandler TaskErrorHandler
+
+	// store is nil if the tasks are not persisted
+	store          TaskStore
+	payloadHandler PayloadHandler
+	lastStoredID   uint64
 }
 
-func NewDelayingExecutor(size int) *DelayingExecutor {
+// NewDelayingExecutor returns a DelayingExecutor whose backlog has the capacity of size
+func NewDelayingExecutor(size int, opts ...DelayingOption) *DelayingExecutor {
 	priorityQueue := collection.NewPriorityQueue[*waitFor](waitForComparator,
 		func(first, second *waitFor) bool {
-			return first.readyAt == second.readyAt &&
-				// Can't simply use `first.function == second.function`,
-				// which will encounter "func can only be compared to nil"
-				reflect.ValueOf(first).Pointer() != reflect.ValueOf(second).Pointer()
-		})
+			// Every entry is a different task, so only the same pointer is equal
+			return first == second
+		}, collection.WithPooling())
 
 	executor := &DelayingExecutor{
 		// Don't need to close the channel, or we may get "panic: send on closed channel"
@@ -53,33 +245,432 @@ func NewDelayingExecutor(size int) *DelayingExecutor {
 		stopCh:          make(chan struct{}),
 		slowStopCh:      make(chan struct{}),
 		priorityQueue:   priorityQueue,
+		wakeCh:          make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(executor)
+	}
+	if executor.workerNum > 0 {
+		executor.startWorkers()
 	}
 
 	go executor.waitingLoop()
 	return executor
 }
 
-func (d *DelayingExecutor) ExcuteAfter(f func(), duration time.Duration) {
-	runtimeErr := runtimeError("Executor has been shutted down!")
-	defer func() {
-		if err := recover(); err != nil {
-			plainErr, isPlainError := err.(runtime.Error)
-			if isPlainError {
-				if plainErr.Error() == "send on closed channel" {
-					panic(runtimeErr)
-				}
-			}
+// WithHooks sets the hooks of the executor and returns the executor.
+//  Only the events that happen after WithHooks returns are guaranteed to be observed.
+func WithHooks(executor *DelayingExecutor, hooks ExecutorHooks) *DelayingExecutor {
+	executor.hooks.Store(hooks)
+	return executor
+}
 
-			panic(err)
-		}
-	}()
+func (d *DelayingExecutor) loadHooks() ExecutorHooks {
+	hooks, _ := d.hooks.Load().(ExecutorHooks)
+	return hooks
+}
+
+// ExecutorMetrics receives the metrics of a DelayingExecutor, e.g. to export them to Prometheus.
+//  The methods are called synchronously, so they should return quickly.
+type ExecutorMetrics interface {
+	// OnEnqueue is called in the goroutine of ExecuteAfter with the number of pending tasks after a task is accepted
+	OnEnqueue(pending int)
+	// OnExecute is called with the number of pending tasks after a task is taken to be executed
+	OnExecute(pending int)
+	// OnLatency is called with how late a task is taken to be executed compared with its readyAt
+	OnLatency(latency time.Duration)
+}
+
+// metricsHolder wraps ExecutorMetrics, because atomic.Value requires the values to be of the same concrete type
+type metricsHolder struct {
+	metrics ExecutorMetrics
+}
 
+// WithMetrics sets the metrics of the executor and returns the executor. A nil metrics disables the metrics.
+func WithMetrics(executor *DelayingExecutor, metrics ExecutorMetrics) *DelayingExecutor {
+	executor.metrics.Store(metricsHolder{metrics: metrics})
+	return executor
+}
+
+func (d *DelayingExecutor) loadMetrics() ExecutorMetrics {
+	holder, _ := d.metrics.Load().(metricsHolder)
+	return holder.metrics
+}
+
+// Len returns the number of the tasks that are neither executed nor canceled
+func (d *DelayingExecutor) Len() int {
+	return int(atomic.LoadInt64(&d.pending))
+}
+
+// Backlog returns the number of the tasks that are accepted but not scheduled by the executor yet
+func (d *DelayingExecutor) Backlog() int {
+	d.overflowLock.Lock()
+	defer d.overflowLock.Unlock()
+
+	return len(d.waitingForAddCh) + len(d.overflow)
+}
+
+// NextReadyAt returns when the first task in the queue is ready. It returns false if no tasks are in the queue.
+// The tasks that are just added by ExecuteAfter may not be in the queue yet.
+func (d *DelayingExecutor) NextReadyAt() (time.Time, bool) {
+	d.queueLock.Lock()
+	defer d.queueLock.Unlock()
+
+	if d.priorityQueue.Len() == 0 {
+		return time.Time{}, false
+	}
+	return d.priorityQueue.Peek().readyAt, true
+}
+
+// TaskHandle can cancel or reschedule a task of a DelayingExecutor before it's executed
+type TaskHandle struct {
+	executor *DelayingExecutor
+	entry    *waitFor
+}
+
+// ID returns the id of the task, which is passed to ExecutorHooks
+func (t *TaskHandle) ID() uint64 {
+	return t.entry.id
+}
+
+// Cancel removes the task if it hasn't been executed. It returns false if the task has been executed or canceled.
+func (t *TaskHandle) Cancel() bool {
+	if !atomic.CompareAndSwapUint32(&t.entry.state, taskPending, taskCanceled) {
+		return false
+	}
+
+	d := t.executor
+	atomic.AddInt64(&d.pending, -1)
+	d.queueLock.Lock()
+	if t.entry.inQueue {
+		d.priorityQueue.RemoveFirst(t.entry)
+		t.entry.inQueue = false
+	}
+	d.queueLock.Unlock()
+
+	d.loadHooks().onCancel(t.entry.id)
+	if t.entry.onCancel != nil {
+		t.entry.onCancel()
+	}
+	return true
+}
+
+// Reschedule makes the task execute newDelay later from now, instead of at the original time.
+// It returns false if the task has been executed or canceled, or is being drained by ShutDownWithDrain.
+func (t *TaskHandle) Reschedule(newDelay time.Duration) bool {
+	d := t.executor
+	d.queueLock.Lock()
+	defer d.queueLock.Unlock()
+
+	if atomic.LoadUint32(&t.entry.state) != taskPending {
+		return false
+	}
+	readyAt := d.clock.Now().Add(newDelay)
+	switch {
+	case t.entry.inQueue:
+		t.entry.readyAt = readyAt
+		d.priorityQueue.Fix(t.entry)
+	case !t.entry.scheduled:
+		// schedule will use the new readyAt
+		t.entry.readyAt = readyAt
+	default:
+		// It has been popped by drainPriorityQueue
+		return false
+	}
+
+	d.wake()
+	return true
+}
+
+// ExecuteAfter executes f after duration. It panics with ErrShutDown if the executor has been shut down.
+func (d *DelayingExecutor) ExecuteAfter(f func(), duration time.Duration) *TaskHandle {
+	handle, err := d.submit(f, duration, "", nil)
+	if err != nil {
+		panic(err)
+	}
+	return handle
+}
+
+// ExcuteAfter is the misspelled name of ExecuteAfter.
+//
+// Deprecated: use ExecuteAfter instead.
+func (d *DelayingExecutor) ExcuteAfter(f func(), duration time.Duration) *TaskHandle {
+	return d.ExecuteAfter(f, duration)
+}
+
+// TryExecuteAfter executes f after duration. It returns ErrShutDown instead of panicking if the executor has been
+// shut down, or ErrExecutorFull if the backlog is full with RejectOnOverflow.
+func (d *DelayingExecutor) TryExecuteAfter(f func(), duration time.Duration) error {
+	_, err := d.submit(f, duration, "", nil)
+	return err
+}
+
+// ExecuteAfterWithLabel is the same as ExecuteAfter, except that label is attached to the TaskError if f panics
+func (d *DelayingExecutor) ExecuteAfterWithLabel(label string, f func(), duration time.Duration) *TaskHandle {
+	handle, err := d.submit(f, duration, label, nil)
+	if err != nil {
+		panic(err)
+	}
+	return handle
+}
+
+func (d *DelayingExecutor) submit(f func(), duration time.Duration, label string,
+	onCancel func()) (*TaskHandle, error) {
+	return d.submitEntry(&waitFor{
+		function: f,
+		label:    label,
+		onCancel: onCancel,
+		readyAt:  d.clock.Now().Add(duration),
+	})
+}
+
+func (d *DelayingExecutor) submitEntry(entry *waitFor) (*TaskHandle, error) {
+	d.addLock.RLock()
+	defer d.addLock.RUnlock()
+
+	if d.isDraining {
+		return nil, ErrShutDown
+	}
 	select {
 	case <-d.stopCh:
-		panic(runtimeErr)
+		return nil, ErrShutDown
 	default:
-		d.waitingForAddCh <- &waitFor{function: f, readyAt: d.clock.Now().Add(duration)}
 	}
+
+	entry.id = atomic.AddUint64(&d.lastID, 1)
+	// Count it before sending, otherwise it may be executed before it's counted
+	pending := atomic.AddInt64(&d.pending, 1)
+	if err := d.send(entry); err != nil {
+		atomic.AddInt64(&d.pending, -1)
+		return nil, err
+	}
+	if metrics := d.loadMetrics(); metrics != nil {
+		metrics.OnEnqueue(int(pending))
+	}
+	return &TaskHandle{executor: d, entry: entry}, nil
+}
+
+// send puts entry into the backlog according to the overflow policy. It should be called with addLock held.
+func (d *DelayingExecutor) send(entry *waitFor) error {
+	if entry.periodic && (d.overflowPolicy == RejectOnOverflow || d.overflowPolicy == DropOldestOnOverflow) {
+		// Only the entries in waitingForAddCh can be dropped, so bypass it
+		d.overflowLock.Lock()
+		defer d.overflowLock.Unlock()
+
+		d.overflow = append(d.overflow, entry)
+		d.wake()
+		return nil
+	}
+
+	switch d.overflowPolicy {
+	case RejectOnOverflow:
+		select {
+		case d.waitingForAddCh <- entry:
+			return nil
+		default:
+			return ErrExecutorFull
+		}
+	case DropOldestOnOverflow:
+		for {
+			select {
+			case d.waitingForAddCh <- entry:
+				return nil
+			default:
+			}
+
+			select {
+			case oldest := <-d.waitingForAddCh:
+				d.drop(oldest)
+			default: // The waitingLoop has made room
+			}
+		}
+	case GrowOnOverflow:
+		d.overflowLock.Lock()
+		defer d.overflowLock.Unlock()
+
+		// Don't jump the queue if some tasks are in overflow
+		if len(d.overflow) == 0 {
+			select {
+			case d.waitingForAddCh <- entry:
+				return nil
+			default:
+			}
+		}
+		d.overflow = append(d.overflow, entry)
+		d.wake()
+		return nil
+	default:
+		select {
+		case <-d.stopCh:
+			// The waitingLoop may have returned, so waitingForAddCh may never be consumed
+			return ErrShutDown
+		case d.waitingForAddCh <- entry:
+			return nil
+		}
+	}
+}
+
+func (d *DelayingExecutor) drop(entry *waitFor) {
+	if atomic.CompareAndSwapUint32(&entry.state, taskPending, taskCanceled) {
+		atomic.AddInt64(&d.pending, -1)
+		d.loadHooks().onCancel(entry.id)
+		if entry.onCancel != nil {
+			entry.onCancel()
+		}
+	}
+}
+
+// wake wakes up the waitingLoop without blocking
+func (d *DelayingExecutor) wake() {
+	select {
+	case d.wakeCh <- struct{}{}:
+	default: // The waitingLoop will wake up anyway
+	}
+}
+
+// scheduleOverflow schedules the tasks in overflow
+func (d *DelayingExecutor) scheduleOverflow() {
+	d.overflowLock.Lock()
+	overflow := d.overflow
+	d.overflow = nil
+	d.overflowLock.Unlock()
+
+	d.scheduleAll(overflow)
+}
+
+// PeriodicOption configures a task of ExecuteEvery
+type PeriodicOption func(*periodicTask)
+
+// WithFixedDelay makes the next execution start interval after the previous one finishes.
+// By default, the executions start every interval regardless of how long they take, so they may overlap.
+func WithFixedDelay() PeriodicOption {
+	return func(t *periodicTask) {
+		t.fixedDelay = true
+	}
+}
+
+// WithTaskPanicHandler handles the panics of the task. By default, the panics are ignored.
+func WithTaskPanicHandler(panicHandler PanicHandler) PeriodicOption {
+	return func(t *periodicTask) {
+		t.panicHandler = panicHandler
+	}
+}
+
+type periodicTask struct {
+	executor     *DelayingExecutor
+	f            func()
+	interval     time.Duration
+	fixedDelay   bool
+	panicHandler PanicHandler
+	// readyAt is when the next execution should start. It's only used in the fixed-rate mode.
+	readyAt time.Time
+
+	// lock guards canceled and handle
+	lock     sync.Mutex
+	canceled bool
+	// handle is the handle of the next execution
+	handle *TaskHandle
+}
+
+// ExecuteEvery executes f every interval, starting interval later. The returned cancel stops the following
+// executions, but doesn't wait for the running one. The task also stops when the executor is shut down.
+// The executions are never rejected or dropped by RejectOnOverflow or DropOldestOnOverflow, so they may exceed the
+// backlog like GrowOnOverflow.
+func (d *DelayingExecutor) ExecuteEvery(f func(), interval time.Duration, opts ...PeriodicOption) (cancel func()) {
+	if interval <= 0 {
+		panic(fmt.Errorf("interval should be positive"))
+	}
+
+	task := &periodicTask{
+		executor: d,
+		f:        f,
+		interval: interval,
+		readyAt:  d.clock.Now().Add(interval),
+	}
+	for _, opt := range opts {
+		opt(task)
+	}
+
+	if err := task.schedule(task.readyAt); err != nil {
+		panic(err)
+	}
+	return task.cancel
+}
+
+// cancel cancels the next execution, which is still counted by Len and waited by ShutDownWithDrain otherwise
+func (t *periodicTask) cancel() {
+	t.lock.Lock()
+	t.canceled = true
+	handle := t.handle
+	t.lock.Unlock()
+
+	if handle != nil {
+		handle.Cancel()
+	}
+}
+
+// schedule returns ErrShutDown if the executor has been shut down
+func (t *periodicTask) schedule(readyAt time.Time) error {
+	d := t.executor
+	handle, err := d.submitEntry(&waitFor{
+		function: t.run,
+		readyAt:  readyAt,
+		periodic: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	t.lock.Lock()
+	t.handle = handle
+	canceled := t.canceled
+	t.lock.Unlock()
+	if canceled { // cancel may have missed the new handle
+		handle.Cancel()
+	}
+	return nil
+}
+
+func (t *periodicTask) isCanceled() bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.canceled
+}
+
+func (t *periodicTask) run() {
+	if t.isCanceled() {
+		return
+	}
+
+	if !t.fixedDelay {
+		t.readyAt = t.readyAt.Add(t.interval)
+		if t.schedule(t.readyAt) != nil {
+			return
+		}
+	}
+
+	t.invoke()
+
+	if t.fixedDelay && !t.isCanceled() {
+		_ = t.schedule(t.executor.clock.Now().Add(t.interval))
+	}
+}
+
+func (t *periodicTask) invoke() {
+	defer func() {
+		recover() // in case a panic happens while handling panics
+	}()
+
+	if t.panicHandler != nil {
+		defer func() {
+			if r := recover(); r != nil {
+				t.panicHandler(r)
+			}
+		}()
+	}
+
+	t.f()
 }
 
 func (d *DelayingExecutor) waitingLoop() {
@@ -89,8 +680,14 @@ func (d *DelayingExecutor) waitingLoop() {
 	// Make a timer that expires when the item at the head of the waiting list is ready
 	var nextReadyAtTimer clock.Timer
 
+	defer func() {
+		d.loadHooks().onShutdown()
+	}()
+
 	for {
 		now := d.clock.Now()
+		var readyEntries []*waitFor
+		d.queueLock.Lock()
 		// Add ready entries
 		for d.priorityQueue.Len() > 0 {
 			entry := d.priorityQueue.Peek()
@@ -99,57 +696,82 @@ func (d *DelayingExecutor) waitingLoop() {
 			}
 
 			entry, _ = d.priorityQueue.TryPop()
-			go d.executeIgnorePanic(entry.function)
+			entry.inQueue = false
+			readyEntries = append(readyEntries, entry)
 		}
 
 		// Set up a wait for the first item's readyAt (if one exists)
 		nextReadyAt := never
+		if nextReadyAtTimer != nil {
+			nextReadyAtTimer.Stop()
+		}
 		if d.priorityQueue.Len() > 0 {
-			if nextReadyAtTimer != nil {
-				nextReadyAtTimer.Stop()
-			}
 			entry := d.priorityQueue.Peek()
 			nextReadyAtTimer = d.clock.NewTimer(entry.readyAt.Sub(now))
 			nextReadyAt = nextReadyAtTimer.C()
 		}
+		d.queueLock.Unlock()
+
+		// Don't hold queueLock while calling the hooks
+		for _, entry := range readyEntries {
+			d.execute(entry)
+		}
 
 		select {
 		case <-d.stopCh:
+			d.cancelPriorityQueue()
 			return
 		case <-nextReadyAt:
+		case <-d.wakeCh:
+			d.scheduleOverflow()
 		case waitEntry := <-d.waitingForAddCh:
 			if waitEntry == nil { // d.waitingForAddCh is closed
+				d.scheduleOverflow()
 				d.drainPriorityQueue()
 				d.closeSlowStopChOnce.Do(func() {
-					// Can't use d.stopCh here, because in drainPriorityQueue, we have the following snippet,
-					// which prevent the remaining tasks from being executed
-					// select {
-					// case <-d.stopCh:
-					// 	return
-					// 	...
-					// }
+					// Can't close d.stopCh here, which prevents the executing tasks from being executed
+					// by executeIgnorePanic
 					close(d.slowStopCh)
 				})
 				return
 			}
-			if waitEntry.readyAt.After(d.clock.Now()) {
-				d.priorityQueue.Add(waitEntry)
-			} else {
-				go d.executeIgnorePanic(waitEntry.function)
-			}
+			d.schedule(waitEntry)
 
 			d.drainWaitingForAddCh()
 		}
 	}
 }
 
+// drainPriorityQueue executes the remaining entries when they are ready. If ShutDownFast is called meanwhile, it stops
+// waiting and cancels the remaining entries.
 func (d *DelayingExecutor) drainPriorityQueue() {
-	for entry, exists := d.priorityQueue.TryPop(); exists; entry, exists = d.priorityQueue.TryPop() {
-		nextReadyAtTimer := d.clock.NewTimer(entry.readyAt.Sub(time.Now()))
+	for entry, exists := d.popEntry(); exists; entry, exists = d.popEntry() {
+		nextReadyAtTimer := d.clock.NewTimer(entry.readyAt.Sub(d.clock.Now()))
 		select {
 		case <-nextReadyAtTimer.C():
+			d.execute(entry)
+		case <-d.stopCh:
 			nextReadyAtTimer.Stop()
-			go d.executeIgnorePanic(entry.function)
+			d.cancel(entry, d.loadHooks())
+			d.cancelPriorityQueue()
+			return
+		}
+	}
+}
+
+// popEntry pops the first entry which is still pending
+func (d *DelayingExecutor) popEntry() (*waitFor, bool) {
+	d.queueLock.Lock()
+	defer d.queueLock.Unlock()
+
+	for {
+		entry, exists := d.priorityQueue.TryPop()
+		if !exists {
+			return nil, false
+		}
+		entry.inQueue = false
+		if atomic.LoadUint32(&entry.state) == taskPending {
+			return entry, true
 		}
 	}
 }
@@ -163,35 +785,193 @@ func (d *DelayingExecutor) drainWaitingForAddCh() {
 			if waitEntry == nil { // d.waitingForAddCh is closed
 				return
 			}
-			if waitEntry.readyAt.After(d.clock.Now()) {
-				d.priorityQueue.Add(waitEntry)
-			} else {
-				go d.executeIgnorePanic(waitEntry.function)
+			d.schedule(waitEntry)
+		default:
+			return
+		}
+	}
+}
+
+func (d *DelayingExecutor) schedule(waitEntry *waitFor) {
+	d.scheduleAll([]*waitFor{waitEntry})
+}
+
+// scheduleAll adds the delayed entries to the priority queue at once, and executes the ready ones
+func (d *DelayingExecutor) scheduleAll(waitEntries []*waitFor) {
+	scheduled := make([]*waitFor, 0, len(waitEntries))
+	// readyAts are the readyAt of the scheduled entries, which may be changed by TaskHandle.Reschedule after unlocking
+	readyAts := make([]time.Time, 0, len(waitEntries))
+	delayed := make([]*waitFor, 0, len(waitEntries))
+	var ready []*waitFor
+
+	d.queueLock.Lock()
+	now := d.clock.Now()
+	for _, waitEntry := range waitEntries {
+		if atomic.LoadUint32(&waitEntry.state) != taskPending {
+			// Canceled by TaskHandle before it's scheduled
+			continue
+		}
+		waitEntry.scheduled = true
+		scheduled = append(scheduled, waitEntry)
+		readyAts = append(readyAts, waitEntry.readyAt)
+		if waitEntry.readyAt.After(now) {
+			waitEntry.inQueue = true
+			delayed = append(delayed, waitEntry)
+		} else {
+			ready = append(ready, waitEntry)
+		}
+	}
+	d.priorityQueue.AddAll(delayed)
+	d.queueLock.Unlock()
+
+	hooks := d.loadHooks()
+	for i, waitEntry := range scheduled {
+		hooks.onSchedule(waitEntry.id, readyAts[i])
+	}
+	for _, waitEntry := range ready {
+		d.execute(waitEntry)
+	}
+}
+
+func (d *DelayingExecutor) startWorkers() {
+	d.readyQueue = collection.NewBlockingPriorityQueue[*waitFor](0, waitForComparator,
+		func(first, second *waitFor) bool {
+			return first == second
+		})
+	// Not using the context of the processor, which stops the workers before the remaining tasks are executed
+	stopCtx, stop := context.WithCancel(context.Background())
+	workers := NewParallelProcessor(func(ctx context.Context) bool {
+		entry, err := d.readyQueue.Pop(stopCtx)
+		if err != nil {
+			// The executor has stopped, but the remaining ready tasks should still be executed.
+			// executeIgnorePanic skips them after ShutDownFast.
+			var exists bool
+			if entry, exists = d.readyQueue.TryPop(); !exists {
+				return false
+			}
+		}
+		d.executeIgnorePanic(entry)
+		return true
+	}, nil)
+	workers.StartAsync(d.workerNum, context.Background())
+
+	go func() {
+		// No more tasks will be ready after slowStopCh is closed
+		<-d.slowStopCh
+		stop()
+	}()
+}
+
+func (d *DelayingExecutor) execute(waitEntry *waitFor) {
+	if !atomic.CompareAndSwapUint32(&waitEntry.state, taskPending, taskExecuted) {
+		// Canceled by TaskHandle
+		return
+	}
+	pending := atomic.AddInt64(&d.pending, -1)
+	if metrics := d.loadMetrics(); metrics != nil {
+		metrics.OnExecute(int(pending))
+		metrics.OnLatency(d.clock.Since(waitEntry.readyAt))
+	}
+	d.loadHooks().onExecute(waitEntry.id)
+	if d.readyQueue != nil {
+		// Never blocks, because readyQueue is unbounded
+		d.readyQueue.TryAdd(waitEntry)
+		return
+	}
+	go d.executeIgnorePanic(waitEntry)
+}
+
+func (d *DelayingExecutor) cancelPriorityQueue() {
+	hooks := d.loadHooks()
+	for entry, exists := d.popEntry(); exists; entry, exists = d.popEntry() {
+		d.cancel(entry, hooks)
+	}
+}
+
+// cancelBacklog cancels the tasks in waitingForAddCh and overflow, which the waitingLoop won't schedule after stopCh
+// is closed
+func (d *DelayingExecutor) cancelBacklog() {
+	hooks := d.loadHooks()
+	d.overflowLock.Lock()
+	overflow := d.overflow
+	d.overflow = nil
+	d.overflowLock.Unlock()
+	for _, entry := range overflow {
+		d.cancel(entry, hooks)
+	}
+
+	for {
+		select {
+		case entry, ok := <-d.waitingForAddCh:
+			if !ok { // Closed by ShutDownWithDrain
+				return
 			}
+			d.cancel(entry, hooks)
 		default:
 			return
 		}
 	}
 }
 
-func (d *DelayingExecutor) executeIgnorePanic(executableFunc func()) {
+// cancel cancels the entry on ShutDownFast, so entry.onCancel is not called
+func (d *DelayingExecutor) cancel(entry *waitFor, hooks ExecutorHooks) {
+	if atomic.CompareAndSwapUint32(&entry.state, taskPending, taskCanceled) {
+		atomic.AddInt64(&d.pending, -1)
+		hooks.onCancel(entry.id)
+	}
+}
+
+func (d *DelayingExecutor) executeIgnorePanic(waitEntry *waitFor) {
 	select {
 	case <-d.stopCh:
 		return
 	default:
 		defer func() {
 			if r := recover(); r != nil {
-
+				d.handlePanic(waitEntry, r)
 			}
 		}()
 
-		executableFunc()
+		waitEntry.function()
+	}
+}
+
+func (d *DelayingExecutor) handlePanic(waitEntry *waitFor, r any) {
+	if d.panicHandler != nil {
+		ignorePanic(func() { d.panicHandler(r) })
+	}
+	if d.errorHandler != nil {
+		ignorePanic(func() {
+			d.errorHandler(&TaskError{
+				ID:      waitEntry.id,
+				Label:   waitEntry.label,
+				ReadyAt: waitEntry.readyAt,
+				Value:   r,
+			})
+		})
 	}
 }
 
+// ignorePanic is used to call the handlers, in case a panic happens while handling panics
+func ignorePanic(f func()) {
+	defer func() {
+		recover()
+	}()
+
+	f()
+}
+
+// ShutDownFast rejects new tasks and cancels the tasks that have not been executed, including the ones being drained by
+// ShutDownWithDrain. It unblocks the callers of ShutDownWithDrain.
+// ShutDownFast and ShutDownWithDrain can be called multiple times and concurrently from multiple goroutines.
 func (d *DelayingExecutor) ShutDownFast() {
 	d.closeStopChOnce.Do(func() { // In case of "close of closed channel"
 		close(d.stopCh)
+		// Wait for the ongoing submits, which either fail or finish sending after stopCh is closed,
+		// so no more tasks will be added to the backlog
+		d.addLock.Lock()
+		d.addLock.Unlock()
+		d.cancelBacklog()
 	})
 
 	d.closeSlowStopChOnce.Do(func() {
@@ -203,11 +983,15 @@ func (d *DelayingExecutor) ShutDownFast() {
 // be executed eventually.
 // it can only guarantee that all tasks will be executed eventually after it returns.
 // After the return, some tasks may not have finished and some tasks may not even begin.
+// If block is true, every caller blocks until all the tasks are drained, or until ShutDownFast is called.
 func (d *DelayingExecutor) ShutDownWithDrain(block bool) {
 	d.closeWaitingForAddChOnce.Do(func() {
 		// To to make sure after ShutDownWithDrain no tasks will be added to it thread-safely,
-		// we can either close d.waitingForAddCh  or use a lock and a flag(let's say `isShutingDownWithDrain bool`)
+		// submit checks isDraining with addLock held
+		d.addLock.Lock()
+		d.isDraining = true
 		close(d.waitingForAddCh)
+		d.addLock.Unlock()
 	})
 	if block {
 		<-d.slowStopCh
@@ -222,9 +1006,9 @@ type DelayingChannel[T any] struct {
 	remainingTasks int64
 }
 
-func NewDelayingChannel[T any](size int) *DelayingChannel[T] {
+func NewDelayingChannel[T any](size int, opts ...DelayingOption) *DelayingChannel[T] {
 	return &DelayingChannel[T]{
-		executor:       NewDelayingExecutor(size),
+		executor:       NewDelayingExecutor(size, opts...),
 		ch:             make(chan T, size),
 		isClosed:       false,
 		closedLock:     &sync.Mutex{},
@@ -232,16 +1016,89 @@ func NewDelayingChannel[T any](size int) *DelayingChannel[T] {
 	}
 }
 
+// Get blocks until an item is ready. It returns the zero value after the channel is closed and all the items are got.
 func (d *DelayingChannel[T]) Get() T {
 	return <-d.ch
 }
 
+// GetWithContext blocks until an item is ready or ctx is done. If ctx is done, ctx.Err() will be returned.
+// It returns ErrChannelClosed after the channel is closed and all the items are got.
+func (d *DelayingChannel[T]) GetWithContext(ctx context.Context) (item T, err error) {
+	select {
+	case <-ctx.Done():
+		return item, ctx.Err()
+	case item, ok := <-d.ch:
+		if !ok {
+			return item, ErrChannelClosed
+		}
+		return item, nil
+	}
+}
+
+// TryGet returns false immediately if no items are ready, or the channel is closed and all the items are got
+func (d *DelayingChannel[T]) TryGet() (item T, ok bool) {
+	select {
+	case item, ok = <-d.ch:
+		return item, ok
+	default:
+		return item, false
+	}
+}
+
 func (d *DelayingChannel[T]) AddAfter(entry T, duration time.Duration) {
+	d.add(func() T {
+		return entry
+	}, duration, nil)
+}
+
+// add sends the item returned by get after duration. onDrop is called if the item is dropped by
+// DropOldestOnOverflow. It can be nil.
+func (d *DelayingChannel[T]) add(get func() T, duration time.Duration, onDrop func()) *TaskHandle {
 	atomic.AddInt64(&d.remainingTasks, 1)
-	d.executor.ExcuteAfter(func() {
-		d.ch <- entry
+	done := func() {
 		atomic.AddInt64(&d.remainingTasks, -1)
-	}, duration)
+	}
+	handle, err := d.executor.submit(func() {
+		d.ch <- get()
+		done()
+	}, duration, "", func() {
+		done()
+		if onDrop != nil {
+			onDrop()
+		}
+	})
+	if err != nil {
+		done()
+		panic(err)
+	}
+	return handle
+}
+
+// Len returns the number of the items that are added but not ready yet
+func (d *DelayingChannel[T]) Len() int {
+	return d.executor.Len()
+}
+
+// Pending returns the number of the items that are added but not got yet, including the ones that are not ready.
+// An item that is just becoming ready may be counted twice for a moment.
+func (d *DelayingChannel[T]) Pending() int {
+	return int(atomic.LoadInt64(&d.remainingTasks)) + len(d.ch)
+}
+
+// Backlog returns the number of the items that are added but not scheduled yet. See DelayingExecutor.Backlog.
+func (d *DelayingChannel[T]) Backlog() int {
+	return d.executor.Backlog()
+}
+
+// NextReadyAt returns when the next item will be ready. See DelayingExecutor.NextReadyAt.
+func (d *DelayingChannel[T]) NextReadyAt() (time.Time, bool) {
+	return d.executor.NextReadyAt()
+}
+
+// WithChannelMetrics sets the metrics of the DelayingExecutor of ch and returns ch
+func WithChannelMetrics[T any](ch *DelayingChannel[T], metrics ExecutorMetrics) *DelayingChannel[T] {
+	WithMetrics(ch.executor, metrics)
+	return ch
 }
 
 func (d *DelayingChannel[T]) Close() {
@@ -1,6 +1,9 @@
 package util_test
 
 import (
+	"context"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/linxiaokun528/go-kit/pkg/util"
@@ -36,15 +39,15 @@ var _ = Describe("DelayingExecutor", func() {
 	})
 
 	It("can execute a task after a specified time", func() {
-		delayingExecutor.ExcuteAfter(helper1.execute, delayingTime1)
+		delayingExecutor.ExecuteAfter(helper1.execute, delayingTime1)
 		start := time.Now()
 		<-helper1.ch
 		Expect(time.Now()).To(BeTemporally("~", start.Add(delayingTime1), maxDeviation))
 	})
 
 	It("can work with multiple tasks.", func() {
-		delayingExecutor.ExcuteAfter(helper1.execute, delayingTime1)
-		delayingExecutor.ExcuteAfter(helper2.execute, delayingTime2)
+		delayingExecutor.ExecuteAfter(helper1.execute, delayingTime1)
+		delayingExecutor.ExecuteAfter(helper2.execute, delayingTime2)
 		start := time.Now()
 		<-helper1.ch
 		Expect(time.Now()).To(BeTemporally("~", start.Add(delayingTime1), maxDeviation))
@@ -53,41 +56,41 @@ var _ = Describe("DelayingExecutor", func() {
 	})
 
 	It("can still work even when tasks panic.", func() {
-		delayingExecutor.ExcuteAfter(func() {
+		delayingExecutor.ExecuteAfter(func() {
 			panic("test")
 		}, 0)
 
 		time.Sleep(maxDeviation)
-		delayingExecutor.ExcuteAfter(func() {
+		delayingExecutor.ExecuteAfter(func() {
 			panic("test")
 		}, delayingTime1)
 
 		time.Sleep(delayingTime1 + maxDeviation)
 
-		delayingExecutor.ExcuteAfter(helper1.execute, 0)
+		delayingExecutor.ExecuteAfter(helper1.execute, 0)
 		time.Sleep(maxDeviation)
 		Expect(helper1.ch).To(HaveLen(1))
 	})
 
 	It("can shut down immediately.", func() {
-		delayingExecutor.ExcuteAfter(helper1.execute, delayingTime1)
+		delayingExecutor.ExecuteAfter(helper1.execute, delayingTime1)
 		delayingExecutor.ShutDownFast()
 		time.Sleep(maxDeviation)
 		Expect(func() {
-			delayingExecutor.ExcuteAfter(helper2.execute, delayingTime2)
+			delayingExecutor.ExecuteAfter(helper2.execute, delayingTime2)
 		}).To(Panic())
 		time.Sleep(delayingTime1)
 		Expect(helper1.ch).To(HaveLen(0))
 	})
 
 	It("can shut down after executing remaining tasks.", func() {
-		delayingExecutor.ExcuteAfter(helper1.execute, delayingTime1)
+		delayingExecutor.ExecuteAfter(helper1.execute, delayingTime1)
 		start := time.Now()
 		delayingExecutor.ShutDownWithDrain(true)
 		Expect(time.Now()).To(BeTemporally("~", start.Add(delayingTime1), maxDeviation),
 			"DelayingExecutor should blocks until all tasks are executed.")
 		Expect(func() {
-			delayingExecutor.ExcuteAfter(helper2.execute, delayingTime1)
+			delayingExecutor.ExecuteAfter(helper2.execute, delayingTime1)
 		}).To(Panic())
 		time.Sleep(maxDeviation) // helper1.execute is executed in a go routine, we need some time to let it finish
 		Expect(helper1.ch).To(HaveLen(1))
@@ -95,7 +98,7 @@ var _ = Describe("DelayingExecutor", func() {
 	})
 
 	It("can shut down and executing remaining tasks in the backend.", func() {
-		delayingExecutor.ExcuteAfter(helper1.execute, delayingTime1)
+		delayingExecutor.ExecuteAfter(helper1.execute, delayingTime1)
 		start := time.Now()
 		delayingExecutor.ShutDownWithDrain(false)
 		Expect(time.Now()).To(BeTemporally("~", start, maxDeviation),
@@ -104,7 +107,7 @@ var _ = Describe("DelayingExecutor", func() {
 		Expect(helper1.ch).To(HaveLen(1))
 
 		Expect(func() {
-			delayingExecutor.ExcuteAfter(helper2.execute, delayingTime1)
+			delayingExecutor.ExecuteAfter(helper2.execute, delayingTime1)
 		}).To(Panic())
 		time.Sleep(maxDeviation) // helper1.execute is executed in a go routine, we need some time to let it finish
 		Expect(helper2.ch).To(HaveLen(0))
@@ -131,6 +134,63 @@ var _ = Describe("DelayingExecutor", func() {
 			delayingExecutor.ShutDownWithDrain(false)
 		}).NotTo(Panic())
 	})
+
+	It("can be shut down concurrently from multiple goroutines.", func() {
+		for i := 0; i < 3; i++ {
+			delayingExecutor.ExecuteAfter(helper1.execute, time.Hour)
+		}
+
+		done := make(chan struct{})
+		wait := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wait.Add(1)
+			go func(i int) {
+				defer wait.Done()
+				if i%2 == 0 {
+					delayingExecutor.ShutDownWithDrain(true)
+				} else {
+					delayingExecutor.ShutDownFast()
+				}
+			}(i)
+		}
+		go func() {
+			wait.Wait()
+			close(done)
+		}()
+		Eventually(done).Should(BeClosed())
+		Expect(delayingExecutor.Len()).To(Equal(0))
+		Expect(helper1.ch).To(HaveLen(0))
+	})
+
+	It("cancels the backlog when shut down immediately.", func() {
+		delayingExecutor = util.NewDelayingExecutor(200)
+		for i := 0; i < 200; i++ {
+			delayingExecutor.ExecuteAfter(helper1.execute, time.Hour)
+		}
+		delayingExecutor.ShutDownFast()
+		Expect(delayingExecutor.Backlog()).To(Equal(0))
+		Eventually(delayingExecutor.Len).Should(Equal(0))
+	})
+
+	It("returns ErrShutDown from TryExecuteAfter after shut down.", func() {
+		Expect(delayingExecutor.TryExecuteAfter(helper1.execute, 0)).To(Succeed())
+		Eventually(helper1.ch).Should(Receive())
+
+		delayingExecutor.ShutDownWithDrain(false)
+		Expect(delayingExecutor.TryExecuteAfter(helper2.execute, 0)).To(Equal(util.ErrShutDown))
+		Expect(func() {
+			delayingExecutor.ExecuteAfter(helper2.execute, 0)
+		}).To(PanicWith(util.ErrShutDown))
+
+		delayingExecutor.ShutDownFast()
+		Expect(delayingExecutor.TryExecuteAfter(helper2.execute, 0)).To(Equal(util.ErrShutDown))
+		Expect(helper2.ch).To(HaveLen(0))
+	})
+
+	It("still supports the deprecated ExcuteAfter.", func() {
+		delayingExecutor.ExcuteAfter(helper1.execute, 0)
+		Eventually(helper1.ch).Should(Receive())
+	})
 })
 
 var _ = Describe("DelayingChannel", func() {
@@ -181,6 +241,38 @@ var _ = Describe("DelayingChannel", func() {
 		Expect(ch.Close).To(Panic())
 	})
 
+	It("can get an item without blocking.", func() {
+		_, ok := ch.TryGet()
+		Expect(ok).To(BeFalse())
+
+		ch.AddAfter(1, delayingTime1)
+		Expect(ch.Pending()).To(Equal(1))
+		Eventually(func() bool {
+			item, ok := ch.TryGet()
+			return ok && item == 1
+		}).Should(BeTrue())
+		Expect(ch.Pending()).To(Equal(0))
+
+		ch.Close()
+		Eventually(func() bool {
+			_, ok := ch.TryGet()
+			return ok
+		}).Should(BeFalse())
+	})
+
+	It("can get an item with a context.", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := ch.GetWithContext(ctx)
+		Expect(err).To(Equal(context.DeadlineExceeded))
+
+		ch.AddAfter(0, delayingTime1)
+		ch.Close()
+		Expect(ch.GetWithContext(context.Background())).To(Equal(0))
+		_, err = ch.GetWithContext(context.Background())
+		Expect(err).To(Equal(util.ErrChannelClosed))
+	})
+
 	It("can return a zero value after closed.", func() {
 		delayingTime := 500 * time.Millisecond
 		ch.AddAfter(1, delayingTime)
@@ -194,3 +286,673 @@ var _ = Describe("DelayingChannel", func() {
 		Expect(anyCh.Get()).To(BeNil())
 	})
 })
+
+var _ = Describe("DelayingExecutor with hooks", func() {
+	type scheduledEvent struct {
+		id      uint64
+		readyAt time.Time
+	}
+
+	var delayingExecutor *util.DelayingExecutor
+	var scheduled chan scheduledEvent
+	var executed chan uint64
+	var cancelled chan uint64
+	var shutdown chan struct{}
+	var maxDeviation time.Duration
+
+	BeforeEach(func() {
+		scheduled = make(chan scheduledEvent, 5)
+		executed = make(chan uint64, 5)
+		cancelled = make(chan uint64, 5)
+		shutdown = make(chan struct{}, 1)
+		maxDeviation = 100 * time.Millisecond
+		delayingExecutor = util.WithHooks(util.NewDelayingExecutor(5), util.ExecutorHooks{
+			OnSchedule: func(id uint64, readyAt time.Time) {
+				scheduled <- scheduledEvent{id: id, readyAt: readyAt}
+			},
+			OnExecute: func(id uint64) {
+				executed <- id
+			},
+			OnCancel: func(id uint64) {
+				cancelled <- id
+			},
+			OnShutdown: func() {
+				shutdown <- struct{}{}
+			},
+		})
+	})
+
+	It("calls OnSchedule and OnExecute for a task.", func() {
+		delayingTime := 300 * time.Millisecond
+		start := time.Now()
+		delayingExecutor.ExecuteAfter(func() {}, delayingTime)
+
+		var event scheduledEvent
+		Eventually(scheduled).Should(Receive(&event))
+		Expect(event.readyAt).To(BeTemporally("~", start.Add(delayingTime), maxDeviation))
+		Expect(executed).NotTo(Receive())
+
+		Eventually(executed).Should(Receive(Equal(event.id)))
+		Expect(time.Now()).To(BeTemporally("~", start.Add(delayingTime), maxDeviation))
+		Expect(cancelled).NotTo(Receive())
+	})
+
+	It("calls OnCancel for the pending tasks and OnShutdown when shut down immediately.", func() {
+		delayingExecutor.ExecuteAfter(func() {}, time.Second)
+		var event scheduledEvent
+		Eventually(scheduled).Should(Receive(&event))
+
+		delayingExecutor.ShutDownFast()
+		Eventually(cancelled).Should(Receive(Equal(event.id)))
+		Eventually(shutdown).Should(Receive())
+		Expect(executed).NotTo(Receive())
+	})
+
+	It("calls OnShutdown after executing remaining tasks.", func() {
+		delayingExecutor.ExecuteAfter(func() {}, 300*time.Millisecond)
+		delayingExecutor.ShutDownWithDrain(true)
+		Eventually(shutdown).Should(Receive())
+		Expect(executed).To(HaveLen(1))
+		Expect(cancelled).To(HaveLen(0))
+	})
+
+	It("calls OnCancel for the draining tasks when shut down immediately.", func() {
+		delayingExecutor.ExecuteAfter(func() {}, time.Hour)
+		delayingExecutor.ExecuteAfter(func() {}, time.Hour)
+		Eventually(scheduled).Should(Receive())
+		Eventually(scheduled).Should(Receive())
+
+		drained := make(chan struct{})
+		go func() {
+			delayingExecutor.ShutDownWithDrain(true)
+			close(drained)
+		}()
+		Consistently(drained).ShouldNot(BeClosed())
+
+		delayingExecutor.ShutDownFast()
+		Eventually(drained).Should(BeClosed())
+		Eventually(cancelled).Should(HaveLen(2))
+		Eventually(shutdown).Should(Receive())
+		Expect(executed).NotTo(Receive())
+		Expect(delayingExecutor.Len()).To(Equal(0))
+	})
+
+	It("calls OnCancel when a task is canceled by its TaskHandle.", func() {
+		handle := delayingExecutor.ExecuteAfter(func() {}, time.Second)
+		Eventually(scheduled).Should(Receive())
+
+		Expect(handle.Cancel()).To(BeTrue())
+		Expect(cancelled).To(Receive(Equal(handle.ID())))
+		delayingExecutor.ShutDownFast()
+		Eventually(shutdown).Should(Receive())
+		Expect(cancelled).NotTo(Receive())
+		Expect(executed).NotTo(Receive())
+	})
+
+	It("works with nil hooks.", func() {
+		delayingExecutor = util.WithHooks(util.NewDelayingExecutor(5), util.ExecutorHooks{})
+		done := make(chan struct{})
+		delayingExecutor.ExecuteAfter(func() {
+			close(done)
+		}, 0)
+		Eventually(done).Should(BeClosed())
+		delayingExecutor.ExecuteAfter(func() {}, time.Second)
+		Expect(delayingExecutor.ShutDownFast).NotTo(Panic())
+	})
+})
+
+type testExecutorMetrics struct {
+	lock      sync.Mutex
+	enqueued  []int
+	executed  []int
+	latencies []time.Duration
+}
+
+func (t *testExecutorMetrics) OnEnqueue(pending int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.enqueued = append(t.enqueued, pending)
+}
+
+func (t *testExecutorMetrics) OnExecute(pending int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.executed = append(t.executed, pending)
+}
+
+func (t *testExecutorMetrics) OnLatency(latency time.Duration) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.latencies = append(t.latencies, latency)
+}
+
+var _ = Describe("DelayingExecutor with metrics", func() {
+	var delayingExecutor *util.DelayingExecutor
+	var metrics *testExecutorMetrics
+
+	BeforeEach(func() {
+		metrics = &testExecutorMetrics{}
+		delayingExecutor = util.WithMetrics(util.NewDelayingExecutor(5), metrics)
+	})
+
+	AfterEach(func() {
+		delayingExecutor.ShutDownFast()
+	})
+
+	It("reports the pending tasks and the next readyAt.", func() {
+		_, exists := delayingExecutor.NextReadyAt()
+		Expect(exists).To(BeFalse())
+		Expect(delayingExecutor.Len()).To(Equal(0))
+
+		start := time.Now()
+		handle := delayingExecutor.ExecuteAfter(func() {}, time.Second)
+		delayingExecutor.ExecuteAfter(func() {}, 100*time.Millisecond)
+		Expect(delayingExecutor.Len()).To(Equal(2))
+		Eventually(func() time.Time {
+			readyAt, _ := delayingExecutor.NextReadyAt()
+			return readyAt
+		}).Should(BeTemporally("~", start.Add(100*time.Millisecond), 50*time.Millisecond))
+
+		Eventually(delayingExecutor.Len).Should(Equal(1))
+		readyAt, exists := delayingExecutor.NextReadyAt()
+		Expect(exists).To(BeTrue())
+		Expect(readyAt).To(BeTemporally("~", start.Add(time.Second), 50*time.Millisecond))
+
+		handle.Cancel()
+		Expect(delayingExecutor.Len()).To(Equal(0))
+		_, exists = delayingExecutor.NextReadyAt()
+		Expect(exists).To(BeFalse())
+	})
+
+	It("calls the metrics.", func() {
+		delayingExecutor.ExecuteAfter(func() {}, 50*time.Millisecond)
+		delayingExecutor.ExecuteAfter(func() {}, 100*time.Millisecond)
+		Eventually(delayingExecutor.Len).Should(Equal(0))
+
+		metrics.lock.Lock()
+		defer metrics.lock.Unlock()
+		Expect(metrics.enqueued).To(Equal([]int{1, 2}))
+		Expect(metrics.executed).To(Equal([]int{1, 0}))
+		Expect(metrics.latencies).To(HaveLen(2))
+		for _, latency := range metrics.latencies {
+			Expect(latency).To(BeNumerically("~", 0, 50*time.Millisecond))
+		}
+	})
+
+	It("works with DelayingChannel.", func() {
+		ch := util.WithChannelMetrics(util.NewDelayingChannel[int](5), metrics)
+		ch.AddAfter(1, 50*time.Millisecond)
+		Expect(ch.Len()).To(Equal(1))
+		Expect(ch.Get()).To(Equal(1))
+		Expect(ch.Len()).To(Equal(0))
+		_, exists := ch.NextReadyAt()
+		Expect(exists).To(BeFalse())
+		ch.Close()
+
+		metrics.lock.Lock()
+		defer metrics.lock.Unlock()
+		Expect(metrics.enqueued).To(Equal([]int{1}))
+	})
+})
+
+var _ = Describe("DelayingExecutor with overflow policies", func() {
+	var delayingExecutor *util.DelayingExecutor
+	var block chan struct{}
+
+	BeforeEach(func() {
+		block = make(chan struct{})
+	})
+
+	AfterEach(func() {
+		close(block)
+		delayingExecutor.ShutDownFast()
+	})
+
+	// blockWaitingLoop keeps the waitingLoop busy in a hook, so the backlog can't be consumed
+	blockWaitingLoop := func(policy util.OverflowPolicy, cancelled chan uint64) {
+		blocked := make(chan struct{})
+		// The waitingLoop may outlive the spec, so don't read the shared variable in it
+		block := block
+		var once sync.Once
+		delayingExecutor = util.WithHooks(util.NewDelayingExecutor(2, util.WithOverflowPolicy(policy)),
+			util.ExecutorHooks{
+				OnSchedule: func(id uint64, readyAt time.Time) {
+					once.Do(func() {
+						close(blocked)
+						<-block
+					})
+				},
+				OnCancel: func(id uint64) {
+					cancelled <- id
+				},
+			})
+		delayingExecutor.ExecuteAfter(func() {}, 0)
+		Eventually(blocked).Should(BeClosed())
+	}
+
+	It("blocks by default.", func() {
+		blockWaitingLoop(util.BlockOnOverflow, make(chan uint64, 5))
+		delayingExecutor.ExecuteAfter(func() {}, 0)
+		delayingExecutor.ExecuteAfter(func() {}, 0)
+		Expect(delayingExecutor.Backlog()).To(Equal(2))
+
+		added := make(chan struct{})
+		go func() {
+			// It fails with ErrShutDown after the executor is shut down in AfterEach
+			_ = delayingExecutor.TryExecuteAfter(func() {}, 0)
+			close(added)
+		}()
+		Consistently(added).ShouldNot(BeClosed())
+	})
+
+	It("can reject the tasks.", func() {
+		blockWaitingLoop(util.RejectOnOverflow, make(chan uint64, 5))
+		Expect(delayingExecutor.TryExecuteAfter(func() {}, 0)).To(Succeed())
+		Expect(delayingExecutor.TryExecuteAfter(func() {}, 0)).To(Succeed())
+		Expect(delayingExecutor.TryExecuteAfter(func() {}, 0)).To(Equal(util.ErrExecutorFull))
+		Expect(func() { delayingExecutor.ExecuteAfter(func() {}, 0) }).To(PanicWith(util.ErrExecutorFull))
+		Expect(delayingExecutor.Backlog()).To(Equal(2))
+		Expect(delayingExecutor.Len()).To(Equal(3))
+	})
+
+	It("can drop the oldest tasks.", func() {
+		cancelled := make(chan uint64, 5)
+		blockWaitingLoop(util.DropOldestOnOverflow, cancelled)
+		oldest := delayingExecutor.ExecuteAfter(func() {}, 0)
+		delayingExecutor.ExecuteAfter(func() {}, 0)
+		delayingExecutor.ExecuteAfter(func() {}, 0)
+
+		Expect(cancelled).To(Receive(Equal(oldest.ID())))
+		Expect(oldest.Cancel()).To(BeFalse())
+		Expect(delayingExecutor.Backlog()).To(Equal(2))
+		Expect(delayingExecutor.Len()).To(Equal(3))
+	})
+
+	It("can grow the backlog.", func() {
+		blockWaitingLoop(util.GrowOnOverflow, make(chan uint64, 5))
+		var executed int64
+		for i := 0; i < 5; i++ {
+			delayingExecutor.ExecuteAfter(func() {
+				atomic.AddInt64(&executed, 1)
+			}, 0)
+		}
+		Expect(delayingExecutor.Backlog()).To(Equal(5))
+
+		block <- struct{}{}
+		Eventually(func() int64 { return atomic.LoadInt64(&executed) }).Should(BeEquivalentTo(5))
+		Expect(delayingExecutor.Backlog()).To(Equal(0))
+	})
+
+	It("cancels the grown backlog when shut down immediately.", func() {
+		cancelled := make(chan uint64, 5)
+		blockWaitingLoop(util.GrowOnOverflow, cancelled)
+		for i := 0; i < 5; i++ {
+			delayingExecutor.ExecuteAfter(func() {}, time.Hour)
+		}
+		Expect(delayingExecutor.Backlog()).To(Equal(5))
+
+		delayingExecutor.ShutDownFast()
+		Expect(delayingExecutor.Backlog()).To(Equal(0))
+		Expect(cancelled).To(HaveLen(5))
+		// The task being scheduled by the blocked waitingLoop
+		Expect(delayingExecutor.Len()).To(Equal(1))
+	})
+
+	It("executes the grown backlog when shut down with drain.", func() {
+		blockWaitingLoop(util.GrowOnOverflow, make(chan uint64, 5))
+		var executed int64
+		for i := 0; i < 5; i++ {
+			delayingExecutor.ExecuteAfter(func() {
+				atomic.AddInt64(&executed, 1)
+			}, 10*time.Millisecond)
+		}
+		block <- struct{}{}
+		delayingExecutor.ShutDownWithDrain(true)
+		Eventually(func() int64 { return atomic.LoadInt64(&executed) }).Should(BeEquivalentTo(5))
+	})
+
+	It("doesn't leak the dropped items of DelayingChannel.", func() {
+		delayingExecutor = util.NewDelayingExecutor(1)
+		ch := util.NewDelayingChannel[int](1, util.WithOverflowPolicy(util.DropOldestOnOverflow))
+		for i := 1; i <= 100; i++ {
+			ch.AddAfter(i, 0)
+		}
+		ch.Close()
+
+		closed := make(chan struct{})
+		go func() {
+			// Get returns the zero value after all the remaining items are got
+			for ch.Get() != 0 {
+			}
+			close(closed)
+		}()
+		Eventually(closed).Should(BeClosed())
+	})
+})
+
+var _ = Describe("DelayingExecutor with workers", func() {
+	It("executes the tasks with bounded workers.", func() {
+		delayingExecutor := util.NewDelayingExecutor(5, util.WithWorkers(2))
+		defer delayingExecutor.ShutDownFast()
+
+		var running, maxRunning, executed int64
+		for i := 0; i < 10; i++ {
+			delayingExecutor.ExecuteAfter(func() {
+				current := atomic.AddInt64(&running, 1)
+				for {
+					max := atomic.LoadInt64(&maxRunning)
+					if current <= max || atomic.CompareAndSwapInt64(&maxRunning, max, current) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt64(&running, -1)
+				atomic.AddInt64(&executed, 1)
+			}, 10*time.Millisecond)
+		}
+
+		Eventually(func() int64 { return atomic.LoadInt64(&executed) }).Should(BeEquivalentTo(10))
+		Expect(atomic.LoadInt64(&maxRunning)).To(BeEquivalentTo(2))
+	})
+
+	It("dispatches the ready tasks in the order of readyAt.", func() {
+		delayingExecutor := util.NewDelayingExecutor(5, util.WithWorkers(1))
+		defer delayingExecutor.ShutDownFast()
+
+		block := make(chan struct{})
+		delayingExecutor.ExecuteAfter(func() { <-block }, 0)
+		var lock sync.Mutex
+		var order []int
+		for _, i := range []int{3, 1, 2} {
+			i := i
+			delayingExecutor.ExecuteAfter(func() {
+				lock.Lock()
+				defer lock.Unlock()
+				order = append(order, i)
+			}, time.Duration(i)*10*time.Millisecond)
+		}
+		// All the tasks are ready while the only worker is blocked
+		time.Sleep(50 * time.Millisecond)
+		close(block)
+
+		Eventually(func() []int {
+			lock.Lock()
+			defer lock.Unlock()
+			return append([]int(nil), order...)
+		}).Should(Equal([]int{1, 2, 3}))
+	})
+
+	It("executes the remaining tasks when shut down with drain.", func() {
+		delayingExecutor := util.NewDelayingExecutor(5, util.WithWorkers(1))
+		var executed int64
+		for i := 0; i < 3; i++ {
+			delayingExecutor.ExecuteAfter(func() {
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt64(&executed, 1)
+			}, 0)
+		}
+		delayingExecutor.ShutDownWithDrain(true)
+		Eventually(func() int64 { return atomic.LoadInt64(&executed) }).Should(BeEquivalentTo(3))
+	})
+
+	It("panics with a non-positive workerNum.", func() {
+		Expect(func() { util.WithWorkers(0) }).To(Panic())
+	})
+})
+
+var _ = Describe("DelayingExecutor with panic handlers", func() {
+	It("invokes the panic handler and the error handler.", func() {
+		panics := make(chan any, 1)
+		errs := make(chan *util.TaskError, 1)
+		delayingExecutor := util.NewDelayingExecutor(5,
+			util.WithExecutorPanicHandler(func(r any) {
+				panics <- r
+				panic(r)
+			}),
+			util.WithTaskErrorHandler(func(err *util.TaskError) {
+				errs <- err
+			}))
+		defer delayingExecutor.ShutDownFast()
+
+		start := time.Now()
+		handle := delayingExecutor.ExecuteAfterWithLabel("test", func() {
+			panic("panic for test")
+		}, 10*time.Millisecond)
+
+		Eventually(panics).Should(Receive(Equal("panic for test")))
+		var err *util.TaskError
+		Eventually(errs).Should(Receive(&err))
+		Expect(err.ID).To(Equal(handle.ID()))
+		Expect(err.Label).To(Equal("test"))
+		Expect(err.ReadyAt).To(BeTemporally("~", start.Add(10*time.Millisecond), 50*time.Millisecond))
+		Expect(err.Value).To(Equal("panic for test"))
+		Expect(err.Error()).To(ContainSubstring("(test)"))
+		Expect(err.Error()).To(HaveSuffix("panicked: panic for test"))
+	})
+
+	It("unwraps the error that a task panics with.", func() {
+		errs := make(chan *util.TaskError, 1)
+		delayingExecutor := util.NewDelayingExecutor(5, util.WithWorkers(1),
+			util.WithTaskErrorHandler(func(err *util.TaskError) {
+				errs <- err
+			}))
+		defer delayingExecutor.ShutDownFast()
+
+		delayingExecutor.ExecuteAfter(func() {
+			panic(util.ErrShutDown)
+		}, 0)
+		var err *util.TaskError
+		Eventually(errs).Should(Receive(&err))
+		Expect(err).To(MatchError(util.ErrShutDown))
+		Expect(err.Label).To(BeEmpty())
+	})
+})
+
+var _ = Describe("DelayingExecutor.ExecuteEvery", func() {
+	var delayingExecutor *util.DelayingExecutor
+
+	BeforeEach(func() {
+		delayingExecutor = util.NewDelayingExecutor(5)
+	})
+
+	AfterEach(func() {
+		delayingExecutor.ShutDownFast()
+	})
+
+	It("executes the task at a fixed rate.", func() {
+		var starts []time.Time
+		lock := sync.Mutex{}
+		start := time.Now()
+		cancel := delayingExecutor.ExecuteEvery(func() {
+			lock.Lock()
+			starts = append(starts, time.Now())
+			lock.Unlock()
+			time.Sleep(30 * time.Millisecond)
+		}, 50*time.Millisecond)
+
+		Eventually(func() int {
+			lock.Lock()
+			defer lock.Unlock()
+			return len(starts)
+		}).Should(Equal(4))
+		cancel()
+
+		lock.Lock()
+		defer lock.Unlock()
+		for i, executed := range starts {
+			Expect(executed).To(BeTemporally("~", start.Add(time.Duration(i+1)*50*time.Millisecond),
+				25*time.Millisecond))
+		}
+	})
+
+	It("executes the task with a fixed delay.", func() {
+		var starts []time.Time
+		lock := sync.Mutex{}
+		start := time.Now()
+		delayingExecutor.ExecuteEvery(func() {
+			lock.Lock()
+			starts = append(starts, time.Now())
+			lock.Unlock()
+			time.Sleep(30 * time.Millisecond)
+		}, 50*time.Millisecond, util.WithFixedDelay())
+
+		Eventually(func() int {
+			lock.Lock()
+			defer lock.Unlock()
+			return len(starts)
+		}).Should(Equal(3))
+
+		lock.Lock()
+		defer lock.Unlock()
+		for i, executed := range starts {
+			Expect(executed).To(BeTemporally("~", start.Add(time.Duration(i+1)*50*time.Millisecond+
+				time.Duration(i)*30*time.Millisecond), 25*time.Millisecond))
+		}
+	})
+
+	It("stops after cancel.", func() {
+		var executed int64
+		cancel := delayingExecutor.ExecuteEvery(func() {
+			atomic.AddInt64(&executed, 1)
+		}, 10*time.Millisecond)
+		Eventually(func() int64 { return atomic.LoadInt64(&executed) }).Should(BeNumerically(">=", 2))
+
+		cancel()
+		// The execution that has started may still finish
+		time.Sleep(20 * time.Millisecond)
+		stopped := atomic.LoadInt64(&executed)
+		Consistently(func() int64 { return atomic.LoadInt64(&executed) }, 100*time.Millisecond).
+			Should(Equal(stopped))
+	})
+
+	It("cancels the queued execution.", func() {
+		cancel := delayingExecutor.ExecuteEvery(func() {}, time.Hour)
+		Expect(delayingExecutor.Len()).To(Equal(1))
+
+		cancel()
+		Expect(delayingExecutor.Len()).To(Equal(0))
+		done := make(chan struct{})
+		go func() {
+			delayingExecutor.ShutDownWithDrain(true)
+			close(done)
+		}()
+		Eventually(done).Should(BeClosed())
+	})
+
+	It("isn't stopped by the overflow policies.", func() {
+		for _, policy := range []util.OverflowPolicy{util.RejectOnOverflow, util.DropOldestOnOverflow} {
+			executor := util.NewDelayingExecutor(1, util.WithOverflowPolicy(policy))
+			var executed int64
+			executor.ExecuteEvery(func() {
+				atomic.AddInt64(&executed, 1)
+			}, 10*time.Millisecond)
+			// Keep the backlog full
+			for i := 0; i < 20; i++ {
+				_ = executor.TryExecuteAfter(func() {}, time.Hour)
+			}
+			Eventually(func() int64 { return atomic.LoadInt64(&executed) }).Should(BeNumerically(">=", 3))
+			executor.ShutDownFast()
+		}
+	})
+
+	It("handles the panics with the task's panicHandler.", func() {
+		var panics int64
+		delayingExecutor.ExecuteEvery(func() {
+			panic("panic for test")
+		}, 10*time.Millisecond, util.WithTaskPanicHandler(func(r any) {
+			atomic.AddInt64(&panics, 1)
+		}))
+		// The task keeps running after panics
+		Eventually(func() int64 { return atomic.LoadInt64(&panics) }).Should(BeNumerically(">=", 3))
+	})
+
+	It("stops when the executor is shut down.", func() {
+		var executed int64
+		delayingExecutor.ExecuteEvery(func() {
+			atomic.AddInt64(&executed, 1)
+		}, 10*time.Millisecond)
+		Eventually(func() int64 { return atomic.LoadInt64(&executed) }).Should(BeNumerically(">=", 1))
+
+		delayingExecutor.ShutDownWithDrain(true)
+		stopped := atomic.LoadInt64(&executed)
+		Consistently(func() int64 { return atomic.LoadInt64(&executed) }, 100*time.Millisecond).
+			Should(BeNumerically("<=", stopped+1))
+	})
+
+	It("panics with a non-positive interval.", func() {
+		Expect(func() { delayingExecutor.ExecuteEvery(func() {}, 0) }).To(Panic())
+	})
+})
+
+var _ = Describe("TaskHandle", func() {
+	var delayingExecutor *util.DelayingExecutor
+	var maxDeviation time.Duration
+
+	BeforeEach(func() {
+		delayingExecutor = util.NewDelayingExecutor(5)
+		maxDeviation = 50 * time.Millisecond
+	})
+
+	AfterEach(func() {
+		delayingExecutor.ShutDownFast()
+	})
+
+	It("can cancel a pending task.", func() {
+		done := make(chan struct{})
+		handle := delayingExecutor.ExecuteAfter(func() {
+			close(done)
+		}, 50*time.Millisecond)
+
+		Expect(handle.Cancel()).To(BeTrue())
+		Expect(handle.Cancel()).To(BeFalse())
+		Consistently(done, 200*time.Millisecond).ShouldNot(BeClosed())
+		Expect(handle.Reschedule(0)).To(BeFalse())
+	})
+
+	It("can't cancel an executed task.", func() {
+		done := make(chan struct{})
+		handle := delayingExecutor.ExecuteAfter(func() {
+			close(done)
+		}, 0)
+
+		Eventually(done).Should(BeClosed())
+		Expect(handle.Cancel()).To(BeFalse())
+		Expect(handle.Reschedule(0)).To(BeFalse())
+	})
+
+	It("can reschedule a task earlier.", func() {
+		done := make(chan struct{})
+		start := time.Now()
+		handle := delayingExecutor.ExecuteAfter(func() {
+			close(done)
+		}, time.Second)
+		// Let the task be scheduled with the original delay
+		time.Sleep(20 * time.Millisecond)
+
+		Expect(handle.Reschedule(100 * time.Millisecond)).To(BeTrue())
+		Eventually(done).Should(BeClosed())
+		Expect(time.Now()).To(BeTemporally("~", start.Add(120*time.Millisecond), maxDeviation))
+	})
+
+	It("can reschedule a task later.", func() {
+		var executedAt1, executedAt2 time.Time
+		done1 := make(chan struct{})
+		done2 := make(chan struct{})
+		start := time.Now()
+		handle := delayingExecutor.ExecuteAfter(func() {
+			executedAt1 = time.Now()
+			close(done1)
+		}, 50*time.Millisecond)
+		delayingExecutor.ExecuteAfter(func() {
+			executedAt2 = time.Now()
+			close(done2)
+		}, 100*time.Millisecond)
+
+		Expect(handle.Reschedule(200 * time.Millisecond)).To(BeTrue())
+		Eventually(done2).Should(BeClosed())
+		Expect(done1).NotTo(BeClosed())
+		Eventually(done1).Should(BeClosed())
+		Expect(executedAt2).To(BeTemporally("~", start.Add(100*time.Millisecond), maxDeviation))
+		Expect(executedAt1).To(BeTemporally("~", start.Add(200*time.Millisecond), maxDeviation))
+	})
+})
@@ -0,0 +1,179 @@
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// StoredTask is a task of a DelayingExecutor saved in a TaskStore
+type StoredTask struct {
+	ID      string
+	Payload []byte
+	ReadyAt time.Time
+}
+
+// TaskStore saves the pending tasks of a DelayingExecutor, so that they can be restored after the process restarts
+type TaskStore interface {
+	// Save saves task. It's called before the task is accepted by the executor.
+	Save(task StoredTask) error
+	// Delete deletes the task with id. It's called after the task is executed or canceled.
+	Delete(id string) error
+	// Load returns all the saved tasks
+	Load() ([]StoredTask, error)
+}
+
+// PayloadHandler executes the payload of a task saved in a TaskStore
+type PayloadHandler func(payload []byte)
+
+// WithStore saves the tasks added by ExecutePayloadAfter in store, and executes them with handler.
+// Call Restore after the executor is created to schedule the tasks saved before the process restarts.
+// A task is deleted from store after it's executed, so it may be executed again if the process crashes in between.
+func WithStore(store TaskStore, handler PayloadHandler) DelayingOption {
+	if store == nil || handler == nil {
+		panic(fmt.Errorf("store and handler should not be nil"))
+	}
+
+	return func(d *DelayingExecutor) {
+		d.store = store
+		d.payloadHandler = handler
+	}
+}
+
+// ExecutePayloadAfter saves the payload in the TaskStore and executes it with the PayloadHandler after duration.
+// The task is kept in the TaskStore if the executor is shut down by ShutDownFast before the task is executed.
+// It panics if the executor has no TaskStore.
+func (d *DelayingExecutor) ExecutePayloadAfter(payload []byte, duration time.Duration) (*TaskHandle, error) {
+	if d.store == nil {
+		panic(fmt.Errorf("the executor has no TaskStore"))
+	}
+
+	task := StoredTask{
+		ID:      fmt.Sprintf("%x-%x", d.clock.Now().UnixNano(), atomic.AddUint64(&d.lastStoredID, 1)),
+		Payload: payload,
+		ReadyAt: d.clock.Now().Add(duration),
+	}
+	if err := d.store.Save(task); err != nil {
+		return nil, err
+	}
+
+	handle, err := d.submitStored(task)
+	if err != nil {
+		_ = d.store.Delete(task.ID)
+		return nil, err
+	}
+	return handle, nil
+}
+
+// Restore schedules the tasks in the TaskStore. It should be called only once after the executor is created.
+// The tasks that should have been executed are executed immediately.
+func (d *DelayingExecutor) Restore() error {
+	if d.store == nil {
+		panic(fmt.Errorf("the executor has no TaskStore"))
+	}
+
+	tasks, err := d.store.Load()
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if _, err := d.submitStored(task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *DelayingExecutor) submitStored(task StoredTask) (*TaskHandle, error) {
+	deleteTask := func() {
+		// If it fails, the task will be executed again after the process restarts
+		_ = d.store.Delete(task.ID)
+	}
+	return d.submit(func() {
+		defer deleteTask()
+		d.payloadHandler(task.Payload)
+	}, task.ReadyAt.Sub(d.clock.Now()), task.ID, deleteTask)
+}
+
+const storedTaskSuffix = ".task"
+
+// FileTaskStore is a TaskStore that saves every task as a JSON file in a directory
+type FileTaskStore struct {
+	dir string
+}
+
+// NewFileTaskStore creates dir if it doesn't exist
+func NewFileTaskStore(dir string) (*FileTaskStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileTaskStore{dir: dir}, nil
+}
+
+func (f *FileTaskStore) path(id string) string {
+	return filepath.Join(f.dir, id+storedTaskSuffix)
+}
+
+func (f *FileTaskStore) Save(task StoredTask) error {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so that Load never sees a partially written task
+	tmp, err := os.CreateTemp(f.dir, task.ID+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), f.path(task.ID))
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
+
+func (f *FileTaskStore) Delete(id string) error {
+	err := os.Remove(f.path(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (f *FileTaskStore) Load() ([]StoredTask, error) {
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []StoredTask
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), storedTaskSuffix) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(f.dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var task StoredTask
+		if err := json.Unmarshal(data, &task); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", entry.Name(), err)
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
@@ -0,0 +1,95 @@
+package util_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/linxiaokun528/go-kit/pkg/util"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DelayingExecutor with TaskStore", func() {
+	var dir string
+	var store *util.FileTaskStore
+	var payloads chan string
+
+	newExecutor := func() *util.DelayingExecutor {
+		return util.NewDelayingExecutor(5, util.WithStore(store, func(payload []byte) {
+			payloads <- string(payload)
+		}))
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "taskstore")
+		Expect(err).To(BeNil())
+		store, err = util.NewFileTaskStore(filepath.Join(dir, "tasks"))
+		Expect(err).To(BeNil())
+		payloads = make(chan string, 5)
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("deletes the tasks after they are executed or canceled.", func() {
+		executor := newExecutor()
+		defer executor.ShutDownFast()
+
+		_, err := executor.ExecutePayloadAfter([]byte("executed"), 0)
+		Expect(err).To(BeNil())
+		handle, err := executor.ExecutePayloadAfter([]byte("canceled"), time.Second)
+		Expect(err).To(BeNil())
+		Expect(handle.Cancel()).To(BeTrue())
+
+		Eventually(payloads).Should(Receive(Equal("executed")))
+		Eventually(func() ([]util.StoredTask, error) { return store.Load() }).Should(BeEmpty())
+		Expect(payloads).NotTo(Receive())
+	})
+
+	It("restores the pending tasks.", func() {
+		executor := newExecutor()
+		start := time.Now()
+		_, err := executor.ExecutePayloadAfter([]byte("later"), 200*time.Millisecond)
+		Expect(err).To(BeNil())
+		_, err = executor.ExecutePayloadAfter([]byte("sooner"), 100*time.Millisecond)
+		Expect(err).To(BeNil())
+		executor.ShutDownFast()
+
+		tasks, err := store.Load()
+		Expect(err).To(BeNil())
+		Expect(tasks).To(HaveLen(2))
+
+		executor = newExecutor()
+		defer executor.ShutDownFast()
+		Expect(executor.Restore()).To(Succeed())
+		Eventually(payloads).Should(Receive(Equal("sooner")))
+		Expect(time.Now()).To(BeTemporally("~", start.Add(100*time.Millisecond), 50*time.Millisecond))
+		Eventually(payloads).Should(Receive(Equal("later")))
+		Expect(time.Now()).To(BeTemporally("~", start.Add(200*time.Millisecond), 50*time.Millisecond))
+		Eventually(func() ([]util.StoredTask, error) { return store.Load() }).Should(BeEmpty())
+	})
+
+	It("deletes the task if the executor has been shut down.", func() {
+		executor := newExecutor()
+		executor.ShutDownFast()
+		_, err := executor.ExecutePayloadAfter([]byte("rejected"), 0)
+		Expect(err).To(Equal(util.ErrShutDown))
+		Expect(store.Load()).To(BeEmpty())
+	})
+
+	It("ignores the files that are not tasks.", func() {
+		Expect(os.WriteFile(filepath.Join(dir, "tasks", "other.tmp"), []byte("{"), 0o644)).To(Succeed())
+		Expect(store.Load()).To(BeEmpty())
+		Expect(store.Delete("not-exist")).To(Succeed())
+	})
+
+	It("panics without a TaskStore.", func() {
+		executor := util.NewDelayingExecutor(5)
+		defer executor.ShutDownFast()
+		Expect(func() { _, _ = executor.ExecutePayloadAfter(nil, 0) }).To(Panic())
+		Expect(func() { _ = executor.Restore() }).To(Panic())
+	})
+})
@@ -0,0 +1,221 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Future holds a value or an error that will be available later. It can only be completed once.
+type Future[T any] struct {
+	lock  sync.Mutex
+	done  chan struct{}
+	value T
+	err   error
+	// callbacks are invoked with the value after the future is completed successfully
+	callbacks []func(T)
+	// panicHandler is nil if the panics of the callbacks are passed on by Complete
+	panicHandler PanicHandler
+}
+
+type FutureOption func(*futureOptions)
+
+type futureOptions struct {
+	panicHandler PanicHandler
+}
+
+// WithCallbackPanicHandler makes the future invoke panicHandler with what a callback panics with, instead of passing
+// the panic on.
+func WithCallbackPanicHandler(panicHandler PanicHandler) FutureOption {
+	return func(o *futureOptions) {
+		o.panicHandler = panicHandler
+	}
+}
+
+func NewFuture[T any](opts ...FutureOption) *Future[T] {
+	o := futureOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &Future[T]{
+		done:         make(chan struct{}),
+		panicHandler: o.panicHandler,
+	}
+}
+
+// Async runs f in a new goroutine and returns a Future of its result.
+// If f panics, the future fails with a *PanicError. The panics of the callbacks are ignored unless
+// WithCallbackPanicHandler is used.
+func Async[T any](f func() (T, error), opts ...FutureOption) *Future[T] {
+	future := NewFuture[T](opts...)
+	go future.run(f)
+	return future
+}
+
+// SubmitAsync runs f in pool and returns a Future of its result. See WorkerPool.Submit for the returned error.
+// If f panics, the future fails with a *PanicError. If f is dropped by WorkerPool.Shutdown, the future fails with
+// ErrPoolShutDown. The panics of the callbacks are handled in the same way as Async.
+func SubmitAsync[T any](pool *WorkerPool, f func(ctx context.Context) (T, error),
+	opts ...FutureOption) (*Future[T], error) {
+	future := NewFuture[T](opts...)
+	err := pool.Submit(func(ctx context.Context) {
+		future.run(func() (T, error) {
+			return f(ctx)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		select {
+		case <-future.Done():
+		case <-pool.dropped():
+			// The task may have started right before the pool gave up waiting
+			<-pool.stopped()
+			future.Fail(fmt.Errorf("the task is dropped: %w", ErrPoolShutDown))
+		}
+	}()
+	return future, nil
+}
+
+// run is invoked in the goroutines started by the library, where nothing can recover from a panic, so the panics of
+// the callbacks are never passed on.
+func (f *Future[T]) run(task func() (T, error)) {
+	value, err := callTask(task)
+	if err != nil {
+		f.Fail(err)
+		return
+	}
+
+	panicHandler := f.panicHandler
+	if panicHandler == nil {
+		panicHandler = func(r any) {}
+	}
+	f.complete(value, panicHandler)
+}
+
+// callTask converts the panic of task to a *PanicError
+func callTask[T any](task func() (T, error)) (value T, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r}
+		}
+	}()
+
+	return task()
+}
+
+// Complete completes the future with value. It returns false if the future has been completed.
+// If a callback panics, the other callbacks are still invoked, and then the first panic is passed on to the caller,
+// unless WithCallbackPanicHandler is used.
+func (f *Future[T]) Complete(value T) bool {
+	return f.complete(value, f.panicHandler)
+}
+
+// complete passes the first panic of the callbacks on if panicHandler is nil
+func (f *Future[T]) complete(value T, panicHandler PanicHandler) bool {
+	f.lock.Lock()
+	if f.isDone() {
+		f.lock.Unlock()
+		return false
+	}
+	f.value = value
+	callbacks := f.callbacks
+	f.callbacks = nil
+	close(f.done)
+	f.lock.Unlock()
+
+	var panicValue any
+	panicked := false
+	for _, callback := range callbacks {
+		func() {
+			defer func() {
+				r := recover()
+				if r == nil {
+					return
+				}
+				if panicHandler != nil {
+					panicHandler(r)
+				} else if !panicked {
+					panicValue = r
+					panicked = true
+				}
+			}()
+			callback(value)
+		}()
+	}
+	if panicked {
+		panic(panicValue)
+	}
+	return true
+}
+
+// Fail completes the future with err. It returns false if the future has been completed.
+func (f *Future[T]) Fail(err error) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.isDone() {
+		return false
+	}
+	f.err = err
+	f.callbacks = nil
+	close(f.done)
+	return true
+}
+
+// isDone should be called with f.lock held
+func (f *Future[T]) isDone() bool {
+	select {
+	case <-f.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Done returns a channel that is closed after the future is completed
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Get blocks until the future is completed or ctx is done.
+// If ctx is done, ctx.Err() will be returned.
+func (f *Future[T]) Get(ctx context.Context) (value T, err error) {
+	select {
+	case <-ctx.Done():
+		return value, ctx.Err()
+	case <-f.done:
+		return f.value, f.err
+	}
+}
+
+// TryGet returns done=false immediately if the future is not completed
+func (f *Future[T]) TryGet() (value T, err error, done bool) {
+	select {
+	case <-f.done:
+		return f.value, f.err, true
+	default:
+		return
+	}
+}
+
+// Then invokes callback with the value after the future is completed successfully. The callback is invoked in the
+// goroutine that completes the future, or in the caller's goroutine if the future has been completed.
+// It's never invoked if the future fails. A panicking callback doesn't stop the others. See Complete and Async for
+// how its panic is handled.
+func (f *Future[T]) Then(callback func(T)) {
+	f.lock.Lock()
+	if !f.isDone() {
+		f.callbacks = append(f.callbacks, callback)
+		f.lock.Unlock()
+		return
+	}
+	f.lock.Unlock()
+
+	if f.err == nil {
+		callback(f.value)
+	}
+}
@@ -0,0 +1,146 @@
+package util_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/linxiaokun528/go-kit/pkg/util"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Future", func() {
+	errTest := errors.New("error for test")
+
+	It("can only be completed once.", func() {
+		future := util.NewFuture[int]()
+		_, _, done := future.TryGet()
+		Expect(done).To(BeFalse())
+
+		Expect(future.Complete(1)).To(BeTrue())
+		Expect(future.Complete(2)).To(BeFalse())
+		Expect(future.Fail(errTest)).To(BeFalse())
+		Expect(future.Done()).To(BeClosed())
+
+		value, err := future.Get(context.Background())
+		Expect(value).To(Equal(1))
+		Expect(err).To(BeNil())
+	})
+
+	It("can fail.", func() {
+		future := util.NewFuture[int]()
+		Expect(future.Fail(errTest)).To(BeTrue())
+		Expect(future.Complete(1)).To(BeFalse())
+
+		_, err, done := future.TryGet()
+		Expect(done).To(BeTrue())
+		Expect(err).To(Equal(errTest))
+	})
+
+	It("returns the error of ctx from Get.", func() {
+		future := util.NewFuture[int]()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := future.Get(ctx)
+		Expect(err).To(Equal(context.DeadlineExceeded))
+	})
+
+	It("invokes the callbacks after it's completed successfully.", func() {
+		future := util.NewFuture[int]()
+		var values []int
+		future.Then(func(value int) { values = append(values, value) })
+		future.Then(func(value int) { values = append(values, value*10) })
+		Expect(values).To(BeEmpty())
+
+		future.Complete(1)
+		Expect(values).To(Equal([]int{1, 10}))
+		future.Then(func(value int) { values = append(values, value*100) })
+		Expect(values).To(Equal([]int{1, 10, 100}))
+
+		failed := util.NewFuture[int]()
+		failed.Then(func(value int) { values = append(values, value) })
+		failed.Fail(errTest)
+		failed.Then(func(value int) { values = append(values, value) })
+		Expect(values).To(Equal([]int{1, 10, 100}))
+	})
+
+	It("invokes the other callbacks if a callback panics.", func() {
+		future := util.NewFuture[int]()
+		var values []int
+		future.Then(func(value int) { panic("panic for test") })
+		future.Then(func(value int) { values = append(values, value) })
+
+		Expect(func() { future.Complete(1) }).To(PanicWith("panic for test"))
+		Expect(values).To(Equal([]int{1}))
+		Expect(future.Get(context.Background())).To(Equal(1))
+	})
+
+	It("doesn't pass the panics of the callbacks on in the goroutine of Async.", func() {
+		block := make(chan struct{})
+		ignored := util.Async(func() (int, error) {
+			<-block
+			return 1, nil
+		})
+		ignored.Then(func(value int) { panic("panic for test") })
+
+		panics := make(chan any, 1)
+		handled := util.Async(func() (int, error) {
+			<-block
+			return 2, nil
+		}, util.WithCallbackPanicHandler(func(r any) { panics <- r }))
+		handled.Then(func(value int) { panic("panic for test") })
+
+		close(block)
+		Expect(ignored.Get(context.Background())).To(Equal(1))
+		Expect(handled.Get(context.Background())).To(Equal(2))
+		Eventually(panics).Should(Receive(Equal("panic for test")))
+	})
+
+	It("runs the function with Async.", func() {
+		value, err := util.Async(func() (int, error) {
+			return 1, nil
+		}).Get(context.Background())
+		Expect(value).To(Equal(1))
+		Expect(err).To(BeNil())
+
+		_, err = util.Async(func() (int, error) {
+			return 0, errTest
+		}).Get(context.Background())
+		Expect(err).To(Equal(errTest))
+
+		_, err = util.Async(func() (int, error) {
+			panic("panic for test")
+		}).Get(context.Background())
+		var panicError *util.PanicError
+		Expect(errors.As(err, &panicError)).To(BeTrue())
+	})
+
+	It("runs the function in a WorkerPool with SubmitAsync.", func() {
+		pool := util.NewWorkerPool(1, 1, doNothingHandler)
+		block := make(chan struct{})
+		running, err := util.SubmitAsync(pool, func(ctx context.Context) (int, error) {
+			<-block
+			return 1, nil
+		})
+		Expect(err).To(BeNil())
+		queued, err := util.SubmitAsync(pool, func(ctx context.Context) (int, error) {
+			return 2, nil
+		})
+		Expect(err).To(BeNil())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		Expect(pool.Shutdown(ctx)).To(Equal(context.DeadlineExceeded))
+		close(block)
+
+		Expect(running.Get(context.Background())).To(Equal(1))
+		_, err = queued.Get(context.Background())
+		Expect(err).To(MatchError(util.ErrPoolShutDown))
+
+		_, err = util.SubmitAsync(pool, func(ctx context.Context) (int, error) {
+			return 3, nil
+		})
+		Expect(err).To(Equal(util.ErrPoolShutDown))
+	})
+})
@@ -0,0 +1,114 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// PanicError is returned by Group.Wait when a function panics
+type PanicError struct {
+	Value any
+}
+
+func (p *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", p.Value)
+}
+
+// Group runs functions in goroutines and waits for them. When the first error is returned, the context of the
+// group is canceled, so the other functions can stop early.
+type Group struct {
+	ctx          context.Context
+	cancel       context.CancelFunc
+	wait         sync.WaitGroup
+	panicHandler PanicHandler
+	// semaphore is nil if the concurrency is unbounded
+	semaphore *Semaphore
+
+	errOnce sync.Once
+	err     error
+}
+
+// NewGroup returns a Group and its context derived from ctx. At most limit functions run at the same time,
+// and 0 means no limit. If a function panics, panicHandler is invoked if it's not nil,
+// and the panic is converted to a *PanicError.
+func NewGroup(ctx context.Context, limit int, panicHandler PanicHandler) (*Group, context.Context) {
+	if limit < 0 {
+		panic(fmt.Errorf("limit should be non-negative"))
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	group := &Group{
+		ctx:          ctx,
+		cancel:       cancel,
+		panicHandler: panicHandler,
+	}
+	if limit > 0 {
+		group.semaphore = NewSemaphore(limit)
+	}
+	return group, ctx
+}
+
+// Go runs f in a new goroutine. It blocks until f can run without exceeding the limit.
+func (g *Group) Go(f func(ctx context.Context) error) {
+	if g.semaphore != nil {
+		// Never fails with context.Background()
+		_ = g.semaphore.Acquire(context.Background())
+	}
+	g.run(f)
+}
+
+// TryGo runs f in a new goroutine only if it doesn't exceed the limit. It returns false if f is not run.
+func (g *Group) TryGo(f func(ctx context.Context) error) bool {
+	if g.semaphore != nil && !g.semaphore.TryAcquire() {
+		return false
+	}
+	g.run(f)
+	return true
+}
+
+func (g *Group) run(f func(ctx context.Context) error) {
+	g.wait.Add(1)
+	go func() {
+		defer g.wait.Done()
+		if g.semaphore != nil {
+			defer g.semaphore.Release()
+		}
+
+		if err := g.invoke(f); err != nil {
+			g.errOnce.Do(func() {
+				g.err = err
+				g.cancel()
+			})
+		}
+	}()
+}
+
+func (g *Group) invoke(f func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r}
+			g.handlePanic(r)
+		}
+	}()
+
+	return f(g.ctx)
+}
+
+func (g *Group) handlePanic(r any) {
+	defer func() {
+		recover() // in case a panic happens while handling panics
+	}()
+
+	if g.panicHandler != nil {
+		g.panicHandler(r)
+	}
+}
+
+// Wait blocks until all the functions return, and returns the first error.
+// The context of the group is canceled after Wait returns.
+func (g *Group) Wait() error {
+	g.wait.Wait()
+	g.cancel()
+	return g.err
+}
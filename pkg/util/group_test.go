@@ -0,0 +1,99 @@
+package util_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/linxiaokun528/go-kit/pkg/util"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Group", func() {
+	It("waits for all the functions.", func() {
+		group, _ := util.NewGroup(context.Background(), 0, doNothingHandler)
+		var finished int64
+		for i := 0; i < 10; i++ {
+			group.Go(func(ctx context.Context) error {
+				time.Sleep(time.Millisecond)
+				atomic.AddInt64(&finished, 1)
+				return nil
+			})
+		}
+		Expect(group.Wait()).To(Succeed())
+		Expect(atomic.LoadInt64(&finished)).To(BeEquivalentTo(10))
+	})
+
+	It("cancels the context with the first error.", func() {
+		errFirst := errors.New("first")
+		group, ctx := util.NewGroup(context.Background(), 0, doNothingHandler)
+		group.Go(func(ctx context.Context) error {
+			<-ctx.Done()
+			return errors.New("second")
+		})
+		group.Go(func(ctx context.Context) error {
+			return errFirst
+		})
+
+		Eventually(ctx.Done()).Should(BeClosed())
+		Expect(group.Wait()).To(Equal(errFirst))
+	})
+
+	It("cancels the context after Wait returns.", func() {
+		group, ctx := util.NewGroup(context.Background(), 0, doNothingHandler)
+		group.Go(func(ctx context.Context) error {
+			return nil
+		})
+		Expect(group.Wait()).To(Succeed())
+		Expect(ctx.Done()).To(BeClosed())
+	})
+
+	It("bounds the concurrency.", func() {
+		group, _ := util.NewGroup(context.Background(), 2, doNothingHandler)
+		block := make(chan struct{})
+		var running int64
+		for i := 0; i < 2; i++ {
+			group.Go(func(ctx context.Context) error {
+				atomic.AddInt64(&running, 1)
+				<-block
+				return nil
+			})
+		}
+		Expect(group.TryGo(func(ctx context.Context) error { return nil })).To(BeFalse())
+
+		started := make(chan struct{})
+		go func() {
+			group.Go(func(ctx context.Context) error { return nil })
+			close(started)
+		}()
+		Consistently(started).ShouldNot(BeClosed())
+		close(block)
+		Eventually(started).Should(BeClosed())
+		Expect(group.Wait()).To(Succeed())
+		Expect(group.TryGo(func(ctx context.Context) error { return nil })).To(BeTrue())
+	})
+
+	It("converts the panics to errors.", func() {
+		var handled any
+		group, _ := util.NewGroup(context.Background(), 0, func(r any) {
+			handled = r
+			panic(r)
+		})
+		group.Go(func(ctx context.Context) error {
+			panic("panic for test")
+		})
+
+		err := group.Wait()
+		var panicError *util.PanicError
+		Expect(errors.As(err, &panicError)).To(BeTrue())
+		Expect(panicError.Value).To(Equal("panic for test"))
+		Expect(err).To(MatchError("panic: panic for test"))
+		Expect(handled).To(Equal("panic for test"))
+	})
+
+	It("panics with a negative limit.", func() {
+		Expect(func() { util.NewGroup(context.Background(), -1, doNothingHandler) }).To(Panic())
+	})
+})
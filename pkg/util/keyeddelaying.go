@@ -0,0 +1,103 @@
+package util
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/linxiaokun528/go-kit/pkg/util/collection"
+)
+
+// DeadlinePolicy decides which deadline is kept when a key is added to a KeyedDelayingChannel again before its item
+// is ready
+type DeadlinePolicy int
+
+const (
+	// KeepEarlierDeadline makes the item ready at the earlier one of the two deadlines
+	KeepEarlierDeadline DeadlinePolicy = iota
+	// KeepLaterDeadline makes the item ready at the later one of the two deadlines
+	KeepLaterDeadline
+)
+
+type keyedItem[T any] struct {
+	item    T
+	readyAt time.Time
+	handle  *TaskHandle
+	// dropped is set to 1 when the item is dropped by DropOldestOnOverflow
+	dropped uint32
+}
+
+// KeyedDelayingChannel is a DelayingChannel that collapses the items with the same key, like the delaying queue of
+// k8s workqueue. Adding a key that is not ready yet reschedules it according to the DeadlinePolicy instead of adding
+// a duplicate, and the newest item of the key is the one to get.
+// The keys don't need to be comparable, because they are stored in a collection.Map with a custom hasher.
+type KeyedDelayingChannel[K any, T any] struct {
+	*DelayingChannel[T]
+	policy DeadlinePolicy
+	lock   sync.Mutex
+	// items holds the keys that are not ready yet
+	items collection.Map[K, *keyedItem[T]]
+}
+
+func NewKeyedDelayingChannel[K any, T any, C comparable](size int, hasher collection.Hasher[K, C],
+	equaler collection.Equaler[K], policy DeadlinePolicy, opts ...DelayingOption) *KeyedDelayingChannel[K, T] {
+	return &KeyedDelayingChannel[K, T]{
+		DelayingChannel: NewDelayingChannel[T](size, opts...),
+		policy:          policy,
+		items:           collection.NewMap[K, *keyedItem[T], C](hasher, equaler),
+	}
+}
+
+// AddAfterKeyed adds item after duration, unless the key has been added and is not ready yet, in which case the item
+// of the key is replaced and the key is rescheduled according to the DeadlinePolicy.
+// If the key is becoming ready at the same time, the item may be ready before the later deadline.
+func (k *KeyedDelayingChannel[K, T]) AddAfterKeyed(key K, item T, duration time.Duration) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	readyAt := k.executor.clock.Now().Add(duration)
+	if existing, exists := k.items.Get(key); exists && atomic.LoadUint32(&existing.dropped) == 0 {
+		existing.item = item
+		if k.policy == KeepEarlierDeadline && readyAt.Before(existing.readyAt) ||
+			k.policy == KeepLaterDeadline && readyAt.After(existing.readyAt) {
+			// Fails only if the key is becoming ready, which will get the new item
+			if existing.handle.Reschedule(duration) {
+				existing.readyAt = readyAt
+			}
+		}
+		return
+	}
+
+	entry := &keyedItem[T]{item: item, readyAt: readyAt}
+	k.items.Put(key, entry)
+	defer func() {
+		if r := recover(); r != nil { // The channel has been closed
+			k.items.Remove(key)
+			panic(r)
+		}
+	}()
+	entry.handle = k.add(func() T {
+		k.lock.Lock()
+		defer k.lock.Unlock()
+
+		k.remove(key, entry)
+		return entry.item
+	}, duration, func() {
+		// It's called in the goroutine that adds an item, which may hold k.lock
+		atomic.StoreUint32(&entry.dropped, 1)
+		go func() {
+			k.lock.Lock()
+			defer k.lock.Unlock()
+
+			k.remove(key, entry)
+		}()
+	})
+}
+
+// remove should be called with k.lock held
+func (k *KeyedDelayingChannel[K, T]) remove(key K, entry *keyedItem[T]) {
+	// The key may have been added again
+	if current, exists := k.items.Get(key); exists && current == entry {
+		k.items.Remove(key)
+	}
+}
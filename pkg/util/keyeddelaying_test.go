@@ -0,0 +1,93 @@
+package util_test
+
+import (
+	"time"
+
+	"github.com/linxiaokun528/go-kit/pkg/util"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type keyedItem struct {
+	key   string
+	value int
+}
+
+var _ = Describe("KeyedDelayingChannel", func() {
+	var maxDeviation time.Duration
+
+	newChannel := func(policy util.DeadlinePolicy,
+		opts ...util.DelayingOption) *util.KeyedDelayingChannel[string, keyedItem] {
+		return util.NewKeyedDelayingChannel[string, keyedItem, string](5, func(key string) string {
+			return key
+		}, func(first, second string) bool {
+			return first == second
+		}, policy, opts...)
+	}
+
+	BeforeEach(func() {
+		maxDeviation = 50 * time.Millisecond
+	})
+
+	It("collapses the items with the same key and keeps the earlier deadline.", func() {
+		ch := newChannel(util.KeepEarlierDeadline)
+		start := time.Now()
+		ch.AddAfterKeyed("a", keyedItem{key: "a", value: 1}, 200*time.Millisecond)
+		ch.AddAfterKeyed("b", keyedItem{key: "b", value: 1}, 150*time.Millisecond)
+		ch.AddAfterKeyed("a", keyedItem{key: "a", value: 2}, 100*time.Millisecond)
+		ch.AddAfterKeyed("a", keyedItem{key: "a", value: 3}, 300*time.Millisecond)
+
+		Expect(ch.Get()).To(Equal(keyedItem{key: "a", value: 3}))
+		Expect(time.Now()).To(BeTemporally("~", start.Add(100*time.Millisecond), maxDeviation))
+		Expect(ch.Get()).To(Equal(keyedItem{key: "b", value: 1}))
+		Expect(time.Now()).To(BeTemporally("~", start.Add(150*time.Millisecond), maxDeviation))
+
+		ch.Close()
+		Expect(ch.Get()).To(Equal(keyedItem{}))
+	})
+
+	It("keeps the later deadline.", func() {
+		ch := newChannel(util.KeepLaterDeadline)
+		start := time.Now()
+		ch.AddAfterKeyed("a", keyedItem{key: "a", value: 1}, 100*time.Millisecond)
+		ch.AddAfterKeyed("a", keyedItem{key: "a", value: 2}, 200*time.Millisecond)
+		ch.AddAfterKeyed("a", keyedItem{key: "a", value: 3}, 50*time.Millisecond)
+
+		Expect(ch.Get()).To(Equal(keyedItem{key: "a", value: 3}))
+		Expect(time.Now()).To(BeTemporally("~", start.Add(200*time.Millisecond), maxDeviation))
+		ch.Close()
+		Expect(ch.Get()).To(Equal(keyedItem{}))
+	})
+
+	It("adds the key again after it's ready.", func() {
+		ch := newChannel(util.KeepEarlierDeadline)
+		ch.AddAfterKeyed("a", keyedItem{key: "a", value: 1}, 0)
+		Expect(ch.Get()).To(Equal(keyedItem{key: "a", value: 1}))
+		ch.AddAfterKeyed("a", keyedItem{key: "a", value: 2}, 0)
+		Expect(ch.Get()).To(Equal(keyedItem{key: "a", value: 2}))
+		ch.Close()
+	})
+
+	It("can't add new items after closed.", func() {
+		ch := newChannel(util.KeepEarlierDeadline)
+		ch.Close()
+		Expect(func() { ch.AddAfterKeyed("a", keyedItem{}, 0) }).To(Panic())
+		Expect(func() { ch.AddAfterKeyed("a", keyedItem{}, 0) }).To(Panic())
+	})
+
+	It("adds the key again after it's dropped.", func() {
+		ch := newChannel(util.KeepEarlierDeadline, util.WithOverflowPolicy(util.DropOldestOnOverflow))
+		for i := 0; i < 100; i++ {
+			ch.AddAfterKeyed(string(rune('a'+i%26)), keyedItem{value: i + 1}, 0)
+		}
+		ch.Close()
+		// Every key is either got or dropped, so Get returns the zero value eventually
+		closed := make(chan struct{})
+		go func() {
+			for ch.Get() != (keyedItem{}) {
+			}
+			close(closed)
+		}()
+		Eventually(closed).Should(BeClosed())
+	})
+})
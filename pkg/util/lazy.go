@@ -0,0 +1,130 @@
+package util
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/linxiaokun528/go-kit/pkg/util/collection"
+	"k8s.io/utils/clock"
+)
+
+// Lazy returns a function that invokes init only once when it's called for the first time, and returns the value
+// of init ever since. It's safe to call the function concurrently.
+// If init panics, the function panics with the same value every time it's called.
+func Lazy[T any](init func() T) func() T {
+	var once sync.Once
+	var value T
+	var panicked bool
+	var panicValue any
+
+	return func() T {
+		once.Do(func() {
+			defer func() {
+				if r := recover(); r != nil {
+					panicked = true
+					panicValue = r
+				}
+			}()
+
+			value = init()
+		})
+
+		if panicked {
+			panic(panicValue)
+		}
+		return value
+	}
+}
+
+type memoizeOptions struct {
+	maxSize int
+	ttl     time.Duration
+	clock   clock.PassiveClock
+}
+
+// MemoizeOption configures the function returned by Memoize
+type MemoizeOption func(*memoizeOptions)
+
+// WithMemoizeMaxSize keeps the results of at most maxSize keys. When it's full, the least recently used result is
+// dropped. By default, the size is unlimited.
+func WithMemoizeMaxSize(maxSize int) MemoizeOption {
+	if maxSize <= 0 {
+		panic(fmt.Errorf("maxSize should be positive"))
+	}
+
+	return func(o *memoizeOptions) {
+		o.maxSize = maxSize
+	}
+}
+
+// WithMemoizeTTL makes the results expire ttl after they are computed. By default, the results never expire.
+func WithMemoizeTTL(ttl time.Duration) MemoizeOption {
+	if ttl <= 0 {
+		panic(fmt.Errorf("ttl should be positive"))
+	}
+
+	return func(o *memoizeOptions) {
+		o.ttl = ttl
+	}
+}
+
+// WithMemoizeClock makes the TTL use clock, e.g. a fake clock in the tests
+func WithMemoizeClock(clock clock.PassiveClock) MemoizeOption {
+	return func(o *memoizeOptions) {
+		o.clock = clock
+	}
+}
+
+// Memoize returns a function that caches the results of f. The concurrent calls with the same absent key invoke f
+// only once. The keys don't need to be comparable, because they are stored in a collection.LRUCache with a custom
+// hasher. If f panics, the call that invokes f panics with the same value, and the concurrent calls waiting for it
+// panic with an error.
+func Memoize[K any, V any, C comparable](f func(key K) V, hasher collection.Hasher[K, C],
+	equaler collection.Equaler[K], opts ...MemoizeOption) func(key K) V {
+	o := memoizeOptions{
+		maxSize: math.MaxInt,
+		clock:   clock.RealClock{},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var lock sync.Mutex
+	results := collection.NewLRUCache[K, V, C](o.maxSize, o.clock, nil, hasher, equaler)
+	singleFlight := NewSingleFlight[K, V, C](hasher, equaler)
+
+	return func(key K) V {
+		lock.Lock()
+		value, exists := results.Get(key)
+		lock.Unlock()
+		if exists {
+			return value
+		}
+
+		value, err, _ := singleFlight.Do(key, func() (V, error) {
+			// Another call may have finished after the Get above
+			lock.Lock()
+			value, exists := results.Get(key)
+			lock.Unlock()
+			if exists {
+				return value, nil
+			}
+
+			value = f(key)
+			lock.Lock()
+			defer lock.Unlock()
+			if o.ttl > 0 {
+				results.PutWithTTL(key, value, o.ttl)
+			} else {
+				results.Put(key, value)
+			}
+			return value, nil
+		})
+		if err != nil { // f panics in another call
+			panic(err)
+		}
+		return value
+	}
+}
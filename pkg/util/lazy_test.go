@@ -0,0 +1,143 @@
+package util_test
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/linxiaokun528/go-kit/pkg/util"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+var _ = Describe("Lazy", func() {
+	It("invokes init only once.", func() {
+		var inits int64
+		get := util.Lazy(func() int {
+			atomic.AddInt64(&inits, 1)
+			return 1
+		})
+		Expect(atomic.LoadInt64(&inits)).To(BeZero())
+
+		var wait sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wait.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wait.Done()
+				Expect(get()).To(Equal(1))
+			}()
+		}
+		wait.Wait()
+		Expect(atomic.LoadInt64(&inits)).To(BeEquivalentTo(1))
+	})
+
+	It("panics every time if init panics.", func() {
+		var inits int64
+		get := util.Lazy(func() int {
+			atomic.AddInt64(&inits, 1)
+			panic("panic for test")
+		})
+		Expect(func() { get() }).To(PanicWith("panic for test"))
+		Expect(func() { get() }).To(PanicWith("panic for test"))
+		Expect(atomic.LoadInt64(&inits)).To(BeEquivalentTo(1))
+	})
+})
+
+var _ = Describe("Memoize", func() {
+	var calls int64
+	var f func(key int) string
+
+	memoize := func(opts ...util.MemoizeOption) func(key int) string {
+		return util.Memoize[int, string, int](f, func(key int) int {
+			return key
+		}, func(first, second int) bool {
+			return first == second
+		}, opts...)
+	}
+
+	BeforeEach(func() {
+		calls = 0
+		f = func(key int) string {
+			atomic.AddInt64(&calls, 1)
+			return strconv.Itoa(key)
+		}
+	})
+
+	It("caches the results.", func() {
+		memoized := memoize()
+		Expect(memoized(1)).To(Equal("1"))
+		Expect(memoized(1)).To(Equal("1"))
+		Expect(memoized(2)).To(Equal("2"))
+		Expect(atomic.LoadInt64(&calls)).To(BeEquivalentTo(2))
+	})
+
+	It("invokes f only once for the concurrent calls.", func() {
+		block := make(chan struct{})
+		f = func(key int) string {
+			atomic.AddInt64(&calls, 1)
+			<-block
+			return strconv.Itoa(key)
+		}
+		memoized := memoize()
+
+		var wait sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wait.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wait.Done()
+				Expect(memoized(1)).To(Equal("1"))
+			}()
+		}
+		Eventually(func() int64 { return atomic.LoadInt64(&calls) }).Should(BeEquivalentTo(1))
+		time.Sleep(10 * time.Millisecond)
+		close(block)
+		wait.Wait()
+		Expect(atomic.LoadInt64(&calls)).To(BeEquivalentTo(1))
+	})
+
+	It("drops the least recently used results.", func() {
+		memoized := memoize(util.WithMemoizeMaxSize(2))
+		memoized(1)
+		memoized(2)
+		memoized(1)
+		memoized(3)
+		Expect(atomic.LoadInt64(&calls)).To(BeEquivalentTo(3))
+		memoized(1)
+		Expect(atomic.LoadInt64(&calls)).To(BeEquivalentTo(3))
+		memoized(2)
+		Expect(atomic.LoadInt64(&calls)).To(BeEquivalentTo(4))
+	})
+
+	It("expires the results after the TTL.", func() {
+		fakeClock := clocktesting.NewFakeClock(time.Now())
+		memoized := memoize(util.WithMemoizeTTL(time.Second), util.WithMemoizeClock(fakeClock))
+		memoized(1)
+		fakeClock.Step(500 * time.Millisecond)
+		memoized(1)
+		Expect(atomic.LoadInt64(&calls)).To(BeEquivalentTo(1))
+		fakeClock.Step(500 * time.Millisecond)
+		memoized(1)
+		Expect(atomic.LoadInt64(&calls)).To(BeEquivalentTo(2))
+	})
+
+	It("doesn't cache the panics.", func() {
+		f = func(key int) string {
+			if atomic.AddInt64(&calls, 1) == 1 {
+				panic("panic for test")
+			}
+			return strconv.Itoa(key)
+		}
+		memoized := memoize()
+		Expect(func() { memoized(1) }).To(PanicWith("panic for test"))
+		Expect(memoized(1)).To(Equal("1"))
+	})
+
+	It("panics with invalid options.", func() {
+		Expect(func() { util.WithMemoizeMaxSize(0) }).To(Panic())
+		Expect(func() { util.WithMemoizeTTL(0) }).To(Panic())
+	})
+})
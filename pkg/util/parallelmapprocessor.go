@@ -0,0 +1,164 @@
+package util
+
+import (
+	"context"
+	"sync"
+
+	"github.com/linxiaokun528/go-kit/pkg/util/collection"
+)
+
+// MapFunc transforms a product into a result. If an error is returned, the product has no result, and the error is
+// handled according to the ErrorPolicy.
+type MapFunc[T any, R any] func(product T, ctx context.Context) (R, error)
+
+// ParallelMapProcessor Products are transformed by mapFunc concurrently, and the results are sent to a channel or
+// added to a collection. In the ordered mode, the results follow the order of production, see
+// NewSequencedParallelConsumingProcessor. If mapFunc panics or returns an error, the product has no result.
+// The processor can only be started once.
+type ParallelMapProcessor[T any, R any] struct {
+	processor *ParallelConsumingProcessor[mapProduct[T]]
+	results   chan R
+}
+
+type mapProduct[T any] struct {
+	value T
+	seq   uint64
+}
+
+// NewParallelMapProcessor sends the results to the channel returned by Results, whose buffer size is bufferSize.
+// After the processor stops, the held results are flushed and the channel is closed, so the channel should be
+// drained. opts are the same as those of NewParallelConsumingProcessorE.
+func NewParallelMapProcessor[T any, R any](producerFunc ProducerFunc[T], mapFunc MapFunc[T, R], bufferSize int,
+	ordered bool, panicHandler PanicHandler, errorPolicy ErrorPolicy, errorHandler ErrorHandler,
+	opts ...ConsumingOption) *ParallelMapProcessor[T, R] {
+	results := make(chan R, bufferSize)
+	var send func(value R, ctx context.Context) bool
+	if ordered {
+		send = func(value R, ctx context.Context) bool {
+			select {
+			case <-ctx.Done():
+				return false
+			case results <- value:
+				return true
+			}
+		}
+	} else {
+		// Without ordering, nothing is held, so the results are sent even if ctx is done
+		send = func(value R, ctx context.Context) bool {
+			results <- value
+			return true
+		}
+	}
+
+	result := newParallelMapProcessor(producerFunc, mapFunc, ordered, send, panicHandler, errorPolicy,
+		errorHandler, opts)
+	result.results = results
+	onStop := result.processor.processor.onStop
+	result.processor.processor.onStop = func() {
+		onStop()
+		close(results)
+	}
+	return result
+}
+
+// NewParallelMapProcessorWithCollection adds the results to results. results doesn't need to be thread-safe,
+// because the results are added one by one, but then it must not be read until Wait returns.
+// Use a thread-safe collection to read the results while the processor is running. opts are the same as those of NewParallelConsumingProcessorE.
+func NewParallelMapProcessorWithCollection[T any, R any](producerFunc ProducerFunc[T], mapFunc MapFunc[T, R],
+	results collection.Collection[R], ordered bool, panicHandler PanicHandler, errorPolicy ErrorPolicy,
+	errorHandler ErrorHandler, opts ...ConsumingOption) *ParallelMapProcessor[T, R] {
+	// In the ordered mode, orderedDelivery already sends the results one by one
+	lock := sync.Mutex{}
+	send := func(value R, ctx context.Context) bool {
+		if !ordered {
+			lock.Lock()
+			defer lock.Unlock()
+		}
+		results.Add(value)
+		return true
+	}
+
+	return newParallelMapProcessor(producerFunc, mapFunc, ordered, send, panicHandler, errorPolicy, errorHandler,
+		opts)
+}
+
+func newParallelMapProcessor[T any, R any](producerFunc ProducerFunc[T], mapFunc MapFunc[T, R], ordered bool,
+	send func(value R, ctx context.Context) bool, panicHandler PanicHandler, errorPolicy ErrorPolicy,
+	errorHandler ErrorHandler, opts []ConsumingOption) *ParallelMapProcessor[T, R] {
+	var consumerFunc ConsumerFuncE[mapProduct[T]]
+	var produce ProducerFunc[mapProduct[T]]
+	var delivery *orderedDelivery[R]
+
+	if ordered {
+		delivery = newOrderedDelivery(send)
+		producer := &sequencedProducer[T]{producerFunc: producerFunc}
+		produce = func(ctx context.Context) mapProduct[T] {
+			value, seq := producer.produce(ctx)
+			return mapProduct[T]{value: value, seq: seq}
+		}
+		consumerFunc = func(product mapProduct[T], ctx context.Context) error {
+			return delivery.deliverOrSkip(product.seq, ctx, func() (R, error) {
+				return mapFunc(product.value, ctx)
+			})
+		}
+	} else {
+		produce = func(ctx context.Context) mapProduct[T] {
+			return mapProduct[T]{value: producerFunc(ctx)}
+		}
+		consumerFunc = func(product mapProduct[T], ctx context.Context) error {
+			value, err := mapFunc(product.value, ctx)
+			if err != nil {
+				return err
+			}
+			send(value, ctx)
+			return nil
+		}
+	}
+
+	processor := NewParallelConsumingProcessorE(produce, consumerFunc, panicHandler, errorPolicy, errorHandler,
+		opts...)
+	onStop := processor.processor.onStop
+	processor.processor.onStop = func() {
+		if onStop != nil {
+			onStop()
+		}
+		if delivery != nil {
+			delivery.flush()
+		}
+	}
+	return &ParallelMapProcessor[T, R]{processor: processor}
+}
+
+// Results returns the channel of the results. It's nil if the results are added to a collection.
+func (p *ParallelMapProcessor[T, R]) Results() <-chan R {
+	return p.results
+}
+
+func (p *ParallelMapProcessor[T, R]) Start(workerNum int, ctx context.Context) {
+	p.processor.Start(workerNum, ctx)
+}
+
+// StartAsync is the non-blocking version of Start. See ParallelProcessor.StartAsync.
+func (p *ParallelMapProcessor[T, R]) StartAsync(workerNum int, ctx context.Context) {
+	p.processor.StartAsync(workerNum, ctx)
+}
+
+// Stop stops the processor as if the context is done. See ParallelProcessor.Stop.
+func (p *ParallelMapProcessor[T, R]) Stop() {
+	p.processor.Stop()
+}
+
+// Wait blocks until all the workers stop. See ParallelProcessor.Wait.
+func (p *ParallelMapProcessor[T, R]) Wait() {
+	p.processor.Wait()
+}
+
+// Err returns the errors of mapFunc. See ParallelProcessor.Err.
+func (p *ParallelMapProcessor[T, R]) Err() error {
+	return p.processor.Err()
+}
+
+// Resize changes the number of the workers while the processor is running. See ParallelProcessor.Resize.
+func (p *ParallelMapProcessor[T, R]) Resize(workerNum int) bool {
+	return p.processor.Resize(workerNum)
+}
@@ -0,0 +1,117 @@
+package util_test
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"github.com/linxiaokun528/go-kit/pkg/util"
+	"github.com/linxiaokun528/go-kit/pkg/util/collection"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParallelMapProcessor", func() {
+	const productNum = 100
+	errOdd := errors.New("odd")
+
+	var produced int64
+	var producerFunc util.ProducerFunc[int]
+	var mapFunc util.MapFunc[int, int]
+
+	BeforeEach(func() {
+		produced = 0
+		// Stops the processor after productNum products
+		producerFunc = func(ctx context.Context) int {
+			n := atomic.AddInt64(&produced, 1)
+			if n > productNum {
+				<-ctx.Done()
+			}
+			return int(n)
+		}
+		mapFunc = func(product int, ctx context.Context) (int, error) {
+			if product > productNum {
+				return 0, ctx.Err()
+			}
+			time.Sleep(time.Duration(rand.Intn(100)) * time.Microsecond)
+			if product%2 == 1 {
+				return 0, errOdd
+			}
+			if product == productNum/2 {
+				panic("panic in mapFunc")
+			}
+			return product * 10, nil
+		}
+	})
+
+	expected := func() (result []int) {
+		for i := 2; i <= productNum; i += 2 {
+			if i != productNum/2 {
+				result = append(result, i*10)
+			}
+		}
+		return
+	}
+
+	collect := func(results <-chan int, stop func()) (collected []int) {
+		for result := range results {
+			collected = append(collected, result)
+			if len(collected) == len(expected()) {
+				stop()
+			}
+		}
+		return
+	}
+
+	It("sends the results in order.", func() {
+		processor := util.NewParallelMapProcessor(producerFunc, mapFunc, 5, true, doNothingHandler,
+			util.CollectErrors, nil)
+		processor.StartAsync(4, context.Background())
+		Expect(collect(processor.Results(), processor.Stop)).To(Equal(expected()))
+
+		var multiError util.MultiError
+		Expect(errors.As(processor.Err(), &multiError)).To(BeTrue())
+		Expect(multiError).To(ContainElement(errOdd))
+	})
+
+	It("sends the results without ordering.", func() {
+		processor := util.NewParallelMapProcessor(producerFunc, mapFunc, 5, false, doNothingHandler,
+			util.CollectErrors, nil, util.WithBuffer(10), util.WithProducerCount(2))
+		processor.StartAsync(4, context.Background())
+		Expect(collect(processor.Results(), processor.Stop)).To(ConsistOf(expected()))
+	})
+
+	It("adds the results to a collection.", func() {
+		for _, ordered := range []bool{true, false} {
+			produced = 0
+			// results is read before Wait returns, so it must be thread-safe
+			var results collection.Collection[int] = collection.NewThreadSafeRingBuffer[int](productNum,
+				collection.OverwriteOldest, func(first, second int) bool {
+					return first == second
+				})
+			processor := util.NewParallelMapProcessorWithCollection(producerFunc, mapFunc, results, ordered,
+				doNothingHandler, util.CollectErrors, nil)
+			Expect(processor.Results()).To(BeNil())
+
+			processor.StartAsync(4, context.Background())
+			Eventually(results.Len).Should(Equal(len(expected())))
+			processor.Stop()
+			processor.Wait()
+			if ordered {
+				Expect(results.ToArray()).To(Equal(expected()))
+			} else {
+				Expect(results.ToArray()).To(ConsistOf(expected()))
+			}
+		}
+	})
+
+	It("stops on the first error.", func() {
+		processor := util.NewParallelMapProcessor(producerFunc, mapFunc, 100, true, doNothingHandler,
+			util.StopOnFirstError, nil)
+		processor.Start(1, context.Background())
+		Expect(processor.Err()).To(Equal(errOdd))
+		Expect(processor.Results()).To(BeClosed())
+	})
+})
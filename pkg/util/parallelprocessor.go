@@ -3,17 +3,102 @@ package util
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type LoopFunc func(ctx context.Context) bool
+
+// LoopFuncE is a LoopFunc that can return an error, which is handled according to the ErrorPolicy
+type LoopFuncE func(ctx context.Context) (bool, error)
 type PanicHandler func(r any)
 
+// ErrorHandler is invoked for every error returned by a LoopFuncE or a ConsumerFuncE
+type ErrorHandler func(err error)
+
+type ErrorPolicy int
+
+const (
+	// StopOnFirstError stops the processor as if the context is done when the first error is returned
+	StopOnFirstError ErrorPolicy = iota
+	// CollectErrors keeps the processor running and collects all the errors
+	CollectErrors
+)
+
+// MultiError holds the errors collected by a processor with the CollectErrors policy
+type MultiError []error
+
+func (m MultiError) Error() string {
+	messages := make([]string, len(m))
+	for i, err := range m {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("%d errors occurred: %s", len(m), strings.Join(messages, "; "))
+}
+
+// Unwrap makes errors.Is and errors.As check all the errors since go 1.20
+func (m MultiError) Unwrap() []error {
+	return m
+}
+
 type ParallelProcessor struct {
 	loopFunc     LoopFunc
 	panicHandler PanicHandler
 	// If we don't mind relying on k8s library, we can use k8s.io/apimachinery/pkg/util.Group
 	wait sync.WaitGroup
+	// maxPanics is the number of panics before the processor stops itself. 0 means no limit.
+	maxPanics uint64
+	panics    uint64
+	stopped   uint32
+	cancel    context.CancelFunc
+	// lock guards cancel, ctx, retireChs, done, workerHooks, lastWorkerID, limiter and semaphore
+	lock sync.Mutex
+	// ctx is the context of the running routines
+	ctx context.Context
+	// retireChs has a channel for each running routine. Closing the channel retires the routine.
+	retireChs []chan struct{}
+	// done is closed when the latest run stops. It's nil if the processor has never started.
+	done chan struct{}
+	// onStart is invoked with the context of the routines before they are spawned
+	onStart func(ctx context.Context)
+	// onStop is invoked after all the routines stop
+	onStop func()
+
+	errorPolicy  ErrorPolicy
+	errorHandler ErrorHandler
+	// errs are the errors of the latest run
+	errs    []error
+	errLock sync.Mutex
+
+	workerHooks  WorkerHooks
+	lastWorkerID int
+	// limiter is shared by all the routines. Nil means no limit.
+	limiter Limiter
+	// semaphore bounds the invocations of loopFunc in flight, and can be shared with other processors
+	semaphore *Semaphore
+}
+
+// WorkerHooks Callbacks for the lifecycle of every worker routine. A nil hook is a no-op.
+// The panics of the hooks are handled like the ones of loopFunc, and a worker whose OnStart panics stops at once.
+type WorkerHooks struct {
+	// OnStart is called in the worker routine before it runs any loopFunc. The returned context is passed to
+	// loopFunc and OnStop, so it can carry worker-local values like buffers or connections.
+	OnStart func(ctx context.Context) context.Context
+	// OnStop is called in the worker routine after it stops, even if it's retired by Resize
+	OnStop func(ctx context.Context)
+}
+
+type workerIDKey struct{}
+
+// WorkerIDFromContext returns the ID of the worker routine that runs loopFunc with ctx.
+// The workers started by Start or StartAsync have IDs from 0 to consumerNum - 1,
+// and the workers spawned by Resize get new IDs that haven't been used in the same run.
+func WorkerIDFromContext(ctx context.Context) (int, bool) {
+	id, ok := ctx.Value(workerIDKey{}).(int)
+	return id, ok
 }
 
 func NewParallelProcessor(loopFunc LoopFunc, panicHandler PanicHandler) *ParallelProcessor {
@@ -24,25 +109,291 @@ func NewParallelProcessor(loopFunc LoopFunc, panicHandler PanicHandler) *Paralle
 	}
 }
 
+// NewParallelProcessorE creates a ParallelProcessor whose loopFunc can return errors.
+// The errors are handled according to errorPolicy, and errorHandler is invoked for every error if it's not nil.
+// Use Err to get the errors after the processor stops.
+func NewParallelProcessorE(loopFunc LoopFuncE, panicHandler PanicHandler, errorPolicy ErrorPolicy,
+	errorHandler ErrorHandler) *ParallelProcessor {
+	processor := NewParallelProcessor(nil, panicHandler)
+	processor.errorPolicy = errorPolicy
+	processor.errorHandler = errorHandler
+	processor.loopFunc = func(ctx context.Context) bool {
+		goNext, err := loopFunc(ctx)
+		if err != nil && !processor.handleError(err) {
+			return false
+		}
+		return goNext
+	}
+	return processor
+}
+
+// handleError returns false if the processor should stop
+func (p *ParallelProcessor) handleError(err error) bool {
+	p.errLock.Lock()
+	p.errs = append(p.errs, err)
+	p.errLock.Unlock()
+
+	if p.errorHandler != nil {
+		p.errorHandler(err)
+	}
+
+	if p.errorPolicy == StopOnFirstError {
+		p.Stop()
+		return false
+	}
+	return true
+}
+
+// Err returns the errors of the latest run. With the StopOnFirstError policy, it returns the first error.
+// Other routines may return errors before they find the processor is stopped, which are only passed to the
+// ErrorHandler. With the CollectErrors policy, it returns a MultiError. It returns nil if there is no error.
+func (p *ParallelProcessor) Err() error {
+	p.errLock.Lock()
+	defer p.errLock.Unlock()
+
+	if len(p.errs) == 0 {
+		return nil
+	}
+	if p.errorPolicy == StopOnFirstError {
+		return p.errs[0]
+	}
+	return append(MultiError{}, p.errs...)
+}
+
+// SetWorkerHooks sets the hooks for the workers. It only takes effect on the workers spawned afterwards.
+func (p *ParallelProcessor) SetWorkerHooks(hooks WorkerHooks) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.workerHooks = hooks
+}
+
+// SetLimiter makes every invocation of loopFunc wait for the limiter, e.g. a TokenBucket.
+// It only takes effect on the workers spawned afterwards. A nil limiter removes the limit.
+func (p *ParallelProcessor) SetLimiter(limiter Limiter) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.limiter = limiter
+}
+
+// SetSemaphore makes every invocation of loopFunc hold the semaphore, so that a semaphore shared by several
+// processors bounds their invocations in flight in total.
+// It only takes effect on the workers spawned afterwards. A nil semaphore removes the limit.
+func (p *ParallelProcessor) SetSemaphore(semaphore *Semaphore) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.semaphore = semaphore
+}
+
+// NewParallelProcessorWithPanicLimit When the total number of panics in all routines reaches maxPanics,
+// the processor stops as if the context is done. A stopped processor can't be started again.
+func NewParallelProcessorWithPanicLimit(loopFunc LoopFunc, panicHandler PanicHandler,
+	maxPanics uint64) *ParallelProcessor {
+	processor := NewParallelProcessor(loopFunc, panicHandler)
+	processor.maxPanics = maxPanics
+	return processor
+}
+
 // Start : blocks until ctx is done or loopFunc returns false in all routines
 func (p *ParallelProcessor) Start(consumerNum int, ctx context.Context) {
+	ctx, cancel, done := p.newRun(consumerNum, ctx)
+	p.run(consumerNum, ctx, cancel, done)
+}
+
+// StartAsync is the non-blocking version of Start. Use Wait to wait for the routines to stop.
+func (p *ParallelProcessor) StartAsync(consumerNum int, ctx context.Context) {
+	ctx, cancel, done := p.newRun(consumerNum, ctx)
+	go p.run(consumerNum, ctx, cancel, done)
+}
+
+// newRun sets p.cancel before returning, so a Stop right after StartAsync won't be lost
+func (p *ParallelProcessor) newRun(consumerNum int, parent context.Context) (
+	ctx context.Context, cancel context.CancelFunc, done chan struct{}) {
 	if consumerNum <= 0 {
 		panic(fmt.Errorf("consumerNum should be positive"))
 	}
 
-	p.wait.Add(consumerNum)
-	for i := 0; i < consumerNum; i++ {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	ctx, cancel = context.WithCancel(parent)
+	p.cancel = cancel
+	done = make(chan struct{})
+	p.done = done
+	p.lastWorkerID = 0
+
+	p.errLock.Lock()
+	p.errs = nil
+	p.errLock.Unlock()
+	return
+}
+
+func (p *ParallelProcessor) run(consumerNum int, ctx context.Context, cancel context.CancelFunc,
+	done chan struct{}) {
+	defer close(done)
+	if p.onStop != nil {
+		defer p.onStop()
+	}
+	defer cancel()
+
+	p.lock.Lock()
+	if atomic.LoadUint32(&p.stopped) == 1 {
+		p.lock.Unlock()
+		return
+	}
+	p.ctx = ctx
+	if p.onStart != nil {
+		p.onStart(ctx)
+	}
+	p.spawn(consumerNum)
+	p.lock.Unlock()
+	p.wait.Wait()
+}
+
+// Stop stops the processor as if the context is done. It doesn't wait for the routines to stop.
+// Unlike reaching the panic limit, the processor can be started again after it stops.
+func (p *ParallelProcessor) Stop() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.cancel != nil {
+		p.cancel()
+	}
+}
+
+// Wait blocks until the routines of the latest Start or StartAsync stop.
+// It returns immediately if the processor has never started.
+func (p *ParallelProcessor) Wait() {
+	p.lock.Lock()
+	done := p.done
+	p.lock.Unlock()
+
+	if done != nil {
+		<-done
+	}
+}
+
+// spawn should be called with p.lock held
+func (p *ParallelProcessor) spawn(num int) {
+	ctx := p.ctx
+	hooks := p.workerHooks
+	limiter := p.limiter
+	semaphore := p.semaphore
+	p.wait.Add(num)
+	for i := 0; i < num; i++ {
+		retireCh := make(chan struct{})
+		p.retireChs = append(p.retireChs, retireCh)
+		ctx := context.WithValue(ctx, workerIDKey{}, p.lastWorkerID)
+		p.lastWorkerID++
 		go func() {
 			defer p.wait.Done()
-			for p.worker(ctx) {
-
+			defer p.removeRetireCh(retireCh)
+			if hooks.OnStart != nil {
+				started := false
+				p.invoke(func() bool {
+					ctx = hooks.OnStart(ctx)
+					started = true
+					return true
+				})
+				if !started {
+					return
+				}
+			}
+			if hooks.OnStop != nil {
+				defer p.invoke(func() bool {
+					hooks.OnStop(ctx)
+					return true
+				})
+			}
+			for {
+				select {
+				case <-retireCh:
+					return
+				default:
+				}
+				if limiter != nil && limiter.Wait(ctx) != nil {
+					return
+				}
+				if semaphore != nil && semaphore.Acquire(ctx) != nil {
+					return
+				}
+				goNext := p.worker(ctx)
+				if semaphore != nil {
+					semaphore.Release()
+				}
+				if !goNext {
+					return
+				}
 			}
 		}()
 	}
-	p.wait.Wait()
 }
 
-func (p *ParallelProcessor) worker(ctx context.Context) (goNext bool) {
+func (p *ParallelProcessor) removeRetireCh(retireCh chan struct{}) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for i, ch := range p.retireChs {
+		if ch == retireCh {
+			p.retireChs = append(p.retireChs[:i], p.retireChs[i+1:]...)
+			return
+		}
+	}
+}
+
+// Resize changes the number of the routines while the processor is running. Extra routines are spawned at once,
+// and the retired routines exit after their current loopFunc returns.
+// It returns false if no routine is running, e.g. the processor hasn't started or has stopped.
+func (p *ParallelProcessor) Resize(consumerNum int) bool {
+	if consumerNum <= 0 {
+		panic(fmt.Errorf("consumerNum should be positive"))
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	// A routine removes its channel before it exits, so p.wait is still positive and Start is still blocked here
+	running := len(p.retireChs)
+	if running == 0 {
+		return false
+	}
+
+	if consumerNum > running {
+		p.spawn(consumerNum - running)
+	} else {
+		for _, retireCh := range p.retireChs[consumerNum:] {
+			close(retireCh)
+		}
+		// The retired routines won't find their channels, which is fine
+		p.retireChs = p.retireChs[:consumerNum]
+	}
+	return true
+}
+
+// Size returns the number of the running routines, excluding the retired ones that haven't exited
+func (p *ParallelProcessor) Size() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return len(p.retireChs)
+}
+
+func (p *ParallelProcessor) worker(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	default:
+		return p.invoke(func() bool {
+			return p.loopFunc(ctx)
+		})
+	}
+}
+
+// invoke handles the panics of f like loopFunc. If f panics, it returns false after panicHandler handles the panic,
+// or true if panicHandler panics too.
+func (p *ParallelProcessor) invoke(f func() bool) (goNext bool) {
 	defer func() {
 		if r := recover(); r != nil { // in case a panic happens while handling panics
 			goNext = true
@@ -57,76 +408,573 @@ func (p *ParallelProcessor) worker(ctx context.Context) (goNext bool) {
 		}()
 	}
 
-	select {
-	case <-ctx.Done():
-		return false
-	default:
-		return p.loopFunc(ctx)
+	if p.maxPanics > 0 {
+		defer func() {
+			if r := recover(); r != nil {
+				p.countPanic()
+				panic(r)
+			}
+		}()
+	}
+
+	return f()
+}
+
+func (p *ParallelProcessor) countPanic() {
+	if atomic.AddUint64(&p.panics, 1) >= p.maxPanics {
+		atomic.StoreUint32(&p.stopped, 1)
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		p.cancel()
 	}
 }
 
 type ProducerFunc[T any] func(ctx context.Context) T
 type ConsumerFunc[T any] func(product T, ctx context.Context)
+
+// ConsumerFuncE is a ConsumerFunc that can return an error, which is handled according to the ErrorPolicy
+type ConsumerFuncE[T any] func(product T, ctx context.Context) error
 type ParallelConsumingProcessor[T any] struct {
 	producerFunc ProducerFunc[T]
-	consumerFunc ConsumerFunc[T]
+	consumerFunc ConsumerFuncE[T]
 	processor    *ParallelProcessor
+	// producers, producerCount and products are only used in the buffered mode
+	producers     *ParallelProcessor
+	producerCount int
+	products      chan T
+
+	drain        bool
+	drainTimeout time.Duration
+	// drainFunc consumes the products left in the buffered mode when the processor stops
+	drainFunc func(products []T, ctx context.Context)
+	// leftovers are the products that the producers fail to send to the buffer because ctx is done
+	leftovers    []T
+	leftoverLock sync.Mutex
+}
+
+// ConsumingOption configures a ParallelConsumingProcessor
+type ConsumingOption func(*consumingOptions)
+
+type consumingOptions struct {
+	buffered      bool
+	buffer        int
+	producerCount int
+	limiter       Limiter
+	semaphore     *Semaphore
+	drain         bool
+	drainTimeout  time.Duration
+}
+
+// WithBuffer enables the buffered mode, in which producerFunc and consumerFunc run in different routines and the
+// products are passed through a channel with the buffer size n, so a slow consumer doesn't block production.
+func WithBuffer(n int) ConsumingOption {
+	if n < 0 {
+		panic(fmt.Errorf("the buffer size should be non-negative"))
+	}
+	return func(o *consumingOptions) {
+		o.buffered = true
+		o.buffer = n
+	}
+}
+
+// WithProducerCount enables the buffered mode with m producer routines. See WithBuffer.
+// Without this option, the buffered mode has one producer routine.
+func WithProducerCount(m int) ConsumingOption {
+	if m <= 0 {
+		panic(fmt.Errorf("producerCount should be positive"))
+	}
+	return func(o *consumingOptions) {
+		o.buffered = true
+		o.producerCount = m
+	}
 }
 
 func NewParallelConsumingProcessor[T any](producerFunc ProducerFunc[T], consumerFunc ConsumerFunc[T],
-	panicHandler PanicHandler) *ParallelConsumingProcessor[T] {
-	result := ParallelConsumingProcessor[T]{
+	panicHandler PanicHandler, opts ...ConsumingOption) *ParallelConsumingProcessor[T] {
+	result := &ParallelConsumingProcessor[T]{
+		producerFunc: producerFunc,
+		consumerFunc: func(product T, ctx context.Context) error {
+			consumerFunc(product, ctx)
+			return nil
+		},
+	}
+	o := newConsumingOptions(opts)
+	loopFunc := result.loopFuncE(o, panicHandler)
+	result.processor = NewParallelProcessor(func(ctx context.Context) bool {
+		goNext, _ := loopFunc(ctx)
+		return goNext
+	}, panicHandler)
+	result.applyOptions(o)
+	return result
+}
+
+// NewParallelConsumingProcessorE creates a ParallelConsumingProcessor whose consumerFunc can return errors.
+// See NewParallelProcessorE for errorPolicy and errorHandler.
+func NewParallelConsumingProcessorE[T any](producerFunc ProducerFunc[T], consumerFunc ConsumerFuncE[T],
+	panicHandler PanicHandler, errorPolicy ErrorPolicy, errorHandler ErrorHandler,
+	opts ...ConsumingOption) *ParallelConsumingProcessor[T] {
+	result := &ParallelConsumingProcessor[T]{
 		producerFunc: producerFunc,
 		consumerFunc: consumerFunc,
 	}
-	result.processor = NewParallelProcessor(result.process, panicHandler)
-	return &result
+	o := newConsumingOptions(opts)
+	result.processor = NewParallelProcessorE(result.loopFuncE(o, panicHandler), panicHandler, errorPolicy,
+		errorHandler)
+	result.applyOptions(o)
+	return result
+}
+
+func (p *ParallelConsumingProcessor[T]) loopFuncE(o consumingOptions, panicHandler PanicHandler) LoopFuncE {
+	if !o.buffered {
+		return p.process
+	}
+
+	p.setUpBuffer(o, panicHandler)
+	return p.consume
+}
+
+// WithRateLimit makes the processor consume at most perSecond products per second on average,
+// and at most burst products at once. See NewTokenBucket.
+func WithRateLimit(perSecond float64, burst int) ConsumingOption {
+	limiter := NewTokenBucket(perSecond, burst)
+	return WithLimiter(limiter)
+}
+
+// WithLimiter makes the processor wait for limiter before consuming a product. See ParallelProcessor.SetLimiter.
+func WithLimiter(limiter Limiter) ConsumingOption {
+	return func(o *consumingOptions) {
+		o.limiter = limiter
+	}
+}
+
+// WithDrain makes the processor consume the products that have been produced when it stops, instead of dropping
+// them. The products are consumed with a new ctx, which is done after timeout. 0 means no timeout.
+// The producers aren't invoked any more once the processor stops.
+func WithDrain(timeout time.Duration) ConsumingOption {
+	if timeout < 0 {
+		panic(fmt.Errorf("the drain timeout should be non-negative"))
+	}
+	return func(o *consumingOptions) {
+		o.drain = true
+		o.drainTimeout = timeout
+	}
+}
+
+// WithSemaphore makes every consuming hold semaphore. See ParallelProcessor.SetSemaphore.
+func WithSemaphore(semaphore *Semaphore) ConsumingOption {
+	return func(o *consumingOptions) {
+		o.semaphore = semaphore
+	}
+}
+
+func newConsumingOptions(opts []ConsumingOption) consumingOptions {
+	o := consumingOptions{producerCount: 1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+func (p *ParallelConsumingProcessor[T]) setUpBuffer(o consumingOptions, panicHandler PanicHandler) {
+	p.products = make(chan T, o.buffer)
+	p.producerCount = o.producerCount
+	p.producers = NewParallelProcessor(p.produce, panicHandler)
+}
+
+// applyOptions should be called after p.processor is created
+func (p *ParallelConsumingProcessor[T]) applyOptions(o consumingOptions) {
+	p.processor.limiter = o.limiter
+	p.processor.semaphore = o.semaphore
+	p.drain = o.drain
+	p.drainTimeout = o.drainTimeout
+	if p.drainFunc == nil {
+		p.drainFunc = p.drainProducts
+	}
+	if p.producers == nil {
+		return
+	}
+
+	// The producers start and stop with the consumers in the buffered mode
+	p.processor.onStart = func(ctx context.Context) {
+		p.producers.StartAsync(p.producerCount, ctx)
+	}
+	p.processor.onStop = func() {
+		// ctx of the consumers is done before onStop is invoked, so the producers are stopping
+		p.producers.Wait()
+
+		// Take the products left in the buffer, so that they won't be consumed in the next run
+		var products []T
+		for taken := false; !taken; {
+			select {
+			case product := <-p.products:
+				products = append(products, product)
+			default:
+				taken = true
+			}
+		}
+		p.leftoverLock.Lock()
+		products = append(products, p.leftovers...)
+		p.leftovers = nil
+		p.leftoverLock.Unlock()
+
+		if p.drain && len(products) > 0 {
+			ctx, cancel := p.drainContext()
+			defer cancel()
+			p.drainFunc(products, ctx)
+		}
+	}
+}
+
+func (p *ParallelConsumingProcessor[T]) drainContext() (context.Context, context.CancelFunc) {
+	if p.drainTimeout > 0 {
+		return context.WithTimeout(context.Background(), p.drainTimeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// drainProducts consumes the products one by one until ctx is done, or a panic or an error stops the processor
+func (p *ParallelConsumingProcessor[T]) drainProducts(products []T, ctx context.Context) {
+	for _, product := range products {
+		if ctx.Err() != nil {
+			return
+		}
+		goNext := p.processor.invoke(func() bool {
+			err := p.consumerFunc(product, ctx)
+			return err == nil || p.processor.handleError(err)
+		})
+		if !goNext {
+			return
+		}
+	}
+}
+
+func (p *ParallelConsumingProcessor[T]) produce(ctx context.Context) bool {
+	product := p.producerFunc(ctx)
+
+	select {
+	case <-ctx.Done():
+		if p.drain {
+			p.leftoverLock.Lock()
+			p.leftovers = append(p.leftovers, product)
+			p.leftoverLock.Unlock()
+		}
+		return false
+	case p.products <- product:
+		return true
+	}
+}
+
+func (p *ParallelConsumingProcessor[T]) consume(ctx context.Context) (bool, error) {
+	select {
+	case <-ctx.Done():
+		return false, nil
+	case product := <-p.products:
+		return true, p.consumerFunc(product, ctx)
+	}
+}
+
+// NewBatchConsumingProcessor Products are grouped into batches before they are passed to batchConsumerFunc.
+// A batch is consumed when it has maxBatch products, or maxWait has passed since its first product arrived.
+// When the processor stops, the partial batches are still consumed, with a ctx that is done.
+// The processor always runs in the buffered mode, and opts can change the buffer size and the number of producers.
+// With WithDrain, the products left in the buffer are consumed in batches of maxBatch.
+func NewBatchConsumingProcessor[T any](producerFunc ProducerFunc[T],
+	batchConsumerFunc func(batch []T, ctx context.Context), maxBatch int, maxWait time.Duration,
+	panicHandler PanicHandler, opts ...ConsumingOption) *ParallelConsumingProcessor[T] {
+	if maxBatch <= 0 {
+		panic(fmt.Errorf("maxBatch should be positive"))
+	}
+	if maxWait <= 0 {
+		panic(fmt.Errorf("maxWait should be positive"))
+	}
+
+	result := &ParallelConsumingProcessor[T]{
+		producerFunc: producerFunc,
+	}
+	o := newConsumingOptions(opts)
+	result.setUpBuffer(o, panicHandler)
+	result.processor = NewParallelProcessor(func(ctx context.Context) bool {
+		batch := result.collect(ctx, maxBatch, maxWait)
+		if len(batch) > 0 {
+			batchConsumerFunc(batch, ctx)
+		}
+		return ctx.Err() == nil
+	}, panicHandler)
+	result.drainFunc = func(products []T, ctx context.Context) {
+		for start := 0; start < len(products) && ctx.Err() == nil; start += maxBatch {
+			end := start + maxBatch
+			if end > len(products) {
+				end = len(products)
+			}
+			result.processor.invoke(func() bool {
+				batchConsumerFunc(products[start:end], ctx)
+				return true
+			})
+		}
+	}
+	result.applyOptions(o)
+	return result
+}
+
+func (p *ParallelConsumingProcessor[T]) collect(ctx context.Context, maxBatch int,
+	maxWait time.Duration) (batch []T) {
+	// timeout is nil and blocks forever before the first product arrives
+	var timeout <-chan time.Time
+	for len(batch) < maxBatch {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timeout:
+			return
+		case product := <-p.products:
+			batch = append(batch, product)
+			if timeout == nil {
+				timer := time.NewTimer(maxWait)
+				defer timer.Stop()
+				timeout = timer.C
+			}
+		}
+	}
+	return
 }
 
 func (p *ParallelConsumingProcessor[T]) Start(consumerNum int, ctx context.Context) {
 	p.processor.Start(consumerNum, ctx)
 }
 
-func (p *ParallelConsumingProcessor[T]) process(ctx context.Context) bool {
-	// Maybe use a channel like the following, so that producer doesn't need to be thread-safe
-	// channel := make(chan T)
-	// go func() {
-	// 	for true {
-	// 		select {
-	// 		case <-ctx.Done():
-	// 			return
-	// 		default:
-	// 			channel <- p.produce()
-	// 		}
-	// 	}
-	// }()
-	//
-	// go func() {
-	// 	for true {
-	// 		select {
-	// 		case <-ctx.Done():
-	// 			return;
-	// 		default:
-	// 			p.consume(<-channel)
-	// 		}
-	// 	}
-	// }()
+// StartAsync is the non-blocking version of Start. See ParallelProcessor.StartAsync.
+func (p *ParallelConsumingProcessor[T]) StartAsync(consumerNum int, ctx context.Context) {
+	p.processor.StartAsync(consumerNum, ctx)
+}
+
+// Stop stops the processor as if the context is done. See ParallelProcessor.Stop.
+func (p *ParallelConsumingProcessor[T]) Stop() {
+	p.processor.Stop()
+}
+
+// Wait blocks until all the consumers stop. See ParallelProcessor.Wait.
+func (p *ParallelConsumingProcessor[T]) Wait() {
+	p.processor.Wait()
+}
+
+// Err returns the errors of the latest run. See ParallelProcessor.Err.
+func (p *ParallelConsumingProcessor[T]) Err() error {
+	return p.processor.Err()
+}
+
+// SetLimiter limits the rate of consuming. See ParallelProcessor.SetLimiter.
+func (p *ParallelConsumingProcessor[T]) SetLimiter(limiter Limiter) {
+	p.processor.SetLimiter(limiter)
+}
 
+// SetSemaphore bounds the consuming in flight. See ParallelProcessor.SetSemaphore.
+func (p *ParallelConsumingProcessor[T]) SetSemaphore(semaphore *Semaphore) {
+	p.processor.SetSemaphore(semaphore)
+}
+
+// SetWorkerHooks sets the hooks for the consumers. See ParallelProcessor.SetWorkerHooks.
+func (p *ParallelConsumingProcessor[T]) SetWorkerHooks(hooks WorkerHooks) {
+	p.processor.SetWorkerHooks(hooks)
+}
+
+// Resize changes the number of the consumers while the processor is running. See ParallelProcessor.Resize.
+func (p *ParallelConsumingProcessor[T]) Resize(consumerNum int) bool {
+	return p.processor.Resize(consumerNum)
+}
+
+func (p *ParallelConsumingProcessor[T]) process(ctx context.Context) (bool, error) {
 	var product T
 
 	select {
 	case <-ctx.Done():
-		return false
+		return false, nil
 	default:
 		product = p.producerFunc(ctx)
 	}
 
+	select {
+	case <-ctx.Done():
+		if !p.drain {
+			return false, nil
+		}
+		drainCtx, cancel := p.drainContext()
+		defer cancel()
+		return false, p.consumerFunc(product, drainCtx)
+	default:
+		return true, p.consumerFunc(product, ctx)
+	}
+}
+
+// NewSequencedParallelConsumingProcessor Products are tagged with sequence numbers when they are produced.
+// Consumers work concurrently, but the results are sent to the returned channel in the order of production.
+// A result is held until the results of all the previous products arrive. If a consumer panics, its result is skipped.
+// consumerNum is used as the buffer size of the returned channel.
+// After the processor stops, the held results are flushed and the returned channel is closed,
+// so the returned processor can only be started once and the channel should be drained.
+func NewSequencedParallelConsumingProcessor[T any, R any](producerFunc ProducerFunc[T],
+	consumerFunc func(product T, ctx context.Context) R, consumerNum int,
+	panicHandler PanicHandler) (*ParallelConsumingProcessor[T], <-chan R) {
+	s := &sequencer[T, R]{
+		producer:     sequencedProducer[T]{producerFunc: producerFunc},
+		consumerFunc: consumerFunc,
+		out:          make(chan R, consumerNum),
+	}
+	s.results = newOrderedDelivery(func(value R, ctx context.Context) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case s.out <- value:
+			return true
+		}
+	})
+	result := ParallelConsumingProcessor[T]{
+		producerFunc: producerFunc,
+	}
+	result.processor = NewParallelProcessor(s.process, panicHandler)
+	result.processor.onStop = s.flush
+	return &result, s.out
+}
+
+type sequencer[T any, R any] struct {
+	producer     sequencedProducer[T]
+	consumerFunc func(product T, ctx context.Context) R
+
+	results *orderedDelivery[R]
+	out     chan R
+}
+
+func (s *sequencer[T, R]) process(ctx context.Context) bool {
+	var product T
+	var seq uint64
+
 	select {
 	case <-ctx.Done():
 		return false
 	default:
-		p.consumerFunc(product, ctx)
+		product, seq = s.producer.produce(ctx)
 	}
 
-	return true
+	err := s.results.deliverOrSkip(seq, ctx, func() (result R, err error) {
+		select {
+		case <-ctx.Done(): // The consumer is not invoked
+			return result, ctx.Err()
+		default:
+			return s.consumerFunc(product, ctx), nil
+		}
+	})
+	return err == nil
+}
+
+func (s *sequencer[T, R]) flush() {
+	s.results.flush()
+	close(s.out)
+}
+
+// sequencedProducer tags the products with sequence numbers, which start from 0
+type sequencedProducer[T any] struct {
+	producerFunc ProducerFunc[T]
+
+	// lock makes sure the sequence numbers follow the order of production
+	lock    sync.Mutex
+	nextSeq uint64
+}
+
+func (p *sequencedProducer[T]) produce(ctx context.Context) (product T, seq uint64) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	product = p.producerFunc(ctx)
+	seq = p.nextSeq
+	p.nextSeq++
+	return
+}
+
+type sequencedResult[R any] struct {
+	value   R
+	skipped bool
+}
+
+// orderedDelivery sends the results in the order of their sequence numbers, which start from 0.
+// A result is held until the results of all the previous sequence numbers arrive.
+type orderedDelivery[R any] struct {
+	// send returns false if the result can't be sent because ctx is done
+	send func(value R, ctx context.Context) bool
+
+	lock sync.Mutex
+	// nextResult is the sequence number of the next result to send
+	nextResult uint64
+	pending    map[uint64]sequencedResult[R]
+}
+
+func newOrderedDelivery[R any](send func(value R, ctx context.Context) bool) *orderedDelivery[R] {
+	return &orderedDelivery[R]{
+		send:    send,
+		pending: map[uint64]sequencedResult[R]{},
+	}
+}
+
+func (o *orderedDelivery[R]) deliver(seq uint64, value R, ctx context.Context) {
+	o.put(seq, sequencedResult[R]{value: value}, ctx)
+}
+
+// deliverOrSkip delivers the result of f with seq. If f panics or returns an error, seq is skipped.
+func (o *orderedDelivery[R]) deliverOrSkip(seq uint64, ctx context.Context, f func() (R, error)) error {
+	delivered := false
+	defer func() {
+		if !delivered {
+			o.skip(seq, ctx)
+		}
+	}()
+
+	value, err := f()
+	if err != nil {
+		return err
+	}
+	o.deliver(seq, value, ctx)
+	delivered = true
+	return nil
+}
+
+// skip marks seq as having no result, so that the following results won't wait for it
+func (o *orderedDelivery[R]) skip(seq uint64, ctx context.Context) {
+	o.put(seq, sequencedResult[R]{skipped: true}, ctx)
+}
+
+func (o *orderedDelivery[R]) put(seq uint64, result sequencedResult[R], ctx context.Context) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	o.pending[seq] = result
+	for {
+		next, exists := o.pending[o.nextResult]
+		if !exists {
+			return
+		}
+		if !next.skipped && !o.send(next.value, ctx) {
+			// Keep it in pending. It will be sent by flush.
+			return
+		}
+		delete(o.pending, o.nextResult)
+		o.nextResult++
+	}
+}
+
+// flush sends all the held results in order, even if some previous results never arrive
+func (o *orderedDelivery[R]) flush() {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	seqs := make([]uint64, 0, len(o.pending))
+	for seq := range o.pending {
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool {
+		return seqs[i] < seqs[j]
+	})
+	for _, seq := range seqs {
+		if !o.pending[seq].skipped {
+			o.send(o.pending[seq].value, context.Background())
+		}
+		delete(o.pending, seq)
+	}
 }
@@ -2,10 +2,14 @@ package util_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"math/rand"
 	"sort"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/linxiaokun528/go-kit/pkg/util"
 	"github.com/linxiaokun528/go-kit/pkg/util/collection"
@@ -355,3 +359,712 @@ var _ = Describe("ParallelConsumingProcessor", func() {
 		})
 	})
 })
+
+// newSequenceProducer returns a producer producing 0, 1, 2, ... until `max` (exclusive), then blocks until ctx is done
+func newSequenceProducer(max int) util.ProducerFunc[int] {
+	next := 0
+	// Producers of a sequenced processor are invoked sequentially, so there is no need to lock
+	return func(ctx context.Context) int {
+		if next >= max {
+			<-ctx.Done()
+			return -1
+		}
+		next++
+		return next - 1
+	}
+}
+
+var _ = Describe("SequencedParallelConsumingProcessor", func() {
+	var ctx context.Context
+	var cancelFunc context.CancelFunc
+
+	start := func(processor *util.ParallelConsumingProcessor[int], results <-chan int, consumerNum int) {
+		stopCh := make(chan bool)
+		go func() {
+			processor.Start(consumerNum, ctx)
+			close(stopCh)
+		}()
+		DeferCleanup(func() {
+			cancelFunc()
+			for range results {
+			}
+			<-stopCh
+		})
+	}
+
+	BeforeEach(func() {
+		ctx, cancelFunc = context.WithCancel(context.Background())
+		DeferCleanup(cancelFunc)
+	})
+
+	It("sends the results in the order of production.", func() {
+		processor, results := util.NewSequencedParallelConsumingProcessor(newSequenceProducer(math.MaxInt),
+			func(product int, ctx context.Context) int {
+				time.Sleep(time.Duration(rand.Intn(10)) * time.Millisecond)
+				return product * 2
+			}, 10, doNothingHandler)
+		start(processor, results, 10)
+
+		for i := 0; i < 100; i++ {
+			Expect(<-results).To(Equal(i * 2))
+		}
+		cancelFunc()
+
+		last := 99 * 2
+		for result := range results {
+			Expect(result).To(BeNumerically(">", last))
+			last = result
+		}
+	})
+
+	It("holds the later results until a slow consumer finishes.", func() {
+		processor, results := util.NewSequencedParallelConsumingProcessor(newSequenceProducer(10),
+			func(product int, ctx context.Context) int {
+				if product == 3 {
+					time.Sleep(500 * time.Millisecond)
+				}
+				return product
+			}, 10, doNothingHandler)
+		start(processor, results, 10)
+
+		for i := 0; i < 3; i++ {
+			Eventually(results).Should(Receive(Equal(i)))
+		}
+		Consistently(results, 200*time.Millisecond).ShouldNot(Receive())
+		Eventually(results, time.Second).Should(Receive(Equal(3)))
+		Eventually(results).Should(Receive(Equal(4)))
+	})
+
+	It("skips the results of the consumers that panic.", func() {
+		processor, results := util.NewSequencedParallelConsumingProcessor(newSequenceProducer(10),
+			func(product int, ctx context.Context) int {
+				if product == 1 {
+					panic("test")
+				}
+				return product
+			}, 10, doNothingHandler)
+		start(processor, results, 3)
+
+		Eventually(results).Should(Receive(Equal(0)))
+		Eventually(results).Should(Receive(Equal(2)))
+	})
+
+	It("flushes the held results after ctx is done.", func() {
+		processor, results := util.NewSequencedParallelConsumingProcessor(newSequenceProducer(20),
+			func(product int, ctx context.Context) int {
+				if product == 0 {
+					<-ctx.Done()
+				}
+				return product
+			}, 10, doNothingHandler)
+		start(processor, results, 10)
+
+		Consistently(results, 200*time.Millisecond).ShouldNot(Receive())
+		cancelFunc()
+
+		actual := []int{}
+		for result := range results {
+			actual = append(actual, result)
+		}
+		Expect(actual).To(Equal(getSequence(20)))
+	})
+})
+
+func getSequence(num int) (result []int) {
+	for i := 0; i < num; i++ {
+		result = append(result, i)
+	}
+	return
+}
+
+var _ = Describe("ParallelProcessorWithPanicLimit", func() {
+	var ctx context.Context
+	var cancelFunc context.CancelFunc
+	var loopInvoked int64
+	var panickingLoopFunc util.LoopFunc
+
+	BeforeEach(func() {
+		ctx, cancelFunc = context.WithCancel(context.Background())
+		DeferCleanup(cancelFunc)
+		loopInvoked = 0
+		panickingLoopFunc = func(ctx context.Context) bool {
+			atomic.AddInt64(&loopInvoked, 1)
+			panic("test")
+		}
+	})
+
+	It("stops after the number of panics reaches the limit.", func() {
+		processor := util.NewParallelProcessorWithPanicLimit(panickingLoopFunc, nil, 5)
+		consumerNum := 3
+		processor.Start(consumerNum, ctx)
+
+		// Other routines may panic concurrently before they find the processor is stopped
+		Expect(atomic.LoadInt64(&loopInvoked)).To(BeNumerically(">=", 5))
+		Expect(atomic.LoadInt64(&loopInvoked)).To(BeNumerically("<", 5+consumerNum))
+	})
+
+	It("still lets panicHandler handle every panic.", func() {
+		var handled int64
+		processor := util.NewParallelProcessorWithPanicLimit(panickingLoopFunc, func(r any) {
+			atomic.AddInt64(&handled, 1)
+		}, 3)
+		processor.Start(10, ctx)
+
+		Expect(atomic.LoadInt64(&handled)).To(BeNumerically(">=", 3))
+		Expect(atomic.LoadInt64(&handled)).To(Equal(atomic.LoadInt64(&loopInvoked)))
+	})
+
+	It("can't be started again after it stops.", func() {
+		processor := util.NewParallelProcessorWithPanicLimit(panickingLoopFunc, nil, 5)
+		processor.Start(1, ctx)
+		Expect(atomic.LoadInt64(&loopInvoked)).To(Equal(int64(5)))
+
+		processor.Start(1, ctx)
+		Expect(atomic.LoadInt64(&loopInvoked)).To(Equal(int64(5)))
+	})
+
+	It("keeps running if the limit is not reached.", func() {
+		processor := util.NewParallelProcessorWithPanicLimit(func(ctx context.Context) bool {
+			if atomic.AddInt64(&loopInvoked, 1) < 3 {
+				panic("test")
+			}
+			return false
+		}, nil, 5)
+
+		processor.Start(1, ctx)
+		Expect(atomic.LoadInt64(&loopInvoked)).To(Equal(int64(3)))
+	})
+})
+
+var _ = Describe("Resize", func() {
+	var ctx context.Context
+	var cancelFunc context.CancelFunc
+	var inLoop int32
+	var processor *util.ParallelProcessor
+	var stopChan chan bool
+
+	BeforeEach(func() {
+		ctx, cancelFunc = context.WithCancel(context.Background())
+		DeferCleanup(cancelFunc)
+		inLoop = 0
+		stopChan = make(chan bool)
+		processor = util.NewParallelProcessor(func(ctx context.Context) bool {
+			atomic.AddInt32(&inLoop, 1)
+			time.Sleep(2 * time.Millisecond)
+			atomic.AddInt32(&inLoop, -1)
+			return true
+		}, doNothingHandler)
+	})
+
+	It("spawns and retires routines while running.", func() {
+		Expect(processor.Resize(3)).To(BeFalse())
+		go func() {
+			processor.Start(2, ctx)
+			close(stopChan)
+		}()
+		Eventually(processor.Size).Should(Equal(2))
+
+		Expect(processor.Resize(5)).To(BeTrue())
+		Expect(processor.Size()).To(Equal(5))
+		Eventually(func() int32 { return atomic.LoadInt32(&inLoop) }).Should(Equal(int32(5)))
+
+		Expect(processor.Resize(1)).To(BeTrue())
+		Expect(processor.Size()).To(Equal(1))
+		// Wait for the retired routines to finish their current loops
+		time.Sleep(20 * time.Millisecond)
+		Consistently(func() int32 { return atomic.LoadInt32(&inLoop) }, 50*time.Millisecond).
+			Should(BeNumerically("<=", 1))
+
+		cancelFunc()
+		Eventually(stopChan).Should(BeClosed())
+		Expect(processor.Size()).To(Equal(0))
+		Expect(processor.Resize(3)).To(BeFalse())
+	})
+
+	It("panics with invalid consumerNum.", func() {
+		Expect(func() { processor.Resize(0) }).To(Panic())
+	})
+})
+
+var _ = Describe("StartAsync, Stop and Wait", func() {
+	var helper *loopFuncHelper
+
+	BeforeEach(func() {
+		helper = newLoopFuncHelper()
+	})
+
+	It("returns immediately and can be stopped.", func() {
+		processor := util.NewParallelProcessor(helper.invokeInfinitely, doNothingHandler)
+		processor.Wait() // Never started
+
+		processor.StartAsync(3, context.Background())
+		Eventually(processor.Size).Should(Equal(3))
+
+		processor.Stop()
+		processor.Wait()
+		Expect(processor.Size()).To(Equal(0))
+
+		// It can be started again
+		processor.StartAsync(1, context.Background())
+		Eventually(processor.Size).Should(Equal(1))
+		processor.Stop()
+		processor.Wait()
+	})
+
+	It("can be stopped right after StartAsync.", func() {
+		processor := util.NewParallelProcessor(helper.invokeInfinitely, doNothingHandler)
+		for i := 0; i < 20; i++ {
+			processor.StartAsync(2, context.Background())
+			processor.Stop()
+			stopped := make(chan struct{})
+			go func() {
+				processor.Wait()
+				close(stopped)
+			}()
+			Eventually(stopped).Should(BeClosed())
+		}
+	})
+
+	It("stops when loopFunc returns false.", func() {
+		processor := util.NewParallelProcessor(helper.invokeOnce, doNothingHandler)
+		processor.StartAsync(3, context.Background())
+		processor.Wait()
+
+		helper.locker.Lock()
+		defer helper.locker.Unlock()
+		Expect(helper.invokedTime).To(Equal(3))
+	})
+
+	It("can stop a blocking Start.", func() {
+		processor := util.NewParallelProcessor(helper.invokeInfinitely, doNothingHandler)
+		stopChan := make(chan bool)
+		go func() {
+			processor.Start(2, context.Background())
+			close(stopChan)
+		}()
+		Eventually(processor.Size).Should(Equal(2))
+
+		processor.Stop()
+		Eventually(stopChan).Should(BeClosed())
+	})
+
+	It("works with ParallelConsumingProcessor.", func() {
+		var consumed int64
+		processor, results := util.NewSequencedParallelConsumingProcessor[int, int](
+			func(ctx context.Context) int {
+				return int(atomic.AddInt64(&consumed, 1))
+			},
+			func(product int, ctx context.Context) int {
+				return product
+			}, 2, doNothingHandler)
+		processor.StartAsync(2, context.Background())
+		Eventually(results).Should(Receive(Equal(1)))
+
+		processor.Stop()
+		drained := make(chan bool)
+		go func() {
+			for range results {
+			}
+			close(drained)
+		}()
+		processor.Wait()
+		// The results channel is closed by the time Wait returns
+		Eventually(drained).Should(BeClosed())
+	})
+})
+
+var _ = Describe("Error policies", func() {
+	errSentinel := fmt.Errorf("sentinel")
+
+	It("stops on the first error with StopOnFirstError.", func() {
+		var invokedTime int64
+		var handled int64
+		processor := util.NewParallelProcessorE(func(ctx context.Context) (bool, error) {
+			if atomic.AddInt64(&invokedTime, 1) == 5 {
+				return true, errSentinel
+			}
+			return true, nil
+		}, doNothingHandler, util.StopOnFirstError, func(err error) {
+			atomic.AddInt64(&handled, 1)
+		})
+
+		processor.Start(1, context.Background())
+		Expect(atomic.LoadInt64(&invokedTime)).To(BeEquivalentTo(5))
+		Expect(atomic.LoadInt64(&handled)).To(BeEquivalentTo(1))
+		Expect(processor.Err()).To(Equal(errSentinel))
+	})
+
+	It("collects all the errors with CollectErrors.", func() {
+		var invokedTime int64
+		var handled int64
+		processor := util.NewParallelProcessorE(func(ctx context.Context) (bool, error) {
+			n := atomic.AddInt64(&invokedTime, 1)
+			if n > 10 {
+				return false, nil
+			}
+			if n%2 == 0 {
+				return true, fmt.Errorf("error %d: %w", n, errSentinel)
+			}
+			return true, nil
+		}, doNothingHandler, util.CollectErrors, func(err error) {
+			atomic.AddInt64(&handled, 1)
+		})
+
+		processor.Start(1, context.Background())
+		Expect(atomic.LoadInt64(&handled)).To(BeEquivalentTo(5))
+		err := processor.Err()
+		Expect(err).To(MatchError(errSentinel))
+		var multiError util.MultiError
+		Expect(errors.As(err, &multiError)).To(BeTrue())
+		Expect(multiError).To(HaveLen(5))
+		Expect(err.Error()).To(HavePrefix("5 errors occurred: error 2: sentinel; "))
+
+		// The errors are reset when the processor starts again
+		atomic.StoreInt64(&invokedTime, 10)
+		processor.Start(1, context.Background())
+		Expect(processor.Err()).To(BeNil())
+	})
+
+	It("works with ConsumerFuncE.", func() {
+		var produced int64
+		processor := util.NewParallelConsumingProcessorE(func(ctx context.Context) int64 {
+			return atomic.AddInt64(&produced, 1)
+		}, func(product int64, ctx context.Context) error {
+			if product == 3 {
+				return errSentinel
+			}
+			return nil
+		}, doNothingHandler, util.StopOnFirstError, nil)
+
+		processor.Start(1, context.Background())
+		Expect(atomic.LoadInt64(&produced)).To(BeEquivalentTo(3))
+		Expect(processor.Err()).To(Equal(errSentinel))
+	})
+})
+
+var _ = Describe("Worker IDs and WorkerHooks", func() {
+	type bufferKey struct{}
+
+	It("passes the worker ID and the worker-local values to loopFunc.", func() {
+		lock := sync.Mutex{}
+		ids := map[int]int{}
+		var stopped []int
+		processor := util.NewParallelProcessor(func(ctx context.Context) bool {
+			id, ok := util.WorkerIDFromContext(ctx)
+			Expect(ok).To(BeTrue())
+			buffer := ctx.Value(bufferKey{}).(*[]int)
+			*buffer = append(*buffer, id)
+			lock.Lock()
+			defer lock.Unlock()
+			ids[id]++
+			return ids[id] < 3
+		}, doNothingHandler)
+		processor.SetWorkerHooks(util.WorkerHooks{
+			OnStart: func(ctx context.Context) context.Context {
+				return context.WithValue(ctx, bufferKey{}, &[]int{})
+			},
+			OnStop: func(ctx context.Context) {
+				buffer := ctx.Value(bufferKey{}).(*[]int)
+				lock.Lock()
+				defer lock.Unlock()
+				stopped = append(stopped, len(*buffer))
+			},
+		})
+
+		processor.Start(4, context.Background())
+		Expect(ids).To(Equal(map[int]int{0: 3, 1: 3, 2: 3, 3: 3}))
+		Expect(stopped).To(Equal([]int{3, 3, 3, 3}))
+
+		// The IDs restart from 0 in a new run
+		ids = map[int]int{}
+		stopped = nil
+		processor.Start(1, context.Background())
+		Expect(ids).To(Equal(map[int]int{0: 3}))
+	})
+
+	It("gives new IDs to the workers spawned by Resize.", func() {
+		var stopped int64
+		lock := sync.Mutex{}
+		ids := map[int]bool{}
+		processor := util.NewParallelConsumingProcessor(func(ctx context.Context) int {
+			id, _ := util.WorkerIDFromContext(ctx)
+			return id
+		}, func(id int, ctx context.Context) {
+			lock.Lock()
+			ids[id] = true
+			lock.Unlock()
+			time.Sleep(time.Millisecond)
+		}, doNothingHandler)
+		processor.SetWorkerHooks(util.WorkerHooks{
+			OnStop: func(ctx context.Context) {
+				atomic.AddInt64(&stopped, 1)
+			},
+		})
+
+		processor.StartAsync(2, context.Background())
+		Eventually(func() bool { return processor.Resize(1) }).Should(BeTrue())
+		Eventually(func() int64 { return atomic.LoadInt64(&stopped) }).Should(BeEquivalentTo(1))
+		Expect(processor.Resize(2)).To(BeTrue())
+		Eventually(func() bool {
+			lock.Lock()
+			defer lock.Unlock()
+			return ids[2]
+		}).Should(BeTrue())
+
+		processor.Stop()
+		processor.Wait()
+		Expect(atomic.LoadInt64(&stopped)).To(BeEquivalentTo(3))
+		Expect(ids).To(HaveLen(3))
+	})
+
+	It("handles the panics of the hooks like loopFunc.", func() {
+		var invoked int64
+		lock := sync.Mutex{}
+		var panics []interface{}
+		processor := util.NewParallelProcessor(func(ctx context.Context) bool {
+			atomic.AddInt64(&invoked, 1)
+			return false
+		}, func(r interface{}) {
+			lock.Lock()
+			defer lock.Unlock()
+			panics = append(panics, r)
+		})
+		processor.SetWorkerHooks(util.WorkerHooks{
+			OnStart: func(ctx context.Context) context.Context {
+				if id, _ := util.WorkerIDFromContext(ctx); id == 0 {
+					panic("OnStart")
+				}
+				return ctx
+			},
+			OnStop: func(ctx context.Context) {
+				panic("OnStop")
+			},
+		})
+
+		processor.Start(2, context.Background())
+		Expect(atomic.LoadInt64(&invoked)).To(BeEquivalentTo(1))
+		Expect(panics).To(ConsistOf("OnStart", "OnStop"))
+	})
+
+	It("returns false without a worker.", func() {
+		_, ok := util.WorkerIDFromContext(context.Background())
+		Expect(ok).To(BeFalse())
+	})
+})
+
+var _ = Describe("Buffered ParallelConsumingProcessor", func() {
+	It("doesn't block production with a slow consumer.", func() {
+		var produced int64
+		var consumed int64
+		block := make(chan struct{})
+		processor := util.NewParallelConsumingProcessor(func(ctx context.Context) int64 {
+			return atomic.AddInt64(&produced, 1)
+		}, func(product int64, ctx context.Context) {
+			<-block
+			atomic.AddInt64(&consumed, 1)
+		}, doNothingHandler, util.WithBuffer(10))
+
+		processor.StartAsync(1, context.Background())
+		// 1 product in the consumer, 10 products in the buffer and 1 product waiting to be sent
+		getProduced := func() int64 { return atomic.LoadInt64(&produced) }
+		Eventually(getProduced).Should(BeEquivalentTo(12))
+		Consistently(getProduced, 100*time.Millisecond).Should(BeEquivalentTo(12))
+
+		processor.Stop()
+		close(block)
+		processor.Wait()
+		Expect(atomic.LoadInt64(&consumed)).To(BeEquivalentTo(1))
+	})
+
+	It("runs the producers in parallel.", func() {
+		var running int64
+		allRunning := make(chan struct{})
+		once := sync.Once{}
+		processor := util.NewParallelConsumingProcessorE(func(ctx context.Context) int {
+			if atomic.AddInt64(&running, 1) == 3 {
+				once.Do(func() { close(allRunning) })
+			}
+			<-allRunning
+			return 0
+		}, func(product int, ctx context.Context) error {
+			return nil
+		}, doNothingHandler, util.CollectErrors, nil, util.WithProducerCount(3))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		processor.StartAsync(1, ctx)
+		Eventually(allRunning).Should(BeClosed())
+		cancel()
+		processor.Wait()
+		Expect(processor.Err()).To(BeNil())
+	})
+
+	It("panics with invalid options.", func() {
+		Expect(func() { util.WithBuffer(-1) }).To(Panic())
+		Expect(func() { util.WithProducerCount(0) }).To(Panic())
+	})
+})
+
+var _ = Describe("BatchConsumingProcessor", func() {
+	It("consumes the products in batches of maxBatch.", func() {
+		var produced int64
+		lock := sync.Mutex{}
+		var batches [][]int64
+		processor := util.NewBatchConsumingProcessor(func(ctx context.Context) int64 {
+			return atomic.AddInt64(&produced, 1)
+		}, func(batch []int64, ctx context.Context) {
+			lock.Lock()
+			defer lock.Unlock()
+			batches = append(batches, batch)
+		}, 3, time.Hour, doNothingHandler)
+
+		processor.StartAsync(1, context.Background())
+		Eventually(func() int {
+			lock.Lock()
+			defer lock.Unlock()
+			return len(batches)
+		}).Should(BeNumerically(">=", 5))
+		processor.Stop()
+		processor.Wait()
+
+		var consumed []int64
+		for i, batch := range batches {
+			if i < len(batches)-1 {
+				Expect(batch).To(HaveLen(3))
+			} else {
+				Expect(len(batch)).To(BeNumerically("<=", 3))
+			}
+			consumed = append(consumed, batch...)
+		}
+		for i, product := range consumed {
+			Expect(product).To(BeEquivalentTo(i + 1))
+		}
+	})
+
+	It("consumes a partial batch after maxWait.", func() {
+		var produced int64
+		batches := make(chan []int64, 10)
+		processor := util.NewBatchConsumingProcessor(func(ctx context.Context) int64 {
+			if atomic.AddInt64(&produced, 1) > 2 {
+				<-ctx.Done()
+			}
+			return atomic.LoadInt64(&produced)
+		}, func(batch []int64, ctx context.Context) {
+			batches <- batch
+		}, 10, 50*time.Millisecond, doNothingHandler)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		processor.StartAsync(1, ctx)
+		Eventually(batches).Should(Receive(Equal([]int64{1, 2})))
+		cancel()
+		processor.Wait()
+	})
+
+	It("panics with invalid arguments.", func() {
+		Expect(func() {
+			util.NewBatchConsumingProcessor[int](nil, nil, 0, time.Second, doNothingHandler)
+		}).To(Panic())
+		Expect(func() {
+			util.NewBatchConsumingProcessor[int](nil, nil, 1, 0, doNothingHandler)
+		}).To(Panic())
+	})
+})
+
+var _ = Describe("Draining ParallelConsumingProcessor", func() {
+	It("consumes the product produced when ctx is done.", func() {
+		for _, drain := range []bool{false, true} {
+			ctx, cancel := context.WithCancel(context.Background())
+			var consumed []int
+			var drainCtxErr error
+			var opts []util.ConsumingOption
+			if drain {
+				opts = append(opts, util.WithDrain(time.Second))
+			}
+			processor := util.NewParallelConsumingProcessor(func(ctx context.Context) int {
+				if len(consumed) == 2 {
+					cancel()
+				}
+				return len(consumed)
+			}, func(product int, ctx context.Context) {
+				consumed = append(consumed, product)
+				drainCtxErr = ctx.Err()
+			}, doNothingHandler, opts...)
+
+			processor.Start(1, ctx)
+			if drain {
+				Expect(consumed).To(Equal([]int{0, 1, 2}))
+				Expect(drainCtxErr).To(BeNil())
+			} else {
+				Expect(consumed).To(Equal([]int{0, 1}))
+			}
+		}
+	})
+
+	It("consumes the products left in the buffer.", func() {
+		var produced int64
+		var consumed int64
+		block := make(chan struct{})
+		processor := util.NewParallelConsumingProcessor(func(ctx context.Context) int64 {
+			return atomic.AddInt64(&produced, 1)
+		}, func(product int64, ctx context.Context) {
+			<-block
+			atomic.AddInt64(&consumed, 1)
+		}, doNothingHandler, util.WithBuffer(5), util.WithDrain(0))
+
+		processor.StartAsync(1, context.Background())
+		getProduced := func() int64 { return atomic.LoadInt64(&produced) }
+		Eventually(getProduced).Should(BeEquivalentTo(7))
+		processor.Stop()
+		close(block)
+		processor.Wait()
+		Expect(atomic.LoadInt64(&consumed)).To(BeEquivalentTo(7))
+	})
+
+	It("gives up draining after the timeout.", func() {
+		var produced int64
+		var consumed int64
+		block := make(chan struct{})
+		processor := util.NewParallelConsumingProcessor(func(ctx context.Context) int64 {
+			return atomic.AddInt64(&produced, 1)
+		}, func(product int64, ctx context.Context) {
+			<-block
+			atomic.AddInt64(&consumed, 1)
+			time.Sleep(30 * time.Millisecond)
+		}, doNothingHandler, util.WithBuffer(5), util.WithDrain(50*time.Millisecond))
+
+		processor.StartAsync(1, context.Background())
+		getProduced := func() int64 { return atomic.LoadInt64(&produced) }
+		Eventually(getProduced).Should(BeEquivalentTo(7))
+		processor.Stop()
+		close(block)
+		processor.Wait()
+		Expect(atomic.LoadInt64(&consumed)).To(BeNumerically("<", 7))
+	})
+
+	It("consumes the products left in the buffer in batches.", func() {
+		var produced int64
+		lock := sync.Mutex{}
+		var batches [][]int64
+		block := make(chan struct{})
+		processor := util.NewBatchConsumingProcessor(func(ctx context.Context) int64 {
+			return atomic.AddInt64(&produced, 1)
+		}, func(batch []int64, ctx context.Context) {
+			<-block
+			lock.Lock()
+			defer lock.Unlock()
+			batches = append(batches, batch)
+		}, 2, time.Hour, doNothingHandler, util.WithBuffer(5), util.WithDrain(0))
+
+		processor.StartAsync(1, context.Background())
+		// 2 products in the consumer, 5 products in the buffer and 1 product waiting to be sent
+		getProduced := func() int64 { return atomic.LoadInt64(&produced) }
+		Eventually(getProduced).Should(BeEquivalentTo(8))
+		processor.Stop()
+		close(block)
+		processor.Wait()
+		Expect(batches).To(Equal([][]int64{{1, 2}, {3, 4}, {5, 6}, {7, 8}}))
+	})
+
+	It("panics with a negative timeout.", func() {
+		Expect(func() { util.WithDrain(-1) }).To(Panic())
+	})
+})
@@ -0,0 +1,130 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/linxiaokun528/go-kit/pkg/util"
+)
+
+// StageFunc processes an item in a stage. If keep is false, the item is dropped and won't reach the next stage.
+type StageFunc[T any] func(item T, ctx context.Context) (result T, keep bool)
+
+type stage[T any] struct {
+	workerNum int
+	stageFunc StageFunc[T]
+}
+
+// Pipeline passes the items through the stages in order. Every stage has its own workers, and the stages are
+// connected by channels, so a slow stage only blocks the previous stages when the channels are full.
+// This generalizes util.ParallelConsumingProcessor to more than two stages.
+type Pipeline[T any] struct {
+	stages       []stage[T]
+	panicHandler util.PanicHandler
+	buffer       int
+}
+
+func NewPipeline[T any]() *Pipeline[T] {
+	return &Pipeline[T]{}
+}
+
+// Stage appends a stage with workerNum workers and returns the pipeline
+func (p *Pipeline[T]) Stage(workerNum int, stageFunc StageFunc[T]) *Pipeline[T] {
+	if workerNum <= 0 {
+		panic(fmt.Errorf("workerNum should be positive"))
+	}
+	p.stages = append(p.stages, stage[T]{workerNum: workerNum, stageFunc: stageFunc})
+	return p
+}
+
+// WithPanicHandler sets the handler for the panics in the stages and returns the pipeline.
+// The item that causes a panic is dropped. Without a panicHandler, the panics are ignored.
+func (p *Pipeline[T]) WithPanicHandler(panicHandler util.PanicHandler) *Pipeline[T] {
+	p.panicHandler = panicHandler
+	return p
+}
+
+// WithBuffer sets the buffer size of the channels between the stages and returns the pipeline
+func (p *Pipeline[T]) WithBuffer(n int) *Pipeline[T] {
+	if n < 0 {
+		panic(fmt.Errorf("the buffer size should be non-negative"))
+	}
+	p.buffer = n
+	return p
+}
+
+// Run starts the stages and returns the output of the last stage. The items are read from in.
+// The output is closed after all the stages stop, which happens when in is closed and all the items are processed,
+// or when ctx is done, in which case the items in the stages are dropped.
+// The pipeline can be run multiple times, and the runs are independent.
+func (p *Pipeline[T]) Run(in <-chan T, ctx context.Context) <-chan T {
+	if len(p.stages) == 0 {
+		panic(fmt.Errorf("a pipeline should have at least one stage"))
+	}
+
+	for _, s := range p.stages {
+		in = p.runStage(s, in, ctx)
+	}
+	return in
+}
+
+func (p *Pipeline[T]) runStage(s stage[T], in <-chan T, ctx context.Context) <-chan T {
+	out := make(chan T, p.buffer)
+	wait := sync.WaitGroup{}
+	wait.Add(s.workerNum)
+	for i := 0; i < s.workerNum; i++ {
+		go func() {
+			defer wait.Done()
+			for {
+				var item T
+				var ok bool
+				select {
+				case <-ctx.Done():
+					return
+				case item, ok = <-in:
+					if !ok {
+						return
+					}
+				}
+
+				result, keep := p.process(s.stageFunc, item, ctx)
+				if !keep {
+					continue
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case out <- result:
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wait.Wait()
+		close(out)
+	}()
+	return out
+}
+
+func (p *Pipeline[T]) process(stageFunc StageFunc[T], item T, ctx context.Context) (result T, keep bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			keep = false
+			p.handlePanic(r)
+		}
+	}()
+
+	return stageFunc(item, ctx)
+}
+
+func (p *Pipeline[T]) handlePanic(r any) {
+	defer func() {
+		recover() // in case a panic happens while handling panics
+	}()
+
+	if p.panicHandler != nil {
+		p.panicHandler(r)
+	}
+}
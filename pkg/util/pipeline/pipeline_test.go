@@ -0,0 +1,97 @@
+package pipeline_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/linxiaokun528/go-kit/pkg/util/pipeline"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func feed(items ...int) <-chan int {
+	in := make(chan int, len(items))
+	for _, item := range items {
+		in <- item
+	}
+	close(in)
+	return in
+}
+
+func drain(out <-chan int) (result []int) {
+	for item := range out {
+		result = append(result, item)
+	}
+	return
+}
+
+var _ = Describe("Pipeline", func() {
+	double := func(item int, ctx context.Context) (int, bool) {
+		return item * 2, true
+	}
+	odd := func(item int, ctx context.Context) (int, bool) {
+		return item, item%4 != 0
+	}
+
+	It("passes the items through all the stages.", func() {
+		p := pipeline.NewPipeline[int]().Stage(3, double).Stage(2, odd).Stage(1, double)
+		Expect(drain(p.Run(feed(1, 2, 3, 4, 5), context.Background()))).To(ConsistOf(4, 12, 20))
+
+		// A pipeline can be run again
+		Expect(drain(p.Run(feed(7), context.Background()))).To(Equal([]int{28}))
+	})
+
+	It("drops the items that cause panics.", func() {
+		var panics int64
+		var handled any
+		p := pipeline.NewPipeline[int]().Stage(2, func(item int, ctx context.Context) (int, bool) {
+			if item == 3 {
+				panic(fmt.Errorf("panic for test"))
+			}
+			return item, true
+		}).WithPanicHandler(func(r any) {
+			atomic.AddInt64(&panics, 1)
+			handled = r
+		}).WithBuffer(2)
+
+		Expect(drain(p.Run(feed(1, 2, 3, 4), context.Background()))).To(ConsistOf(1, 2, 4))
+		Expect(atomic.LoadInt64(&panics)).To(BeEquivalentTo(1))
+		Expect(handled).To(MatchError("panic for test"))
+	})
+
+	It("ignores the panics of the panicHandler.", func() {
+		p := pipeline.NewPipeline[int]().Stage(1, func(item int, ctx context.Context) (int, bool) {
+			panic("panic for test")
+		}).WithPanicHandler(func(r any) {
+			panic(r)
+		})
+
+		Expect(drain(p.Run(feed(1, 2), context.Background()))).To(BeEmpty())
+	})
+
+	It("shuts down when ctx is done.", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		in := make(chan int)
+		var processed int64
+		p := pipeline.NewPipeline[int]().Stage(2, func(item int, ctx context.Context) (int, bool) {
+			atomic.AddInt64(&processed, 1)
+			return item, true
+		}).Stage(2, double)
+
+		out := p.Run(in, ctx)
+		in <- 1
+		Eventually(out).Should(Receive(Equal(2)))
+		// Nobody reads the output, so the stages are blocked
+		in <- 2
+		in <- 3
+		cancel()
+		Eventually(out).Should(BeClosed())
+	})
+
+	It("panics with invalid arguments.", func() {
+		Expect(func() { pipeline.NewPipeline[int]().Stage(0, double) }).To(Panic())
+		Expect(func() { pipeline.NewPipeline[int]().WithBuffer(-1) }).To(Panic())
+		Expect(func() { pipeline.NewPipeline[int]().Run(feed(), context.Background()) }).To(Panic())
+	})
+})
@@ -0,0 +1,252 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/utils/clock"
+)
+
+// ErrPoolClosed is returned when getting an object from a Pool that has been closed
+var ErrPoolClosed = errors.New("the pool has been closed")
+
+// PoolFactory creates a new object for a Pool
+type PoolFactory[T any] func(ctx context.Context) (T, error)
+
+// PoolOption configures a Pool
+type PoolOption[T any] func(*Pool[T])
+
+// WithMaxIdle keeps at most maxIdle idle objects. The other objects are destroyed when they are put back.
+// The default is 0, which means no limit.
+func WithMaxIdle[T any](maxIdle int) PoolOption[T] {
+	if maxIdle < 0 {
+		panic(fmt.Errorf("maxIdle should be non-negative"))
+	}
+
+	return func(p *Pool[T]) {
+		p.maxIdle = maxIdle
+	}
+}
+
+// WithMaxActive allows at most maxActive objects, including the idle ones and the borrowed ones.
+// Get blocks when the limit is reached. The default is 0, which means no limit.
+func WithMaxActive[T any](maxActive int) PoolOption[T] {
+	if maxActive < 0 {
+		panic(fmt.Errorf("maxActive should be non-negative"))
+	}
+
+	return func(p *Pool[T]) {
+		p.maxActive = maxActive
+	}
+}
+
+// WithIdleTimeout destroys the objects that have been idle for idleTimeout. executor checks the idle objects every
+// idleTimeout, so an object may be idle for at most 2 * idleTimeout before it's destroyed.
+func WithIdleTimeout[T any](idleTimeout time.Duration, executor *DelayingExecutor) PoolOption[T] {
+	if idleTimeout <= 0 {
+		panic(fmt.Errorf("idleTimeout should be positive"))
+	}
+	if executor == nil {
+		panic(fmt.Errorf("executor should not be nil"))
+	}
+
+	return func(p *Pool[T]) {
+		p.idleTimeout = idleTimeout
+		p.executor = executor
+		p.clock = executor.clock
+	}
+}
+
+// WithValidator makes Get validate the idle objects before lending them. The invalid objects are destroyed.
+func WithValidator[T any](validate func(object T) bool) PoolOption[T] {
+	return func(p *Pool[T]) {
+		p.validate = validate
+	}
+}
+
+// WithDestroyer makes the pool call destroy when an object is discarded, evicted or destroyed by Close
+func WithDestroyer[T any](destroy func(object T)) PoolOption[T] {
+	return func(p *Pool[T]) {
+		p.destroy = destroy
+	}
+}
+
+type idleObject[T any] struct {
+	object T
+	since  time.Time
+}
+
+// PoolStats is the numbers of the objects of a Pool
+type PoolStats struct {
+	// Active is the number of all the objects, including the idle ones
+	Active int
+	Idle   int
+}
+
+// Pool lends reusable objects, such as connections and buffers.
+// The borrowed objects should be returned by Put, or Discard if they are broken.
+type Pool[T any] struct {
+	factory     PoolFactory[T]
+	maxIdle     int
+	maxActive   int
+	idleTimeout time.Duration
+	executor    *DelayingExecutor
+	clock       clock.Clock
+	validate    func(object T) bool
+	destroy     func(object T)
+	// stopEviction is nil if the idle objects are never evicted
+	stopEviction func()
+
+	lock sync.Mutex
+	// idle is ordered by when the objects are put back, so the most recently used one is the last
+	idle   []idleObject[T]
+	active int
+	closed bool
+	// changed is closed and replaced whenever an object is put back or destroyed, to wake up the waiting Gets
+	changed chan struct{}
+}
+
+func NewPool[T any](factory PoolFactory[T], opts ...PoolOption[T]) *Pool[T] {
+	pool := &Pool[T]{
+		factory: factory,
+		clock:   clock.RealClock{},
+		changed: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(pool)
+	}
+	if pool.executor != nil {
+		pool.stopEviction = pool.executor.ExecuteEvery(pool.evict, pool.idleTimeout)
+	}
+	return pool
+}
+
+// notify should be called with p.lock held
+func (p *Pool[T]) notify() {
+	close(p.changed)
+	p.changed = make(chan struct{})
+}
+
+// Get returns the most recently used idle object that is valid, or creates a new one if there is no idle object.
+// It blocks until an object is available or ctx is done. If ctx is done, ctx.Err() will be returned.
+func (p *Pool[T]) Get(ctx context.Context) (object T, err error) {
+	for {
+		p.lock.Lock()
+		if p.closed {
+			p.lock.Unlock()
+			return object, ErrPoolClosed
+		}
+
+		if len(p.idle) > 0 {
+			object = p.idle[len(p.idle)-1].object
+			p.idle = p.idle[:len(p.idle)-1]
+			p.lock.Unlock()
+
+			if p.validate != nil && !p.validate(object) {
+				p.Discard(object)
+				continue
+			}
+			return object, nil
+		}
+
+		if p.maxActive == 0 || p.active < p.maxActive {
+			p.active++
+			p.lock.Unlock()
+
+			object, err = p.factory(ctx)
+			if err != nil {
+				p.lock.Lock()
+				p.active--
+				p.notify()
+				p.lock.Unlock()
+			}
+			return object, err
+		}
+
+		changed := p.changed
+		p.lock.Unlock()
+		select {
+		case <-ctx.Done():
+			return object, ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// Put returns a borrowed object to the pool. The object is destroyed if the pool has been closed or there are
+// maxIdle idle objects.
+func (p *Pool[T]) Put(object T) {
+	p.lock.Lock()
+	if p.closed || p.maxIdle > 0 && len(p.idle) >= p.maxIdle {
+		p.lock.Unlock()
+		p.Discard(object)
+		return
+	}
+
+	p.idle = append(p.idle, idleObject[T]{object: object, since: p.clock.Now()})
+	p.notify()
+	p.lock.Unlock()
+}
+
+// Discard destroys a borrowed object, e.g. when it's broken, so that a new object can be created
+func (p *Pool[T]) Discard(object T) {
+	p.lock.Lock()
+	p.active--
+	p.notify()
+	p.lock.Unlock()
+
+	if p.destroy != nil {
+		p.destroy(object)
+	}
+}
+
+func (p *Pool[T]) evict() {
+	deadline := p.clock.Now().Add(-p.idleTimeout)
+
+	p.lock.Lock()
+	expired := 0
+	for expired < len(p.idle) && !p.idle[expired].since.After(deadline) {
+		expired++
+	}
+	evicted := make([]idleObject[T], expired)
+	copy(evicted, p.idle)
+	p.idle = p.idle[expired:]
+	p.lock.Unlock()
+
+	for _, idle := range evicted {
+		p.Discard(idle.object)
+	}
+}
+
+// Stats returns the numbers of the objects
+func (p *Pool[T]) Stats() PoolStats {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return PoolStats{Active: p.active, Idle: len(p.idle)}
+}
+
+// Close destroys the idle objects and stops the eviction. The borrowed objects are destroyed when they are put back.
+// The waiting Gets return ErrPoolClosed.
+func (p *Pool[T]) Close() {
+	p.lock.Lock()
+	if p.closed {
+		p.lock.Unlock()
+		return
+	}
+	p.closed = true
+	idle := p.idle
+	p.idle = nil
+	p.notify()
+	p.lock.Unlock()
+
+	if p.stopEviction != nil {
+		p.stopEviction()
+	}
+	for _, object := range idle {
+		p.Discard(object.object)
+	}
+}
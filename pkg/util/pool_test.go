@@ -0,0 +1,185 @@
+package util_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/linxiaokun528/go-kit/pkg/util"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+type pooledConn struct {
+	id     int64
+	broken bool
+}
+
+var _ = Describe("Pool", func() {
+	var created int64
+	var destroyed chan int64
+	var factory util.PoolFactory[*pooledConn]
+
+	BeforeEach(func() {
+		created = 0
+		destroyed = make(chan int64, 10)
+		factory = func(ctx context.Context) (*pooledConn, error) {
+			return &pooledConn{id: atomic.AddInt64(&created, 1)}, nil
+		}
+	})
+
+	destroyer := func() util.PoolOption[*pooledConn] {
+		return util.WithDestroyer(func(conn *pooledConn) {
+			destroyed <- conn.id
+		})
+	}
+
+	It("reuses the most recently used object.", func() {
+		pool := util.NewPool(factory, destroyer())
+		defer pool.Close()
+
+		first, err := pool.Get(context.Background())
+		Expect(err).To(BeNil())
+		second, err := pool.Get(context.Background())
+		Expect(err).To(BeNil())
+		Expect(pool.Stats()).To(Equal(util.PoolStats{Active: 2, Idle: 0}))
+
+		pool.Put(first)
+		pool.Put(second)
+		Expect(pool.Stats()).To(Equal(util.PoolStats{Active: 2, Idle: 2}))
+		Expect(pool.Get(context.Background())).To(BeIdenticalTo(second))
+		Expect(atomic.LoadInt64(&created)).To(BeEquivalentTo(2))
+	})
+
+	It("destroys the objects over maxIdle.", func() {
+		pool := util.NewPool(factory, destroyer(), util.WithMaxIdle[*pooledConn](1))
+		defer pool.Close()
+
+		first, _ := pool.Get(context.Background())
+		second, _ := pool.Get(context.Background())
+		pool.Put(first)
+		pool.Put(second)
+		Expect(destroyed).To(Receive(Equal(second.id)))
+		Expect(pool.Stats()).To(Equal(util.PoolStats{Active: 1, Idle: 1}))
+	})
+
+	It("blocks when maxActive is reached.", func() {
+		pool := util.NewPool(factory, util.WithMaxActive[*pooledConn](1))
+		defer pool.Close()
+
+		conn, _ := pool.Get(context.Background())
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := pool.Get(ctx)
+		Expect(err).To(Equal(context.DeadlineExceeded))
+
+		got := make(chan *pooledConn)
+		go func() {
+			conn, _ := pool.Get(context.Background())
+			got <- conn
+		}()
+		Consistently(got).ShouldNot(Receive())
+		pool.Put(conn)
+		Eventually(got).Should(Receive(BeIdenticalTo(conn)))
+	})
+
+	It("validates the idle objects on borrow.", func() {
+		pool := util.NewPool(factory, destroyer(), util.WithValidator(func(conn *pooledConn) bool {
+			return !conn.broken
+		}))
+		defer pool.Close()
+
+		conn, _ := pool.Get(context.Background())
+		conn.broken = true
+		pool.Put(conn)
+
+		newConn, err := pool.Get(context.Background())
+		Expect(err).To(BeNil())
+		Expect(newConn).NotTo(BeIdenticalTo(conn))
+		Expect(destroyed).To(Receive(Equal(conn.id)))
+	})
+
+	It("releases the slot of a discarded object.", func() {
+		pool := util.NewPool(factory, destroyer(), util.WithMaxActive[*pooledConn](1))
+		defer pool.Close()
+
+		conn, _ := pool.Get(context.Background())
+		pool.Discard(conn)
+		Expect(destroyed).To(Receive(Equal(conn.id)))
+		Expect(pool.Stats()).To(Equal(util.PoolStats{}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		newConn, err := pool.Get(ctx)
+		Expect(err).To(BeNil())
+		Expect(newConn).NotTo(BeIdenticalTo(conn))
+	})
+
+	It("returns the error of the factory.", func() {
+		errTest := errors.New("error for test")
+		pool := util.NewPool(func(ctx context.Context) (*pooledConn, error) {
+			return nil, errTest
+		}, util.WithMaxActive[*pooledConn](1))
+		defer pool.Close()
+
+		_, err := pool.Get(context.Background())
+		Expect(err).To(Equal(errTest))
+		_, err = pool.Get(context.Background())
+		Expect(err).To(Equal(errTest))
+		Expect(pool.Stats()).To(Equal(util.PoolStats{}))
+	})
+
+	It("evicts the idle objects.", func() {
+		fakeClock := clocktesting.NewFakeClock(time.Now())
+		executor := util.NewDelayingExecutor(5, util.WithClock(fakeClock))
+		defer executor.ShutDownFast()
+		pool := util.NewPool(factory, destroyer(), util.WithIdleTimeout[*pooledConn](time.Second, executor))
+		defer pool.Close()
+
+		conn, _ := pool.Get(context.Background())
+		pool.Put(conn)
+		Eventually(fakeClock.HasWaiters).Should(BeTrue())
+		fakeClock.Step(time.Second)
+		Eventually(destroyed).Should(Receive(Equal(conn.id)))
+		Expect(pool.Stats()).To(Equal(util.PoolStats{}))
+	})
+
+	It("destroys the objects after closed.", func() {
+		pool := util.NewPool(factory, destroyer())
+		first, _ := pool.Get(context.Background())
+		second, _ := pool.Get(context.Background())
+		pool.Put(first)
+
+		pool.Close()
+		Expect(destroyed).To(Receive(Equal(first.id)))
+		_, err := pool.Get(context.Background())
+		Expect(err).To(Equal(util.ErrPoolClosed))
+
+		pool.Put(second)
+		Expect(destroyed).To(Receive(Equal(second.id)))
+		Expect(pool.Stats()).To(Equal(util.PoolStats{}))
+		Expect(pool.Close).NotTo(Panic())
+	})
+
+	It("wakes up the waiting Gets when closed.", func() {
+		pool := util.NewPool(factory, util.WithMaxActive[*pooledConn](1))
+		_, _ = pool.Get(context.Background())
+		errs := make(chan error)
+		go func() {
+			_, err := pool.Get(context.Background())
+			errs <- err
+		}()
+		Consistently(errs).ShouldNot(Receive())
+		pool.Close()
+		Eventually(errs).Should(Receive(Equal(util.ErrPoolClosed)))
+	})
+
+	It("panics with invalid arguments.", func() {
+		Expect(func() { util.WithMaxIdle[int](-1) }).To(Panic())
+		Expect(func() { util.WithMaxActive[int](-1) }).To(Panic())
+		Expect(func() { util.WithIdleTimeout[int](0, nil) }).To(Panic())
+		Expect(func() { util.WithIdleTimeout[int](time.Second, nil) }).To(Panic())
+	})
+})
@@ -0,0 +1,117 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/utils/clock"
+)
+
+// Limiter throttles the callers of Wait
+type Limiter interface {
+	// Wait blocks until the caller is allowed to go on or ctx is done.
+	// If ctx is done, ctx.Err() will be returned.
+	Wait(ctx context.Context) error
+}
+
+// TokenBucket is a Limiter that allows perSecond calls per second on average, and at most burst calls at once.
+type TokenBucket struct {
+	clock     clock.Clock
+	perSecond float64
+	burst     float64
+	lock      sync.Mutex
+	// tokens can be negative, which means some callers are waiting for the tokens
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket creates a TokenBucket that is full at the beginning
+func NewTokenBucket(perSecond float64, burst int) *TokenBucket {
+	return NewTokenBucketWithClock(perSecond, burst, clock.RealClock{})
+}
+
+func NewTokenBucketWithClock(perSecond float64, burst int, clock clock.Clock) *TokenBucket {
+	if perSecond <= 0 {
+		panic(fmt.Errorf("perSecond should be positive"))
+	}
+	if burst <= 0 {
+		panic(fmt.Errorf("burst should be positive"))
+	}
+
+	return &TokenBucket{
+		clock:     clock,
+		perSecond: perSecond,
+		burst:     float64(burst),
+		tokens:    float64(burst),
+		last:      clock.Now(),
+	}
+}
+
+// refill should be called with t.lock held
+func (t *TokenBucket) refill() {
+	now := t.clock.Now()
+	t.tokens += now.Sub(t.last).Seconds() * t.perSecond
+	if t.tokens > t.burst {
+		t.tokens = t.burst
+	}
+	t.last = now
+}
+
+// TryTake returns false immediately if there is no token
+func (t *TokenBucket) TryTake() bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.refill()
+	if t.tokens < 1 {
+		return false
+	}
+	t.tokens--
+	return true
+}
+
+// Reserve takes a token in advance, and returns how long the caller should wait before using it.
+// The waiting callers are served in the order of Reserve.
+func (t *TokenBucket) Reserve() time.Duration {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.refill()
+	t.tokens--
+	if t.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-t.tokens / t.perSecond * float64(time.Second))
+}
+
+func (t *TokenBucket) Wait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	wait := t.Reserve()
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := t.clock.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		// Give the token back
+		t.lock.Lock()
+		t.refill()
+		t.tokens++
+		if t.tokens > t.burst {
+			t.tokens = t.burst
+		}
+		t.lock.Unlock()
+		return ctx.Err()
+	}
+}
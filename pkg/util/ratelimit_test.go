@@ -0,0 +1,119 @@
+package util_test
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/linxiaokun528/go-kit/pkg/util"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+var _ = Describe("TokenBucket", func() {
+	var fakeClock *clocktesting.FakeClock
+	var bucket *util.TokenBucket
+
+	BeforeEach(func() {
+		fakeClock = clocktesting.NewFakeClock(time.Now())
+		bucket = util.NewTokenBucketWithClock(10, 3, fakeClock)
+	})
+
+	It("reserves the tokens in advance.", func() {
+		for i := 0; i < 3; i++ {
+			Expect(bucket.Reserve()).To(BeZero())
+		}
+		Expect(bucket.Reserve()).To(Equal(100 * time.Millisecond))
+		Expect(bucket.Reserve()).To(Equal(200 * time.Millisecond))
+		Expect(bucket.TryTake()).To(BeFalse())
+	})
+
+	It("allows burst calls at once.", func() {
+		for i := 0; i < 3; i++ {
+			Expect(bucket.TryTake()).To(BeTrue())
+		}
+		Expect(bucket.TryTake()).To(BeFalse())
+
+		fakeClock.Step(100 * time.Millisecond)
+		Expect(bucket.TryTake()).To(BeTrue())
+		Expect(bucket.TryTake()).To(BeFalse())
+
+		// The tokens never exceed burst
+		fakeClock.Step(time.Hour)
+		for i := 0; i < 3; i++ {
+			Expect(bucket.TryTake()).To(BeTrue())
+		}
+		Expect(bucket.TryTake()).To(BeFalse())
+	})
+
+	It("blocks Wait until there is a token.", func() {
+		for i := 0; i < 3; i++ {
+			Expect(bucket.Wait(context.Background())).To(Succeed())
+		}
+
+		waited := make(chan error)
+		go func() {
+			waited <- bucket.Wait(context.Background())
+		}()
+		Eventually(fakeClock.HasWaiters).Should(BeTrue())
+		Consistently(waited).ShouldNot(Receive())
+
+		fakeClock.Step(100 * time.Millisecond)
+		Eventually(waited).Should(Receive(BeNil()))
+	})
+
+	It("returns the error of ctx and gives the token back.", func() {
+		for i := 0; i < 3; i++ {
+			Expect(bucket.TryTake()).To(BeTrue())
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		waited := make(chan error)
+		go func() {
+			waited <- bucket.Wait(ctx)
+		}()
+		Eventually(fakeClock.HasWaiters).Should(BeTrue())
+		cancel()
+		Eventually(waited).Should(Receive(Equal(context.Canceled)))
+
+		fakeClock.Step(100 * time.Millisecond)
+		Expect(bucket.TryTake()).To(BeTrue())
+	})
+
+	It("panics with invalid arguments.", func() {
+		Expect(func() { util.NewTokenBucket(0, 1) }).To(Panic())
+		Expect(func() { util.NewTokenBucket(1, 0) }).To(Panic())
+	})
+})
+
+var _ = Describe("Rate-limited processors", func() {
+	It("limits the rate of loopFunc.", func() {
+		var invokedTime int64
+		processor := util.NewParallelProcessor(func(ctx context.Context) bool {
+			return atomic.AddInt64(&invokedTime, 1) < 15
+		}, doNothingHandler)
+		processor.SetLimiter(util.NewTokenBucket(100, 5))
+
+		start := time.Now()
+		processor.Start(3, context.Background())
+		// 5 invocations at once, and the other 10 invocations take 100ms
+		Expect(time.Since(start)).To(BeNumerically(">=", 90*time.Millisecond))
+	})
+
+	It("limits the rate of consuming with WithRateLimit.", func() {
+		var consumed int64
+		processor := util.NewParallelConsumingProcessor(func(ctx context.Context) int {
+			return 0
+		}, func(product int, ctx context.Context) {
+			atomic.AddInt64(&consumed, 1)
+		}, doNothingHandler, util.WithRateLimit(1, 2))
+
+		processor.StartAsync(2, context.Background())
+		getConsumed := func() int64 { return atomic.LoadInt64(&consumed) }
+		Eventually(getConsumed).Should(BeEquivalentTo(2))
+		Consistently(getConsumed, 200*time.Millisecond).Should(BeEquivalentTo(2))
+		processor.Stop()
+		processor.Wait()
+	})
+})
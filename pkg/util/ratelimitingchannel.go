@@ -0,0 +1,167 @@
+package util
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/linxiaokun528/go-kit/pkg/util/collection"
+)
+
+// ItemRateLimiter decides how long an item should wait before it's added again, like the RateLimiter of
+// k8s workqueue
+type ItemRateLimiter[T any] interface {
+	// When returns how long item should wait. It's called every time item is added with rate limiting.
+	When(item T) time.Duration
+	// Forget stops tracking item, e.g. after item is processed successfully
+	Forget(item T)
+	// NumRequeues returns how many times item has been added with rate limiting since it's forgotten
+	NumRequeues(item T) int
+}
+
+// ItemExponentialRateLimiter makes an item wait base * 2^n, where n is the number of the requeues of the item.
+// The waiting time is at most max.
+type ItemExponentialRateLimiter[T any] struct {
+	base     time.Duration
+	max      time.Duration
+	lock     sync.Mutex
+	failures collection.Map[T, int]
+}
+
+func NewItemExponentialRateLimiter[T any, C comparable](base, max time.Duration, hasher collection.Hasher[T, C],
+	equaler collection.Equaler[T]) *ItemExponentialRateLimiter[T] {
+	if base <= 0 {
+		panic(fmt.Errorf("base should be positive"))
+	}
+	if max < base {
+		panic(fmt.Errorf("max should not be less than base"))
+	}
+
+	return &ItemExponentialRateLimiter[T]{
+		base:     base,
+		max:      max,
+		failures: collection.NewMap[T, int, C](hasher, equaler),
+	}
+}
+
+func (i *ItemExponentialRateLimiter[T]) When(item T) time.Duration {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	failures, _ := i.failures.Get(item)
+	i.failures.Put(item, failures+1)
+
+	delay := i.base
+	for ; failures > 0 && delay < i.max; failures-- {
+		delay *= 2
+	}
+	if delay > i.max {
+		return i.max
+	}
+	return delay
+}
+
+func (i *ItemExponentialRateLimiter[T]) Forget(item T) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	i.failures.Remove(item)
+}
+
+func (i *ItemExponentialRateLimiter[T]) NumRequeues(item T) int {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	failures, _ := i.failures.Get(item)
+	return failures
+}
+
+// BucketRateLimiter limits the overall rate of all the items with a TokenBucket. It doesn't track the items.
+type BucketRateLimiter[T any] struct {
+	bucket *TokenBucket
+}
+
+func NewBucketRateLimiter[T any](bucket *TokenBucket) *BucketRateLimiter[T] {
+	return &BucketRateLimiter[T]{bucket: bucket}
+}
+
+func (b *BucketRateLimiter[T]) When(item T) time.Duration {
+	return b.bucket.Reserve()
+}
+
+func (b *BucketRateLimiter[T]) Forget(item T) {}
+
+func (b *BucketRateLimiter[T]) NumRequeues(item T) int {
+	return 0
+}
+
+// MaxOfRateLimiter makes an item wait the longest time of all the limiters
+type MaxOfRateLimiter[T any] struct {
+	limiters []ItemRateLimiter[T]
+}
+
+func NewMaxOfRateLimiter[T any](limiters ...ItemRateLimiter[T]) *MaxOfRateLimiter[T] {
+	if len(limiters) == 0 {
+		panic(fmt.Errorf("at least one limiter is required"))
+	}
+
+	return &MaxOfRateLimiter[T]{limiters: limiters}
+}
+
+func (m *MaxOfRateLimiter[T]) When(item T) time.Duration {
+	var max time.Duration
+	for _, limiter := range m.limiters {
+		if delay := limiter.When(item); delay > max {
+			max = delay
+		}
+	}
+	return max
+}
+
+func (m *MaxOfRateLimiter[T]) Forget(item T) {
+	for _, limiter := range m.limiters {
+		limiter.Forget(item)
+	}
+}
+
+// NumRequeues returns the maximum NumRequeues of all the limiters
+func (m *MaxOfRateLimiter[T]) NumRequeues(item T) int {
+	var max int
+	for _, limiter := range m.limiters {
+		if requeues := limiter.NumRequeues(item); requeues > max {
+			max = requeues
+		}
+	}
+	return max
+}
+
+// RateLimitingChannel is a DelayingChannel that can add the items after the time decided by an ItemRateLimiter,
+// which is useful to retry the failed items with backoff.
+type RateLimitingChannel[T any] struct {
+	*DelayingChannel[T]
+	limiter ItemRateLimiter[T]
+}
+
+func NewRateLimitingChannel[T any](size int, limiter ItemRateLimiter[T],
+	opts ...DelayingOption) *RateLimitingChannel[T] {
+	return &RateLimitingChannel[T]{
+		DelayingChannel: NewDelayingChannel[T](size, opts...),
+		limiter:         limiter,
+	}
+}
+
+// AddRateLimited adds item after the time decided by the ItemRateLimiter
+func (r *RateLimitingChannel[T]) AddRateLimited(item T) {
+	r.AddAfter(item, r.limiter.When(item))
+}
+
+// Forget stops the ItemRateLimiter from tracking item. It should be called after item is processed successfully,
+// otherwise item waits longer and longer every time it's added with AddRateLimited.
+func (r *RateLimitingChannel[T]) Forget(item T) {
+	r.limiter.Forget(item)
+}
+
+// NumRequeues returns how many times item has been added with AddRateLimited since it's forgotten
+func (r *RateLimitingChannel[T]) NumRequeues(item T) int {
+	return r.limiter.NumRequeues(item)
+}
@@ -0,0 +1,88 @@
+package util_test
+
+import (
+	"time"
+
+	"github.com/linxiaokun528/go-kit/pkg/util"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func newStringExponentialRateLimiter(base, max time.Duration) *util.ItemExponentialRateLimiter[string] {
+	return util.NewItemExponentialRateLimiter[string, string](base, max, func(item string) string {
+		return item
+	}, func(first, second string) bool {
+		return first == second
+	})
+}
+
+var _ = Describe("ItemRateLimiter", func() {
+	It("backs off exponentially per item.", func() {
+		limiter := newStringExponentialRateLimiter(time.Millisecond, 5*time.Millisecond)
+		Expect(limiter.When("a")).To(Equal(time.Millisecond))
+		Expect(limiter.When("a")).To(Equal(2 * time.Millisecond))
+		Expect(limiter.When("b")).To(Equal(time.Millisecond))
+		Expect(limiter.When("a")).To(Equal(4 * time.Millisecond))
+		Expect(limiter.When("a")).To(Equal(5 * time.Millisecond))
+		Expect(limiter.NumRequeues("a")).To(Equal(4))
+
+		limiter.Forget("a")
+		Expect(limiter.NumRequeues("a")).To(Equal(0))
+		Expect(limiter.When("a")).To(Equal(time.Millisecond))
+		Expect(limiter.NumRequeues("b")).To(Equal(1))
+	})
+
+	It("limits the overall rate with a bucket.", func() {
+		fakeClock := clocktesting.NewFakeClock(time.Now())
+		limiter := util.NewBucketRateLimiter[string](util.NewTokenBucketWithClock(10, 1, fakeClock))
+		Expect(limiter.When("a")).To(BeZero())
+		Expect(limiter.When("b")).To(Equal(100 * time.Millisecond))
+		Expect(limiter.NumRequeues("a")).To(Equal(0))
+	})
+
+	It("takes the longest time of the limiters.", func() {
+		fakeClock := clocktesting.NewFakeClock(time.Now())
+		limiter := util.NewMaxOfRateLimiter[string](
+			newStringExponentialRateLimiter(time.Millisecond, time.Second),
+			util.NewBucketRateLimiter[string](util.NewTokenBucketWithClock(10, 2, fakeClock)))
+		Expect(limiter.When("a")).To(Equal(time.Millisecond))
+		Expect(limiter.When("a")).To(Equal(2 * time.Millisecond))
+		Expect(limiter.When("a")).To(Equal(100 * time.Millisecond))
+		Expect(limiter.NumRequeues("a")).To(Equal(3))
+
+		limiter.Forget("a")
+		Expect(limiter.NumRequeues("a")).To(Equal(0))
+	})
+
+	It("panics with invalid arguments.", func() {
+		Expect(func() { newStringExponentialRateLimiter(0, time.Second) }).To(Panic())
+		Expect(func() { newStringExponentialRateLimiter(time.Second, time.Millisecond) }).To(Panic())
+		Expect(func() { util.NewMaxOfRateLimiter[string]() }).To(Panic())
+	})
+})
+
+var _ = Describe("RateLimitingChannel", func() {
+	It("adds the items after the time decided by the limiter.", func() {
+		ch := util.NewRateLimitingChannel[string](5, newStringExponentialRateLimiter(50*time.Millisecond, time.Second))
+		defer ch.Close()
+
+		start := time.Now()
+		ch.AddRateLimited("a")
+		Expect(ch.Get()).To(Equal("a"))
+		Expect(time.Now()).To(BeTemporally("~", start.Add(50*time.Millisecond), 25*time.Millisecond))
+
+		start = time.Now()
+		ch.AddRateLimited("a")
+		Expect(ch.Get()).To(Equal("a"))
+		Expect(time.Now()).To(BeTemporally("~", start.Add(100*time.Millisecond), 25*time.Millisecond))
+		Expect(ch.NumRequeues("a")).To(Equal(2))
+
+		ch.Forget("a")
+		Expect(ch.NumRequeues("a")).To(Equal(0))
+		start = time.Now()
+		ch.AddRateLimited("a")
+		Expect(ch.Get()).To(Equal("a"))
+		Expect(time.Now()).To(BeTemporally("~", start.Add(50*time.Millisecond), 25*time.Millisecond))
+	})
+})
@@ -0,0 +1,145 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"k8s.io/utils/clock"
+)
+
+// RetryPolicy decides whether to retry after the attempt-th attempt fails with err, and how long to wait before the
+// next attempt. elapsed is the time since the first attempt started.
+// Policies can be composed with the methods, e.g.
+//  ExponentialBackoff(time.Millisecond, time.Second, 2, 0.5).WithMaxAttempts(5).RetryOn(isTemporary)
+type RetryPolicy func(attempt int, elapsed time.Duration, err error) (delay time.Duration, retry bool)
+
+// ConstantBackoff always retries after delay
+func ConstantBackoff(delay time.Duration) RetryPolicy {
+	if delay < 0 {
+		panic(fmt.Errorf("delay should be non-negative"))
+	}
+	return func(attempt int, elapsed time.Duration, err error) (time.Duration, bool) {
+		return delay, true
+	}
+}
+
+// ExponentialBackoff always retries. The delay starts from initial and is multiplied by multiplier after every
+// attempt, until it reaches max. With jitter in [0, 1], the delay is randomized in [delay * (1 - jitter), delay].
+func ExponentialBackoff(initial time.Duration, max time.Duration, multiplier float64, jitter float64) RetryPolicy {
+	if initial < 0 || max < initial {
+		panic(fmt.Errorf("0 <= initial <= max is required"))
+	}
+	if multiplier < 1 {
+		panic(fmt.Errorf("multiplier should be at least 1"))
+	}
+	if jitter < 0 || jitter > 1 {
+		panic(fmt.Errorf("jitter should be in [0, 1]"))
+	}
+
+	return func(attempt int, elapsed time.Duration, err error) (time.Duration, bool) {
+		delay := float64(initial)
+		for i := 1; i < attempt && delay < float64(max); i++ {
+			delay *= multiplier
+		}
+		if delay > float64(max) {
+			delay = float64(max)
+		}
+		delay -= delay * jitter * rand.Float64()
+		return time.Duration(delay), true
+	}
+}
+
+// WithMaxAttempts gives up after maxAttempts attempts, including the first one
+func (r RetryPolicy) WithMaxAttempts(maxAttempts int) RetryPolicy {
+	if maxAttempts <= 0 {
+		panic(fmt.Errorf("maxAttempts should be positive"))
+	}
+	return func(attempt int, elapsed time.Duration, err error) (time.Duration, bool) {
+		if attempt >= maxAttempts {
+			return 0, false
+		}
+		return r(attempt, elapsed, err)
+	}
+}
+
+// WithMaxElapsedTime gives up if the next attempt would start after maxElapsedTime since the first attempt
+func (r RetryPolicy) WithMaxElapsedTime(maxElapsedTime time.Duration) RetryPolicy {
+	return func(attempt int, elapsed time.Duration, err error) (time.Duration, bool) {
+		delay, retry := r(attempt, elapsed, err)
+		if elapsed+delay > maxElapsedTime {
+			return 0, false
+		}
+		return delay, retry
+	}
+}
+
+// RetryOn only retries the errors that match shouldRetry
+func (r RetryPolicy) RetryOn(shouldRetry func(err error) bool) RetryPolicy {
+	return func(attempt int, elapsed time.Duration, err error) (time.Duration, bool) {
+		if !shouldRetry(err) {
+			return 0, false
+		}
+		return r(attempt, elapsed, err)
+	}
+}
+
+type retryOptions struct {
+	clock clock.Clock
+}
+
+// RetryOption configures Retry
+type RetryOption func(*retryOptions)
+
+// WithRetryClock makes Retry measure the elapsed time and wait for the next attempt with clock.
+// The default is clock.RealClock{}.
+func WithRetryClock(clock clock.Clock) RetryOption {
+	if clock == nil {
+		panic(fmt.Errorf("clock should not be nil"))
+	}
+
+	return func(o *retryOptions) {
+		o.clock = clock
+	}
+}
+
+// Retry invokes f until it succeeds or policy gives up, in which case the last error of f will be returned.
+// If ctx is done while waiting for the next attempt, ctx.Err() will be returned.
+func Retry(ctx context.Context, policy RetryPolicy, f func(ctx context.Context) error, opts ...RetryOption) error {
+	options := retryOptions{clock: clock.RealClock{}}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	start := options.clock.Now()
+	for attempt := 1; ; attempt++ {
+		err := f(ctx)
+		if err == nil {
+			return nil
+		}
+
+		delay, retry := policy(attempt, options.clock.Since(start), err)
+		if !retry {
+			return err
+		}
+
+		timer := options.clock.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C():
+		}
+	}
+}
+
+// RetryingConsumer wraps consumerFunc so that a product is retried with policy before its error is returned.
+// The returned ConsumerFuncE can be used in NewParallelConsumingProcessorE.
+func RetryingConsumer[T any](consumerFunc ConsumerFuncE[T], policy RetryPolicy, opts ...RetryOption) ConsumerFuncE[T] {
+	return func(product T, ctx context.Context) error {
+		return Retry(ctx, policy, func(ctx context.Context) error {
+			return consumerFunc(product, ctx)
+		}, opts...)
+	}
+}
@@ -0,0 +1,143 @@
+package util_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/linxiaokun528/go-kit/pkg/util"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+var _ = Describe("Retry", func() {
+	errTemporary := errors.New("temporary")
+	errPermanent := errors.New("permanent")
+
+	failing := func(failures int, err error, attempts *int) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			*attempts++
+			if *attempts <= failures {
+				return err
+			}
+			return nil
+		}
+	}
+
+	It("retries until f succeeds.", func() {
+		attempts := 0
+		Expect(util.Retry(context.Background(), util.ConstantBackoff(time.Millisecond),
+			failing(3, errTemporary, &attempts))).To(Succeed())
+		Expect(attempts).To(Equal(4))
+	})
+
+	It("gives up after maxAttempts.", func() {
+		attempts := 0
+		err := util.Retry(context.Background(), util.ConstantBackoff(0).WithMaxAttempts(3),
+			failing(10, errTemporary, &attempts))
+		Expect(err).To(Equal(errTemporary))
+		Expect(attempts).To(Equal(3))
+	})
+
+	It("gives up after maxElapsedTime.", func() {
+		attempts := 0
+		err := util.Retry(context.Background(),
+			util.ConstantBackoff(20*time.Millisecond).WithMaxElapsedTime(50*time.Millisecond),
+			failing(10, errTemporary, &attempts))
+		Expect(err).To(Equal(errTemporary))
+		Expect(attempts).To(Equal(3))
+	})
+
+	It("only retries the errors that match the predicate.", func() {
+		policy := util.ConstantBackoff(0).RetryOn(func(err error) bool {
+			return errors.Is(err, errTemporary)
+		})
+
+		attempts := 0
+		Expect(util.Retry(context.Background(), policy, failing(2, errTemporary, &attempts))).To(Succeed())
+		Expect(attempts).To(Equal(3))
+
+		attempts = 0
+		Expect(util.Retry(context.Background(), policy, failing(2, errPermanent, &attempts))).
+			To(Equal(errPermanent))
+		Expect(attempts).To(Equal(1))
+	})
+
+	It("returns the error of ctx when ctx is done.", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		attempts := 0
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			cancel()
+		}()
+		err := util.Retry(ctx, util.ConstantBackoff(time.Hour), failing(10, errTemporary, &attempts))
+		Expect(err).To(Equal(context.Canceled))
+		Expect(attempts).To(Equal(1))
+	})
+
+	It("waits with the given clock.", func() {
+		fakeClock := clocktesting.NewFakeClock(time.Now())
+		attempts := 0
+		result := make(chan error, 1)
+		go func() {
+			result <- util.Retry(context.Background(),
+				util.ConstantBackoff(time.Hour).WithMaxElapsedTime(90*time.Minute),
+				failing(10, errTemporary, &attempts), util.WithRetryClock(fakeClock))
+		}()
+
+		Eventually(fakeClock.HasWaiters).Should(BeTrue())
+		Consistently(result).ShouldNot(Receive())
+		fakeClock.Step(time.Hour)
+		Eventually(result).Should(Receive(Equal(errTemporary)))
+		Expect(attempts).To(Equal(2))
+	})
+
+	It("backs off exponentially.", func() {
+		policy := util.ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond, 2, 0)
+		var delays []time.Duration
+		for attempt := 1; attempt <= 5; attempt++ {
+			delay, retry := policy(attempt, 0, errTemporary)
+			Expect(retry).To(BeTrue())
+			delays = append(delays, delay)
+		}
+		Expect(delays).To(Equal([]time.Duration{10 * time.Millisecond, 20 * time.Millisecond,
+			40 * time.Millisecond, 50 * time.Millisecond, 50 * time.Millisecond}))
+
+		jittered := util.ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond, 2, 0.5)
+		for i := 0; i < 100; i++ {
+			delay, _ := jittered(2, 0, errTemporary)
+			Expect(delay).To(BeNumerically(">=", 10*time.Millisecond))
+			Expect(delay).To(BeNumerically("<=", 20*time.Millisecond))
+		}
+	})
+
+	It("panics with invalid arguments.", func() {
+		Expect(func() { util.ConstantBackoff(-1) }).To(Panic())
+		Expect(func() { util.ExponentialBackoff(2, 1, 2, 0) }).To(Panic())
+		Expect(func() { util.ExponentialBackoff(1, 2, 0.5, 0) }).To(Panic())
+		Expect(func() { util.ExponentialBackoff(1, 2, 2, 2) }).To(Panic())
+		Expect(func() { util.ConstantBackoff(0).WithMaxAttempts(0) }).To(Panic())
+	})
+
+	It("retries the products in RetryingConsumer.", func() {
+		var produced int64
+		var attempts int64
+		processor := util.NewParallelConsumingProcessorE(func(ctx context.Context) int64 {
+			return atomic.AddInt64(&produced, 1)
+		}, util.RetryingConsumer(func(product int64, ctx context.Context) error {
+			if atomic.AddInt64(&attempts, 1)%3 != 0 {
+				return errTemporary
+			}
+			if product == 2 {
+				return errPermanent
+			}
+			return nil
+		}, util.ConstantBackoff(0).WithMaxAttempts(3)), doNothingHandler, util.StopOnFirstError, nil)
+
+		processor.Start(1, context.Background())
+		Expect(atomic.LoadInt64(&attempts)).To(BeEquivalentTo(6))
+		Expect(processor.Err()).To(Equal(errPermanent))
+	})
+})
@@ -0,0 +1,181 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Semaphore bounds the number of the goroutines in a section
+type Semaphore struct {
+	// ch holds a token for every acquirer
+	ch chan struct{}
+}
+
+func NewSemaphore(size int) *Semaphore {
+	if size <= 0 {
+		panic(fmt.Errorf("size should be positive"))
+	}
+	return &Semaphore{
+		ch: make(chan struct{}, size),
+	}
+}
+
+// Acquire blocks until the semaphore is acquired or ctx is done.
+// If ctx is done, ctx.Err() will be returned.
+func (s *Semaphore) Acquire(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case s.ch <- struct{}{}:
+		return nil
+	}
+}
+
+// TryAcquire returns false immediately if the semaphore is not available
+func (s *Semaphore) TryAcquire() bool {
+	select {
+	case s.ch <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Release panics if the semaphore is not acquired
+func (s *Semaphore) Release() {
+	select {
+	case <-s.ch:
+	default:
+		panic(fmt.Errorf("release a semaphore that is not acquired"))
+	}
+}
+
+type semaphoreWaiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+// WeightedSemaphore is a Semaphore whose acquirers can take different weights.
+// The waiting acquirers are served in order, so a heavy acquirer won't starve.
+type WeightedSemaphore struct {
+	size    int64
+	lock    sync.Mutex
+	current int64
+	waiters []*semaphoreWaiter
+}
+
+func NewWeightedSemaphore(size int64) *WeightedSemaphore {
+	if size <= 0 {
+		panic(fmt.Errorf("size should be positive"))
+	}
+	return &WeightedSemaphore{
+		size: size,
+	}
+}
+
+// Acquire blocks until the semaphore is acquired with weight n or ctx is done.
+// If ctx is done, ctx.Err() will be returned. If n is larger than the size, Acquire blocks until ctx is done.
+func (s *WeightedSemaphore) Acquire(ctx context.Context, n int64) error {
+	s.checkWeight(n)
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	if n > s.size {
+		// It can never be served, so don't block the waiters behind it
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	s.lock.Lock()
+	if s.size-s.current >= n && len(s.waiters) == 0 {
+		s.current += n
+		s.lock.Unlock()
+		return nil
+	}
+
+	waiter := &semaphoreWaiter{n: n, ready: make(chan struct{})}
+	s.waiters = append(s.waiters, waiter)
+	s.lock.Unlock()
+
+	select {
+	case <-waiter.ready:
+		return nil
+	case <-ctx.Done():
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
+		select {
+		case <-waiter.ready:
+			// Acquired after ctx is done. Pretend it's acquired before.
+			return nil
+		default:
+		}
+		for i, w := range s.waiters {
+			if w == waiter {
+				s.waiters = append(s.waiters[:i], s.waiters[i+1:]...)
+				break
+			}
+		}
+		// The waiters behind it may be able to go on now
+		s.notifyWaiters()
+		return ctx.Err()
+	}
+}
+
+// TryAcquire returns false immediately if the semaphore is not available for weight n
+func (s *WeightedSemaphore) TryAcquire(n int64) bool {
+	s.checkWeight(n)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.size-s.current >= n && len(s.waiters) == 0 {
+		s.current += n
+		return true
+	}
+	return false
+}
+
+// Release releases weight n. It panics if more weight is released than acquired.
+func (s *WeightedSemaphore) Release(n int64) {
+	s.checkWeight(n)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.current < n {
+		panic(fmt.Errorf("release more weight than acquired"))
+	}
+	s.current -= n
+	s.notifyWaiters()
+}
+
+// notifyWaiters should be called with s.lock held
+func (s *WeightedSemaphore) notifyWaiters() {
+	for len(s.waiters) > 0 {
+		waiter := s.waiters[0]
+		if s.size-s.current < waiter.n {
+			return
+		}
+		s.current += waiter.n
+		s.waiters = s.waiters[1:]
+		close(waiter.ready)
+	}
+}
+
+func (s *WeightedSemaphore) checkWeight(n int64) {
+	if n <= 0 {
+		panic(fmt.Errorf("the weight should be positive"))
+	}
+}
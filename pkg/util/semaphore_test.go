@@ -0,0 +1,162 @@
+package util_test
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/linxiaokun528/go-kit/pkg/util"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Semaphore", func() {
+	It("bounds the acquirers.", func() {
+		semaphore := util.NewSemaphore(2)
+		Expect(semaphore.Acquire(context.Background())).To(Succeed())
+		Expect(semaphore.TryAcquire()).To(BeTrue())
+		Expect(semaphore.TryAcquire()).To(BeFalse())
+
+		acquired := make(chan error)
+		go func() {
+			acquired <- semaphore.Acquire(context.Background())
+		}()
+		Consistently(acquired).ShouldNot(Receive())
+		semaphore.Release()
+		Eventually(acquired).Should(Receive(BeNil()))
+	})
+
+	It("returns the error of ctx.", func() {
+		semaphore := util.NewSemaphore(1)
+		Expect(semaphore.TryAcquire()).To(BeTrue())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		Expect(semaphore.Acquire(ctx)).To(Equal(context.DeadlineExceeded))
+	})
+
+	It("panics with invalid arguments.", func() {
+		Expect(func() { util.NewSemaphore(0) }).To(Panic())
+		Expect(func() { util.NewSemaphore(1).Release() }).To(Panic())
+	})
+})
+
+var _ = Describe("WeightedSemaphore", func() {
+	var semaphore *util.WeightedSemaphore
+
+	BeforeEach(func() {
+		semaphore = util.NewWeightedSemaphore(10)
+	})
+
+	It("bounds the total weight.", func() {
+		Expect(semaphore.Acquire(context.Background(), 6)).To(Succeed())
+		Expect(semaphore.TryAcquire(5)).To(BeFalse())
+		Expect(semaphore.TryAcquire(4)).To(BeTrue())
+
+		semaphore.Release(6)
+		Expect(semaphore.TryAcquire(6)).To(BeTrue())
+	})
+
+	It("serves the waiters in order.", func() {
+		Expect(semaphore.TryAcquire(10)).To(BeTrue())
+
+		heavy := make(chan error)
+		go func() {
+			heavy <- semaphore.Acquire(context.Background(), 8)
+		}()
+		Consistently(heavy).ShouldNot(Receive())
+
+		// A light acquirer can't jump the queue
+		Expect(semaphore.TryAcquire(1)).To(BeFalse())
+		light := make(chan error)
+		go func() {
+			light <- semaphore.Acquire(context.Background(), 2)
+		}()
+
+		semaphore.Release(5)
+		Consistently(heavy).ShouldNot(Receive())
+		Consistently(light).ShouldNot(Receive())
+
+		semaphore.Release(5)
+		Eventually(heavy).Should(Receive(BeNil()))
+		Eventually(light).Should(Receive(BeNil()))
+	})
+
+	It("lets the following waiters go on when a waiter gives up.", func() {
+		Expect(semaphore.TryAcquire(5)).To(BeTrue())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		heavy := make(chan error)
+		go func() {
+			heavy <- semaphore.Acquire(ctx, 10)
+		}()
+		Consistently(heavy).ShouldNot(Receive())
+
+		light := make(chan error)
+		go func() {
+			light <- semaphore.Acquire(context.Background(), 5)
+		}()
+		Consistently(light).ShouldNot(Receive())
+
+		cancel()
+		Eventually(heavy).Should(Receive(Equal(context.Canceled)))
+		Eventually(light).Should(Receive(BeNil()))
+	})
+
+	It("doesn't let an oversize acquirer block the others.", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		oversize := make(chan error)
+		go func() {
+			oversize <- semaphore.Acquire(ctx, 11)
+		}()
+		Consistently(oversize).ShouldNot(Receive())
+
+		Expect(semaphore.TryAcquire(1)).To(BeTrue())
+		Expect(semaphore.Acquire(context.Background(), 9)).To(Succeed())
+
+		cancel()
+		Eventually(oversize).Should(Receive(Equal(context.Canceled)))
+	})
+
+	It("panics with invalid arguments.", func() {
+		Expect(func() { util.NewWeightedSemaphore(0) }).To(Panic())
+		Expect(func() { semaphore.TryAcquire(0) }).To(Panic())
+		Expect(func() { semaphore.Release(1) }).To(Panic())
+	})
+})
+
+var _ = Describe("Processors with a Semaphore", func() {
+	It("bounds the invocations in flight across processors.", func() {
+		semaphore := util.NewSemaphore(2)
+		var inFlight int64
+		var maxInFlight int64
+		var invokedTime int64
+		loopFunc := func(ctx context.Context) bool {
+			current := atomic.AddInt64(&inFlight, 1)
+			for {
+				max := atomic.LoadInt64(&maxInFlight)
+				if current <= max || atomic.CompareAndSwapInt64(&maxInFlight, max, current) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt64(&inFlight, -1)
+			return atomic.AddInt64(&invokedTime, 1) < 50
+		}
+
+		first := util.NewParallelProcessor(loopFunc, doNothingHandler)
+		first.SetSemaphore(semaphore)
+		second := util.NewParallelConsumingProcessor(func(ctx context.Context) int {
+			return 0
+		}, func(product int, ctx context.Context) {
+			loopFunc(ctx)
+		}, doNothingHandler, util.WithSemaphore(semaphore))
+
+		first.StartAsync(3, context.Background())
+		second.StartAsync(3, context.Background())
+		first.Wait()
+		second.Stop()
+		second.Wait()
+		Expect(atomic.LoadInt64(&maxInFlight)).To(BeEquivalentTo(2))
+	})
+})
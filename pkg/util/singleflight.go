@@ -0,0 +1,74 @@
+package util
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/linxiaokun528/go-kit/pkg/util/collection"
+)
+
+type singleFlightCall[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// SingleFlight collapses the concurrent calls with the same key into one. The keys don't need to be comparable,
+// because they are stored in a collection.Map with a custom hasher.
+type SingleFlight[K any, V any] struct {
+	lock  sync.Mutex
+	calls collection.Map[K, *singleFlightCall[V]]
+}
+
+func NewSingleFlight[K any, V any, C comparable](hasher collection.Hasher[K, C],
+	equaler collection.Equaler[K]) *SingleFlight[K, V] {
+	return &SingleFlight[K, V]{
+		calls: collection.NewMap[K, *singleFlightCall[V], C](hasher, equaler),
+	}
+}
+
+// Do invokes fn, unless there is an ongoing call for the key, in which case it waits for the ongoing call and
+// returns its result with shared=true.
+// If fn panics, the panic is passed on to the caller that invokes fn, and the other callers get an error.
+func (s *SingleFlight[K, V]) Do(key K, fn func() (V, error)) (value V, err error, shared bool) {
+	s.lock.Lock()
+	if call, exists := s.calls.Get(key); exists {
+		s.lock.Unlock()
+		<-call.done
+		return call.value, call.err, true
+	}
+
+	call := &singleFlightCall[V]{done: make(chan struct{})}
+	s.calls.Put(key, call)
+	s.lock.Unlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			call.err = fmt.Errorf("the call panics: %v", r)
+			s.finish(key, call)
+			panic(r)
+		}
+	}()
+
+	call.value, call.err = fn()
+	s.finish(key, call)
+	return call.value, call.err, false
+}
+
+func (s *SingleFlight[K, V]) finish(key K, call *singleFlightCall[V]) {
+	s.lock.Lock()
+	// The call may have been forgotten and replaced by a new one
+	if current, exists := s.calls.Get(key); exists && current == call {
+		s.calls.Remove(key)
+	}
+	s.lock.Unlock()
+	close(call.done)
+}
+
+// Forget makes the following calls for the key invoke fn instead of waiting for the ongoing call
+func (s *SingleFlight[K, V]) Forget(key K) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.calls.Remove(key)
+}
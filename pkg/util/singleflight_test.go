@@ -0,0 +1,150 @@
+package util_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/linxiaokun528/go-kit/pkg/util"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// resourceKey is not comparable
+type resourceKey struct {
+	path []string
+}
+
+func newResourceSingleFlight() *util.SingleFlight[resourceKey, int] {
+	return util.NewSingleFlight[resourceKey, int, string](func(key resourceKey) string {
+		if len(key.path) == 0 {
+			return ""
+		}
+		return key.path[0]
+	}, func(original, new resourceKey) bool {
+		if len(original.path) != len(new.path) {
+			return false
+		}
+		for i := range original.path {
+			if original.path[i] != new.path[i] {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+var _ = Describe("SingleFlight", func() {
+	var singleFlight *util.SingleFlight[resourceKey, int]
+	var block chan struct{}
+	var invokedTime int64
+
+	BeforeEach(func() {
+		singleFlight = newResourceSingleFlight()
+		block = make(chan struct{})
+		invokedTime = 0
+	})
+
+	slowFn := func(value int, err error) func() (int, error) {
+		return func() (int, error) {
+			atomic.AddInt64(&invokedTime, 1)
+			<-block
+			return value, err
+		}
+	}
+
+	It("collapses the concurrent calls with the same key.", func() {
+		errFetch := errors.New("fetch failed")
+		wait := sync.WaitGroup{}
+		var shared int64
+		for i := 0; i < 10; i++ {
+			wait.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wait.Done()
+				value, err, isShared := singleFlight.Do(resourceKey{path: []string{"a", "b"}}, slowFn(1, errFetch))
+				Expect(value).To(Equal(1))
+				Expect(err).To(Equal(errFetch))
+				if isShared {
+					atomic.AddInt64(&shared, 1)
+				}
+			}()
+		}
+
+		Eventually(func() int64 { return atomic.LoadInt64(&invokedTime) }).Should(BeEquivalentTo(1))
+		// Give the other callers time to wait for the ongoing call
+		Consistently(func() int64 { return atomic.LoadInt64(&invokedTime) }).Should(BeEquivalentTo(1))
+		close(block)
+		wait.Wait()
+		Expect(atomic.LoadInt64(&shared)).To(BeEquivalentTo(9))
+
+		// The key is released after the call finishes
+		value, err, isShared := singleFlight.Do(resourceKey{path: []string{"a", "b"}}, func() (int, error) {
+			return 2, nil
+		})
+		Expect(value).To(Equal(2))
+		Expect(err).To(BeNil())
+		Expect(isShared).To(BeFalse())
+	})
+
+	It("doesn't collapse the calls with different keys.", func() {
+		close(block)
+		wait := sync.WaitGroup{}
+		for _, path := range [][]string{{"a"}, {"a", "b"}, {"c"}} {
+			wait.Add(1)
+			go func(path []string) {
+				defer wait.Done()
+				singleFlight.Do(resourceKey{path: path}, slowFn(0, nil))
+			}(path)
+		}
+		wait.Wait()
+		Expect(atomic.LoadInt64(&invokedTime)).To(BeEquivalentTo(3))
+	})
+
+	It("invokes fn again after Forget.", func() {
+		key := resourceKey{path: []string{"a"}}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			singleFlight.Do(key, slowFn(1, nil))
+		}()
+		Eventually(func() int64 { return atomic.LoadInt64(&invokedTime) }).Should(BeEquivalentTo(1))
+
+		singleFlight.Forget(key)
+		value, _, isShared := singleFlight.Do(key, func() (int, error) {
+			return 2, nil
+		})
+		Expect(value).To(Equal(2))
+		Expect(isShared).To(BeFalse())
+		close(block)
+		// Don't let it read the variables of the next spec
+		Eventually(done).Should(BeClosed())
+	})
+
+	It("passes on the panic to the caller that invokes fn.", func() {
+		key := resourceKey{path: []string{"a"}}
+		result := make(chan error)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			defer func() {
+				recover()
+			}()
+			singleFlight.Do(key, func() (int, error) {
+				atomic.AddInt64(&invokedTime, 1)
+				<-block
+				panic("panic for test")
+			})
+		}()
+		Eventually(func() int64 { return atomic.LoadInt64(&invokedTime) }).Should(BeEquivalentTo(1))
+
+		go func() {
+			_, err, _ := singleFlight.Do(key, slowFn(0, nil))
+			result <- err
+		}()
+		Consistently(result).ShouldNot(Receive())
+		close(block)
+		Eventually(result).Should(Receive(MatchError(ContainSubstring("panic for test"))))
+		Eventually(done).Should(BeClosed())
+	})
+})
@@ -0,0 +1,81 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Watchdog expects Kick to be called within timeout. When the deadline passes, onTimeout is invoked and the context
+// of the watchdog is canceled, which is useful to supervise a loopFunc that might hang.
+type Watchdog struct {
+	executor  *DelayingExecutor
+	timeout   time.Duration
+	onTimeout func()
+	cancel    context.CancelFunc
+
+	lock   sync.Mutex
+	handle *TaskHandle
+	// fired is true after the deadline passes
+	fired bool
+}
+
+// NewWatchdog returns a Watchdog and its context derived from ctx. The deadline is timeout later, and it's checked
+// by executor, which can be shared with other tasks. onTimeout is invoked in the goroutine of executor if it's not
+// nil. It panics if executor has been shut down.
+func NewWatchdog(ctx context.Context, executor *DelayingExecutor, timeout time.Duration,
+	onTimeout func()) (*Watchdog, context.Context) {
+	if timeout <= 0 {
+		panic(fmt.Errorf("timeout should be positive"))
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	watchdog := &Watchdog{
+		executor:  executor,
+		timeout:   timeout,
+		onTimeout: onTimeout,
+		cancel:    cancel,
+	}
+	watchdog.handle = executor.ExecuteAfter(watchdog.fire, timeout)
+	return watchdog, ctx
+}
+
+func (w *Watchdog) fire() {
+	w.lock.Lock()
+	w.fired = true
+	w.lock.Unlock()
+
+	w.cancel()
+	if w.onTimeout != nil {
+		w.onTimeout()
+	}
+}
+
+// Kick postpones the deadline to timeout later from now.
+// It returns false if the deadline has passed or the watchdog is stopped.
+func (w *Watchdog) Kick() bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	// Reschedule fails if the deadline is passing
+	return !w.fired && w.handle.Reschedule(w.timeout)
+}
+
+// Fired returns true if the deadline has passed
+func (w *Watchdog) Fired() bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return w.fired
+}
+
+// Stop stops the watchdog without invoking onTimeout, and cancels its context to release the resources.
+// It returns false if the deadline has passed or the watchdog has been stopped.
+func (w *Watchdog) Stop() bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.cancel()
+	return !w.fired && w.handle.Cancel()
+}
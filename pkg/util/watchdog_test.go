@@ -0,0 +1,73 @@
+package util_test
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/linxiaokun528/go-kit/pkg/util"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+var _ = Describe("Watchdog", func() {
+	var executor *util.DelayingExecutor
+
+	BeforeEach(func() {
+		executor = util.NewDelayingExecutor(5)
+	})
+
+	AfterEach(func() {
+		executor.ShutDownFast()
+	})
+
+	It("fires after the deadline.", func() {
+		fakeClock := clocktesting.NewFakeClock(time.Now())
+		executor.ShutDownFast()
+		executor = util.NewDelayingExecutor(5, util.WithClock(fakeClock))
+		var fired int64
+		watchdog, ctx := util.NewWatchdog(context.Background(), executor, time.Second, func() {
+			atomic.AddInt64(&fired, 1)
+		})
+
+		Eventually(fakeClock.HasWaiters).Should(BeTrue())
+		Expect(watchdog.Fired()).To(BeFalse())
+		fakeClock.Step(time.Second)
+
+		Eventually(ctx.Done()).Should(BeClosed())
+		Expect(watchdog.Fired()).To(BeTrue())
+		Eventually(func() int64 { return atomic.LoadInt64(&fired) }).Should(BeEquivalentTo(1))
+		Expect(watchdog.Kick()).To(BeFalse())
+		Expect(watchdog.Stop()).To(BeFalse())
+	})
+
+	It("postpones the deadline when kicked.", func() {
+		watchdog, ctx := util.NewWatchdog(context.Background(), executor, 100*time.Millisecond, nil)
+		for i := 0; i < 5; i++ {
+			time.Sleep(50 * time.Millisecond)
+			Expect(watchdog.Kick()).To(BeTrue())
+		}
+		Expect(ctx.Done()).NotTo(BeClosed())
+
+		start := time.Now()
+		Eventually(ctx.Done()).Should(BeClosed())
+		Expect(time.Now()).To(BeTemporally("~", start.Add(100*time.Millisecond), 50*time.Millisecond))
+	})
+
+	It("doesn't fire after stopped.", func() {
+		var fired int64
+		watchdog, ctx := util.NewWatchdog(context.Background(), executor, 50*time.Millisecond, func() {
+			atomic.AddInt64(&fired, 1)
+		})
+		Expect(watchdog.Stop()).To(BeTrue())
+		Expect(ctx.Done()).To(BeClosed())
+		Expect(watchdog.Kick()).To(BeFalse())
+		Consistently(func() int64 { return atomic.LoadInt64(&fired) }, 100*time.Millisecond).Should(BeZero())
+		Expect(watchdog.Fired()).To(BeFalse())
+	})
+
+	It("panics with a non-positive timeout.", func() {
+		Expect(func() { util.NewWatchdog(context.Background(), executor, 0, nil) }).To(Panic())
+	})
+})
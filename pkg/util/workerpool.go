@@ -0,0 +1,183 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrPoolShutDown is returned when submitting a task to a WorkerPool that has been shut down
+var ErrPoolShutDown = errors.New("the worker pool has been shut down")
+
+// Task is a function submitted to a WorkerPool. ctx is done when the pool is shut down forcibly.
+type Task func(ctx context.Context)
+
+// WorkerPool runs the submitted tasks with a fixed number of goroutines. The tasks are queued in a bounded queue,
+// so Submit blocks when the queue is full, which can be used for back-pressure.
+// Unlike ParallelProcessor, which keeps invoking the same LoopFunc, a WorkerPool runs ad-hoc tasks.
+type WorkerPool struct {
+	tasks        chan Task
+	panicHandler PanicHandler
+	// ctx is passed to the tasks, and is canceled when Shutdown gives up waiting
+	ctx    context.Context
+	cancel context.CancelFunc
+	wait   sync.WaitGroup
+	// closing is closed when Shutdown is called, which unblocks the pending Submits
+	closing     chan struct{}
+	closingOnce sync.Once
+	// stoppedCh is closed when all the workers exit. It's created by the first Shutdown, which closes tasks.
+	stoppedCh chan struct{}
+	// lock makes sure no task is sent to tasks after it's closed
+	lock   sync.RWMutex
+	closed bool
+}
+
+// NewWorkerPool starts workerNum goroutines. At most queueSize tasks can wait for an idle worker.
+// If a task panics, panicHandler will be invoked, and the worker goes on to run the next task.
+func NewWorkerPool(workerNum int, queueSize int, panicHandler PanicHandler) *WorkerPool {
+	if workerNum <= 0 {
+		panic(fmt.Errorf("workerNum should be positive"))
+	}
+	if queueSize < 0 {
+		panic(fmt.Errorf("queueSize should be non-negative"))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := &WorkerPool{
+		tasks:        make(chan Task, queueSize),
+		panicHandler: panicHandler,
+		ctx:          ctx,
+		cancel:       cancel,
+		closing:      make(chan struct{}),
+	}
+	pool.wait.Add(workerNum)
+	for i := 0; i < workerNum; i++ {
+		go pool.work()
+	}
+	return pool
+}
+
+func (p *WorkerPool) work() {
+	defer p.wait.Done()
+	for task := range p.tasks {
+		if p.ctx.Err() != nil {
+			// Shut down forcibly. Drop the queued tasks.
+			continue
+		}
+		p.run(task)
+	}
+}
+
+func (p *WorkerPool) run(task Task) {
+	defer func() {
+		recover() // in case a panic happens while handling panics
+	}()
+
+	if p.panicHandler != nil {
+		defer func() {
+			if r := recover(); r != nil {
+				p.panicHandler(r)
+			}
+		}()
+	}
+
+	task(p.ctx)
+}
+
+// Submit blocks until the task is queued. It returns ErrPoolShutDown if the pool has been shut down.
+// Don't call Submit in a task when the queue may be full, or the task may block forever.
+func (p *WorkerPool) Submit(task Task) error {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	if p.closed {
+		return ErrPoolShutDown
+	}
+
+	select {
+	case <-p.closing:
+		return ErrPoolShutDown
+	case p.tasks <- task:
+		return nil
+	}
+}
+
+// SubmitWait submits the task and blocks until it finishes.
+// It returns an error if the pool has been shut down, or the task panics or is dropped by Shutdown.
+func (p *WorkerPool) SubmitWait(task Task) error {
+	done := make(chan struct{})
+	finished := false
+	var recovered any
+	err := p.Submit(func(ctx context.Context) {
+		defer close(done)
+		defer func() {
+			if r := recover(); r != nil {
+				recovered = r
+				panic(r) // Let the panicHandler handle it
+			}
+		}()
+
+		task(ctx)
+		finished = true
+	})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-done:
+	case <-p.dropped():
+		// The task may have started right before the pool gave up waiting
+		<-p.stopped()
+		select {
+		case <-done:
+		default:
+			return fmt.Errorf("the task is dropped: %w", ErrPoolShutDown)
+		}
+	}
+	if !finished {
+		return fmt.Errorf("the task panics: %v", recovered)
+	}
+	return nil
+}
+
+// dropped is closed when the queued tasks are dropped by Shutdown
+func (p *WorkerPool) dropped() <-chan struct{} {
+	return p.ctx.Done()
+}
+
+// stopped is closed when all the workers exit. It should only be called after Shutdown is called.
+func (p *WorkerPool) stopped() <-chan struct{} {
+	return p.stoppedCh
+}
+
+// Shutdown stops accepting new tasks, and blocks until all the submitted tasks finish or ctx is done.
+// If ctx is done first, the ctx passed to the tasks will be canceled, the queued tasks will be dropped,
+// and ctx.Err() will be returned. Shutdown can be called multiple times.
+func (p *WorkerPool) Shutdown(ctx context.Context) error {
+	p.closingOnce.Do(func() {
+		close(p.closing)
+
+		// Wait for the pending Submits to return
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		p.closed = true
+		close(p.tasks)
+
+		p.stoppedCh = make(chan struct{})
+		go func() {
+			p.wait.Wait()
+			close(p.stoppedCh)
+		}()
+	})
+
+	select {
+	case <-p.stopped():
+		p.cancel()
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,137 @@
+package util_test
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/linxiaokun528/go-kit/pkg/util"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WorkerPool", func() {
+	var pool *util.WorkerPool
+	var panics int32
+
+	BeforeEach(func() {
+		panics = 0
+		pool = util.NewWorkerPool(4, 10, func(r any) {
+			atomic.AddInt32(&panics, 1)
+		})
+		DeferCleanup(func() {
+			pool.Shutdown(context.Background())
+		})
+	})
+
+	It("runs the submitted tasks concurrently.", func() {
+		var running int32
+		var maxRunning int32
+		var finished int32
+		for i := 0; i < 20; i++ {
+			Expect(pool.Submit(func(ctx context.Context) {
+				current := atomic.AddInt32(&running, 1)
+				for {
+					old := atomic.LoadInt32(&maxRunning)
+					if current <= old || atomic.CompareAndSwapInt32(&maxRunning, old, current) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				atomic.AddInt32(&finished, 1)
+			})).To(Succeed())
+		}
+
+		Expect(pool.Shutdown(context.Background())).To(Succeed())
+		Expect(atomic.LoadInt32(&finished)).To(Equal(int32(20)))
+		Expect(atomic.LoadInt32(&maxRunning)).To(BeNumerically("<=", 4))
+	})
+
+	It("can wait for a task.", func() {
+		result := 0
+		Expect(pool.SubmitWait(func(ctx context.Context) {
+			result = 1
+		})).To(Succeed())
+		Expect(result).To(Equal(1))
+	})
+
+	It("keeps working when the tasks panic.", func() {
+		err := pool.SubmitWait(func(ctx context.Context) {
+			panic("test")
+		})
+		Expect(err).To(MatchError(ContainSubstring("test")))
+		Expect(atomic.LoadInt32(&panics)).To(Equal(int32(1)))
+
+		Expect(pool.SubmitWait(func(ctx context.Context) {})).To(Succeed())
+	})
+
+	It("rejects the tasks after being shut down.", func() {
+		Expect(pool.Shutdown(context.Background())).To(Succeed())
+		Expect(pool.Submit(func(ctx context.Context) {})).To(MatchError(util.ErrPoolShutDown))
+		Expect(pool.SubmitWait(func(ctx context.Context) {})).To(MatchError(util.ErrPoolShutDown))
+		Expect(pool.Shutdown(context.Background())).To(Succeed())
+	})
+
+	It("cancels the tasks and drops the queued ones when Shutdown times out.", func() {
+		pool = util.NewWorkerPool(1, 10, nil)
+		var canceled int32
+		var ran int32
+		Expect(pool.Submit(func(ctx context.Context) {
+			<-ctx.Done()
+			atomic.StoreInt32(&canceled, 1)
+		})).To(Succeed())
+		for i := 0; i < 5; i++ {
+			Expect(pool.Submit(func(ctx context.Context) {
+				atomic.AddInt32(&ran, 1)
+			})).To(Succeed())
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		Expect(pool.Shutdown(ctx)).To(MatchError(context.DeadlineExceeded))
+		Eventually(func() int32 { return atomic.LoadInt32(&canceled) }).Should(Equal(int32(1)))
+		Expect(pool.Shutdown(context.Background())).To(Succeed())
+		Expect(atomic.LoadInt32(&ran)).To(Equal(int32(0)))
+	})
+
+	It("doesn't leak goroutines when Shutdown times out repeatedly.", func() {
+		pool = util.NewWorkerPool(1, 0, nil)
+		block := make(chan struct{})
+		defer close(block)
+		Expect(pool.Submit(func(ctx context.Context) { <-block })).To(Succeed())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		Expect(pool.Shutdown(ctx)).To(MatchError(context.Canceled))
+		goroutines := runtime.NumGoroutine()
+		for i := 0; i < 100; i++ {
+			Expect(pool.Shutdown(ctx)).To(MatchError(context.Canceled))
+		}
+		Expect(runtime.NumGoroutine()).To(BeNumerically("<", goroutines+10))
+	})
+
+	It("unblocks the pending Submits when shut down.", func() {
+		pool = util.NewWorkerPool(1, 0, nil)
+		block := make(chan struct{})
+		Expect(pool.Submit(func(ctx context.Context) {
+			<-block
+		})).To(Succeed())
+
+		submitted := make(chan error)
+		go func() {
+			submitted <- pool.Submit(func(ctx context.Context) {})
+		}()
+		Consistently(submitted, 50*time.Millisecond).ShouldNot(Receive())
+
+		go pool.Shutdown(context.Background())
+		Eventually(submitted).Should(Receive(MatchError(util.ErrPoolShutDown)))
+		close(block)
+	})
+
+	It("panics with invalid arguments.", func() {
+		Expect(func() { util.NewWorkerPool(0, 1, nil) }).To(Panic())
+		Expect(func() { util.NewWorkerPool(1, -1, nil) }).To(Panic())
+	})
+})